@@ -0,0 +1,256 @@
+// Package auth obtains Minecraft access tokens and profiles through the
+// Microsoft device-code flow and the Xbox Live token exchange.
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Endpoints struct {
+	DeviceCode   string
+	Token        string
+	XboxLive     string
+	XSTS         string
+	Minecraft    string
+	Profile      string
+	Certificates string
+}
+
+var DefaultEndpoints = Endpoints{
+	DeviceCode:   "https://login.microsoftonline.com/consumers/oauth2/v2.0/devicecode",
+	Token:        "https://login.microsoftonline.com/consumers/oauth2/v2.0/token",
+	XboxLive:     "https://user.auth.xboxlive.com/user/authenticate",
+	XSTS:         "https://xsts.auth.xboxlive.com/xsts/authorize",
+	Minecraft:    "https://api.minecraftservices.com/authentication/login_with_xbox",
+	Profile:      "https://api.minecraftservices.com/minecraft/profile",
+	Certificates: "https://api.minecraftservices.com/player/certificates",
+}
+
+const DefaultScope = "XboxLive.signin offline_access"
+
+var (
+	ErrMissingClientID       = errors.New("auth: client id is required")
+	ErrDeviceCodeExpired     = errors.New("auth: device code expired")
+	ErrAuthorizationDeclined = errors.New("auth: authorization declined")
+	ErrNoXboxAccount         = errors.New("auth: microsoft account has no xbox profile")
+	ErrXboxUnavailable       = errors.New("auth: xbox live is unavailable in this region")
+	ErrChildAccount          = errors.New("auth: child account must be added to a family")
+	ErrNoMinecraftProfile    = errors.New("auth: account does not own minecraft")
+)
+
+type Profile struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+type Account struct {
+	AccessToken string    `json:"accessToken"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+	Profile     Profile   `json:"profile"`
+}
+
+func (a *Account) Valid() bool {
+	return a != nil && a.AccessToken != "" && time.Until(a.ExpiresAt) > time.Minute
+}
+
+type DeviceCode struct {
+	UserCode        string `json:"user_code"`
+	DeviceCode      string `json:"device_code"`
+	VerificationURI string `json:"verification_uri"`
+	Message         string `json:"message"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+}
+
+type Client struct {
+	ClientID   string
+	Scope      string
+	Endpoints  Endpoints
+	HTTPClient *http.Client
+	Cache      Cache
+
+	// OnDeviceCode is called once the device code has been issued; the user
+	// must visit VerificationURI and enter UserCode to continue the login.
+	OnDeviceCode func(DeviceCode)
+}
+
+func NewClient(clientID string) *Client {
+	return &Client{
+		ClientID:  clientID,
+		Scope:     DefaultScope,
+		Endpoints: DefaultEndpoints,
+	}
+}
+
+// Login returns a Minecraft account, preferring a still valid cached token,
+// then a refresh of the cached Microsoft token and finally the device-code
+// flow.
+func (c *Client) Login(ctx context.Context) (*Account, error) {
+	if c.ClientID == "" {
+		return nil, ErrMissingClientID
+	}
+
+	var cached *CachedSession
+	if c.Cache != nil {
+		session, err := c.Cache.Load()
+		if err != nil {
+			return nil, fmt.Errorf("auth: load token cache: %w", err)
+		}
+		cached = session
+	}
+
+	if cached != nil && cached.Account.Valid() {
+		account := cached.Account
+		return &account, nil
+	}
+
+	var token *microsoftToken
+	if cached != nil && cached.RefreshToken != "" {
+		if refreshed, err := c.refreshToken(ctx, cached.RefreshToken); err == nil {
+			token = refreshed
+		}
+	}
+
+	if token == nil {
+		var err error
+		if token, err = c.deviceCodeLogin(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	account, err := c.LoginWithMicrosoftToken(ctx, token.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Cache != nil {
+		session := &CachedSession{RefreshToken: token.RefreshToken, Account: *account}
+		if err := c.Cache.Store(session); err != nil {
+			return nil, fmt.Errorf("auth: store token cache: %w", err)
+		}
+	}
+
+	return account, nil
+}
+
+// LoginWithMicrosoftToken exchanges a Microsoft OAuth access token for a
+// Minecraft account through Xbox Live and XSTS.
+func (c *Client) LoginWithMicrosoftToken(ctx context.Context, msAccessToken string) (*Account, error) {
+	xbl, err := c.xboxLiveToken(ctx, msAccessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	xsts, err := c.xstsToken(ctx, xbl.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := c.minecraftToken(ctx, xsts)
+	if err != nil {
+		return nil, err
+	}
+
+	if account.Profile, err = c.FetchProfile(ctx, account.AccessToken); err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+func (c *Client) FetchProfile(ctx context.Context, accessToken string) (Profile, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoints().Profile, nil)
+	if err != nil {
+		return Profile{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	var profile Profile
+	status, err := c.doJSON(req, &profile)
+	if status == http.StatusNotFound {
+		return Profile{}, ErrNoMinecraftProfile
+	}
+	if err != nil {
+		return Profile{}, fmt.Errorf("auth: fetch profile: %w", err)
+	}
+
+	return profile, nil
+}
+
+func (c *Client) endpoints() Endpoints {
+	if c.Endpoints == (Endpoints{}) {
+		return DefaultEndpoints
+	}
+	return c.Endpoints
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+func (c *Client) postJSON(ctx context.Context, url string, body, out any) (int, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	return c.doJSON(req, out)
+}
+
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d: %s", e.StatusCode, e.Body)
+}
+
+func (c *Client) doJSON(req *http.Request, out any) (int, error) {
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return resp.StatusCode, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		if out != nil {
+			_ = json.Unmarshal(data, out)
+		}
+		return resp.StatusCode, &StatusError{StatusCode: resp.StatusCode, Body: string(data)}
+	}
+
+	if out == nil {
+		return resp.StatusCode, nil
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return resp.StatusCode, fmt.Errorf("decode response: %w", err)
+	}
+
+	return resp.StatusCode, nil
+}
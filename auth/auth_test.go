@@ -0,0 +1,290 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type fakeServices struct {
+	*httptest.Server
+
+	polls     atomic.Int32
+	refreshes atomic.Int32
+	logins    atomic.Int32
+	xstsErr   int64
+	noProfile bool
+
+	certificates map[string]any
+}
+
+func newFakeServices(t *testing.T) *fakeServices {
+	t.Helper()
+
+	f := &fakeServices{}
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/devicecode", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"user_code":        "ABCD-EFGH",
+			"device_code":      "device",
+			"verification_uri": "https://example.invalid/link",
+			"expires_in":       60,
+			"interval":         1,
+		})
+	})
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		switch r.Form.Get("grant_type") {
+		case deviceCodeGrantType:
+			if f.polls.Add(1) == 1 {
+				w.WriteHeader(http.StatusBadRequest)
+				_ = json.NewEncoder(w).Encode(map[string]string{"error": "authorization_pending"})
+				return
+			}
+		case "refresh_token":
+			f.refreshes.Add(1)
+			if r.Form.Get("refresh_token") != "refresh" {
+				w.WriteHeader(http.StatusBadRequest)
+				_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+				return
+			}
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token":  "ms-token",
+			"refresh_token": "refresh",
+			"expires_in":    3600,
+		})
+	})
+
+	mux.HandleFunc("/xbl", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Properties struct{ RpsTicket string }
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Properties.RpsTicket != "d=ms-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"Token":         "xbl-token",
+			"DisplayClaims": map[string]any{"xui": []map[string]string{{"uhs": "userhash"}}},
+		})
+	})
+
+	mux.HandleFunc("/xsts", func(w http.ResponseWriter, r *http.Request) {
+		if f.xstsErr != 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]any{"XErr": f.xstsErr})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"Token":         "xsts-token",
+			"DisplayClaims": map[string]any{"xui": []map[string]string{{"uhs": "userhash"}}},
+		})
+	})
+
+	mux.HandleFunc("/minecraft", func(w http.ResponseWriter, r *http.Request) {
+		var body struct{ IdentityToken string }
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.IdentityToken != "XBL3.0 x=userhash;xsts-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		f.logins.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "mc-token", "expires_in": 86400})
+	})
+
+	mux.HandleFunc("/profile", func(w http.ResponseWriter, r *http.Request) {
+		if f.noProfile {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer mc-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"id": "069a79f444e94726a5befca90e38aaf5", "name": "Notch"})
+	})
+
+	mux.HandleFunc("/certificates", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Authorization") != "Bearer mc-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(f.certificates)
+	})
+
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+
+	return f
+}
+
+func (f *fakeServices) client(cachePath string) *Client {
+	c := NewClient("client-id")
+	c.Endpoints = Endpoints{
+		DeviceCode:   f.URL + "/devicecode",
+		Token:        f.URL + "/token",
+		XboxLive:     f.URL + "/xbl",
+		XSTS:         f.URL + "/xsts",
+		Minecraft:    f.URL + "/minecraft",
+		Profile:      f.URL + "/profile",
+		Certificates: f.URL + "/certificates",
+	}
+	if cachePath != "" {
+		c.Cache = NewFileCache(cachePath)
+	}
+	return c
+}
+
+func TestDeviceCodeLogin(t *testing.T) {
+	services := newFakeServices(t)
+	cachePath := filepath.Join(t.TempDir(), "tokens", "account.json")
+
+	c := services.client(cachePath)
+
+	var shownCode string
+	c.OnDeviceCode = func(code DeviceCode) { shownCode = code.UserCode }
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	account, err := c.Login(ctx)
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	if shownCode != "ABCD-EFGH" {
+		t.Fatalf("expected device code callback, got %q", shownCode)
+	}
+	if account.AccessToken != "mc-token" || account.Profile.Name != "Notch" {
+		t.Fatalf("unexpected account %+v", account)
+	}
+	if account.Profile.ID != uuid.MustParse("069a79f4-44e9-4726-a5be-fca90e38aaf5") {
+		t.Fatalf("unexpected profile id %s", account.Profile.ID)
+	}
+
+	cached, err := NewFileCache(cachePath).Load()
+	if err != nil || cached == nil {
+		t.Fatalf("expected cached session, got %v (%v)", cached, err)
+	}
+	if cached.RefreshToken != "refresh" {
+		t.Fatalf("expected refresh token to be cached, got %q", cached.RefreshToken)
+	}
+
+	again, err := services.client(cachePath).Login(ctx)
+	if err != nil {
+		t.Fatalf("cached Login failed: %v", err)
+	}
+	if again.AccessToken != "mc-token" || services.logins.Load() != 1 {
+		t.Fatalf("expected cached account without a new login, got %d logins", services.logins.Load())
+	}
+}
+
+func TestLoginRefreshesExpiredToken(t *testing.T) {
+	services := newFakeServices(t)
+	cachePath := filepath.Join(t.TempDir(), "account.json")
+
+	expired := &CachedSession{
+		RefreshToken: "refresh",
+		Account:      Account{AccessToken: "old", ExpiresAt: time.Now().Add(-time.Hour)},
+	}
+	if err := NewFileCache(cachePath).Store(expired); err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+
+	c := services.client(cachePath)
+	c.OnDeviceCode = func(DeviceCode) { t.Error("device code flow should not be used when refresh succeeds") }
+
+	account, err := c.Login(context.Background())
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	if account.AccessToken != "mc-token" {
+		t.Fatalf("unexpected access token %q", account.AccessToken)
+	}
+	if services.refreshes.Load() != 1 || services.polls.Load() != 0 {
+		t.Fatalf("expected a single refresh, got %d refreshes and %d polls", services.refreshes.Load(), services.polls.Load())
+	}
+}
+
+func TestLoginErrors(t *testing.T) {
+	services := newFakeServices(t)
+	services.xstsErr = xErrNoAccount
+
+	_, err := services.client("").LoginWithMicrosoftToken(context.Background(), "ms-token")
+	if !errors.Is(err, ErrNoXboxAccount) {
+		t.Fatalf("expected ErrNoXboxAccount, got %v", err)
+	}
+
+	services.xstsErr = 0
+	services.noProfile = true
+
+	_, err = services.client("").LoginWithMicrosoftToken(context.Background(), "ms-token")
+	if !errors.Is(err, ErrNoMinecraftProfile) {
+		t.Fatalf("expected ErrNoMinecraftProfile, got %v", err)
+	}
+
+	if _, err := NewClient("").Login(context.Background()); !errors.Is(err, ErrMissingClientID) {
+		t.Fatalf("expected ErrMissingClientID, got %v", err)
+	}
+}
+
+func TestFetchPlayerKey(t *testing.T) {
+	services := newFakeServices(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	privateDER, _ := x509.MarshalPKCS8PrivateKey(key)
+	publicDER, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	expiresAt := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Second)
+
+	services.certificates = map[string]any{
+		"keyPair": map[string]string{
+			"privateKey": string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: privateDER})),
+			"publicKey":  string(pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: publicDER})),
+		},
+		"publicKeySignature":   base64.StdEncoding.EncodeToString([]byte("v1")),
+		"publicKeySignatureV2": base64.StdEncoding.EncodeToString([]byte("v2")),
+		"expiresAt":            expiresAt.Format(time.RFC3339),
+	}
+
+	playerKey, err := services.client("").FetchPlayerKey(context.Background(), "mc-token")
+	if err != nil {
+		t.Fatalf("FetchPlayerKey failed: %v", err)
+	}
+
+	if !playerKey.PrivateKey.Equal(key) {
+		t.Fatalf("private key mismatch")
+	}
+	if !bytes.Equal(playerKey.PublicKey, publicDER) {
+		t.Fatalf("public key mismatch")
+	}
+	if string(playerKey.Signature) != "v1" || string(playerKey.SignatureV2) != "v2" {
+		t.Fatalf("unexpected signatures %q %q", playerKey.Signature, playerKey.SignatureV2)
+	}
+	if !playerKey.ExpiresAt.Equal(expiresAt) {
+		t.Fatalf("expected expiry %s, got %s", expiresAt, playerKey.ExpiresAt)
+	}
+}
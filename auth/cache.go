@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+type CachedSession struct {
+	RefreshToken string  `json:"refreshToken"`
+	Account      Account `json:"account"`
+}
+
+type Cache interface {
+	// Load returns the cached session, or nil when nothing has been stored.
+	Load() (*CachedSession, error)
+	Store(session *CachedSession) error
+}
+
+type FileCache struct {
+	Path string
+}
+
+func NewFileCache(path string) *FileCache {
+	return &FileCache{Path: path}
+}
+
+func (f *FileCache) Load() (*CachedSession, error) {
+	data, err := os.ReadFile(f.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var session CachedSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (f *FileCache) Store(session *CachedSession) error {
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0o700); err != nil {
+		return err
+	}
+
+	tmp := f.Path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, f.Path)
+}
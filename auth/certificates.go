@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/obeliskdev/gophermc/protocol"
+)
+
+type Certificates struct {
+	KeyPair struct {
+		PrivateKey string `json:"privateKey"`
+		PublicKey  string `json:"publicKey"`
+	} `json:"keyPair"`
+	PublicKeySignature   string    `json:"publicKeySignature"`
+	PublicKeySignatureV2 string    `json:"publicKeySignatureV2"`
+	ExpiresAt            time.Time `json:"expiresAt"`
+	RefreshedAfter       time.Time `json:"refreshedAfter"`
+}
+
+func (c *Client) FetchCertificates(ctx context.Context, accessToken string) (*Certificates, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoints().Certificates, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	var certificates Certificates
+	if _, err := c.doJSON(req, &certificates); err != nil {
+		return nil, fmt.Errorf("auth: fetch certificates: %w", err)
+	}
+
+	return &certificates, nil
+}
+
+func (c *Client) FetchPlayerKey(ctx context.Context, accessToken string) (*protocol.PlayerKey, error) {
+	certificates, err := c.FetchCertificates(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return certificates.PlayerKey()
+}
+
+// PlayerKey decodes the certificate bundle into a key usable for signing.
+// Despite their PEM headers, Mojang serves PKCS#8 private keys and X.509
+// public keys.
+func (c *Certificates) PlayerKey() (*protocol.PlayerKey, error) {
+	privateBlock, _ := pem.Decode([]byte(c.KeyPair.PrivateKey))
+	if privateBlock == nil {
+		return nil, errors.New("auth: certificate private key is not PEM encoded")
+	}
+
+	privateKey, err := parsePrivateKey(privateBlock.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	publicBlock, _ := pem.Decode([]byte(c.KeyPair.PublicKey))
+	if publicBlock == nil {
+		return nil, errors.New("auth: certificate public key is not PEM encoded")
+	}
+
+	if _, err := x509.ParsePKIXPublicKey(publicBlock.Bytes); err != nil {
+		return nil, fmt.Errorf("auth: parse certificate public key: %w", err)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(c.PublicKeySignature)
+	if err != nil {
+		return nil, fmt.Errorf("auth: decode public key signature: %w", err)
+	}
+
+	signatureV2, err := base64.StdEncoding.DecodeString(c.PublicKeySignatureV2)
+	if err != nil {
+		return nil, fmt.Errorf("auth: decode public key signature v2: %w", err)
+	}
+
+	return &protocol.PlayerKey{
+		PrivateKey:  privateKey,
+		PublicKey:   publicBlock.Bytes,
+		ExpiresAt:   c.ExpiresAt,
+		Signature:   signature,
+		SignatureV2: signatureV2,
+	}, nil
+}
+
+func parsePrivateKey(der []byte) (*rsa.PrivateKey, error) {
+	if key, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("auth: unexpected private key type %T", key)
+		}
+		return rsaKey, nil
+	}
+
+	key, err := x509.ParsePKCS1PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("auth: parse certificate private key: %w", err)
+	}
+
+	return key, nil
+}
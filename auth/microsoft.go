@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+type microsoftToken struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func (c *Client) scope() string {
+	if c.Scope == "" {
+		return DefaultScope
+	}
+	return c.Scope
+}
+
+func (c *Client) postForm(ctx context.Context, endpoint string, form url.Values, out any) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	return c.doJSON(req, out)
+}
+
+func (c *Client) requestDeviceCode(ctx context.Context) (*DeviceCode, error) {
+	var code DeviceCode
+	_, err := c.postForm(ctx, c.endpoints().DeviceCode, url.Values{
+		"client_id": {c.ClientID},
+		"scope":     {c.scope()},
+	}, &code)
+	if err != nil {
+		return nil, fmt.Errorf("auth: request device code: %w", err)
+	}
+
+	return &code, nil
+}
+
+func (c *Client) deviceCodeLogin(ctx context.Context) (*microsoftToken, error) {
+	code, err := c.requestDeviceCode(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.OnDeviceCode != nil {
+		c.OnDeviceCode(*code)
+	}
+
+	return c.pollDeviceCode(ctx, code)
+}
+
+// pollDeviceCode waits for the user to complete the device-code login and
+// returns the resulting Microsoft token.
+func (c *Client) pollDeviceCode(ctx context.Context, code *DeviceCode) (*microsoftToken, error) {
+	interval := time.Duration(code.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	var deadline <-chan time.Time
+	if code.ExpiresIn > 0 {
+		timer := time.NewTimer(time.Duration(code.ExpiresIn) * time.Second)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-deadline:
+			return nil, ErrDeviceCodeExpired
+		case <-time.After(interval):
+		}
+
+		var token microsoftToken
+		_, err := c.postForm(ctx, c.endpoints().Token, url.Values{
+			"client_id":   {c.ClientID},
+			"grant_type":  {deviceCodeGrantType},
+			"device_code": {code.DeviceCode},
+		}, &token)
+
+		switch token.Error {
+		case "":
+			if err != nil {
+				return nil, fmt.Errorf("auth: poll device code: %w", err)
+			}
+			return &token, nil
+		case "authorization_pending":
+			continue
+		case "slow_down":
+			interval += 5 * time.Second
+			continue
+		case "expired_token":
+			return nil, ErrDeviceCodeExpired
+		case "authorization_declined", "access_denied":
+			return nil, ErrAuthorizationDeclined
+		default:
+			return nil, fmt.Errorf("auth: poll device code: %s: %s", token.Error, token.ErrorDescription)
+		}
+	}
+}
+
+func (c *Client) refreshToken(ctx context.Context, refreshToken string) (*microsoftToken, error) {
+	var token microsoftToken
+	_, err := c.postForm(ctx, c.endpoints().Token, url.Values{
+		"client_id":     {c.ClientID},
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+		"scope":         {c.scope()},
+	}, &token)
+	if err != nil {
+		return nil, fmt.Errorf("auth: refresh token: %w", err)
+	}
+
+	return &token, nil
+}
@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	xErrNoAccount   = 2148916233
+	xErrUnavailable = 2148916235
+	xErrChild       = 2148916238
+)
+
+type xboxToken struct {
+	Token         string `json:"Token"`
+	DisplayClaims struct {
+		XUI []struct {
+			UHS string `json:"uhs"`
+		} `json:"xui"`
+	} `json:"DisplayClaims"`
+
+	XErr    int64  `json:"XErr"`
+	Message string `json:"Message"`
+}
+
+func (t *xboxToken) userHash() string {
+	if len(t.DisplayClaims.XUI) == 0 {
+		return ""
+	}
+	return t.DisplayClaims.XUI[0].UHS
+}
+
+func (c *Client) xboxLiveToken(ctx context.Context, msAccessToken string) (*xboxToken, error) {
+	var token xboxToken
+	_, err := c.postJSON(ctx, c.endpoints().XboxLive, map[string]any{
+		"Properties": map[string]any{
+			"AuthMethod": "RPS",
+			"SiteName":   "user.auth.xboxlive.com",
+			"RpsTicket":  "d=" + msAccessToken,
+		},
+		"RelyingParty": "http://auth.xboxlive.com",
+		"TokenType":    "JWT",
+	}, &token)
+	if err != nil {
+		return nil, fmt.Errorf("auth: xbox live authenticate: %w", err)
+	}
+
+	return &token, nil
+}
+
+func (c *Client) xstsToken(ctx context.Context, xblToken string) (*xboxToken, error) {
+	var token xboxToken
+	_, err := c.postJSON(ctx, c.endpoints().XSTS, map[string]any{
+		"Properties": map[string]any{
+			"SandboxId":  "RETAIL",
+			"UserTokens": []string{xblToken},
+		},
+		"RelyingParty": "rp://api.minecraftservices.com/",
+		"TokenType":    "JWT",
+	}, &token)
+
+	switch token.XErr {
+	case 0:
+	case xErrNoAccount:
+		return nil, ErrNoXboxAccount
+	case xErrUnavailable:
+		return nil, ErrXboxUnavailable
+	case xErrChild:
+		return nil, ErrChildAccount
+	default:
+		return nil, fmt.Errorf("auth: xsts authorize: XErr %d: %s", token.XErr, token.Message)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("auth: xsts authorize: %w", err)
+	}
+
+	return &token, nil
+}
+
+func (c *Client) minecraftToken(ctx context.Context, xsts *xboxToken) (*Account, error) {
+	var resp struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+
+	_, err := c.postJSON(ctx, c.endpoints().Minecraft, map[string]string{
+		"identityToken": fmt.Sprintf("XBL3.0 x=%s;%s", xsts.userHash(), xsts.Token),
+	}, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("auth: minecraft login: %w", err)
+	}
+
+	return &Account{
+		AccessToken: resp.AccessToken,
+		ExpiresAt:   time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second),
+	}, nil
+}
@@ -0,0 +1,523 @@
+// Package bungeecord encodes the messages of BungeeCord's plugin messaging
+// channel. Servers behind a BungeeCord or Velocity proxy send requests over
+// a player's connection and the proxy answers on the same connection;
+// proxies drop the messages when a client sends them.
+package bungeecord
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/google/uuid"
+	"github.com/obeliskdev/gophermc/protocol"
+)
+
+// Channel is the messaging channel from 1.13, LegacyChannel the one before.
+const (
+	Channel       = "bungeecord:main"
+	LegacyChannel = "BungeeCord"
+)
+
+// ChannelFor returns the channel name for v.
+func ChannelFor(v protocol.Version) string {
+	if v < protocol.V1_13 {
+		return LegacyChannel
+	}
+	return Channel
+}
+
+// AllServers targets every server in PlayerCount, PlayerList and Forward.
+// Forward also accepts OnlineServers, the servers with players on them.
+const (
+	AllServers    = "ALL"
+	OnlineServers = "ONLINE"
+)
+
+var ErrInvalidMessage = errors.New("bungeecord: invalid message")
+
+// Request is a message for the proxy.
+type Request interface {
+	Subchannel() string
+	encode(w *writer)
+}
+
+// Response is the proxy's answer to a Request, or a message forwarded from
+// another server.
+type Response interface {
+	Subchannel() string
+	encode(w *writer)
+}
+
+type (
+	// Connect sends the player to Server.
+	Connect struct{ Server string }
+	// ConnectOther sends Player to Server.
+	ConnectOther struct{ Player, Server string }
+	// IP asks for the player's address.
+	IP struct{}
+	// IPOther asks for Player's address.
+	IPOther struct{ Player string }
+	// PlayerCount asks for the number of players on Server, or AllServers.
+	PlayerCount struct{ Server string }
+	// PlayerList asks for the players on Server, or AllServers.
+	PlayerList struct{ Server string }
+	// GetServers asks for the names of all servers.
+	GetServers struct{}
+	// GetServer asks for the name of the server the player is on.
+	GetServer struct{}
+	// GetPlayerServer asks for the name of the server Player is on.
+	GetPlayerServer struct{ Player string }
+	// UUID asks for the player's UUID.
+	UUID struct{}
+	// UUIDOther asks for Player's UUID.
+	UUIDOther struct{ Player string }
+	// ServerIP asks for the address of Server.
+	ServerIP struct{ Server string }
+	// Message sends Player, or every player with AllServers, a chat message.
+	Message struct{ Player, Text string }
+	// MessageRaw sends Player a chat component in its JSON form.
+	MessageRaw struct{ Player, JSON string }
+	// Forward sends Data on Channel to Server, AllServers or OnlineServers.
+	// Servers receive it as a Forwarded response.
+	Forward struct {
+		Server  string
+		Channel string
+		Data    []byte
+	}
+	// ForwardToPlayer sends Data on Channel to the server Player is on.
+	ForwardToPlayer struct {
+		Player  string
+		Channel string
+		Data    []byte
+	}
+	// KickPlayer disconnects Player from the proxy.
+	KickPlayer struct{ Player, Reason string }
+)
+
+type (
+	IPResponse struct {
+		IP   string
+		Port int32
+	}
+	IPOtherResponse struct {
+		Player string
+		IP     string
+		Port   int32
+	}
+	PlayerCountResponse struct {
+		Server string
+		Count  int32
+	}
+	PlayerListResponse struct {
+		Server  string
+		Players []string
+	}
+	GetServersResponse      struct{ Servers []string }
+	GetServerResponse       struct{ Server string }
+	GetPlayerServerResponse struct {
+		Player string
+		Server string
+	}
+	UUIDResponse      struct{ UUID uuid.UUID }
+	UUIDOtherResponse struct {
+		Player string
+		UUID   uuid.UUID
+	}
+	ServerIPResponse struct {
+		Server string
+		IP     string
+		Port   uint16
+	}
+	// Forwarded is the data another server sent with Forward or
+	// ForwardToPlayer.
+	Forwarded struct {
+		Channel string
+		Data    []byte
+	}
+)
+
+func (Connect) Subchannel() string         { return "Connect" }
+func (ConnectOther) Subchannel() string    { return "ConnectOther" }
+func (IP) Subchannel() string              { return "IP" }
+func (IPOther) Subchannel() string         { return "IPOther" }
+func (PlayerCount) Subchannel() string     { return "PlayerCount" }
+func (PlayerList) Subchannel() string      { return "PlayerList" }
+func (GetServers) Subchannel() string      { return "GetServers" }
+func (GetServer) Subchannel() string       { return "GetServer" }
+func (GetPlayerServer) Subchannel() string { return "GetPlayerServer" }
+func (UUID) Subchannel() string            { return "UUID" }
+func (UUIDOther) Subchannel() string       { return "UUIDOther" }
+func (ServerIP) Subchannel() string        { return "ServerIP" }
+func (Message) Subchannel() string         { return "Message" }
+func (MessageRaw) Subchannel() string      { return "MessageRaw" }
+func (Forward) Subchannel() string         { return "Forward" }
+func (ForwardToPlayer) Subchannel() string { return "ForwardToPlayer" }
+func (KickPlayer) Subchannel() string      { return "KickPlayer" }
+
+func (IPResponse) Subchannel() string              { return "IP" }
+func (IPOtherResponse) Subchannel() string         { return "IPOther" }
+func (PlayerCountResponse) Subchannel() string     { return "PlayerCount" }
+func (PlayerListResponse) Subchannel() string      { return "PlayerList" }
+func (GetServersResponse) Subchannel() string      { return "GetServers" }
+func (GetServerResponse) Subchannel() string       { return "GetServer" }
+func (GetPlayerServerResponse) Subchannel() string { return "GetPlayerServer" }
+func (UUIDResponse) Subchannel() string            { return "UUID" }
+func (UUIDOtherResponse) Subchannel() string       { return "UUIDOther" }
+func (ServerIPResponse) Subchannel() string        { return "ServerIP" }
+func (f Forwarded) Subchannel() string             { return f.Channel }
+
+func (m Connect) encode(w *writer) {
+	w.utf(m.Server)
+}
+
+func (m ConnectOther) encode(w *writer) {
+	w.utf(m.Player)
+	w.utf(m.Server)
+}
+
+func (IP) encode(*writer) {}
+
+func (m IPOther) encode(w *writer) {
+	w.utf(m.Player)
+}
+
+func (m PlayerCount) encode(w *writer) {
+	w.utf(m.Server)
+}
+
+func (m PlayerList) encode(w *writer) {
+	w.utf(m.Server)
+}
+
+func (GetServers) encode(*writer) {}
+
+func (GetServer) encode(*writer) {}
+
+func (m GetPlayerServer) encode(w *writer) {
+	w.utf(m.Player)
+}
+
+func (UUID) encode(*writer) {}
+
+func (m UUIDOther) encode(w *writer) {
+	w.utf(m.Player)
+}
+
+func (m ServerIP) encode(w *writer) {
+	w.utf(m.Server)
+}
+
+func (m Message) encode(w *writer) {
+	w.utf(m.Player)
+	w.utf(m.Text)
+}
+
+func (m MessageRaw) encode(w *writer) {
+	w.utf(m.Player)
+	w.utf(m.JSON)
+}
+
+func (m Forward) encode(w *writer) {
+	w.utf(m.Server)
+	w.utf(m.Channel)
+	w.data(m.Data)
+}
+
+func (m ForwardToPlayer) encode(w *writer) {
+	w.utf(m.Player)
+	w.utf(m.Channel)
+	w.data(m.Data)
+}
+
+func (m KickPlayer) encode(w *writer) {
+	w.utf(m.Player)
+	w.utf(m.Reason)
+}
+
+func (m IPResponse) encode(w *writer) {
+	w.utf(m.IP)
+	w.int(m.Port)
+}
+
+func (m IPOtherResponse) encode(w *writer) {
+	w.utf(m.Player)
+	w.utf(m.IP)
+	w.int(m.Port)
+}
+
+func (m PlayerCountResponse) encode(w *writer) {
+	w.utf(m.Server)
+	w.int(m.Count)
+}
+
+func (m PlayerListResponse) encode(w *writer) {
+	w.utf(m.Server)
+	w.utf(strings.Join(m.Players, ", "))
+}
+
+func (m GetServersResponse) encode(w *writer) {
+	w.utf(strings.Join(m.Servers, ", "))
+}
+
+func (m GetServerResponse) encode(w *writer) {
+	w.utf(m.Server)
+}
+
+func (m GetPlayerServerResponse) encode(w *writer) {
+	w.utf(m.Player)
+	w.utf(m.Server)
+}
+
+func (m UUIDResponse) encode(w *writer) {
+	w.utf(dashless(m.UUID))
+}
+
+func (m UUIDOtherResponse) encode(w *writer) {
+	w.utf(m.Player)
+	w.utf(dashless(m.UUID))
+}
+
+func (m ServerIPResponse) encode(w *writer) {
+	w.utf(m.Server)
+	w.utf(m.IP)
+	w.short(m.Port)
+}
+
+func (m Forwarded) encode(w *writer) {
+	w.data(m.Data)
+}
+
+// EncodeRequest returns the plugin message data for r.
+func EncodeRequest(r Request) []byte {
+	return encode(r.Subchannel(), r.encode)
+}
+
+// EncodeResponse returns the plugin message data for r.
+func EncodeResponse(r Response) []byte {
+	return encode(r.Subchannel(), r.encode)
+}
+
+func encode(subchannel string, body func(*writer)) []byte {
+	w := &writer{}
+	w.utf(subchannel)
+	body(w)
+	return w.Bytes()
+}
+
+// DecodeRequest reads a request sent to the proxy.
+func DecodeRequest(data []byte) (Request, error) {
+	r := &reader{Reader: bytes.NewReader(data)}
+	subchannel := r.utf()
+
+	var m Request
+	switch subchannel {
+	case "Connect":
+		m = Connect{Server: r.utf()}
+	case "ConnectOther":
+		m = ConnectOther{Player: r.utf(), Server: r.utf()}
+	case "IP":
+		m = IP{}
+	case "IPOther":
+		m = IPOther{Player: r.utf()}
+	case "PlayerCount":
+		m = PlayerCount{Server: r.utf()}
+	case "PlayerList":
+		m = PlayerList{Server: r.utf()}
+	case "GetServers":
+		m = GetServers{}
+	case "GetServer":
+		m = GetServer{}
+	case "GetPlayerServer":
+		m = GetPlayerServer{Player: r.utf()}
+	case "UUID":
+		m = UUID{}
+	case "UUIDOther":
+		m = UUIDOther{Player: r.utf()}
+	case "ServerIP":
+		m = ServerIP{Server: r.utf()}
+	case "Message":
+		m = Message{Player: r.utf(), Text: r.utf()}
+	case "MessageRaw":
+		m = MessageRaw{Player: r.utf(), JSON: r.utf()}
+	case "Forward":
+		m = Forward{Server: r.utf(), Channel: r.utf(), Data: r.data()}
+	case "ForwardToPlayer":
+		m = ForwardToPlayer{Player: r.utf(), Channel: r.utf(), Data: r.data()}
+	case "KickPlayer":
+		m = KickPlayer{Player: r.utf(), Reason: r.utf()}
+	default:
+		if r.err == nil {
+			return nil, fmt.Errorf("%w: unknown subchannel %q", ErrInvalidMessage, subchannel)
+		}
+	}
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	return m, nil
+}
+
+// DecodeResponse reads a message from the proxy. Subchannels that are not
+// responses are read as Forwarded data.
+func DecodeResponse(data []byte) (Response, error) {
+	r := &reader{Reader: bytes.NewReader(data)}
+	subchannel := r.utf()
+
+	var m Response
+	switch subchannel {
+	case "IP":
+		m = IPResponse{IP: r.utf(), Port: r.int()}
+	case "IPOther":
+		m = IPOtherResponse{Player: r.utf(), IP: r.utf(), Port: r.int()}
+	case "PlayerCount":
+		m = PlayerCountResponse{Server: r.utf(), Count: r.int()}
+	case "PlayerList":
+		m = PlayerListResponse{Server: r.utf(), Players: csv(r.utf())}
+	case "GetServers":
+		m = GetServersResponse{Servers: csv(r.utf())}
+	case "GetServer":
+		m = GetServerResponse{Server: r.utf()}
+	case "GetPlayerServer":
+		m = GetPlayerServerResponse{Player: r.utf(), Server: r.utf()}
+	case "UUID":
+		m = UUIDResponse{UUID: r.uuid()}
+	case "UUIDOther":
+		m = UUIDOtherResponse{Player: r.utf(), UUID: r.uuid()}
+	case "ServerIP":
+		m = ServerIPResponse{Server: r.utf(), IP: r.utf(), Port: r.short()}
+	default:
+		m = Forwarded{Channel: subchannel, Data: r.data()}
+	}
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	return m, nil
+}
+
+func csv(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(s, ", ")
+}
+
+func dashless(id uuid.UUID) string {
+	return strings.ReplaceAll(id.String(), "-", "")
+}
+
+// writer writes Java's DataOutput encoding.
+type writer struct{ bytes.Buffer }
+
+// utf writes s in the modified UTF-8 of DataOutput.writeUTF, after its
+// length as an unsigned short.
+func (w *writer) utf(s string) {
+	var encoded []byte
+	for _, c := range utf16.Encode([]rune(s)) {
+		switch {
+		case c != 0 && c < 0x80:
+			encoded = append(encoded, byte(c))
+		case c < 0x800:
+			encoded = append(encoded, 0xC0|byte(c>>6), 0x80|byte(c&0x3F))
+		default:
+			encoded = append(encoded, 0xE0|byte(c>>12), 0x80|byte(c>>6&0x3F), 0x80|byte(c&0x3F))
+		}
+	}
+	w.short(uint16(len(encoded)))
+	w.Write(encoded)
+}
+
+func (w *writer) data(data []byte) {
+	w.short(uint16(len(data)))
+	w.Write(data)
+}
+
+func (w *writer) short(v uint16) { _ = binary.Write(w, binary.BigEndian, v) }
+func (w *writer) int(v int32)    { _ = binary.Write(w, binary.BigEndian, v) }
+
+// reader reads what writer writes, keeping the first error.
+type reader struct {
+	*bytes.Reader
+	err error
+}
+
+func (r *reader) fail(err error) {
+	if r.err == nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			err = fmt.Errorf("%w: %w", ErrInvalidMessage, io.ErrUnexpectedEOF)
+		}
+		r.err = err
+	}
+}
+
+func (r *reader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r.Reader, b); err != nil {
+		r.fail(err)
+		return nil
+	}
+	return b
+}
+
+func (r *reader) short() uint16 {
+	b := r.bytes(2)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint16(b)
+}
+
+func (r *reader) int() int32 {
+	b := r.bytes(4)
+	if b == nil {
+		return 0
+	}
+	return int32(binary.BigEndian.Uint32(b))
+}
+
+func (r *reader) data() []byte {
+	return r.bytes(int(r.short()))
+}
+
+func (r *reader) utf() string {
+	b := r.data()
+
+	var chars []uint16
+	for i := 0; i < len(b); {
+		switch c := b[i]; {
+		case c < 0x80:
+			chars = append(chars, uint16(c))
+			i++
+		case c&0xE0 == 0xC0 && i+1 < len(b):
+			chars = append(chars, uint16(c&0x1F)<<6|uint16(b[i+1]&0x3F))
+			i += 2
+		case c&0xF0 == 0xE0 && i+2 < len(b):
+			chars = append(chars, uint16(c&0x0F)<<12|uint16(b[i+1]&0x3F)<<6|uint16(b[i+2]&0x3F))
+			i += 3
+		default:
+			r.fail(fmt.Errorf("%w: malformed string", ErrInvalidMessage))
+			return ""
+		}
+	}
+	return string(utf16.Decode(chars))
+}
+
+func (r *reader) uuid() uuid.UUID {
+	s := r.utf()
+	if r.err != nil {
+		return uuid.Nil
+	}
+	id, err := uuid.Parse(s)
+	if err != nil {
+		r.fail(fmt.Errorf("%w: %w", ErrInvalidMessage, err))
+	}
+	return id
+}
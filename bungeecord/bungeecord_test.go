@@ -0,0 +1,85 @@
+package bungeecord
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestEncodeRequest(t *testing.T) {
+	want := []byte("\x00\x07Connect\x00\x05lobby")
+	if got := EncodeRequest(Connect{Server: "lobby"}); !bytes.Equal(got, want) {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+
+	for _, request := range []Request{
+		Connect{Server: "lobby"},
+		ConnectOther{Player: "Steve", Server: "lobby"},
+		IP{},
+		IPOther{Player: "Steve"},
+		PlayerCount{Server: AllServers},
+		PlayerList{Server: "lobby"},
+		GetServers{},
+		GetServer{},
+		GetPlayerServer{Player: "Steve"},
+		UUID{},
+		UUIDOther{Player: "Steve"},
+		ServerIP{Server: "lobby"},
+		Message{Player: AllServers, Text: "héllo \x00 wörld 🎉"},
+		MessageRaw{Player: "Steve", JSON: `{"text":"hi"}`},
+		Forward{Server: OnlineServers, Channel: "example", Data: []byte{1, 2, 3}},
+		ForwardToPlayer{Player: "Steve", Channel: "example", Data: []byte{}},
+		KickPlayer{Player: "Steve", Reason: "bye"},
+	} {
+		got, err := DecodeRequest(EncodeRequest(request))
+		if err != nil || !reflect.DeepEqual(got, request) {
+			t.Errorf("expected %#v, got %#v (%v)", request, got, err)
+		}
+	}
+}
+
+func TestDecodeResponse(t *testing.T) {
+	id := uuid.MustParse("069a79f4-44e9-4726-a5be-fca90e38aaf5")
+
+	data := EncodeResponse(UUIDResponse{UUID: id})
+	if !bytes.Contains(data, []byte("069a79f444e94726a5befca90e38aaf5")) {
+		t.Fatalf("expected a dashless uuid in %q", data)
+	}
+
+	for _, response := range []Response{
+		IPResponse{IP: "203.0.113.7", Port: 51234},
+		IPOtherResponse{Player: "Steve", IP: "203.0.113.7", Port: 51234},
+		PlayerCountResponse{Server: AllServers, Count: 42},
+		PlayerListResponse{Server: "lobby", Players: []string{"Steve", "Alex"}},
+		PlayerListResponse{Server: "empty", Players: []string{}},
+		GetServersResponse{Servers: []string{"lobby", "survival"}},
+		GetServerResponse{Server: "lobby"},
+		GetPlayerServerResponse{Player: "Steve", Server: "lobby"},
+		UUIDResponse{UUID: id},
+		UUIDOtherResponse{Player: "Steve", UUID: id},
+		ServerIPResponse{Server: "lobby", IP: "10.0.0.2", Port: 25565},
+		Forwarded{Channel: "example", Data: []byte("payload")},
+	} {
+		got, err := DecodeResponse(EncodeResponse(response))
+		if err != nil || !reflect.DeepEqual(got, response) {
+			t.Errorf("expected %#v, got %#v (%v)", response, got, err)
+		}
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	data := EncodeRequest(ConnectOther{Player: "Steve", Server: "lobby"})
+
+	if _, err := DecodeRequest(data[:len(data)-2]); !errors.Is(err, ErrInvalidMessage) {
+		t.Errorf("expected a truncated request to fail, got %v", err)
+	}
+	if _, err := DecodeRequest(EncodeResponse(Forwarded{Channel: "example"})); !errors.Is(err, ErrInvalidMessage) {
+		t.Errorf("expected an unknown subchannel to fail, got %v", err)
+	}
+	if _, err := DecodeResponse([]byte{0x00}); !errors.Is(err, ErrInvalidMessage) {
+		t.Errorf("expected a short response to fail, got %v", err)
+	}
+}
@@ -0,0 +1,394 @@
+package gophermc_test
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"github.com/obeliskdev/gophermc"
+	"github.com/obeliskdev/gophermc/component"
+	"github.com/obeliskdev/gophermc/protocol"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func newTestPlayerKey(t *testing.T) *protocol.PlayerKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	publicKey, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+
+	return &protocol.PlayerKey{
+		PrivateKey:  key,
+		PublicKey:   publicKey,
+		ExpiresAt:   time.Now().Add(time.Hour),
+		Signature:   []byte("signature-v1"),
+		SignatureV2: []byte("signature-v2"),
+	}
+}
+
+func TestSecureChatSession(t *testing.T) {
+	playerKey := newTestPlayerKey(t)
+	profileID := uuid.New()
+
+	server := newFakeServer(t, protocol.V1_19_4)
+
+	type result struct {
+		session *protocol.ServerboundChatSessionUpdate
+		chat    *protocol.ServerboundChatMessage
+		err     error
+	}
+	resultChan := make(chan result, 1)
+
+	go func() {
+		var res result
+		defer func() { resultChan <- res }()
+
+		if _, res.err = server.offlineLogin(); res.err != nil {
+			return
+		}
+
+		res.session = &protocol.ServerboundChatSessionUpdate{}
+		if res.err = server.expectPacket(protocol.StatePlay, res.session); res.err != nil {
+			return
+		}
+
+		res.chat = &protocol.ServerboundChatMessage{}
+		res.err = server.expectPacket(protocol.StatePlay, res.chat)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := gophermc.NewClient(
+		gophermc.WithAddr(server.Addr()),
+		gophermc.WithVersion(protocol.V1_19_4),
+		gophermc.WithUUID(profileID),
+		gophermc.WithPlayerKey(playerKey),
+	)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.Join(ctx); err != nil {
+		t.Fatalf("Join failed: %v", err)
+	}
+
+	if err := client.Chat("hello secure world"); err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+
+	res := <-resultChan
+	if res.err != nil {
+		t.Fatalf("fake server failed: %v", res.err)
+	}
+
+	if !bytes.Equal(res.session.PublicKey, playerKey.PublicKey) || string(res.session.KeySignature) != "signature-v2" {
+		t.Fatalf("unexpected chat session %+v", res.session)
+	}
+
+	var data bytes.Buffer
+	_ = binary.Write(&data, binary.BigEndian, int32(1))
+	data.Write(profileID[:])
+	data.Write(res.session.SessionID[:])
+	_ = binary.Write(&data, binary.BigEndian, int32(0))
+	_ = binary.Write(&data, binary.BigEndian, res.chat.Salt)
+	_ = binary.Write(&data, binary.BigEndian, res.chat.Timestamp.Unix())
+	_ = binary.Write(&data, binary.BigEndian, int32(len(res.chat.Message)))
+	data.WriteString(res.chat.Message)
+	_ = binary.Write(&data, binary.BigEndian, int32(0))
+
+	hash := sha256.Sum256(data.Bytes())
+	if err := rsa.VerifyPKCS1v15(&playerKey.PrivateKey.PublicKey, crypto.SHA256, hash[:], res.chat.Signature); err != nil {
+		t.Fatalf("chat signature does not verify: %v", err)
+	}
+}
+
+func TestHeaderedChatChain(t *testing.T) {
+	playerKey := newTestPlayerKey(t)
+	profileID := uuid.New()
+	other := protocol.LastSeenMessage{Sender: uuid.New(), Signature: bytes.Repeat([]byte{7}, 256)}
+
+	server := newFakeServer(t, protocol.V1_19_2)
+
+	chats := make(chan *protocol.ServerboundChatMessage, 2)
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- func() error {
+			if _, err := server.offlineLogin(); err != nil {
+				return err
+			}
+
+			chat := &protocol.ClientboundPlayerChat{Sender: other.Sender, Signature: other.Signature, Message: "hi", SenderName: component.Text("Alex")}
+			if err := server.writePacket(protocol.StatePlay, chat); err != nil {
+				return err
+			}
+
+			chatID, _ := protocol.GetPacketID(protocol.V1_19_2, protocol.StatePlay, protocol.DirectionServerbound, &protocol.ServerboundChatMessage{})
+			for len(chats) < cap(chats) {
+				id, body, err := server.readPacket()
+				if err != nil {
+					return err
+				}
+				if id != chatID {
+					continue
+				}
+
+				chat := &protocol.ServerboundChatMessage{}
+				if err := chat.Decode(body, protocol.V1_19_2); err != nil {
+					return err
+				}
+				chats <- chat
+			}
+			return nil
+		}()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := gophermc.NewClient(
+		gophermc.WithAddr(server.Addr()),
+		gophermc.WithVersion(protocol.V1_19_2),
+		gophermc.WithUUID(profileID),
+		gophermc.WithPlayerKey(playerKey),
+	)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Destroy()
+
+	events, err := client.JoinAndListen(ctx, 10)
+	if err != nil {
+		t.Fatalf("JoinAndListen failed: %v", err)
+	}
+
+	for received := false; !received; {
+		select {
+		case event := <-events:
+			_, received = event.(gophermc.ChatMessageEvent)
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for the chat message")
+		}
+	}
+
+	for _, message := range []string{"first", "second"} {
+		if err := client.Chat(message); err != nil {
+			t.Fatalf("Chat failed: %v", err)
+		}
+	}
+
+	if err := <-errChan; err != nil {
+		t.Fatalf("fake server failed: %v", err)
+	}
+	first, second := <-chats, <-chats
+
+	if len(first.LastSeen) != 1 || first.LastSeen[0].Sender != other.Sender || !bytes.Equal(first.LastSeen[0].Signature, other.Signature) {
+		t.Fatalf("expected the received message to be seen, got %+v", first.LastSeen)
+	}
+	if first.LastReceived == nil || first.LastReceived.Sender != other.Sender {
+		t.Fatalf("expected the received message as last received, got %+v", first.LastReceived)
+	}
+	if len(second.LastSeen) != 1 || second.LastReceived != nil {
+		t.Fatalf("expected the second message to only keep the seen list, got %+v %+v", second.LastSeen, second.LastReceived)
+	}
+
+	// Each header chains to the signature of the previous message.
+	var previous []byte
+	for _, chat := range []*protocol.ServerboundChatMessage{first, second} {
+		var body bytes.Buffer
+		_ = binary.Write(&body, binary.BigEndian, chat.Salt)
+		_ = binary.Write(&body, binary.BigEndian, chat.Timestamp.Unix())
+		body.WriteString(chat.Message)
+		body.WriteByte(70)
+		for _, entry := range chat.LastSeen {
+			body.WriteByte(70)
+			body.Write(entry.Sender[:])
+			body.Write(entry.Signature)
+		}
+		bodyHash := sha256.Sum256(body.Bytes())
+
+		header := append(append(append([]byte(nil), previous...), profileID[:]...), bodyHash[:]...)
+		hash := sha256.Sum256(header)
+		if err := rsa.VerifyPKCS1v15(&playerKey.PrivateKey.PublicKey, crypto.SHA256, hash[:], chat.Signature); err != nil {
+			t.Fatalf("signature of %q does not verify: %v", chat.Message, err)
+		}
+		previous = chat.Signature
+	}
+}
+
+func TestLoginStartPublicKey(t *testing.T) {
+	playerKey := newTestPlayerKey(t)
+	server := newFakeServer(t, protocol.V1_19)
+
+	loginChan := make(chan *protocol.ServerboundLoginStart, 1)
+	errChan := make(chan error, 1)
+	go func() {
+		loginStart, err := server.offlineLogin()
+		loginChan <- loginStart
+		errChan <- err
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, _ := gophermc.NewClient(
+		gophermc.WithAddr(server.Addr()),
+		gophermc.WithVersion(protocol.V1_19),
+		gophermc.WithPlayerKey(playerKey),
+	)
+	defer client.Close()
+
+	if err := client.Join(ctx); err != nil {
+		t.Fatalf("Join failed: %v", err)
+	}
+
+	loginStart := <-loginChan
+	if err := <-errChan; err != nil {
+		t.Fatalf("fake server failed: %v", err)
+	}
+
+	if loginStart.PlayerKey == nil || string(loginStart.PlayerKey.Signature) != "signature-v1" {
+		t.Fatalf("expected login start to carry the v1 key signature, got %+v", loginStart.PlayerKey)
+	}
+	if loginStart.PlayerKey.ExpiresAt.UnixMilli() != playerKey.ExpiresAt.UnixMilli() {
+		t.Fatalf("unexpected key expiry %s", loginStart.PlayerKey.ExpiresAt)
+	}
+}
+
+func TestJoinEncryptionSignedVerifyToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	playerKey := newTestPlayerKey(t)
+
+	for _, version := range []protocol.Version{protocol.V1_19, protocol.V1_19_2} {
+		t.Run(version.String(), func(t *testing.T) {
+			server := newFakeServer(t, version)
+
+			errChan := make(chan error, 1)
+			go func() {
+				_, err := server.encryptedLogin(key, "")
+				errChan <- err
+			}()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			client, err := gophermc.NewClient(
+				gophermc.WithAddr(server.Addr()),
+				gophermc.WithVersion(version),
+				gophermc.WithPlayerKey(playerKey),
+			)
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+			defer client.Close()
+
+			if err := client.Join(ctx); err != nil {
+				t.Fatalf("Join failed: %v", err)
+			}
+
+			if err := <-errChan; err != nil {
+				t.Fatalf("fake server failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestChatEventsRendered(t *testing.T) {
+	server := newFakeServer(t, protocol.V1_12_2)
+
+	errChan := make(chan error, 1)
+	go func() {
+		if _, err := server.offlineLogin(); err != nil {
+			errChan <- err
+			return
+		}
+
+		chatID, _ := protocol.GetPacketID(protocol.V1_12_2, protocol.StatePlay, protocol.DirectionClientbound, &protocol.ClientboundChatMessage{})
+		for _, message := range []struct {
+			json     string
+			position byte
+		}{
+			{`{"translate":"chat.type.text","with":[{"text":"Steve"},"hi there"]}`, protocol.ChatPositionChat},
+			{`{"translate":"death.attack.mob","with":["Steve",{"translate":"entity.minecraft.zombie","fallback":"Zombie"}]}`, protocol.ChatPositionSystem},
+			{`{"text":"§aHealth: 20"}`, protocol.ChatPositionGameInfo},
+		} {
+			var body bytes.Buffer
+			_ = protocol.WriteVarInt(&body, chatID)
+			_ = protocol.WriteString(&body, message.json)
+			_ = protocol.WriteByte(&body, message.position)
+			if err := server.writeRaw(body.Bytes()); err != nil {
+				errChan <- err
+				return
+			}
+		}
+		errChan <- nil
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := gophermc.NewClient(
+		gophermc.WithAddr(server.Addr()),
+		gophermc.WithVersion(protocol.V1_12_2),
+	)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Destroy()
+
+	events, err := client.JoinAndListen(ctx, 10)
+	if err != nil {
+		t.Fatalf("JoinAndListen failed: %v", err)
+	}
+
+	if err := <-errChan; err != nil {
+		t.Fatalf("fake server failed: %v", err)
+	}
+
+	var chat gophermc.ChatMessageEvent
+	var system gophermc.SystemMessageEvent
+	var actionBar gophermc.ActionBarEvent
+	for received := 0; received < 3; {
+		select {
+		case event := <-events:
+			switch e := event.(type) {
+			case gophermc.ChatMessageEvent:
+				chat = e
+				received++
+			case gophermc.SystemMessageEvent:
+				system = e
+				received++
+			case gophermc.ActionBarEvent:
+				actionBar = e
+				received++
+			}
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for chat events")
+		}
+	}
+
+	if chat.Sender != "Steve" || chat.Message != "hi there" {
+		t.Fatalf("unexpected chat event %+v", chat)
+	}
+	if system.Message != "Steve was slain by Zombie" {
+		t.Fatalf("unexpected system message %q", system.Message)
+	}
+	if actionBar.Message != "Health: 20" {
+		t.Fatalf("unexpected action bar %q", actionBar.Message)
+	}
+}
@@ -3,13 +3,20 @@ package gophermc
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
 	"crypto/rsa"
+	"crypto/x509"
 	"errors"
 	"fmt"
+	"github.com/obeliskdev/fastrand"
+	"github.com/obeliskdev/gophermc/auth"
+	"github.com/obeliskdev/gophermc/component"
 	"github.com/obeliskdev/gophermc/protocol"
 	"io"
 	"log"
+	"math"
 	"net"
+	"strconv"
 	"sync"
 	"time"
 
@@ -20,18 +27,58 @@ type Client struct {
 	*protocol.Conn
 	version protocol.Version
 
-	addr *net.TCPAddr
+	// host and port are set by WithAddr and resolved into addr by Connect.
+	host     string
+	port     int
+	addr     *net.TCPAddr
+	resolver Resolver
+	// dialHost replaces addr's IP when dialing through a dialer, which then
+	// resolves the host name itself.
+	dialHost string
 
-	privateKey *rsa.PrivateKey
+	dialer         Dialer
+	sourceIP       net.IP
+	connectTimeout time.Duration
+	proxyHeader    *protocol.ProxyHeader
 
-	eventChan  chan Event
-	readerCtx  context.Context
-	cancelRead context.CancelFunc
-	readerWg   sync.WaitGroup
+	optionErr error
 
-	serverHostname string
+	privateKey *rsa.PrivateKey
 
-	brand string
+	playerKey       *protocol.PlayerKey
+	certificatesURL string
+	chatSession     *protocol.ChatSession
+	messageChain    *protocol.MessageChain
+	lastSeen        protocol.LastSeenTracker
+	lastSeenList    protocol.LastSeenList
+	// chatMu keeps signed messages in the order of their chain.
+	chatMu sync.Mutex
+
+	authenticator    Authenticator
+	accessToken      string
+	sessionServerURL string
+
+	eventChan chan Event
+	// joinEvents holds the events emitted while JoinAndListen is joining,
+	// before eventChan exists, if queueEvents is set.
+	joinEvents  []Event
+	queueEvents bool
+	readerCtx   context.Context
+	cancelRead  context.CancelFunc
+	readerWg    sync.WaitGroup
+
+	serverHostname     string
+	legacyPingFallback bool
+	forwarding         *forwarding
+	forge              *forge
+	loginPlugins       map[string]LoginPluginHandler
+	loginPluginsMu     sync.RWMutex
+	pluginChannels     map[string]PluginMessageHandler
+	pluginChannelsMu   sync.RWMutex
+	queryPort          int
+
+	brand        string
+	translations *component.Translations
 
 	username string
 	uniqueId uuid.UUID
@@ -40,7 +87,11 @@ type Client struct {
 	playerPosition *protocol.PlayerPosition
 }
 
-var publicDialler = &net.Dialer{}
+// Dialer opens the connection to the server. *net.Dialer and the dialers of
+// the proxy package implement it.
+type Dialer interface {
+	DialContext(ctx context.Context, network, address string) (net.Conn, error)
+}
 
 func NewClient(opts ...ClientOption) (*Client, error) {
 	readerCtx, cancelRead := context.WithCancel(context.Background())
@@ -50,6 +101,7 @@ func NewClient(opts ...ClientOption) (*Client, error) {
 		readerCtx:      readerCtx,
 		cancelRead:     cancelRead,
 		brand:          "vanilla",
+		translations:   component.English(),
 		username:       "GopherMC",
 		playerPosition: new(protocol.PlayerPosition),
 		settings: protocol.ClientSettings{
@@ -66,6 +118,11 @@ func NewClient(opts ...ClientOption) (*Client, error) {
 		opt(c)
 	}
 
+	if c.optionErr != nil {
+		cancelRead()
+		return nil, c.optionErr
+	}
+
 	return c, nil
 }
 
@@ -74,16 +131,63 @@ func (c *Client) Connect(ctx context.Context) error {
 		_ = c.Close()
 	}
 
-	netConn, err := publicDialler.DialContext(ctx, "tcp", c.addr.String())
+	if c.connectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.connectTimeout)
+		defer cancel()
+	}
+
+	if err := c.resolve(ctx, c.dialer != nil); err != nil {
+		return err
+	}
+
+	address := c.addr.String()
+	if c.dialHost != "" {
+		address = net.JoinHostPort(c.dialHost, strconv.Itoa(c.addr.Port))
+	}
+
+	netConn, err := c.netDialer().DialContext(ctx, "tcp", address)
 	if err != nil {
 		return fmt.Errorf("failed to dial server: %w", err)
 	}
 
+	if c.proxyHeader != nil {
+		if err := c.writeProxyHeader(netConn); err != nil {
+			_ = netConn.Close()
+			return fmt.Errorf("failed to send PROXY header: %w", err)
+		}
+	}
+
 	c.Conn = protocol.NewConn(netConn, c.version)
 
 	return nil
 }
 
+func (c *Client) netDialer() Dialer {
+	if c.dialer != nil {
+		return c.dialer
+	}
+
+	dialer := &net.Dialer{}
+	if c.sourceIP != nil {
+		dialer.LocalAddr = &net.TCPAddr{IP: c.sourceIP}
+	}
+	return dialer
+}
+
+// writeProxyHeader sends the PROXY header, taking missing addresses from
+// conn.
+func (c *Client) writeProxyHeader(conn net.Conn) error {
+	header := *c.proxyHeader
+	if header.Source == nil {
+		header.Source = conn.LocalAddr()
+	}
+	if header.Destination == nil {
+		header.Destination = conn.RemoteAddr()
+	}
+	return header.Write(conn)
+}
+
 func (c *Client) Close() error {
 	if c.Conn == nil {
 		return nil
@@ -98,6 +202,12 @@ func (c *Client) Close() error {
 
 func (c *Client) Destroy() error {
 	c.cancelRead()
+
+	// Wake a read loop that is blocked waiting for the next packet.
+	if c.Conn != nil {
+		_ = c.Conn.SetReadDeadline(time.Now())
+	}
+
 	c.readerWg.Wait()
 
 	if c.eventChan != nil {
@@ -127,6 +237,12 @@ func (c *Client) GetStatus(ctx context.Context) (string, time.Duration, error) {
 		return "", 0, fmt.Errorf("sending status request failed: %w", err)
 	}
 
+	if legacy, err := c.isLegacyKick(); err != nil {
+		return "", 0, fmt.Errorf("reading status response failed: %w", err)
+	} else if legacy {
+		return "", 0, ErrLegacyServer
+	}
+
 	p, err := c.ReadPacket()
 	if err != nil {
 		return "", 0, fmt.Errorf("reading status response failed: %w", err)
@@ -145,6 +261,27 @@ func (c *Client) GetStatus(ctx context.Context) (string, time.Duration, error) {
 	return statusResp.JSONResponse, latency, nil
 }
 
+// Status is GetStatus with the response parsed. The JSON document is kept
+// in StatusResponse.Raw. With WithLegacyPingFallback, pre-1.7 servers are
+// asked again with the 1.6 legacy ping.
+func (c *Client) Status(ctx context.Context) (*protocol.StatusResponse, time.Duration, error) {
+	data, latency, err := c.GetStatus(ctx)
+	if errors.Is(err, ErrLegacyServer) && c.legacyPingFallback {
+		_ = c.Close()
+		return c.LegacyStatus(ctx, protocol.LegacyPing16)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	status, err := protocol.ParseStatusResponse(data)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return status, latency, nil
+}
+
 func (c *Client) Ping() (time.Duration, error) {
 	if c.Conn == nil {
 		return 0, fmt.Errorf("client not connected")
@@ -180,6 +317,8 @@ func (c *Client) Join(ctx context.Context) error {
 
 	c.SetState(protocol.StateLogin)
 
+	c.preparePlayerKey(ctx)
+
 	if err := c.SendLogin(c.username, c.uniqueId); err != nil {
 		return fmt.Errorf("handshake failed: %w", err)
 	}
@@ -195,11 +334,21 @@ func (c *Client) Join(ctx context.Context) error {
 		}
 
 		switch p := packet.(type) {
+		case *protocol.ClientboundEncryptionRequest:
+			if err := c.handleEncryptionRequest(ctx, p); err != nil {
+				return fmt.Errorf("encryption handshake failed: %w", err)
+			}
+
 		case *protocol.ClientboundSetCompression:
 			c.SetCompression(int(p.Threshold))
 
-		case *protocol.ClientboundDisconnect:
-			return fmt.Errorf("disconnected by server: %s, %s", c.State(), p.Reason)
+		case *protocol.ClientboundLoginPluginRequest:
+			if err := c.handleLoginPluginRequest(p); err != nil {
+				return fmt.Errorf("login plugin request failed: %w", err)
+			}
+
+		case *protocol.ClientboundLoginDisconnect:
+			return fmt.Errorf("disconnected by server: %s, %s", c.State(), p.Reason.Render(c.translations))
 
 		case *protocol.ClientboundLoginSuccess:
 			if c.version >= protocol.V1_20_2 {
@@ -208,11 +357,25 @@ func (c *Client) Join(ctx context.Context) error {
 					return fmt.Errorf("failed to send login acknowledged: %w", err)
 				}
 				c.SetState(protocol.StateConfiguration)
-				return c.handleConfiguration()
+				if err := c.handleConfiguration(); err != nil {
+					return err
+				}
+				return c.startChatSession()
 			}
 
 			c.SetState(protocol.StatePlay)
-			return c.SendClientSettings(c.settings)
+			if c.forge != nil && c.version < protocol.V1_13 {
+				if err := c.forgeLegacyHandshake(); err != nil {
+					return fmt.Errorf("forge handshake failed: %w", err)
+				}
+			}
+			if err := c.announceChannels(); err != nil {
+				return fmt.Errorf("failed to register plugin channels: %w", err)
+			}
+			if err := c.SendClientSettings(c.settings); err != nil {
+				return err
+			}
+			return c.startChatSession()
 
 		case *protocol.ClientboundJoinGame:
 			return nil
@@ -227,8 +390,112 @@ func (c *Client) Join(ctx context.Context) error {
 		}
 	}
 }
+
+func (c *Client) handleEncryptionRequest(ctx context.Context, p *protocol.ClientboundEncryptionRequest) error {
+	key, err := x509.ParsePKIXPublicKey(p.PublicKey)
+	if err != nil {
+		return fmt.Errorf("failed to parse server public key: %w", err)
+	}
+
+	publicKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("unexpected server public key type %T", key)
+	}
+
+	sharedSecret := make([]byte, 16)
+	if _, err := rand.Read(sharedSecret); err != nil {
+		return fmt.Errorf("failed to generate shared secret: %w", err)
+	}
+
+	if authenticator := c.Authenticator(); authenticator != nil && p.ShouldAuthenticate {
+		serverHash := ServerHash(p.ServerID, sharedSecret, p.PublicKey)
+		if err := authenticator.JoinServer(ctx, serverHash); err != nil {
+			return fmt.Errorf("session join failed: %w", err)
+		}
+	}
+
+	encryptedSecret, err := rsa.EncryptPKCS1v15(rand.Reader, publicKey, sharedSecret)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt shared secret: %w", err)
+	}
+
+	response := &protocol.ServerboundEncryptionResponse{SharedSecret: encryptedSecret}
+	if c.playerKey != nil && c.playerKey.PrivateKey != nil && c.version >= protocol.V1_19 && c.version <= protocol.V1_19_2 {
+		response.Salt = fastrand.NumberN[int64](math.MaxInt64)
+		if response.MessageSignature, err = c.playerKey.SignVerifyToken(p.VerifyToken, response.Salt); err != nil {
+			return err
+		}
+	} else if response.VerifyToken, err = rsa.EncryptPKCS1v15(rand.Reader, publicKey, p.VerifyToken); err != nil {
+		return fmt.Errorf("failed to encrypt verify token: %w", err)
+	}
+
+	if err := c.WritePacket(response); err != nil {
+		return fmt.Errorf("failed to send encryption response: %w", err)
+	}
+
+	return c.EnableEncryption(sharedSecret)
+}
+
+func (c *Client) preparePlayerKey(ctx context.Context) {
+	if c.playerKey != nil || c.accessToken == "" || c.version < protocol.V1_19 {
+		return
+	}
+
+	authClient := auth.NewClient("")
+	if c.certificatesURL != "" {
+		authClient.Endpoints.Certificates = c.certificatesURL
+	}
+
+	key, err := authClient.FetchPlayerKey(ctx, c.accessToken)
+	if err != nil {
+		log.Printf("[WARN] Failed to fetch chat signing key, chat will be unsigned: %v", err)
+		return
+	}
+
+	c.playerKey = key
+	c.privateKey = key.PrivateKey
+}
+
+func (c *Client) startChatSession() error {
+	if c.playerKey == nil || c.version < protocol.V1_19_2 {
+		return nil
+	}
+
+	if c.version < protocol.V1_19_3 {
+		c.messageChain = protocol.NewMessageChain(c.playerKey)
+		return nil
+	}
+
+	c.chatSession = protocol.NewChatSession(c.playerKey)
+	if err := c.WritePacket(c.chatSession.UpdatePacket(c.version)); err != nil {
+		return fmt.Errorf("failed to send chat session: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client) Authenticator() Authenticator {
+	if c.authenticator != nil {
+		return c.authenticator
+	}
+
+	if c.accessToken == "" {
+		return nil
+	}
+
+	return &SessionAuthenticator{
+		URL:         c.sessionServerURL,
+		AccessToken: c.accessToken,
+		ProfileID:   c.uniqueId,
+	}
+}
+
 func (c *Client) JoinAndListen(ctx context.Context, eventCount int) (<-chan Event, error) {
-	if err := c.Join(ctx); err != nil {
+	c.queueEvents = true
+	err := c.Join(ctx)
+	queued := c.joinEvents
+	c.queueEvents, c.joinEvents = false, nil
+	if err != nil {
 		return nil, err
 	}
 
@@ -242,10 +509,18 @@ func (c *Client) JoinAndListen(ctx context.Context, eventCount int) (<-chan Even
 	c.readerWg.Wait()
 	c.readerWg.Add(1)
 
-	go c.readLoop()
-
 	c.eventChan <- ReadyEvent{Username: c.username}
 
+	// Events from the configuration state follow ReadyEvent. They are sent
+	// from the read loop's goroutine, so more than eventCount of them cannot
+	// block JoinAndListen.
+	go func() {
+		for _, event := range queued {
+			c.eventChan <- event
+		}
+		c.readLoop()
+	}()
+
 	return c.Events(), nil
 }
 
@@ -262,6 +537,34 @@ func (c *Client) Chat(message string) error {
 		Message:    message,
 		PrivateKey: c.privateKey,
 		UUID:       c.uniqueId,
+		Timestamp:  time.Now(),
+		Salt:       fastrand.NumberN[int64](math.MaxInt64),
+	}
+
+	c.chatMu.Lock()
+	defer c.chatMu.Unlock()
+
+	switch {
+	case c.version >= protocol.V1_19_3:
+		update := c.lastSeen.Update()
+		packet.Offset = update.Offset
+		packet.Acknowledged = update.Acknowledged
+		packet.Checksum = update.Checksum
+
+		if c.chatSession != nil {
+			if err := c.chatSession.Sign(c.uniqueId, packet, update.Signatures); err != nil {
+				return err
+			}
+		}
+
+	case c.version >= protocol.V1_19_2:
+		packet.LastSeen, packet.LastReceived = c.lastSeenList.Update()
+
+		if c.messageChain != nil {
+			if err := c.messageChain.Sign(c.uniqueId, packet); err != nil {
+				return err
+			}
+		}
 	}
 
 	return c.WritePacket(packet)
@@ -302,6 +605,10 @@ func (c *Client) readLoop() {
 					continue
 				}
 
+				if c.readerCtx.Err() != nil {
+					return
+				}
+
 				if !errors.Is(err, io.EOF) {
 					log.Printf("Error reading packet: %v", err)
 				}
@@ -334,28 +641,128 @@ func (c *Client) handlePacket(packet protocol.Packet) {
 		}
 
 	case *protocol.ClientboundChatMessage:
+		switch p.Position {
+		case protocol.ChatPositionSystem:
+			c.emitSystemMessage(p.Component, false)
+		case protocol.ChatPositionGameInfo:
+			c.emitSystemMessage(p.Component, true)
+		default:
+			sender, message := c.splitLegacyChat(p.Component)
+			if c.eventChan != nil {
+				c.eventChan <- ChatMessageEvent{
+					Component:  message,
+					Message:    message.Render(c.translations),
+					Sender:     sender,
+					SenderUUID: p.Sender,
+					Time:       time.Now(),
+				}
+			}
+		}
+
+	case *protocol.ClientboundPlayerChat:
+		c.acknowledgeChat(p.Sender, p.Signature)
+
+		content := p.Content()
 		if c.eventChan != nil {
 			c.eventChan <- ChatMessageEvent{
-				Component: p.Component,
-				Message:   p.Component.String(),
-				Sender:    p.Sender,
+				Component:  content,
+				Message:    content.Render(c.translations),
+				Sender:     p.SenderName.Render(c.translations),
+				SenderUUID: p.Sender,
+				ChatType:   p.ChatType,
+				Signed:     p.Signature != nil,
+				Time:       time.Now(),
+			}
+		}
+
+	case *protocol.ClientboundDisguisedChat:
+		if c.eventChan != nil {
+			c.eventChan <- ChatMessageEvent{
+				Component: p.Message,
+				Message:   p.Message.Render(c.translations),
+				Sender:    p.SenderName.Render(c.translations),
+				ChatType:  p.ChatType,
 				Time:      time.Now(),
 			}
 		}
 
+	case *protocol.ClientboundSystemChat:
+		c.emitSystemMessage(p.Component, p.Overlay)
+
+	case *protocol.ClientboundCustomPayload:
+		c.handlePluginMessage(p)
+
 	case *protocol.ClientboundDisconnect:
 		if c.eventChan != nil {
-			c.eventChan <- DisconnectEvent{Reason: p.Reason}
+			c.eventChan <- DisconnectEvent{Reason: p.Reason.Render(c.translations), Component: &p.Reason}
 		}
 
 		c.cancelRead()
 	}
 }
 
+func (c *Client) emitSystemMessage(chat component.ChatComponent, overlay bool) {
+	if c.eventChan == nil {
+		return
+	}
+
+	if overlay {
+		c.eventChan <- ActionBarEvent{Message: chat.Render(c.translations), Component: chat, Time: time.Now()}
+		return
+	}
+
+	c.eventChan <- SystemMessageEvent{Message: chat.Render(c.translations), Component: chat, Time: time.Now()}
+}
+
+// acknowledgeChat records a signed message as seen and, once enough messages
+// are pending, acknowledges them so the server does not kick for a stale chain.
+func (c *Client) acknowledgeChat(sender uuid.UUID, signature []byte) {
+	if c.version < protocol.V1_19_2 || signature == nil {
+		return
+	}
+
+	var ack *protocol.ServerboundMessageAcknowledgement
+	if c.version < protocol.V1_19_3 {
+		if c.lastSeenList.Track(sender, signature) <= 64 {
+			return
+		}
+
+		ack = &protocol.ServerboundMessageAcknowledgement{}
+		ack.LastSeen, ack.LastReceived = c.lastSeenList.Update()
+	} else {
+		if c.lastSeen.Track(signature) <= 64 {
+			return
+		}
+
+		ack = &protocol.ServerboundMessageAcknowledgement{Offset: c.lastSeen.TakeOffset()}
+	}
+
+	if err := c.WritePacket(ack); err != nil {
+		log.Printf("Failed to acknowledge chat messages: %v", err)
+	}
+}
+
+// splitLegacyChat pulls the sender and message body out of the vanilla
+// "<%s> %s" style translations used before 1.19, so that the event carries
+// the same parts as a 1.19+ player chat message.
+func (c *Client) splitLegacyChat(chat component.ChatComponent) (string, component.ChatComponent) {
+	switch chat.Translate {
+	case "chat.type.text", "chat.type.announcement", "chat.type.emote":
+		if len(chat.With) >= 2 {
+			return chat.With[0].Render(c.translations), chat.With[1]
+		}
+	}
+
+	return "", chat
+}
+
 func (c *Client) handleConfiguration() error {
 	if err := c.SendClientSettings(c.settings); err != nil {
 		return fmt.Errorf("failed to send client settings in config: %w", err)
 	}
+	if err := c.announceChannels(); err != nil {
+		return fmt.Errorf("failed to register plugin channels: %w", err)
+	}
 
 	for {
 		packet, err := c.ReadPacket()
@@ -377,6 +784,7 @@ func (c *Client) handleConfiguration() error {
 			}
 
 		case *protocol.ClientboundCustomPayload:
+			c.handlePluginMessage(p)
 			if p.Channel == "minecraft:brand" {
 				var buf bytes.Buffer
 				if err := protocol.WriteString(&buf, c.brand); err != nil {
@@ -418,7 +826,7 @@ func (c *Client) handleConfiguration() error {
 			return nil
 
 		case *protocol.ClientboundDisconnect:
-			return fmt.Errorf("disconnected during config: %s", p.Reason)
+			return fmt.Errorf("disconnected during config: %s", p.Reason.Render(c.translations))
 
 		case *protocol.ClientboundCookieRequest:
 			cookieResp := &protocol.ServerboundCookieResponse{Key: p.Key, Data: nil}
@@ -439,13 +847,21 @@ func (c *Client) handleConfiguration() error {
 func (c *Client) ServerHostname() string {
 	var serverHostname = c.serverHostname
 
-	if serverHostname == "" {
+	if serverHostname == "" && c.addr != nil {
 		serverHostname = c.addr.IP.String()
 	}
 
 	return serverHostname
 }
 
+// serverPort is the port sent in the handshake, the one dialed by Connect.
+func (c *Client) serverPort() uint16 {
+	if c.addr != nil {
+		return uint16(c.addr.Port)
+	}
+	return uint16(portOr(c.port, defaultPort))
+}
+
 func (c *Client) SendHandshake(state protocol.State) error {
 	if c.Conn == nil {
 		return fmt.Errorf("client not connected")
@@ -454,10 +870,20 @@ func (c *Client) SendHandshake(state protocol.State) error {
 	handshake := &protocol.ServerboundHandshake{
 		ProtocolVersion: c.version.Protocol(),
 		ServerAddress:   c.ServerHostname(),
-		ServerPort:      uint16(c.addr.Port),
+		ServerPort:      c.serverPort(),
 		NextState:       state,
 	}
 
+	if state == protocol.StateLogin && c.forwarding != nil && c.forwarding.bungeeCord {
+		address, err := c.bungeeCordAddress()
+		if err != nil {
+			return err
+		}
+		handshake.ServerAddress = address
+	} else if state == protocol.StateLogin && c.forge != nil {
+		handshake.ServerAddress += protocol.ForgeMarker(c.version)
+	}
+
 	return c.WritePacket(handshake)
 }
 
@@ -475,8 +901,9 @@ func (c *Client) SendLogin(username string, uniqueId uuid.UUID) error {
 	}
 
 	login := &protocol.ServerboundLoginStart{
-		Username: username,
-		UUID:     uniqueId,
+		Username:  username,
+		UUID:      uniqueId,
+		PlayerKey: c.playerKey,
 	}
 
 	return c.WritePacket(login)
@@ -6,6 +6,7 @@ import (
 	"flag"
 	"fmt"
 	"github.com/obeliskdev/gophermc"
+	"github.com/obeliskdev/gophermc/component"
 	"github.com/obeliskdev/gophermc/protocol"
 	"log"
 	"os"
@@ -15,6 +16,11 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "rcon" {
+		runRcon(os.Args[2:])
+		return
+	}
+
 	serverHost := flag.String("host", "127.0.0.1:36000", "Minecraft server host")
 	username := flag.String("username", "GopherBot", "Username to use for login")
 	versionStr := flag.String("version", "latest", "Minecraft version string (e.g., 1.18.2, latest)")
@@ -56,6 +62,8 @@ func main() {
 
 	log.Printf("Logged in as %s on version %s. You can now send chat messages.", *username, mcVersion.String())
 
+	renderer := component.ANSIRenderer{Mode: component.DetectColorMode(os.Stdout), Lang: component.English()}
+
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 	go func() {
@@ -93,7 +101,13 @@ func main() {
 				log.Printf("Disconnected: %s", e.Reason)
 				return
 			case gophermc.ChatMessageEvent:
-				fmt.Printf("[Chat] %s\n", e.Message)
+				if e.Sender != "" {
+					fmt.Printf("[Chat] <%s> %s\n", e.Sender, renderer.Render(e.Component))
+				} else {
+					fmt.Printf("[Chat] %s\n", renderer.Render(e.Component))
+				}
+			case gophermc.SystemMessageEvent:
+				fmt.Printf("[System] %s\n", renderer.Render(e.Component))
 			}
 		case <-ctx.Done():
 			log.Println("Context done. Exiting.")
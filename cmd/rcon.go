@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/obeliskdev/gophermc/component"
+	"github.com/obeliskdev/gophermc/rcon"
+)
+
+// runRcon reads console commands from stdin and prints the responses.
+func runRcon(args []string) {
+	flags := flag.NewFlagSet("rcon", flag.ExitOnError)
+	serverHost := flags.String("host", "127.0.0.1:25575", "RCON address")
+	password := flags.String("password", os.Getenv("RCON_PASSWORD"), "RCON password (defaults to $RCON_PASSWORD)")
+	timeout := flags.Duration("timeout", 10*time.Second, "Timeout for connecting and for each command")
+	_ = flags.Parse(args)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	dialCtx, dialCancel := context.WithTimeout(ctx, *timeout)
+	client, err := rcon.Dial(dialCtx, *serverHost, *password)
+	dialCancel()
+	if err != nil {
+		log.Fatalf("Failed to connect to RCON: %v", err)
+	}
+	defer client.Close()
+
+	renderer := component.ANSIRenderer{Mode: component.DetectColorMode(os.Stdout), Lang: component.English()}
+
+	// Commands given as arguments are run once instead of reading stdin.
+	if flags.NArg() > 0 {
+		for _, command := range flags.Args() {
+			runRconCommand(ctx, client, renderer, command, *timeout)
+		}
+		return
+	}
+
+	log.Printf("Connected to %s. Type commands, Ctrl-D to exit.", *serverHost)
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if command := scanner.Text(); command != "" {
+			runRconCommand(ctx, client, renderer, command, *timeout)
+		}
+	}
+}
+
+func runRconCommand(ctx context.Context, client *rcon.Client, renderer component.ANSIRenderer, command string, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	response, err := client.Command(ctx, command)
+	if err != nil {
+		log.Printf("Command failed: %v", err)
+		return
+	}
+	if response != "" {
+		// Plugins color their output with legacy codes.
+		fmt.Println(renderer.Render(component.ParseLegacy(response, component.SectionSign)))
+	}
+}
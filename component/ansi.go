@@ -0,0 +1,161 @@
+package component
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ColorMode selects the escape sequences an ANSIRenderer emits.
+type ColorMode int
+
+const (
+	// ColorNone renders plain text, for pipes, files and dumb terminals.
+	ColorNone ColorMode = iota
+	// Color16 maps every color to the nearest of the 16 standard colors.
+	Color16
+	// ColorTrue emits 24-bit colors.
+	ColorTrue
+)
+
+// DetectColorMode picks a mode for f: ColorNone when f is not a terminal,
+// NO_COLOR is set or TERM is "dumb", ColorTrue when COLORTERM advertises
+// truecolor and Color16 otherwise.
+func DetectColorMode(f *os.File) ColorMode {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok || os.Getenv("TERM") == "dumb" {
+		return ColorNone
+	}
+
+	info, err := f.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return ColorNone
+	}
+
+	switch os.Getenv("COLORTERM") {
+	case "truecolor", "24bit":
+		return ColorTrue
+	}
+	return Color16
+}
+
+var ansiColors = map[Color]int{
+	Black:       30,
+	DarkBlue:    34,
+	DarkGreen:   32,
+	DarkAqua:    36,
+	DarkRed:     31,
+	DarkPurple:  35,
+	Gold:        33,
+	Gray:        37,
+	DarkGray:    90,
+	Blue:        94,
+	Green:       92,
+	Aqua:        96,
+	Red:         91,
+	LightPurple: 95,
+	Yellow:      93,
+	White:       97,
+}
+
+const ansiReset = "\x1b[0m"
+
+// ANSIRenderer renders components for a terminal.
+type ANSIRenderer struct {
+	Mode ColorMode
+	Lang *Translations
+}
+
+func (r ANSIRenderer) Render(c ChatComponent) string {
+	segments := c.Segments(r.Lang)
+
+	var sb strings.Builder
+	styled := false
+	for _, segment := range segments {
+		text := stripControl(segment.Text)
+		if segment.Style.IsObfuscated() {
+			text = obfuscate(text)
+		}
+
+		if r.Mode == ColorNone {
+			sb.WriteString(text)
+			continue
+		}
+
+		if styled {
+			sb.WriteString(ansiReset)
+		}
+		codes := r.sgr(segment.Style)
+		if styled = codes != ""; styled {
+			sb.WriteString("\x1b[")
+			sb.WriteString(codes)
+			sb.WriteByte('m')
+		}
+		sb.WriteString(text)
+	}
+
+	if styled {
+		sb.WriteString(ansiReset)
+	}
+	return sb.String()
+}
+
+// stripControl drops the C0 and C1 control characters other than newline and
+// tab, so that server text cannot smuggle its own escape sequences into the
+// terminal.
+func stripControl(text string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' {
+			return r
+		}
+		if r < 0x20 || r >= 0x7F && r <= 0x9F {
+			return -1
+		}
+		return r
+	}, text)
+}
+
+func (r ANSIRenderer) sgr(style Style) string {
+	var codes []string
+	if style.IsBold() {
+		codes = append(codes, "1")
+	}
+	if style.IsItalic() {
+		codes = append(codes, "3")
+	}
+	if style.IsUnderlined() {
+		codes = append(codes, "4")
+	}
+	if style.IsStrikethrough() {
+		codes = append(codes, "9")
+	}
+
+	if red, green, blue, ok := style.Color.RGB(); ok {
+		if r.Mode == ColorTrue && !style.Color.Named() {
+			codes = append(codes, "38;2;"+strconv.Itoa(int(red))+";"+strconv.Itoa(int(green))+";"+strconv.Itoa(int(blue)))
+		} else {
+			codes = append(codes, strconv.Itoa(ansiColors[nearestNamed(style.Color, red, green, blue)]))
+		}
+	}
+
+	return strings.Join(codes, ";")
+}
+
+// nearestNamed returns c itself when it is named, otherwise the named color
+// closest to it in RGB space.
+func nearestNamed(c Color, r, g, b uint8) Color {
+	if c.Named() {
+		return c
+	}
+
+	best, bestDistance := White, -1
+	for _, named := range NamedColors {
+		value := namedRGB[named]
+		dr := int(r) - int(value>>16&0xFF)
+		dg := int(g) - int(value>>8&0xFF)
+		db := int(b) - int(value&0xFF)
+		if distance := dr*dr + dg*dg + db*db; bestDistance < 0 || distance < bestDistance {
+			best, bestDistance = named, distance
+		}
+	}
+	return best
+}
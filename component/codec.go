@@ -0,0 +1,623 @@
+package component
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/obeliskdev/gophermc/nbt"
+)
+
+// EventFormat selects how click and hover events are laid out. 1.21.5
+// renamed clickEvent/hoverEvent to click_event/hover_event and moved their
+// arguments to action-specific keys.
+type EventFormat int
+
+const (
+	CamelCaseEvents EventFormat = iota
+	SnakeCaseEvents
+)
+
+func (c ChatComponent) MarshalJSON() ([]byte, error) {
+	return c.JSON(CamelCaseEvents)
+}
+
+// JSON encodes the component with the given event layout.
+func (c ChatComponent) JSON(format EventFormat) ([]byte, error) {
+	return json.Marshal(c.encode(format))
+}
+
+func (c *ChatComponent) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+
+	decoded, err := decode(value)
+	if err != nil {
+		return err
+	}
+
+	*c = decoded
+	return nil
+}
+
+func (c ChatComponent) MarshalNBT() (nbt.Tag, error) {
+	return c.NBTTag(CamelCaseEvents)
+}
+
+// NBTTag encodes the component as used on the wire from 1.20.3. A component
+// with only unstyled text is written as a bare string tag.
+func (c ChatComponent) NBTTag(format EventFormat) (nbt.Tag, error) {
+	if c.isPlainText() {
+		return nbt.String(c.Text), nil
+	}
+	return toTag(c.encode(format))
+}
+
+func (c *ChatComponent) UnmarshalNBT(tag nbt.Tag) error {
+	decoded, err := decode(nbt.Value(tag))
+	if err != nil {
+		return err
+	}
+
+	*c = decoded
+	return nil
+}
+
+func (c ChatComponent) isPlainText() bool {
+	return c.Style.IsZero() && len(c.Extra) == 0 && c.Translate == "" && c.Score == nil &&
+		c.Selector == "" && c.Keybind == "" && c.NBT == ""
+}
+
+func (c ChatComponent) encode(format EventFormat) map[string]any {
+	m := map[string]any{}
+
+	switch {
+	case c.Translate != "":
+		m["translate"] = c.Translate
+		if c.Fallback != "" {
+			m["fallback"] = c.Fallback
+		}
+		if len(c.With) > 0 {
+			m["with"] = encodeList(c.With, format)
+		}
+	case c.Score != nil:
+		score := map[string]any{"name": c.Score.Name, "objective": c.Score.Objective}
+		if c.Score.Value != "" {
+			score["value"] = c.Score.Value
+		}
+		m["score"] = score
+	case c.Selector != "":
+		m["selector"] = c.Selector
+		if c.Separator != nil {
+			m["separator"] = c.Separator.encode(format)
+		}
+	case c.Keybind != "":
+		m["keybind"] = c.Keybind
+	case c.NBT != "":
+		m["nbt"] = c.NBT
+		if c.Interpret {
+			m["interpret"] = true
+		}
+		if c.Separator != nil {
+			m["separator"] = c.Separator.encode(format)
+		}
+		for key, value := range map[string]string{"block": c.Block, "entity": c.Entity, "storage": c.Storage, "source": c.Source} {
+			if value != "" {
+				m[key] = value
+			}
+		}
+	default:
+		m["text"] = c.Text
+	}
+
+	c.Style.encode(m, format)
+
+	if len(c.Extra) > 0 {
+		m["extra"] = encodeList(c.Extra, format)
+	}
+
+	return m
+}
+
+func encodeList(components []ChatComponent, format EventFormat) []any {
+	list := make([]any, len(components))
+	for i, child := range components {
+		list[i] = child.encode(format)
+	}
+	return list
+}
+
+func (s Style) encode(m map[string]any, format EventFormat) {
+	if s.Color != "" {
+		m["color"] = string(s.Color)
+	}
+
+	for key, value := range map[string]*bool{
+		"bold":          s.Bold,
+		"italic":        s.Italic,
+		"underlined":    s.Underlined,
+		"strikethrough": s.Strikethrough,
+		"obfuscated":    s.Obfuscated,
+	} {
+		if value != nil {
+			m[key] = *value
+		}
+	}
+
+	if s.Font != "" {
+		m["font"] = s.Font
+	}
+	if s.Insertion != "" {
+		m["insertion"] = s.Insertion
+	}
+	if s.ShadowColor != nil {
+		m["shadow_color"] = *s.ShadowColor
+	}
+
+	if s.ClickEvent != nil {
+		click := map[string]any{"action": s.ClickEvent.Action}
+		if format == SnakeCaseEvents {
+			key := clickKeys[s.ClickEvent.Action]
+			if key == "" {
+				key = "value"
+			}
+
+			if page, err := strconv.Atoi(s.ClickEvent.Value); key == "page" && err == nil {
+				click[key] = page
+			} else {
+				click[key] = s.ClickEvent.Value
+			}
+			m["click_event"] = click
+		} else {
+			click["value"] = s.ClickEvent.Value
+			m["clickEvent"] = click
+		}
+	}
+
+	if s.HoverEvent != nil {
+		if format == SnakeCaseEvents {
+			m["hover_event"] = s.HoverEvent.encodeSnake(format)
+		} else {
+			m["hoverEvent"] = s.HoverEvent.encodeCamel(format)
+		}
+	}
+}
+
+func (h *HoverEvent) encodeCamel(format EventFormat) map[string]any {
+	hover := map[string]any{"action": h.Action}
+
+	switch {
+	case h.Text != nil:
+		hover["contents"] = h.Text.encode(format)
+	case h.Item != nil:
+		item := map[string]any{"id": h.Item.ID}
+		if h.Item.Count > 1 {
+			item["count"] = h.Item.Count
+		}
+		if h.Item.Tag != "" {
+			item["tag"] = h.Item.Tag
+		}
+		if len(h.Item.Components) > 0 {
+			item["components"] = h.Item.Components
+		}
+		hover["contents"] = item
+	case h.Entity != nil:
+		entity := map[string]any{"type": h.Entity.Type, "id": h.Entity.ID}
+		if h.Entity.Name != nil {
+			entity["name"] = h.Entity.Name.encode(format)
+		}
+		hover["contents"] = entity
+	}
+
+	return hover
+}
+
+func (h *HoverEvent) encodeSnake(format EventFormat) map[string]any {
+	hover := map[string]any{"action": h.Action}
+
+	switch {
+	case h.Text != nil:
+		hover["value"] = h.Text.encode(format)
+	case h.Item != nil:
+		hover["id"] = h.Item.ID
+		if h.Item.Count > 1 {
+			hover["count"] = h.Item.Count
+		}
+		if len(h.Item.Components) > 0 {
+			hover["components"] = h.Item.Components
+		}
+	case h.Entity != nil:
+		hover["id"] = h.Entity.Type
+		hover["uuid"] = h.Entity.ID
+		if h.Entity.Name != nil {
+			hover["name"] = h.Entity.Name.encode(format)
+		}
+	}
+
+	return hover
+}
+
+// toTag converts an encoded component value into NBT. Lists that mix
+// compounds with other tags wrap the others as {"": value}, like vanilla.
+func toTag(value any) (nbt.Tag, error) {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return nbt.Byte(1), nil
+		}
+		return nbt.Byte(0), nil
+	case uuid.UUID:
+		ints := make(nbt.IntArray, 4)
+		for i := range ints {
+			ints[i] = int32(uint32(v[4*i])<<24 | uint32(v[4*i+1])<<16 | uint32(v[4*i+2])<<8 | uint32(v[4*i+3]))
+		}
+		return ints, nil
+	case map[string]any:
+		compound := make(nbt.Compound, len(v))
+		for key, child := range v {
+			tag, err := toTag(child)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			compound[key] = tag
+		}
+		return compound, nil
+	case []any:
+		elements := make([]nbt.Tag, len(v))
+		mixed := false
+		for i, child := range v {
+			tag, err := toTag(child)
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = tag
+			mixed = mixed || tag.Type() != elements[0].Type()
+		}
+
+		if mixed {
+			for i, tag := range elements {
+				if tag.Type() != nbt.TagCompound {
+					elements[i] = nbt.Compound{"": tag}
+				}
+			}
+		}
+		return nbt.NewList(elements...)
+	}
+
+	return nbt.ToTag(value)
+}
+
+func decode(value any) (ChatComponent, error) {
+	switch v := value.(type) {
+	case nil:
+		return ChatComponent{}, nil
+	case string:
+		return ChatComponent{Text: v}, nil
+	case []any:
+		if len(v) == 0 {
+			return ChatComponent{}, nil
+		}
+
+		parts, err := decodeList(v)
+		if err != nil {
+			return ChatComponent{}, err
+		}
+
+		base := parts[0]
+		base.Extra = append(base.Extra, parts[1:]...)
+		return base, nil
+	case map[string]any:
+		return decodeMap(v)
+	}
+
+	if text, ok := scalarString(value); ok {
+		return ChatComponent{Text: text}, nil
+	}
+
+	return ChatComponent{}, fmt.Errorf("component: unexpected %T", value)
+}
+
+func decodeList(values []any) ([]ChatComponent, error) {
+	components := make([]ChatComponent, 0, len(values))
+	for i, value := range values {
+		child, err := decode(value)
+		if err != nil {
+			return nil, fmt.Errorf("[%d]: %w", i, err)
+		}
+		components = append(components, child)
+	}
+	return components, nil
+}
+
+func decodeMap(m map[string]any) (c ChatComponent, err error) {
+	if inner, ok := m[""]; ok && len(m) == 1 {
+		return decode(inner)
+	}
+
+	c.Text, _ = scalarString(m["text"])
+	c.Translate, _ = m["translate"].(string)
+	c.Fallback, _ = m["fallback"].(string)
+	c.Selector, _ = m["selector"].(string)
+	c.Keybind, _ = m["keybind"].(string)
+	c.NBT, _ = m["nbt"].(string)
+	c.Block, _ = m["block"].(string)
+	c.Entity, _ = m["entity"].(string)
+	c.Storage, _ = m["storage"].(string)
+	c.Source, _ = m["source"].(string)
+	if interpret := decodeBool(m["interpret"]); interpret != nil {
+		c.Interpret = *interpret
+	}
+
+	if with, ok := m["with"].([]any); ok {
+		if c.With, err = decodeList(with); err != nil {
+			return c, fmt.Errorf("with: %w", err)
+		}
+	}
+
+	if extra, ok := m["extra"].([]any); ok {
+		if c.Extra, err = decodeList(extra); err != nil {
+			return c, fmt.Errorf("extra: %w", err)
+		}
+	}
+
+	if score, ok := m["score"].(map[string]any); ok {
+		c.Score = &Score{}
+		c.Score.Name, _ = score["name"].(string)
+		c.Score.Objective, _ = score["objective"].(string)
+		c.Score.Value, _ = scalarString(score["value"])
+	}
+
+	if separator, ok := m["separator"]; ok {
+		decoded, err := decode(separator)
+		if err != nil {
+			return c, fmt.Errorf("separator: %w", err)
+		}
+		c.Separator = &decoded
+	}
+
+	c.Style, err = decodeStyle(m)
+	return c, err
+}
+
+func decodeStyle(m map[string]any) (s Style, err error) {
+	if color, ok := m["color"].(string); ok {
+		s.Color = Color(color)
+	}
+
+	s.Bold = decodeBool(m["bold"])
+	s.Italic = decodeBool(m["italic"])
+	s.Underlined = decodeBool(m["underlined"])
+	s.Strikethrough = decodeBool(m["strikethrough"])
+	s.Obfuscated = decodeBool(m["obfuscated"])
+	s.Font, _ = m["font"].(string)
+	s.Insertion, _ = m["insertion"].(string)
+
+	switch shadow := m["shadow_color"].(type) {
+	case nil:
+	case []any:
+		// [r, g, b, a] floats in 0..1.
+		if len(shadow) == 4 {
+			var argb uint32
+			for i, channel := range []int{3, 0, 1, 2} {
+				f, _ := number(shadow[channel])
+				argb |= uint32(math.Round(math.Max(0, math.Min(1, f))*255)) << (24 - 8*i)
+			}
+			value := int32(argb)
+			s.ShadowColor = &value
+		}
+	default:
+		if f, ok := number(shadow); ok {
+			value := int32(int64(f))
+			s.ShadowColor = &value
+		}
+	}
+
+	click, ok := m["clickEvent"].(map[string]any)
+	if !ok {
+		click, ok = m["click_event"].(map[string]any)
+	}
+	if ok {
+		s.ClickEvent = decodeClick(click)
+	}
+
+	hover, ok := m["hoverEvent"].(map[string]any)
+	if !ok {
+		hover, ok = m["hover_event"].(map[string]any)
+	}
+	if ok {
+		if s.HoverEvent, err = decodeHover(hover); err != nil {
+			return s, fmt.Errorf("hover event: %w", err)
+		}
+	}
+
+	return s, nil
+}
+
+func decodeClick(m map[string]any) *ClickEvent {
+	click := &ClickEvent{}
+	click.Action, _ = m["action"].(string)
+
+	value, ok := scalarString(m["value"])
+	if !ok {
+		if key := clickKeys[click.Action]; key != "" {
+			value, _ = scalarString(m[key])
+		}
+	}
+	click.Value = value
+
+	return click
+}
+
+func decodeHover(m map[string]any) (*HoverEvent, error) {
+	hover := &HoverEvent{}
+	hover.Action, _ = m["action"].(string)
+
+	contents, hasContents := m["contents"]
+	if !hasContents && hover.Action == ShowText {
+		contents, hasContents = m["value"]
+	}
+
+	switch hover.Action {
+	case ShowText:
+		if hasContents {
+			text, err := decode(contents)
+			if err != nil {
+				return nil, err
+			}
+			hover.Text = &text
+		}
+	case ShowItem:
+		item := &HoverItem{Count: 1}
+		source := m
+		switch contents := contents.(type) {
+		case string:
+			item.ID = contents
+			source = nil
+		case map[string]any:
+			source = contents
+		}
+
+		if source != nil {
+			item.ID, _ = source["id"].(string)
+			if count, ok := number(source["count"]); ok {
+				item.Count = int32(count)
+			}
+			item.Tag, _ = source["tag"].(string)
+			item.Components, _ = source["components"].(map[string]any)
+		}
+
+		// The pre-1.16 form stores the item as SNBT text in value.
+		if !hasContents && item.ID == "" {
+			if legacy, err := decode(m["value"]); err == nil {
+				item.Tag = legacy.String()
+			}
+		}
+		hover.Item = item
+	case ShowEntity:
+		entity := &HoverEntity{}
+		name := m["name"]
+		if contents, ok := contents.(map[string]any); ok {
+			entity.Type, _ = contents["type"].(string)
+			entity.ID, _ = decodeUUID(contents["id"])
+			name = contents["name"]
+		} else {
+			entity.Type, _ = m["id"].(string)
+			entity.ID, _ = decodeUUID(m["uuid"])
+		}
+
+		if name != nil {
+			decoded, err := decode(name)
+			if err != nil {
+				return nil, err
+			}
+			entity.Name = &decoded
+		}
+		hover.Entity = entity
+	}
+
+	return hover, nil
+}
+
+func decodeUUID(value any) (uuid.UUID, bool) {
+	var ints []int64
+	switch v := value.(type) {
+	case string:
+		id, err := uuid.Parse(v)
+		return id, err == nil
+	case []int32:
+		for _, i := range v {
+			ints = append(ints, int64(i))
+		}
+	case []any:
+		for _, element := range v {
+			f, ok := number(element)
+			if !ok {
+				return uuid.Nil, false
+			}
+			ints = append(ints, int64(f))
+		}
+	}
+
+	if len(ints) != 4 {
+		return uuid.Nil, false
+	}
+
+	var id uuid.UUID
+	for i, word := range ints {
+		id[4*i] = byte(word >> 24)
+		id[4*i+1] = byte(word >> 16)
+		id[4*i+2] = byte(word >> 8)
+		id[4*i+3] = byte(word)
+	}
+	return id, true
+}
+
+// decodeBool accepts JSON booleans and NBT bytes.
+func decodeBool(value any) *bool {
+	switch v := value.(type) {
+	case bool:
+		return &v
+	case string:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return &b
+		}
+		return nil
+	}
+
+	if f, ok := number(value); ok {
+		b := f != 0
+		return &b
+	}
+	return nil
+}
+
+func number(value any) (float64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func scalarString(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32), true
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), true
+	}
+
+	if f, ok := number(value); ok {
+		return strconv.FormatInt(int64(f), 10), true
+	}
+	return "", false
+}
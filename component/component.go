@@ -1,64 +1,122 @@
 package component
 
 import (
-	"encoding/json"
 	"strings"
 )
 
+// ChatComponent is a Minecraft text component. Exactly one content field is
+// expected to be set: Text, Translate, Score, Selector, Keybind or NBT.
 type ChatComponent struct {
-	Text      string          `json:"text"`
-	With      []ChatComponent `json:"with"`
-	Translate string          `json:"translate"`
-	Extra     []ChatComponent `json:"extra"`
+	Style
+
+	Text string
+
+	Translate string
+	Fallback  string
+	With      []ChatComponent
+
+	Score *Score
+
+	Selector  string
+	Separator *ChatComponent
+
+	Keybind string
+
+	NBT       string
+	Interpret bool
+	Block     string
+	Entity    string
+	Storage   string
+	Source    string
+
+	Extra []ChatComponent
 }
 
-func (c *ChatComponent) UnmarshalJSON(data []byte) error {
-	type Alias ChatComponent
+type Score struct {
+	Name      string
+	Objective string
+	Value     string
+}
 
-	aux := &struct {
-		With  []json.RawMessage `json:"with"`
-		Extra []json.RawMessage `json:"extra"`
-		*Alias
-	}{
-		Alias: (*Alias)(c),
-	}
+func Text(text string) ChatComponent {
+	return ChatComponent{Text: text}
+}
 
-	if err := json.Unmarshal(data, &aux); err != nil {
-		return err
-	}
+func Translatable(key string, args ...ChatComponent) ChatComponent {
+	return ChatComponent{Translate: key, With: args}
+}
+
+// Append adds children to the component and returns it.
+func (c ChatComponent) Append(children ...ChatComponent) ChatComponent {
+	c.Extra = append(c.Extra, children...)
+	return c
+}
 
-	for _, raw := range aux.With {
-		var comp ChatComponent
-		if err := json.Unmarshal(raw, &comp); err == nil {
-			c.With = append(c.With, comp)
-		} else {
-			var s string
-			if err := json.Unmarshal(raw, &s); err == nil {
-				c.With = append(c.With, ChatComponent{Text: s})
+// Styled returns the component with its style replaced.
+func (c ChatComponent) Styled(style Style) ChatComponent {
+	c.Style = style
+	return c
+}
+
+// String renders the component as plain text, substituting translation
+// arguments and stripping legacy § formatting codes. Translation keys are
+// not looked up; use Render for that.
+func (c ChatComponent) String() string {
+	return c.Render(nil)
+}
+
+func (c ChatComponent) writePlain(sb *strings.Builder, lang *Translations) {
+	if c.Translate != "" {
+		for _, part := range c.translationParts(lang) {
+			if part.arg >= 0 {
+				c.With[part.arg].writePlain(sb, lang)
+			} else {
+				sb.WriteString(StripCodes(part.text))
 			}
 		}
+	} else {
+		sb.WriteString(StripCodes(c.content()))
 	}
 
-	for _, raw := range aux.Extra {
-		var comp ChatComponent
-		if err := json.Unmarshal(raw, &comp); err == nil {
-			c.Extra = append(c.Extra, comp)
-		}
+	for _, extra := range c.Extra {
+		extra.writePlain(sb, lang)
 	}
-
-	return nil
 }
 
-func (c *ChatComponent) String() string {
-	var sb strings.Builder
-	sb.WriteString(c.Text)
+// content returns the literal text of every non-translatable component.
+// Selectors, keybinds and NBT paths are resolved by the server or client, so
+// their raw values are shown.
+func (c ChatComponent) content() string {
+	switch {
+	case c.Score != nil:
+		return c.Score.Value
+	case c.Selector != "":
+		return c.Selector
+	case c.Keybind != "":
+		return c.Keybind
+	case c.NBT != "":
+		return c.NBT
+	}
+	return c.Text
+}
 
-	for _, part := range c.With {
-		sb.WriteString(part.String())
+// StripCodes removes legacy § formatting codes from s.
+func StripCodes(s string) string {
+	if !strings.Contains(s, "§") {
+		return s
 	}
 
-	for _, extra := range c.Extra {
-		sb.WriteString(extra.String())
+	var sb strings.Builder
+	skip := false
+	for _, r := range s {
+		switch {
+		case skip:
+			skip = false
+		case r == '§':
+			skip = true
+		default:
+			sb.WriteRune(r)
+		}
 	}
 	return sb.String()
 }
@@ -0,0 +1,204 @@
+package component
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/obeliskdev/gophermc/nbt"
+)
+
+func sample() ChatComponent {
+	entity := uuid.MustParse("f84c6a79-0a4e-45e0-879b-cd49ebd4c4e2")
+	name := Text("Steve")
+	hover := Text("click me").Styled(Style{Color: Gold})
+	shadow := int32(-16777216)
+
+	return ChatComponent{
+		Style: Style{
+			Color:       HexColor(0x12, 0xAB, 0xEF),
+			Bold:        Bool(true),
+			Italic:      Bool(false),
+			Font:        "minecraft:uniform",
+			Insertion:   "inserted",
+			ShadowColor: &shadow,
+			ClickEvent:  &ClickEvent{Action: RunCommand, Value: "/help"},
+			HoverEvent:  &HoverEvent{Action: ShowText, Text: &hover},
+		},
+		Translate: "chat.type.text",
+		Fallback:  "<%s> %s",
+		With: []ChatComponent{
+			{Text: "Steve", Style: Style{HoverEvent: &HoverEvent{Action: ShowEntity, Entity: &HoverEntity{Type: "minecraft:player", ID: entity, Name: &name}}}},
+			{Text: "hi", Style: Style{Underlined: Bool(true)}},
+		},
+		Extra: []ChatComponent{
+			{Score: &Score{Name: "@p", Objective: "kills", Value: "3"}},
+			{Selector: "@a", Separator: &ChatComponent{Text: ", "}},
+			{Keybind: "key.jump", Style: Style{Obfuscated: Bool(true), Strikethrough: Bool(true)}},
+			{NBT: "Inventory[0]", Interpret: true, Entity: "@s"},
+			{Text: "item", Style: Style{HoverEvent: &HoverEvent{Action: ShowItem, Item: &HoverItem{ID: "minecraft:diamond", Count: 2}}}},
+			{Text: "page", Style: Style{ClickEvent: &ClickEvent{Action: ChangePage, Value: "4"}}},
+		},
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	for _, format := range []EventFormat{CamelCaseEvents, SnakeCaseEvents} {
+		data, err := sample().JSON(format)
+		if err != nil {
+			t.Fatalf("format %d: JSON: %v", format, err)
+		}
+
+		var decoded ChatComponent
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("format %d: Unmarshal: %v", format, err)
+		}
+
+		if !reflect.DeepEqual(decoded, sample()) {
+			t.Fatalf("format %d: round trip mismatch\n%s\n%+v", format, data, decoded)
+		}
+	}
+}
+
+func TestNBTRoundTrip(t *testing.T) {
+	for _, format := range []EventFormat{CamelCaseEvents, SnakeCaseEvents} {
+		tag, err := sample().NBTTag(format)
+		if err != nil {
+			t.Fatalf("format %d: NBTTag: %v", format, err)
+		}
+
+		data, err := nbt.Marshal(tag)
+		if err != nil {
+			t.Fatalf("format %d: Marshal: %v", format, err)
+		}
+
+		var decoded ChatComponent
+		if err := nbt.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("format %d: Unmarshal: %v", format, err)
+		}
+
+		if !reflect.DeepEqual(decoded, sample()) {
+			t.Fatalf("format %d: round trip mismatch\n%s\n%+v", format, nbt.Stringify(tag), decoded)
+		}
+	}
+
+	if tag, _ := Text("plain").NBTTag(CamelCaseEvents); tag != nbt.String("plain") {
+		t.Fatalf("expected unstyled text as a bare string, got %v", tag)
+	}
+}
+
+func TestDecodeVanillaJSON(t *testing.T) {
+	data := `{"text":"","extra":["plain",{"text":"red","color":"red","bold":true},1,` +
+		`{"translate":"death.attack.player","with":[{"text":"Alex"},"Steve"]}],` +
+		`"hoverEvent":{"action":"show_entity","contents":{"type":"minecraft:player","id":[-129209735,172901856,-2019832503,-338377502]}},` +
+		`"clickEvent":{"action":"open_url","value":"https://example.com"}}`
+
+	var c ChatComponent
+	if err := json.Unmarshal([]byte(data), &c); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if len(c.Extra) != 4 || c.Extra[0].Text != "plain" || c.Extra[2].Text != "1" {
+		t.Fatalf("unexpected extra %+v", c.Extra)
+	}
+	if c.Extra[1].Color != Red || !c.Extra[1].IsBold() {
+		t.Fatalf("unexpected style %+v", c.Extra[1].Style)
+	}
+	if c.HoverEvent.Entity.ID != uuid.MustParse("f84c6a79-0a4e-45e0-879b-cd49ebd4c4e2") {
+		t.Fatalf("unexpected entity %+v", c.HoverEvent.Entity)
+	}
+	if c.ClickEvent.Value != "https://example.com" {
+		t.Fatalf("unexpected click %+v", c.ClickEvent)
+	}
+
+	var legacy ChatComponent
+	if err := json.Unmarshal([]byte(`{"text":"x","hoverEvent":{"action":"show_text","value":[{"text":"a"},"b"]}}`), &legacy); err != nil {
+		t.Fatalf("Unmarshal legacy hover: %v", err)
+	}
+	if legacy.HoverEvent.Text.String() != "ab" {
+		t.Fatalf("unexpected legacy hover %+v", legacy.HoverEvent)
+	}
+
+	var snake ChatComponent
+	if err := json.Unmarshal([]byte(`{"text":"x","click_event":{"action":"change_page","page":7}}`), &snake); err != nil {
+		t.Fatalf("Unmarshal snake click: %v", err)
+	}
+	if snake.ClickEvent.Value != "7" {
+		t.Fatalf("unexpected snake click %+v", snake.ClickEvent)
+	}
+}
+
+func TestDecodeNBTHeterogeneousList(t *testing.T) {
+	tag := nbt.Compound{
+		"text": nbt.String("a"),
+		"bold": nbt.Byte(1),
+		"extra": &nbt.List{ElementType: nbt.TagCompound, Elements: []nbt.Tag{
+			nbt.Compound{"": nbt.String("b")},
+			nbt.Compound{"text": nbt.String("c"), "italic": nbt.Byte(0)},
+		}},
+	}
+
+	var c ChatComponent
+	if err := c.UnmarshalNBT(tag); err != nil {
+		t.Fatalf("UnmarshalNBT: %v", err)
+	}
+
+	if c.String() != "abc" || !c.IsBold() || c.Extra[1].Italic == nil || *c.Extra[1].Italic {
+		t.Fatalf("unexpected component %+v", c)
+	}
+
+	mixed, err := Text("a").Append(Text("b"), Text("c").Styled(Style{Color: Red})).NBTTag(CamelCaseEvents)
+	if err != nil {
+		t.Fatalf("NBTTag: %v", err)
+	}
+	if list := mixed.(nbt.Compound)["extra"].(*nbt.List); list.ElementType != nbt.TagCompound {
+		t.Fatalf("expected compound list, got %s", nbt.Stringify(mixed))
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	tests := []struct {
+		name string
+		c    ChatComponent
+		want string
+	}{
+		{"text", Text("hello").Append(Text(" world")), "hello world"},
+		{"legacy codes", Text("§aGreen §lbold§r"), "Green bold"},
+		{"fallback", ChatComponent{Translate: "x.y", Fallback: "<%s> %s", With: []ChatComponent{Text("Steve"), Text("hi")}}, "<Steve> hi"},
+		{"indexed", ChatComponent{Translate: "k", Fallback: "%2$s then %1$s, 100%%", With: []ChatComponent{Text("a"), Text("b")}}, "b then a, 100%"},
+		{"missing arg", ChatComponent{Translate: "k", Fallback: "%s and %s", With: []ChatComponent{Text("a")}}, "%s and %s"},
+		{"bad conversion", ChatComponent{Translate: "k", Fallback: "%d", With: []ChatComponent{Text("a")}}, "%d"},
+		{"untranslated key", Translatable("chat.type.text", Text("Steve"), Text("hi")), "chat.type.text"},
+		{"nested args", ChatComponent{Translate: "k", Fallback: "[%s]", With: []ChatComponent{Text("a").Append(Text("b"))}}, "[ab]"},
+		{"score", ChatComponent{Score: &Score{Name: "@p", Objective: "o", Value: "12"}}, "12"},
+		{"keybind", ChatComponent{Keybind: "key.jump"}, "key.jump"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.c.String(); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestColor(t *testing.T) {
+	if r, g, b, ok := Gold.RGB(); !ok || r != 0xFF || g != 0xAA || b != 0 {
+		t.Fatalf("unexpected gold %02x%02x%02x", r, g, b)
+	}
+	if r, g, b, ok := Color("#0a0B0c").RGB(); !ok || r != 10 || g != 11 || b != 12 {
+		t.Fatalf("unexpected hex %02x%02x%02x", r, g, b)
+	}
+	if _, _, _, ok := Color("reset").RGB(); ok {
+		t.Fatal("reset should not resolve")
+	}
+	if Green.Code() != 0xa || NamedColors[0xc] != Red {
+		t.Fatal("legacy code order mismatch")
+	}
+
+	parent := Style{Color: Red, Bold: Bool(true)}
+	child := Style{Bold: Bool(false)}.Inherit(parent)
+	if child.Color != Red || child.IsBold() {
+		t.Fatalf("unexpected inherited style %+v", child)
+	}
+}
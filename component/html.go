@@ -0,0 +1,96 @@
+package component
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+)
+
+// HTMLRenderer renders components as HTML spans. All text is escaped and
+// styles are generated from parsed values only, so the output is safe to
+// embed even when the component came from an untrusted server. Obfuscated
+// runs carry the mc-obfuscated class, letting a page animate them.
+type HTMLRenderer struct {
+	Lang *Translations
+}
+
+func (r HTMLRenderer) Render(c ChatComponent) string {
+	var sb strings.Builder
+	for _, segment := range c.Segments(r.Lang) {
+		writeHTMLSegment(&sb, segment)
+	}
+	return sb.String()
+}
+
+func writeHTMLSegment(sb *strings.Builder, segment Segment) {
+	style := segment.Style
+	text := segment.Text
+	if style.IsObfuscated() {
+		text = obfuscate(text)
+	}
+	text = strings.ReplaceAll(html.EscapeString(text), "\n", "<br>")
+
+	href := safeURL(style.ClickEvent)
+	if href != "" {
+		fmt.Fprintf(sb, `<a href="%s" rel="noopener noreferrer nofollow" target="_blank">`, html.EscapeString(href))
+	}
+
+	sb.WriteString("<span")
+	if style.IsObfuscated() {
+		sb.WriteString(` class="mc-obfuscated"`)
+	}
+	if css := cssStyle(style); css != "" {
+		fmt.Fprintf(sb, ` style="%s"`, css)
+	}
+	if style.HoverEvent != nil && style.HoverEvent.Action == ShowText && style.HoverEvent.Text != nil {
+		fmt.Fprintf(sb, ` title="%s"`, html.EscapeString(style.HoverEvent.Text.String()))
+	}
+	sb.WriteByte('>')
+	sb.WriteString(text)
+	sb.WriteString("</span>")
+
+	if href != "" {
+		sb.WriteString("</a>")
+	}
+}
+
+func cssStyle(style Style) string {
+	var decls []string
+	if r, g, b, ok := style.Color.RGB(); ok {
+		decls = append(decls, fmt.Sprintf("color:#%02x%02x%02x", r, g, b))
+	}
+	if style.IsBold() {
+		decls = append(decls, "font-weight:bold")
+	}
+	if style.IsItalic() {
+		decls = append(decls, "font-style:italic")
+	}
+
+	var lines []string
+	if style.IsUnderlined() {
+		lines = append(lines, "underline")
+	}
+	if style.IsStrikethrough() {
+		lines = append(lines, "line-through")
+	}
+	if len(lines) > 0 {
+		decls = append(decls, "text-decoration:"+strings.Join(lines, " "))
+	}
+
+	return strings.Join(decls, ";")
+}
+
+// safeURL returns the target of an open_url click event if it is an absolute
+// http or https URL, and "" for anything else (javascript:, data:, ...).
+func safeURL(click *ClickEvent) string {
+	if click == nil || click.Action != OpenURL {
+		return ""
+	}
+
+	u, err := url.Parse(click.Value)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return u.String()
+}
@@ -0,0 +1,217 @@
+package component
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// SectionSign prefixes the formatting codes the client understands.
+	SectionSign = '§'
+	// Ampersand is the conventional stand-in for § in configs and commands.
+	Ampersand = '&'
+)
+
+const legacyCodes = "0123456789abcdefklmnor"
+
+// ParseLegacy converts a string with legacy formatting codes introduced by
+// char (usually SectionSign or Ampersand) into a component. Colors reset the
+// decorations, §r resets everything, and hex colors are accepted in the
+// BungeeCord "§x§r§r§g§g§b§b" form as well as "&#rrggbb". A prefix that is
+// not followed by a valid code is kept as text.
+func ParseLegacy(text string, char rune) ChatComponent {
+	var segments []Segment
+	var style Style
+	var sb strings.Builder
+
+	flush := func() {
+		push(&segments, sb.String(), style)
+		sb.Reset()
+	}
+
+	for len(text) > 0 {
+		r, size := utf8.DecodeRuneInString(text)
+		if r != char {
+			sb.WriteString(text[:size])
+			text = text[size:]
+			continue
+		}
+
+		rest := text[size:]
+		if color, n := legacyHex(rest, char); n > 0 {
+			flush()
+			style = legacyColor(style, color)
+			text = rest[n:]
+			continue
+		}
+
+		if rest == "" || strings.IndexByte(legacyCodes, lower(rest[0])) < 0 {
+			sb.WriteString(text[:size])
+			text = rest
+			continue
+		}
+
+		flush()
+		style = applyLegacyCode(style, Style{}, lower(rest[0]))
+		text = rest[1:]
+	}
+	flush()
+
+	switch {
+	case len(segments) == 0:
+		return Text("")
+	case len(segments) == 1 && segments[0].Style.IsZero():
+		return Text(segments[0].Text)
+	}
+
+	root := Text("")
+	for _, segment := range segments {
+		root.Extra = append(root.Extra, Text(segment.Text).Styled(segment.Style))
+	}
+	return root
+}
+
+// legacyHex reads a hex color following the prefix character and returns it
+// with the number of bytes consumed, or 0 if there is none.
+func legacyHex(rest string, char rune) (Color, int) {
+	if rest == "" {
+		return "", 0
+	}
+
+	if rest[0] == '#' && char != SectionSign {
+		if len(rest) >= 7 && isHex(rest[1:7]) {
+			return Color("#" + strings.ToUpper(rest[1:7])), 7
+		}
+		return "", 0
+	}
+
+	if lower(rest[0]) != 'x' {
+		return "", 0
+	}
+
+	prefix := string(char)
+	digits := make([]byte, 0, 6)
+	i := 1
+	for range 6 {
+		if !strings.HasPrefix(rest[i:], prefix) {
+			return "", 0
+		}
+		i += len(prefix)
+		if i >= len(rest) || !isHex(rest[i:i+1]) {
+			return "", 0
+		}
+		digits = append(digits, rest[i])
+		i++
+	}
+
+	return Color("#" + strings.ToUpper(string(digits))), i
+}
+
+func isHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := lower(s[i])
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func lower(c byte) byte {
+	if c >= 'A' && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
+
+// LegacyRenderer flattens components into legacy formatting codes. Click,
+// hover, font and insertion have no legacy form and are dropped; hex colors
+// use the "§x§r§r§g§g§b§b" form.
+type LegacyRenderer struct {
+	// Char defaults to SectionSign.
+	Char rune
+	Lang *Translations
+}
+
+func (r LegacyRenderer) Render(c ChatComponent) string {
+	char := r.Char
+	if char == 0 {
+		char = SectionSign
+	}
+
+	var sb strings.Builder
+	var previous Style
+	for _, segment := range c.Segments(r.Lang) {
+		style := legacyStyle(segment.Style)
+		if !style.Equal(previous) {
+			writeLegacyCodes(&sb, char, style, previous)
+			previous = style
+		}
+		sb.WriteString(segment.Text)
+	}
+	return sb.String()
+}
+
+// legacyStyle keeps the parts of a style that legacy codes can express.
+func legacyStyle(style Style) Style {
+	legacy := Style{Color: style.Color}
+	if _, _, _, ok := style.Color.RGB(); !ok {
+		legacy.Color = ""
+	}
+
+	if style.IsObfuscated() {
+		legacy.Obfuscated = Bool(true)
+	}
+	if style.IsBold() {
+		legacy.Bold = Bool(true)
+	}
+	if style.IsStrikethrough() {
+		legacy.Strikethrough = Bool(true)
+	}
+	if style.IsUnderlined() {
+		legacy.Underlined = Bool(true)
+	}
+	if style.IsItalic() {
+		legacy.Italic = Bool(true)
+	}
+	return legacy
+}
+
+// writeLegacyCodes switches from the previous style to style. Without a
+// color to reset the decorations, §r is used, unless nothing was set yet.
+func writeLegacyCodes(sb *strings.Builder, char rune, style, previous Style) {
+	code := func(c byte) {
+		sb.WriteRune(char)
+		sb.WriteByte(c)
+	}
+
+	switch {
+	case style.Color == "":
+		if !previous.IsZero() {
+			code('r')
+		}
+	case style.Color.Named():
+		code(legacyCodes[style.Color.Code()])
+	default:
+		code('x')
+		for _, digit := range strings.ToLower(string(style.Color[1:])) {
+			code(byte(digit))
+		}
+	}
+
+	if style.IsObfuscated() {
+		code('k')
+	}
+	if style.IsBold() {
+		code('l')
+	}
+	if style.IsStrikethrough() {
+		code('m')
+	}
+	if style.IsUnderlined() {
+		code('n')
+	}
+	if style.IsItalic() {
+		code('o')
+	}
+}
@@ -0,0 +1,101 @@
+package component
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLegacy(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		char  rune
+		want  ChatComponent
+	}{
+		{"plain", "hello", SectionSign, Text("hello")},
+		{"empty", "", SectionSign, Text("")},
+		{"single color", "§ahi", SectionSign, Text("").Append(Text("hi").Styled(Style{Color: Green}))},
+		{
+			"color resets decorations", "§l§cbold? §ono",
+			SectionSign,
+			Text("").Append(
+				Text("bold? ").Styled(Style{Color: Red}),
+				Text("no").Styled(Style{Color: Red, Italic: Bool(true)}),
+			),
+		},
+		{
+			"reset", "&6&lgold&r plain &nline",
+			Ampersand,
+			Text("").Append(
+				Text("gold").Styled(Style{Color: Gold, Bold: Bool(true)}),
+				Text(" plain "),
+				Text("line").Styled(Style{Underlined: Bool(true)}),
+			),
+		},
+		{
+			"bungee hex", "§x§1§2§a§B§c§Dhex§lbold",
+			SectionSign,
+			Text("").Append(
+				Text("hex").Styled(Style{Color: "#12ABCD"}),
+				Text("bold").Styled(Style{Color: "#12ABCD", Bold: Bool(true)}),
+			),
+		},
+		{"ampersand hex", "&#00ff00go", Ampersand, Text("").Append(Text("go").Styled(Style{Color: "#00FF00"}))},
+		{"uppercase code", "§Ahi", SectionSign, Text("").Append(Text("hi").Styled(Style{Color: Green}))},
+		{"invalid codes kept", "Tom & Jerry &z §", Ampersand, Text("Tom & Jerry &z §")},
+		{"truncated hex kept", "&x&1&2go", Ampersand, Text("").Append(Text("&x"), Text("go").Styled(Style{Color: DarkGreen}))},
+		{"redundant codes", "§a§a§bx§b", SectionSign, Text("").Append(Text("x").Styled(Style{Color: Aqua}))},
+	}
+
+	for _, tt := range tests {
+		got := ParseLegacy(tt.input, tt.char)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: expected %+v, got %+v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestLegacyRenderer(t *testing.T) {
+	c := Text("a").Styled(Style{Color: Red}).Append(
+		Text("b").Styled(Style{Bold: Bool(true), ClickEvent: &ClickEvent{Action: OpenURL, Value: "https://example.com"}}),
+		Text("c").Styled(Style{Color: HexColor(0x12, 0xAB, 0xEF), Italic: Bool(true)}),
+	).Append(Text(" d"))
+
+	tests := []struct {
+		char rune
+		want string
+	}{
+		{0, "§ca§c§lb§x§1§2§a§b§e§f§oc§c d"},
+		{Ampersand, "&ca&c&lb&x&1&2&a&b&e&f&oc&c d"},
+	}
+
+	for _, tt := range tests {
+		if got := (LegacyRenderer{Char: tt.char}).Render(c); got != tt.want {
+			t.Errorf("char %q: expected %q, got %q", tt.char, tt.want, got)
+		}
+	}
+
+	if got := (LegacyRenderer{}).Render(Text("plain")); got != "plain" {
+		t.Errorf("expected unstyled text without codes, got %q", got)
+	}
+	bold := Text("x").Styled(Style{Bold: Bool(true)}).Append(Text("y").Styled(Style{Bold: Bool(false)}))
+	if got := (LegacyRenderer{}).Render(bold); got != "§lx§ry" {
+		t.Errorf("unexpected reset rendering %q", got)
+	}
+}
+
+func TestLegacyRoundTrip(t *testing.T) {
+	for _, input := range []string{
+		"§ahello §lworld§r!",
+		"§x§f§f§0§0§8§8pink §kmagic",
+		"§7[§cAdmin§7] §fSteve§7: §ohi",
+	} {
+		rendered := LegacyRenderer{}.Render(ParseLegacy(input, SectionSign))
+		if again := (LegacyRenderer{}).Render(ParseLegacy(rendered, SectionSign)); again != rendered {
+			t.Errorf("%q: not stable, %q then %q", input, rendered, again)
+		}
+		if StripCodes(ParseLegacy(input, SectionSign).String()) != StripCodes(input) {
+			t.Errorf("%q: text changed", input)
+		}
+	}
+}
@@ -0,0 +1,497 @@
+package component
+
+import (
+	"strings"
+)
+
+// ParseMiniMessage converts MiniMessage-style markup into a component tree.
+// It understands colors (<red>, <#ff8800>, <color:gold>), decorations and
+// their negation (<bold>, <b>, <!italic>), <reset>, <click:action:value>,
+// <hover:show_text:'markup'>, <insertion:text>, <font:key>, <key:keybind>,
+// <lang:key:args...>, <lang_or:key:fallback:args...>, <selector:@a>,
+// <score:name:objective> and <newline>. Arguments may be quoted with ' or "
+// and a backslash escapes the next character. Unknown or malformed tags are
+// kept as text, \< writes a literal <, and tags left open are closed at the
+// end of the input.
+func ParseMiniMessage(markup string) ChatComponent {
+	p := miniParser{stack: []miniFrame{{}}}
+
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			p.top().appendText(text.String())
+			text.Reset()
+		}
+	}
+
+	for i := 0; i < len(markup); {
+		switch c := markup[i]; {
+		case c == '\\' && i+1 < len(markup) && (markup[i+1] == '<' || markup[i+1] == '\\'):
+			text.WriteByte(markup[i+1])
+			i += 2
+
+		case c == '<':
+			end := tagEnd(markup, i+1)
+			if end < 0 {
+				text.WriteByte(c)
+				i++
+				continue
+			}
+
+			flush()
+			if !p.tag(markup[i+1 : end]) {
+				text.WriteString(markup[i : end+1])
+			}
+			i = end + 1
+
+		default:
+			text.WriteByte(c)
+			i++
+		}
+	}
+	flush()
+
+	for len(p.stack) > 1 {
+		p.pop()
+	}
+
+	root := p.stack[0].component
+	if root.Text == "" && len(root.Extra) == 1 {
+		return root.Extra[0]
+	}
+	return root
+}
+
+type miniFrame struct {
+	key       string
+	component ChatComponent
+}
+
+func (f *miniFrame) appendText(s string) {
+	c := &f.component
+	if len(c.Extra) == 0 {
+		c.Text += s
+		return
+	}
+
+	if n := len(c.Extra); n > 0 && c.Extra[n-1].isPlainText() {
+		c.Extra[n-1].Text += s
+		return
+	}
+	c.Extra = append(c.Extra, Text(s))
+}
+
+type miniParser struct {
+	stack []miniFrame
+}
+
+func (p *miniParser) top() *miniFrame {
+	return &p.stack[len(p.stack)-1]
+}
+
+// pop closes the innermost tag, dropping it if nothing was written inside
+// and folding its style into its only child if that is all it holds, so that
+// <red><bold>x yields a single component.
+func (p *miniParser) pop() {
+	frame := p.stack[len(p.stack)-1]
+	p.stack = p.stack[:len(p.stack)-1]
+
+	c := frame.component
+	switch {
+	case c.Text == "" && len(c.Extra) == 0:
+		return
+	case c.Text == "" && len(c.Extra) == 1:
+		child := c.Extra[0]
+		child.Style = child.Style.Inherit(c.Style)
+		c = child
+	}
+
+	parent := &p.top().component
+	parent.Extra = append(parent.Extra, c)
+}
+
+func (p *miniParser) tag(body string) bool {
+	if name, ok := strings.CutPrefix(body, "/"); ok {
+		return p.close(tagKey(name))
+	}
+
+	args := splitTagArgs(body)
+	if len(args) == 0 || args[0] == "" {
+		return false
+	}
+
+	name := strings.ToLower(args[0])
+	args = args[1:]
+
+	if name == "reset" {
+		for len(p.stack) > 1 {
+			p.pop()
+		}
+		return true
+	}
+
+	if leaf, ok := miniLeaf(name, args); ok {
+		parent := &p.top().component
+		if leaf.Text != "" && leaf.isPlainText() {
+			p.top().appendText(leaf.Text)
+		} else {
+			parent.Extra = append(parent.Extra, leaf)
+		}
+		return true
+	}
+
+	style, ok := miniStyle(name, args)
+	if !ok {
+		return false
+	}
+
+	p.stack = append(p.stack, miniFrame{key: tagKey(name), component: ChatComponent{Style: style}})
+	return true
+}
+
+// close pops up to and including the innermost tag matching key. A closing
+// tag with no matching open tag is kept as text.
+func (p *miniParser) close(key string) bool {
+	for i := len(p.stack) - 1; i > 0; i-- {
+		if p.stack[i].key == key {
+			for len(p.stack) > i {
+				p.pop()
+			}
+			return true
+		}
+	}
+	return false
+}
+
+var miniAliases = map[string]string{
+	"b":            "bold",
+	"i":            "italic",
+	"em":           "italic",
+	"u":            "underlined",
+	"st":           "strikethrough",
+	"obf":          "obfuscated",
+	"colour":       "color",
+	"c":            "color",
+	"grey":         string(Gray),
+	"dark_grey":    string(DarkGray),
+	"tr":           "lang",
+	"translate":    "lang",
+	"tr_or":        "lang_or",
+	"translate_or": "lang_or",
+	"sel":          "selector",
+	"br":           "newline",
+	"keybind":      "key",
+}
+
+func canonicalTag(name string) string {
+	if alias, ok := miniAliases[name]; ok {
+		return alias
+	}
+	return name
+}
+
+// tagKey is what an open tag is matched against when it is closed, so that
+// </b> closes <bold> and </bold> closes <!bold>.
+func tagKey(name string) string {
+	name = strings.ToLower(name)
+	if i := strings.IndexByte(name, ':'); i >= 0 {
+		name = name[:i]
+	}
+	return canonicalTag(strings.TrimPrefix(name, "!"))
+}
+
+func miniColor(name string) (Color, bool) {
+	name = canonicalTag(strings.ToLower(name))
+	if Color(name).Named() {
+		return Color(name), true
+	}
+	if len(name) == 7 && name[0] == '#' && isHex(name[1:]) {
+		return Color(strings.ToUpper(name)), true
+	}
+	return "", false
+}
+
+func miniStyle(name string, args []string) (Style, bool) {
+	var style Style
+
+	if color, ok := miniColor(name); ok && len(args) == 0 {
+		style.Color = color
+		return style, true
+	}
+
+	negated := strings.HasPrefix(name, "!")
+	name = canonicalTag(strings.TrimPrefix(name, "!"))
+	value := Bool(!negated)
+
+	switch name {
+	case "bold":
+		style.Bold = value
+	case "italic":
+		style.Italic = value
+	case "underlined":
+		style.Underlined = value
+	case "strikethrough":
+		style.Strikethrough = value
+	case "obfuscated":
+		style.Obfuscated = value
+	default:
+		if negated {
+			return style, false
+		}
+		return miniArgStyle(name, args)
+	}
+
+	return style, len(args) == 0
+}
+
+func miniArgStyle(name string, args []string) (Style, bool) {
+	var style Style
+	if len(args) == 0 {
+		return style, false
+	}
+	joined := strings.Join(args, ":")
+
+	switch name {
+	case "color":
+		color, ok := miniColor(args[0])
+		style.Color = color
+		return style, ok && len(args) == 1
+
+	case "click":
+		if len(args) < 2 {
+			return style, false
+		}
+		style.ClickEvent = &ClickEvent{Action: strings.ToLower(args[0]), Value: strings.Join(args[1:], ":")}
+
+	case "hover":
+		if len(args) < 2 || strings.ToLower(args[0]) != ShowText {
+			return style, false
+		}
+		text := ParseMiniMessage(strings.Join(args[1:], ":"))
+		style.HoverEvent = &HoverEvent{Action: ShowText, Text: &text}
+
+	case "insertion":
+		style.Insertion = joined
+
+	case "font":
+		style.Font = joined
+
+	default:
+		return style, false
+	}
+
+	return style, true
+}
+
+func miniLeaf(name string, args []string) (ChatComponent, bool) {
+	switch canonicalTag(name) {
+	case "newline":
+		return Text("\n"), len(args) == 0
+
+	case "key":
+		if len(args) != 1 {
+			return ChatComponent{}, false
+		}
+		return ChatComponent{Keybind: args[0]}, true
+
+	case "lang", "lang_or":
+		c := ChatComponent{}
+		if len(args) == 0 {
+			return c, false
+		}
+		c.Translate, args = args[0], args[1:]
+		if canonicalTag(name) == "lang_or" {
+			if len(args) == 0 {
+				return c, false
+			}
+			c.Fallback, args = args[0], args[1:]
+		}
+		for _, arg := range args {
+			c.With = append(c.With, ParseMiniMessage(arg))
+		}
+		return c, true
+
+	case "selector":
+		if len(args) == 0 || len(args) > 2 {
+			return ChatComponent{}, false
+		}
+		c := ChatComponent{Selector: args[0]}
+		if len(args) == 2 {
+			separator := ParseMiniMessage(args[1])
+			c.Separator = &separator
+		}
+		return c, true
+
+	case "score":
+		if len(args) != 2 {
+			return ChatComponent{}, false
+		}
+		return ChatComponent{Score: &Score{Name: args[0], Objective: args[1]}}, true
+	}
+
+	return ChatComponent{}, false
+}
+
+// tagEnd returns the index of the '>' closing the tag whose body starts at
+// start, skipping over quoted arguments, or -1 if the tag is unterminated.
+func tagEnd(s string, start int) int {
+	var quote byte
+	for i := start; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case (c == '\'' || c == '"') && (i == start || s[i-1] == ':'):
+			quote = c
+		case c == '<':
+			return -1
+		case c == '>':
+			return i
+		}
+	}
+	return -1
+}
+
+// splitTagArgs splits a tag body at colons outside quotes and unquotes each
+// argument.
+func splitTagArgs(body string) []string {
+	var args []string
+	var arg strings.Builder
+	var quote byte
+
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && i+1 < len(body) {
+				i++
+				arg.WriteByte(body[i])
+			} else if c == quote {
+				quote = 0
+			} else {
+				arg.WriteByte(c)
+			}
+		case (c == '\'' || c == '"') && arg.Len() == 0:
+			quote = c
+		case c == ':':
+			args = append(args, arg.String())
+			arg.Reset()
+		default:
+			arg.WriteByte(c)
+		}
+	}
+
+	return append(args, arg.String())
+}
+
+// MiniMessage serializes the component as MiniMessage-style markup that
+// ParseMiniMessage reads back. Hover events other than show_text, shadow
+// colors and NBT components have no tag and are written as their plain
+// text.
+func (c ChatComponent) MiniMessage() string {
+	var sb strings.Builder
+	c.writeMiniMessage(&sb)
+	return sb.String()
+}
+
+func (c ChatComponent) writeMiniMessage(sb *strings.Builder) {
+	closers := writeMiniStyle(sb, c.Style)
+
+	switch {
+	case c.Translate != "":
+		if c.Fallback != "" {
+			sb.WriteString("<lang_or:" + quoteMiniArg(c.Translate) + ":" + quoteMiniArg(c.Fallback))
+		} else {
+			sb.WriteString("<lang:" + quoteMiniArg(c.Translate))
+		}
+		for _, arg := range c.With {
+			sb.WriteString(":" + quoteMiniArg(arg.MiniMessage()))
+		}
+		sb.WriteByte('>')
+	case c.Keybind != "":
+		sb.WriteString("<key:" + quoteMiniArg(c.Keybind) + ">")
+	case c.Selector != "":
+		sb.WriteString("<selector:" + quoteMiniArg(c.Selector))
+		if c.Separator != nil {
+			sb.WriteString(":" + quoteMiniArg(c.Separator.MiniMessage()))
+		}
+		sb.WriteByte('>')
+	case c.Score != nil && c.Score.Value == "":
+		sb.WriteString("<score:" + quoteMiniArg(c.Score.Name) + ":" + quoteMiniArg(c.Score.Objective) + ">")
+	default:
+		sb.WriteString(escapeMiniText(c.content()))
+	}
+
+	for _, extra := range c.Extra {
+		extra.writeMiniMessage(sb)
+	}
+
+	for i := len(closers) - 1; i >= 0; i-- {
+		sb.WriteString("</" + closers[i] + ">")
+	}
+}
+
+// writeMiniStyle writes the opening tags for style and returns the names to
+// close them with.
+func writeMiniStyle(sb *strings.Builder, style Style) []string {
+	var closers []string
+	open := func(tag, closer string) {
+		sb.WriteString("<" + tag + ">")
+		closers = append(closers, closer)
+	}
+
+	if style.Color != "" {
+		if style.Color.Named() {
+			open(string(style.Color), string(style.Color))
+		} else if _, _, _, ok := style.Color.RGB(); ok {
+			color := strings.ToLower(string(style.Color))
+			open(color, color)
+		}
+	}
+
+	for _, decoration := range []struct {
+		name  string
+		value *bool
+	}{
+		{"bold", style.Bold},
+		{"italic", style.Italic},
+		{"underlined", style.Underlined},
+		{"strikethrough", style.Strikethrough},
+		{"obfuscated", style.Obfuscated},
+	} {
+		switch {
+		case decoration.value == nil:
+		case *decoration.value:
+			open(decoration.name, decoration.name)
+		default:
+			open("!"+decoration.name, "!"+decoration.name)
+		}
+	}
+
+	if style.Font != "" {
+		open("font:"+quoteMiniArg(style.Font), "font")
+	}
+	if style.Insertion != "" {
+		open("insertion:"+quoteMiniArg(style.Insertion), "insertion")
+	}
+	if style.ClickEvent != nil {
+		open("click:"+style.ClickEvent.Action+":"+quoteMiniArg(style.ClickEvent.Value), "click")
+	}
+	if style.HoverEvent != nil && style.HoverEvent.Action == ShowText && style.HoverEvent.Text != nil {
+		open("hover:show_text:"+quoteMiniArg(style.HoverEvent.Text.MiniMessage()), "hover")
+	}
+
+	return closers
+}
+
+func escapeMiniText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "<", `\<`).Replace(s)
+}
+
+func quoteMiniArg(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
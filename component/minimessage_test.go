@@ -0,0 +1,123 @@
+package component
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseMiniMessage(t *testing.T) {
+	hover := Text("tip").Styled(Style{Color: Gold})
+
+	tests := []struct {
+		name  string
+		input string
+		want  ChatComponent
+	}{
+		{"plain", "hello", Text("hello")},
+		{"color", "<red>hi</red>", Text("hi").Styled(Style{Color: Red})},
+		{"unclosed", "a<red>b", Text("a").Append(Text("b").Styled(Style{Color: Red}))},
+		{
+			"nesting", "<red>a<bold>b</bold>c</red>d",
+			Text("").Append(
+				Text("a").Styled(Style{Color: Red}).Append(
+					Text("b").Styled(Style{Bold: Bool(true)}),
+					Text("c"),
+				),
+				Text("d"),
+			),
+		},
+		{
+			"closing an outer tag closes inner ones", "<red>a<b>b</red>c",
+			Text("").Append(
+				Text("a").Styled(Style{Color: Red}).Append(Text("b").Styled(Style{Bold: Bool(true)})),
+				Text("c"),
+			),
+		},
+		{
+			"reset", "<green><u>a<reset>b",
+			Text("").Append(
+				Text("a").Styled(Style{Color: Green, Underlined: Bool(true)}),
+				Text("b"),
+			),
+		},
+		{"hex", "<#ff8800>x</#FF8800>", Text("x").Styled(Style{Color: "#FF8800"})},
+		{"color argument", "<color:dark_grey>x</color>", Text("x").Styled(Style{Color: DarkGray})},
+		{"negation", "<!italic>x</italic>", Text("x").Styled(Style{Italic: Bool(false)})},
+		{
+			"click with colons", "<click:open_url:https://example.com/a?b=c>go</click>",
+			Text("go").Styled(Style{ClickEvent: &ClickEvent{Action: OpenURL, Value: "https://example.com/a?b=c"}}),
+		},
+		{
+			"quoted hover", `<hover:show_text:'<gold>tip'>x`,
+			Text("x").Styled(Style{HoverEvent: &HoverEvent{Action: ShowText, Text: &hover}}),
+		},
+		{
+			"translatable", `<lang:death.attack.player:Steve:'<red>Alex'>`,
+			ChatComponent{Translate: "death.attack.player", With: []ChatComponent{Text("Steve"), Text("Alex").Styled(Style{Color: Red})}},
+		},
+		{"keybind", "press <key:key.jump>", Text("press ").Append(ChatComponent{Keybind: "key.jump"})},
+		{"newline", "a<newline>b<br>c", Text("a\nb\nc")},
+		{"escaped", `\<red> and \\`, Text(`<red> and \`)},
+		{"unknown tags kept", "<notatag>a</b> 1 < 2", Text("<notatag>a</b> 1 < 2")},
+		{"empty tag dropped", "<red></red>x", Text("x")},
+		{"font", "<font:minecraft:uniform>x", Text("x").Styled(Style{Font: "minecraft:uniform"})},
+	}
+
+	for _, tt := range tests {
+		got := ParseMiniMessage(tt.input)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: expected %s, got %s", tt.name, mustJSON(tt.want), mustJSON(got))
+		}
+	}
+}
+
+func TestMiniMessageSerialize(t *testing.T) {
+	hover := Text("it's <b>").Styled(Style{Color: Gray})
+	c := Text("hi ").Styled(Style{Color: HexColor(0x12, 0xAB, 0xEF), Bold: Bool(true)}).Append(
+		Text(`a\b`).Styled(Style{
+			Italic:     Bool(false),
+			ClickEvent: &ClickEvent{Action: RunCommand, Value: "/say 'hi'"},
+			HoverEvent: &HoverEvent{Action: ShowText, Text: &hover},
+		}),
+		Translatable("chat.type.text", Text("Steve"), Text("<3").Styled(Style{Color: Red})),
+		ChatComponent{Keybind: "key.jump", Style: Style{Font: "minecraft:alt", Insertion: "x:y"}},
+	)
+
+	want := `<#12abef><bold>hi ` +
+		`<!italic><click:run_command:'/say \'hi\''><hover:show_text:'<gray>it\'s \\<b></gray>'>a\\b</hover></click></!italic>` +
+		`<lang:'chat.type.text':'Steve':'<red>\\<3</red>'>` +
+		`<font:'minecraft:alt'><insertion:'x:y'><key:'key.jump'></insertion></font>` +
+		`</bold></#12abef>`
+	if got := c.MiniMessage(); got != want {
+		t.Fatalf("expected\n%s\ngot\n%s", want, got)
+	}
+
+	if back := ParseMiniMessage(want); !reflect.DeepEqual(back, c) {
+		t.Fatalf("round trip mismatch\n%s\n%s", mustJSON(c), mustJSON(back))
+	}
+}
+
+func TestMiniMessageRoundTrip(t *testing.T) {
+	for _, input := range []string{
+		"<red>a<bold>b</bold>c</red>d",
+		"<green>hello <!bold>world</!bold></green>",
+		`<hover:show_text:'<gold>nested <red>tags'>hover me</hover>`,
+		"<selector:'@a':', '>",
+		"<score:'@p':'kills'>",
+	} {
+		first := ParseMiniMessage(input)
+		serialized := first.MiniMessage()
+		if second := ParseMiniMessage(serialized); !reflect.DeepEqual(first, second) {
+			t.Errorf("%q: round trip through %q changed\n%s\n%s", input, serialized, mustJSON(first), mustJSON(second))
+		}
+	}
+}
+
+func mustJSON(c ChatComponent) string {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err.Error()
+	}
+	return string(data)
+}
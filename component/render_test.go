@@ -0,0 +1,115 @@
+package component
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSegments(t *testing.T) {
+	c := Text("a").Styled(Style{Color: Red}).Append(
+		Text("b"),
+		Text("§lc§9d§re").Styled(Style{Italic: Bool(true)}),
+	)
+
+	got := c.Segments(nil)
+	want := []struct {
+		text  string
+		color Color
+		bold  bool
+	}{
+		{"ab", Red, false},
+		{"c", Red, true},
+		{"d", Blue, false},
+		{"e", Red, false},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("expected %d segments, got %+v", len(want), got)
+	}
+	for i, w := range want {
+		if got[i].Text != w.text || got[i].Style.Color != w.color || got[i].Style.IsBold() != w.bold {
+			t.Errorf("segment %d: expected %+v, got %q %+v", i, w, got[i].Text, got[i].Style)
+		}
+	}
+	if got[2].Style.IsItalic() || !got[3].Style.IsItalic() {
+		t.Errorf("expected §9 to clear italic and §r to restore it: %+v", got)
+	}
+}
+
+func TestANSIRenderer(t *testing.T) {
+	c := Text("hi ").Styled(Style{Color: Red, Bold: Bool(true)}).Append(
+		Text("there").Styled(Style{Color: HexColor(0x20, 0x20, 0xB0), Bold: Bool(false)}),
+	)
+
+	tests := []struct {
+		mode ColorMode
+		want string
+	}{
+		{ColorNone, "hi there"},
+		{Color16, "\x1b[1;91mhi \x1b[0m\x1b[34mthere\x1b[0m"},
+		{ColorTrue, "\x1b[1;91mhi \x1b[0m\x1b[38;2;32;32;176mthere\x1b[0m"},
+	}
+
+	for _, tt := range tests {
+		if got := (ANSIRenderer{Mode: tt.mode}).Render(c); got != tt.want {
+			t.Errorf("mode %d: expected %q, got %q", tt.mode, tt.want, got)
+		}
+	}
+
+	lang := NewTranslations(map[string]string{"greet": "Hello, %s!"})
+	got := ANSIRenderer{Mode: Color16, Lang: lang}.Render(Translatable("greet", Text("Steve").Styled(Style{Underlined: Bool(true)})))
+	if want := "Hello, \x1b[4mSteve\x1b[0m!"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestANSIRendererStripsControl(t *testing.T) {
+	c := Text("a\x1b[2Jb\u009b31mc\x07\n\td").Styled(Style{Color: Red})
+
+	if got, want := (ANSIRenderer{}).Render(c), "a[2Jb31mc\n\td"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if got, want := (ANSIRenderer{Mode: Color16}).Render(c), "\x1b[91ma[2Jb31mc\n\td\x1b[0m"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestHTMLRenderer(t *testing.T) {
+	hover := Text(`"quoted" <b>`)
+	c := Text("<script>alert(1)</script>\n").Append(
+		Text("link").Styled(Style{
+			Color:      Gold,
+			Underlined: Bool(true),
+			ClickEvent: &ClickEvent{Action: OpenURL, Value: "https://example.com/?a=1&b=2"},
+			HoverEvent: &HoverEvent{Action: ShowText, Text: &hover},
+		}),
+		Text("bad").Styled(Style{ClickEvent: &ClickEvent{Action: OpenURL, Value: "javascript:alert(1)"}}),
+		Text("evil").Styled(Style{Color: `red;background:url(x)`}),
+	)
+
+	got := HTMLRenderer{}.Render(c)
+	want := `<span>&lt;script&gt;alert(1)&lt;/script&gt;<br></span>` +
+		`<a href="https://example.com/?a=1&amp;b=2" rel="noopener noreferrer nofollow" target="_blank">` +
+		`<span style="color:#ffaa00;text-decoration:underline" title="&#34;quoted&#34; &lt;b&gt;">link</span></a>` +
+		`<span>bad</span><span>evil</span>`
+	if got != want {
+		t.Fatalf("expected\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestObfuscatedText(t *testing.T) {
+	c := Text("secret text").Styled(Style{Obfuscated: Bool(true)})
+
+	plain := ANSIRenderer{}.Render(c)
+	if utf8.RuneCountInString(plain) != len("secret text") || plain[6] != ' ' {
+		t.Fatalf("expected same-length scrambled text, got %q", plain)
+	}
+
+	html := HTMLRenderer{}.Render(Text("§k<>&\""))
+	inner, prefixed := strings.CutPrefix(html, `<span class="mc-obfuscated">`)
+	inner, suffixed := strings.CutSuffix(inner, "</span>")
+	if !prefixed || !suffixed || strings.ContainsAny(inner, `<>"`) {
+		t.Fatalf("unexpected obfuscated html %q", html)
+	}
+}
@@ -0,0 +1,131 @@
+package component
+
+import (
+	"strings"
+
+	"github.com/obeliskdev/fastrand"
+)
+
+// Segment is a run of text with the style it is displayed in, after
+// inheritance from its parents and any legacy § codes have been applied.
+type Segment struct {
+	Text  string
+	Style Style
+}
+
+// Segments flattens the component into styled runs in display order,
+// resolving translations through lang. Adjacent runs with the same style
+// are merged.
+func (c ChatComponent) Segments(lang *Translations) []Segment {
+	var segments []Segment
+	c.appendSegments(&segments, Style{}, lang)
+	return segments
+}
+
+func (c ChatComponent) appendSegments(segments *[]Segment, parent Style, lang *Translations) {
+	style := c.Style.Inherit(parent)
+
+	if c.Translate != "" {
+		for _, part := range c.translationParts(lang) {
+			if part.arg >= 0 {
+				c.With[part.arg].appendSegments(segments, style, lang)
+			} else {
+				appendText(segments, part.text, style)
+			}
+		}
+	} else {
+		appendText(segments, c.content(), style)
+	}
+
+	for _, extra := range c.Extra {
+		extra.appendSegments(segments, style, lang)
+	}
+}
+
+// appendText splits text at legacy § codes the way the client does: a color
+// resets the decorations, a format code adds one and §r returns to the
+// component's own style.
+func appendText(segments *[]Segment, text string, base Style) {
+	style := base
+	for {
+		i := strings.Index(text, "§")
+		if i < 0 {
+			break
+		}
+
+		push(segments, text[:i], style)
+		rest := text[i+len("§"):]
+		if rest == "" {
+			return
+		}
+
+		code := rest[0]
+		if code >= 'A' && code <= 'Z' {
+			code += 'a' - 'A'
+		}
+		style = applyLegacyCode(style, base, code)
+		text = rest[1:]
+	}
+
+	push(segments, text, style)
+}
+
+func push(segments *[]Segment, text string, style Style) {
+	if text == "" {
+		return
+	}
+
+	if n := len(*segments); n > 0 && (*segments)[n-1].Style.Equal(style) {
+		(*segments)[n-1].Text += text
+		return
+	}
+
+	*segments = append(*segments, Segment{Text: text, Style: style})
+}
+
+func applyLegacyCode(style, base Style, code byte) Style {
+	switch {
+	case code >= '0' && code <= '9':
+		return legacyColor(style, NamedColors[code-'0'])
+	case code >= 'a' && code <= 'f':
+		return legacyColor(style, NamedColors[code-'a'+10])
+	}
+
+	switch code {
+	case 'k':
+		style.Obfuscated = Bool(true)
+	case 'l':
+		style.Bold = Bool(true)
+	case 'm':
+		style.Strikethrough = Bool(true)
+	case 'n':
+		style.Underlined = Bool(true)
+	case 'o':
+		style.Italic = Bool(true)
+	case 'r':
+		return base
+	}
+	return style
+}
+
+func legacyColor(style Style, color Color) Style {
+	style.Color = color
+	style.Bold, style.Italic, style.Underlined, style.Strikethrough, style.Obfuscated = nil, nil, nil, nil, nil
+	return style
+}
+
+const obfuscationGlyphs = "!\"#$%&'()*+,-./0123456789:;<=>?@ABCDEFGHIJKLMNOPQRSTUVWXYZ[\\]^_abcdefghijklmnopqrstuvwxyz{|}~"
+
+// obfuscate replaces every visible character with a random glyph, as the
+// client does every frame for §k text.
+func obfuscate(text string) string {
+	var sb strings.Builder
+	for _, r := range text {
+		if r == ' ' || r == '\n' || r == '\t' {
+			sb.WriteRune(r)
+			continue
+		}
+		sb.WriteByte(obfuscationGlyphs[fastrand.IntN(len(obfuscationGlyphs))])
+	}
+	return sb.String()
+}
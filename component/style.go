@@ -0,0 +1,253 @@
+package component
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Color is either one of the sixteen named chat colors ("red") or a
+// "#RRGGBB" hex color.
+type Color string
+
+const (
+	Black       Color = "black"
+	DarkBlue    Color = "dark_blue"
+	DarkGreen   Color = "dark_green"
+	DarkAqua    Color = "dark_aqua"
+	DarkRed     Color = "dark_red"
+	DarkPurple  Color = "dark_purple"
+	Gold        Color = "gold"
+	Gray        Color = "gray"
+	DarkGray    Color = "dark_gray"
+	Blue        Color = "blue"
+	Green       Color = "green"
+	Aqua        Color = "aqua"
+	Red         Color = "red"
+	LightPurple Color = "light_purple"
+	Yellow      Color = "yellow"
+	White       Color = "white"
+)
+
+// NamedColors lists the named colors in legacy code order, so that
+// NamedColors[0xa] is the color of §a.
+var NamedColors = [16]Color{
+	Black, DarkBlue, DarkGreen, DarkAqua, DarkRed, DarkPurple, Gold, Gray,
+	DarkGray, Blue, Green, Aqua, Red, LightPurple, Yellow, White,
+}
+
+var namedRGB = map[Color]uint32{
+	Black:       0x000000,
+	DarkBlue:    0x0000AA,
+	DarkGreen:   0x00AA00,
+	DarkAqua:    0x00AAAA,
+	DarkRed:     0xAA0000,
+	DarkPurple:  0xAA00AA,
+	Gold:        0xFFAA00,
+	Gray:        0xAAAAAA,
+	DarkGray:    0x555555,
+	Blue:        0x5555FF,
+	Green:       0x55FF55,
+	Aqua:        0x55FFFF,
+	Red:         0xFF5555,
+	LightPurple: 0xFF55FF,
+	Yellow:      0xFFFF55,
+	White:       0xFFFFFF,
+}
+
+func HexColor(r, g, b uint8) Color {
+	return Color(fmt.Sprintf("#%02X%02X%02X", r, g, b))
+}
+
+// RGB resolves named and hex colors. ok is false for an empty or unknown
+// color.
+func (c Color) RGB() (r, g, b uint8, ok bool) {
+	value, known := namedRGB[c]
+	if !known {
+		if len(c) != 7 || c[0] != '#' {
+			return 0, 0, 0, false
+		}
+
+		parsed, err := strconv.ParseUint(string(c[1:]), 16, 32)
+		if err != nil {
+			return 0, 0, 0, false
+		}
+		value = uint32(parsed)
+	}
+
+	return uint8(value >> 16), uint8(value >> 8), uint8(value), true
+}
+
+// Named reports whether c is one of the sixteen named colors.
+func (c Color) Named() bool {
+	_, ok := namedRGB[c]
+	return ok
+}
+
+// Code returns the legacy formatting code of a named color, or -1.
+func (c Color) Code() int {
+	for i, named := range NamedColors {
+		if named == c {
+			return i
+		}
+	}
+	return -1
+}
+
+// Style holds the formatting shared by every component type. Nil decoration
+// pointers and empty strings mean "inherit from the parent".
+type Style struct {
+	Color         Color
+	Bold          *bool
+	Italic        *bool
+	Underlined    *bool
+	Strikethrough *bool
+	Obfuscated    *bool
+	Font          string
+	Insertion     string
+	ShadowColor   *int32
+	ClickEvent    *ClickEvent
+	HoverEvent    *HoverEvent
+}
+
+// Inherit fills every unset field of s from parent.
+func (s Style) Inherit(parent Style) Style {
+	if s.Color == "" {
+		s.Color = parent.Color
+	}
+	if s.Bold == nil {
+		s.Bold = parent.Bold
+	}
+	if s.Italic == nil {
+		s.Italic = parent.Italic
+	}
+	if s.Underlined == nil {
+		s.Underlined = parent.Underlined
+	}
+	if s.Strikethrough == nil {
+		s.Strikethrough = parent.Strikethrough
+	}
+	if s.Obfuscated == nil {
+		s.Obfuscated = parent.Obfuscated
+	}
+	if s.Font == "" {
+		s.Font = parent.Font
+	}
+	if s.Insertion == "" {
+		s.Insertion = parent.Insertion
+	}
+	if s.ShadowColor == nil {
+		s.ShadowColor = parent.ShadowColor
+	}
+	if s.ClickEvent == nil {
+		s.ClickEvent = parent.ClickEvent
+	}
+	if s.HoverEvent == nil {
+		s.HoverEvent = parent.HoverEvent
+	}
+	return s
+}
+
+// IsZero reports whether the style sets nothing.
+func (s Style) IsZero() bool {
+	return s == Style{}
+}
+
+// Equal compares styles by value rather than by pointer identity.
+func (s Style) Equal(other Style) bool {
+	return s.Color == other.Color && s.Font == other.Font && s.Insertion == other.Insertion &&
+		equalBool(s.Bold, other.Bold) && equalBool(s.Italic, other.Italic) &&
+		equalBool(s.Underlined, other.Underlined) && equalBool(s.Strikethrough, other.Strikethrough) &&
+		equalBool(s.Obfuscated, other.Obfuscated) &&
+		(s.ShadowColor == other.ShadowColor || s.ShadowColor != nil && other.ShadowColor != nil && *s.ShadowColor == *other.ShadowColor) &&
+		(s.ClickEvent == other.ClickEvent || s.ClickEvent != nil && other.ClickEvent != nil && *s.ClickEvent == *other.ClickEvent) &&
+		s.HoverEvent == other.HoverEvent
+}
+
+func equalBool(a, b *bool) bool {
+	return a == b || a != nil && b != nil && *a == *b
+}
+
+// Bool returns a pointer for the decoration fields.
+func Bool(v bool) *bool {
+	return &v
+}
+
+func isSet(b *bool) bool {
+	return b != nil && *b
+}
+
+func (s Style) IsBold() bool          { return isSet(s.Bold) }
+func (s Style) IsItalic() bool        { return isSet(s.Italic) }
+func (s Style) IsUnderlined() bool    { return isSet(s.Underlined) }
+func (s Style) IsStrikethrough() bool { return isSet(s.Strikethrough) }
+func (s Style) IsObfuscated() bool    { return isSet(s.Obfuscated) }
+
+const (
+	OpenURL         = "open_url"
+	OpenFile        = "open_file"
+	RunCommand      = "run_command"
+	SuggestCommand  = "suggest_command"
+	ChangePage      = "change_page"
+	CopyToClipboard = "copy_to_clipboard"
+
+	ShowText   = "show_text"
+	ShowItem   = "show_item"
+	ShowEntity = "show_entity"
+)
+
+// ClickEvent keeps the action's argument in Value whichever key it is
+// stored under on the wire (url, path, command, page or value).
+type ClickEvent struct {
+	Action string
+	Value  string
+}
+
+// clickKeys maps actions to the argument key used from 1.21.5.
+var clickKeys = map[string]string{
+	OpenURL:         "url",
+	OpenFile:        "path",
+	RunCommand:      "command",
+	SuggestCommand:  "command",
+	ChangePage:      "page",
+	CopyToClipboard: "value",
+	"show_dialog":   "dialog",
+	"custom":        "id",
+}
+
+type HoverEvent struct {
+	Action string
+	Text   *ChatComponent
+	Item   *HoverItem
+	Entity *HoverEntity
+}
+
+type HoverItem struct {
+	ID         string
+	Count      int32
+	Tag        string
+	Components map[string]any
+}
+
+type HoverEntity struct {
+	Type string
+	ID   uuid.UUID
+	Name *ChatComponent
+}
+
+func (h *HoverEvent) String() string {
+	switch {
+	case h.Text != nil:
+		return h.Text.String()
+	case h.Item != nil:
+		return h.Item.ID
+	case h.Entity != nil:
+		if h.Entity.Name != nil {
+			return h.Entity.Name.String()
+		}
+		return strings.TrimPrefix(h.Entity.Type, "minecraft:")
+	}
+	return ""
+}
@@ -0,0 +1,107 @@
+package component
+
+import (
+	"strconv"
+	"strings"
+)
+
+// translationPart is either literal text or, when arg >= 0, an index into
+// the component's With arguments.
+type translationPart struct {
+	text string
+	arg  int
+}
+
+// translationParts splits the component's format string: the entry in lang,
+// then the fallback, then the key itself. A key that lang does not cover and
+// that has no fallback is followed by its arguments, so that messages outside
+// the bundled table still carry the player names and values they mention.
+func (c ChatComponent) translationParts(lang *Translations) []translationPart {
+	if format, ok := lang.Translate(c.Translate); ok {
+		return parseTranslation(format, len(c.With))
+	}
+	if c.Fallback != "" {
+		return parseTranslation(c.Fallback, len(c.With))
+	}
+	if lang == nil || len(c.With) == 0 {
+		return []translationPart{{text: c.Translate, arg: -1}}
+	}
+
+	parts := []translationPart{{text: c.Translate + " [", arg: -1}}
+	for i := range c.With {
+		if i > 0 {
+			parts = append(parts, translationPart{text: ", ", arg: -1})
+		}
+		parts = append(parts, translationPart{arg: i})
+	}
+	return append(parts, translationPart{text: "]", arg: -1})
+}
+
+// parseTranslation follows the vanilla rules: %s takes the next argument,
+// %n$s the n-th, and %% is a literal percent sign. A malformed format or an
+// out-of-range argument makes the whole format render literally.
+func parseTranslation(format string, args int) []translationPart {
+	var parts []translationPart
+	next := 0
+	literal := func() []translationPart {
+		return []translationPart{{text: format, arg: -1}}
+	}
+
+	rest := format
+	for {
+		i := strings.IndexByte(rest, '%')
+		if i < 0 {
+			break
+		}
+
+		if i > 0 {
+			parts = append(parts, translationPart{text: rest[:i], arg: -1})
+		}
+		rest = rest[i+1:]
+
+		index := -1
+		digits := 0
+		for digits < len(rest) && rest[digits] >= '0' && rest[digits] <= '9' {
+			digits++
+		}
+		if digits > 0 && digits < len(rest) && rest[digits] == '$' {
+			n, err := strconv.Atoi(rest[:digits])
+			if err != nil {
+				return literal()
+			}
+			index = n - 1
+			rest = rest[digits+1:]
+		}
+
+		if rest == "" {
+			// A trailing % with no conversion is malformed.
+			return literal()
+		}
+
+		switch rest[0] {
+		case '%':
+			if index >= 0 {
+				return literal()
+			}
+			parts = append(parts, translationPart{text: "%", arg: -1})
+		case 's':
+			if index < 0 {
+				index = next
+				next++
+			}
+			if index < 0 || index >= args {
+				return literal()
+			}
+			parts = append(parts, translationPart{arg: index})
+		default:
+			return literal()
+		}
+		rest = rest[1:]
+	}
+
+	if rest != "" {
+		parts = append(parts, translationPart{text: rest, arg: -1})
+	}
+
+	return parts
+}
@@ -0,0 +1,100 @@
+package component
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+//go:generate go run ../generator/lang
+
+//go:embed lang/en_us.json
+var englishJSON []byte
+
+// Translations maps translation keys to format strings, as found in a
+// Minecraft language file such as assets/minecraft/lang/en_us.json.
+type Translations struct {
+	entries  map[string]string
+	fallback *Translations
+}
+
+var (
+	englishOnce sync.Once
+	english     *Translations
+)
+
+// English returns the bundled en_us table from lang/en_us.json. Running
+// go generate replaces that file with the full vanilla language asset of the
+// latest release; until then it holds a subset of about 230 chat,
+// multiplayer, disconnect, death and common command keys. Missing keys render
+// as the key followed by their arguments.
+func English() *Translations {
+	englishOnce.Do(func() {
+		var entries map[string]string
+		if err := json.Unmarshal(englishJSON, &entries); err != nil {
+			panic(fmt.Sprintf("component: bundled en_us.json: %v", err))
+		}
+		english = NewTranslations(entries)
+	})
+	return english
+}
+
+func NewTranslations(entries map[string]string) *Translations {
+	return &Translations{entries: entries}
+}
+
+// ReadTranslations parses a language JSON object.
+func ReadTranslations(r io.Reader) (*Translations, error) {
+	var entries map[string]string
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("component: parse translations: %w", err)
+	}
+	return NewTranslations(entries), nil
+}
+
+// LoadTranslations reads a language JSON file from disk.
+func LoadTranslations(path string) (*Translations, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadTranslations(f)
+}
+
+// WithFallback returns a table that looks keys up in t first and then in
+// fallback, the way the client falls back to en_us.
+func (t *Translations) WithFallback(fallback *Translations) *Translations {
+	return &Translations{entries: t.entries, fallback: fallback}
+}
+
+func (t *Translations) Translate(key string) (string, bool) {
+	for table := t; table != nil; table = table.fallback {
+		if format, ok := table.entries[key]; ok {
+			return format, true
+		}
+	}
+	return "", false
+}
+
+func (t *Translations) Len() int {
+	if t == nil {
+		return 0
+	}
+	return len(t.entries)
+}
+
+// Render renders the component as plain text, resolving translation keys
+// through lang. Keys missing from lang use the component's fallback and
+// then the key itself, followed by its arguments in brackets. A nil lang
+// behaves like String.
+func (c ChatComponent) Render(lang *Translations) string {
+	var sb strings.Builder
+	c.writePlain(&sb, lang)
+	return sb.String()
+}
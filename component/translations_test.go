@@ -0,0 +1,78 @@
+package component
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderEnglish(t *testing.T) {
+	death := Translatable("death.attack.player.item",
+		Text("Steve"),
+		Text("Alex").Styled(Style{Color: Red}),
+		ChatComponent{Translate: "item.minecraft.diamond_sword", Fallback: "Diamond Sword"},
+	)
+	if got := death.Render(English()); got != "Steve was slain by Alex using Diamond Sword" {
+		t.Fatalf("unexpected death message %q", got)
+	}
+
+	chat := Translatable("chat.type.text", Text("Steve"), Text("hi"))
+	if got := chat.Render(English()); got != "<Steve> hi" {
+		t.Fatalf("unexpected chat %q", got)
+	}
+	if got := chat.String(); got != "chat.type.text" {
+		t.Fatalf("String should not translate, got %q", got)
+	}
+
+	joined := Translatable("multiplayer.player.joined", Text("Steve").Styled(Style{Color: Yellow})).Append(Text("!"))
+	if got := joined.Render(English()); got != "Steve joined the game!" {
+		t.Fatalf("unexpected join message %q", got)
+	}
+}
+
+func TestRenderMissingKey(t *testing.T) {
+	unknown := Translatable("commands.example.success", Text("Steve"), Text("3"))
+	if got := unknown.Render(English()); got != "commands.example.success [Steve, 3]" {
+		t.Fatalf("expected the key followed by its arguments, got %q", got)
+	}
+	if got := unknown.String(); got != "commands.example.success" {
+		t.Fatalf("String should render the bare key, got %q", got)
+	}
+
+	unknown.Fallback = "%s did it %s times"
+	if got := unknown.Render(English()); got != "Steve did it 3 times" {
+		t.Fatalf("expected the component fallback, got %q", got)
+	}
+
+	if got := Translatable("commands.example.none").Render(English()); got != "commands.example.none" {
+		t.Fatalf("expected the bare key without arguments, got %q", got)
+	}
+}
+
+func TestLoadTranslations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "de_de.json")
+	if err := os.WriteFile(path, []byte(`{"chat.type.text":"<%s> sagt %s","demo.swap":"%2$s vor %1$s"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	german, err := LoadTranslations(path)
+	if err != nil {
+		t.Fatalf("LoadTranslations: %v", err)
+	}
+	german = german.WithFallback(English())
+
+	if got := Translatable("chat.type.text", Text("A"), Text("b")).Render(german); got != "<A> sagt b" {
+		t.Fatalf("unexpected translation %q", got)
+	}
+	if got := Translatable("demo.swap", Text("1"), Text("2")).Render(german); got != "2 vor 1" {
+		t.Fatalf("unexpected positional translation %q", got)
+	}
+	if got := Translatable("multiplayer.player.left", Text("A")).Render(german); got != "A left the game" {
+		t.Fatalf("expected English fallback, got %q", got)
+	}
+
+	if _, err := ReadTranslations(strings.NewReader(`["not", "an", "object"]`)); err == nil {
+		t.Fatal("expected an error for a non-object language file")
+	}
+}
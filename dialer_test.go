@@ -0,0 +1,131 @@
+package gophermc_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/obeliskdev/gophermc"
+	"github.com/obeliskdev/gophermc/component"
+	"github.com/obeliskdev/gophermc/protocol"
+)
+
+type dialerFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+func (f dialerFunc) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	return f(ctx, network, address)
+}
+
+func TestWithDialer(t *testing.T) {
+	remotes := make(chan net.Addr, 1)
+	addr := startStatusServer(t, &gophermc.StatusServer{
+		Handler: func(r *gophermc.StatusRequest) *protocol.StatusResponse {
+			remotes <- r.RemoteAddr
+			return &protocol.StatusResponse{Description: component.Text("dialed")}
+		},
+	})
+
+	var dialed string
+	dialer := dialerFunc(func(ctx context.Context, network, address string) (net.Conn, error) {
+		dialed = address
+		return (&net.Dialer{}).DialContext(ctx, network, address)
+	})
+
+	client, _ := gophermc.NewClient(gophermc.WithAddr(addr), gophermc.WithDialer(dialer))
+	if _, _, err := client.Status(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if dialed != addr {
+		t.Fatalf("expected the dialer to dial %s, got %q", addr, dialed)
+	}
+	<-remotes
+
+	client, _ = gophermc.NewClient(gophermc.WithAddr(addr), gophermc.WithSourceIP(net.IPv4(127, 0, 0, 2)))
+	if _, _, err := client.Status(context.Background()); err != nil {
+		t.Skipf("cannot bind 127.0.0.2: %v", err)
+	}
+	if remote := (<-remotes).(*net.TCPAddr); !remote.IP.Equal(net.IPv4(127, 0, 0, 2)) {
+		t.Fatalf("expected the connection from 127.0.0.2, got %s", remote)
+	}
+}
+
+func TestWithDialerHostname(t *testing.T) {
+	addr := startStatusServer(t, &gophermc.StatusServer{
+		Handler: func(r *gophermc.StatusRequest) *protocol.StatusResponse {
+			return &protocol.StatusResponse{Description: component.Text("proxied")}
+		},
+	})
+
+	var dialed string
+	dialer := dialerFunc(func(ctx context.Context, network, address string) (net.Conn, error) {
+		dialed = address
+		return (&net.Dialer{}).DialContext(ctx, network, addr)
+	})
+
+	// The resolver knows no addresses, so only the proxy can resolve the host.
+	client, _ := gophermc.NewClient(
+		gophermc.WithAddr("play.example.com:25566"),
+		gophermc.WithResolver(&fakeResolver{}),
+		gophermc.WithDialer(dialer),
+	)
+	if _, _, err := client.Status(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if dialed != "play.example.com:25566" {
+		t.Fatalf("expected the dialer to get the host name, got %q", dialed)
+	}
+}
+
+func TestWithConnectTimeout(t *testing.T) {
+	hanging := dialerFunc(func(ctx context.Context, _, _ string) (net.Conn, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	client, _ := gophermc.NewClient(
+		gophermc.WithAddr("127.0.0.1:25565"),
+		gophermc.WithDialer(hanging),
+		gophermc.WithConnectTimeout(50*time.Millisecond),
+	)
+
+	start := time.Now()
+	if err := client.Connect(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a deadline error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("connect took %s", elapsed)
+	}
+}
+
+func TestWithProxyHeader(t *testing.T) {
+	remotes := make(chan net.Addr, 1)
+	server := &gophermc.StatusServer{
+		Handler: func(r *gophermc.StatusRequest) *protocol.StatusResponse {
+			remotes <- r.RemoteAddr
+			return &protocol.StatusResponse{Description: component.Text("proxied")}
+		},
+	}
+
+	l, err := protocol.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.ProxyPolicy = protocol.ProxyHeaderRequired
+	go func() { _ = server.Serve(l) }()
+	defer server.Close()
+
+	for _, version := range []int{1, 2} {
+		client, _ := gophermc.NewClient(
+			gophermc.WithAddr(l.Addr().String()),
+			gophermc.WithProxyHeader(protocol.ProxyHeader{Version: version, Source: &net.TCPAddr{IP: net.IPv4(203, 0, 113, 7), Port: 4242}}),
+		)
+		if _, _, err := client.Status(context.Background()); err != nil {
+			t.Fatalf("v%d: %v", version, err)
+		}
+		if remote := <-remotes; remote.String() != "203.0.113.7:4242" {
+			t.Errorf("v%d: expected the proxied address, got %s", version, remote)
+		}
+	}
+}
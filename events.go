@@ -1,7 +1,9 @@
 package gophermc
 
 import (
+	"github.com/google/uuid"
 	"github.com/obeliskdev/gophermc/component"
+	"github.com/obeliskdev/gophermc/protocol"
 	"time"
 )
 
@@ -15,6 +17,9 @@ type ReadyEvent struct {
 type DisconnectEvent struct {
 	Event
 	Reason string
+	// Component is the reason as sent by the server, nil when the
+	// connection was lost instead.
+	Component *component.ChatComponent
 }
 
 type KeepAliveEvent struct {
@@ -23,9 +28,35 @@ type KeepAliveEvent struct {
 }
 
 type ChatMessageEvent struct {
+	Event
+	Message    string
+	Component  component.ChatComponent
+	Sender     string
+	SenderUUID uuid.UUID
+	ChatType   int32
+	Signed     bool
+	Time       time.Time
+}
+
+type SystemMessageEvent struct {
+	Event
+	Message   string
+	Component component.ChatComponent
+	Time      time.Time
+}
+
+type ActionBarEvent struct {
 	Event
 	Message   string
 	Component component.ChatComponent
-	Sender    string
 	Time      time.Time
 }
+
+// PluginMessageEvent is a plugin message received in the configuration or
+// play state, on any channel.
+type PluginMessageEvent struct {
+	Event
+	Channel string
+	Data    []byte
+	State   protocol.State
+}
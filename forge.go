@@ -0,0 +1,176 @@
+package gophermc
+
+import (
+	"fmt"
+
+	"github.com/obeliskdev/gophermc/protocol"
+)
+
+// forge is the mod list a client claims in the Forge handshake, and the
+// progress of the FML1 handshake.
+type forge struct {
+	mods []protocol.ForgeMod
+
+	legacyVersion byte
+	legacyAcks    int
+}
+
+// forgeLoginWrapper is the LoginPluginHandler WithForge registers for the
+// FML2 and FML3 login wrapper. Messages for other inner channels go to the
+// handlers registered for them.
+func (c *Client) forgeLoginWrapper(data []byte) ([]byte, bool, error) {
+	channel, payload, err := protocol.ReadForgeLoginWrapper(data)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var reply []byte
+	if channel == protocol.ForgeHandshakeChannel {
+		if reply, err = c.forgeHandshake(payload); err != nil {
+			return nil, false, err
+		}
+	} else {
+		handler := c.loginPluginHandler(channel)
+		if handler == nil {
+			return nil, false, nil
+		}
+
+		var understood bool
+		reply, understood, err = handler(payload)
+		if err != nil || !understood {
+			return nil, false, err
+		}
+	}
+
+	return protocol.ForgeLoginWrapper(channel, reply), true, nil
+}
+
+// forgeHandshake answers the server's mod list with the declared mods, or
+// with the server's own when none were declared, and acknowledges
+// everything else.
+func (c *Client) forgeHandshake(payload []byte) ([]byte, error) {
+	id, r, err := protocol.ReadForgeHandshake(payload)
+	if err != nil {
+		return nil, err
+	}
+	if id != protocol.ForgeModListID {
+		return protocol.ForgeAcknowledge(), nil
+	}
+
+	list, err := protocol.ReadForgeModList(r)
+	if err != nil {
+		return nil, fmt.Errorf("forge mod list: %w", err)
+	}
+
+	reply := &protocol.ForgeModListReply{Mods: list.Mods, Channels: list.Channels}
+	if c.forge.mods != nil {
+		reply.Mods = make([]string, 0, len(c.forge.mods))
+		for _, mod := range c.forge.mods {
+			reply.Mods = append(reply.Mods, mod.ID)
+		}
+	}
+	for _, registry := range list.Registries {
+		reply.Registries = append(reply.Registries, protocol.ForgeRegistry{Name: registry})
+	}
+	return reply.Bytes(), nil
+}
+
+// forgeLegacyHandshake runs the FML1 handshake, which Forge servers before
+// 1.13 start in the play state right after login success.
+func (c *Client) forgeLegacyHandshake() error {
+	for {
+		packet, err := c.ReadPacket()
+		if err != nil {
+			return err
+		}
+
+		switch p := packet.(type) {
+		case *protocol.ClientboundDisconnect:
+			return fmt.Errorf("disconnected by server: %s", p.Reason.Render(c.translations))
+
+		case *protocol.ClientboundCustomPayload:
+			if p.Channel != protocol.ForgeLegacyHandshakeChannel {
+				c.handlePacket(p)
+				continue
+			}
+
+			done, err := c.handleForgeLegacyMessage(p.Data)
+			if err != nil || done {
+				return err
+			}
+
+		default:
+			c.handlePacket(packet)
+		}
+	}
+}
+
+// handleForgeLegacyMessage advances the FML1 handshake with a FML|HS
+// message and reports whether it is complete.
+func (c *Client) handleForgeLegacyMessage(data []byte) (bool, error) {
+	if len(data) == 0 {
+		return false, protocol.ErrInvalidForgeMessage
+	}
+
+	switch data[0] {
+	case protocol.ForgeLegacyServerHelloID:
+		c.forge.legacyVersion = 2
+		if len(data) > 1 {
+			c.forge.legacyVersion = data[1]
+		}
+
+		register := &protocol.ServerboundCustomPayload{CustomPayloadData: protocol.CustomPayloadData{
+			Channel: "REGISTER",
+			Data:    []byte("FML|HS\x00FML\x00FML|MP\x00FORGE"),
+		}}
+		if err := c.WritePacket(register); err != nil {
+			return false, err
+		}
+		if err := c.writeForgeLegacy([]byte{protocol.ForgeLegacyClientHelloID, c.forge.legacyVersion}); err != nil {
+			return false, err
+		}
+		return false, c.writeForgeLegacy(protocol.ForgeLegacyModList(c.forge.mods))
+
+	case protocol.ForgeLegacyModListID:
+		return false, c.writeForgeLegacyAck(forgeLegacyWaitingServerData)
+
+	case protocol.ForgeLegacyRegistryDataID:
+		// Registry data comes in parts before FML1 protocol version 2 sent
+		// it in one message; the last part clears its "has more" flag.
+		if c.forge.legacyVersion >= 2 && len(data) > 1 && data[1] != 0 {
+			return false, nil
+		}
+		return false, c.writeForgeLegacyAck(forgeLegacyWaitingServerComplete)
+
+	case protocol.ForgeLegacyHandshakeAckID:
+		c.forge.legacyAcks++
+		if c.forge.legacyAcks == 1 {
+			return false, c.writeForgeLegacyAck(forgeLegacyPendingComplete)
+		}
+		return true, c.writeForgeLegacyAck(forgeLegacyComplete)
+
+	case protocol.ForgeLegacyHandshakeResetID:
+		c.forge.legacyAcks = 0
+	}
+
+	return false, nil
+}
+
+// The client handshake phases FML1 acknowledges server messages with.
+const (
+	forgeLegacyWaitingServerData     = 2
+	forgeLegacyWaitingServerComplete = 3
+	forgeLegacyPendingComplete       = 4
+	forgeLegacyComplete              = 5
+)
+
+func (c *Client) writeForgeLegacyAck(phase byte) error {
+	return c.writeForgeLegacy([]byte{protocol.ForgeLegacyHandshakeAckID, phase})
+}
+
+func (c *Client) writeForgeLegacy(data []byte) error {
+	return c.WritePacket(&protocol.ServerboundCustomPayload{CustomPayloadData: protocol.CustomPayloadData{
+		Channel: protocol.ForgeLegacyHandshakeChannel,
+		Data:    data,
+	}})
+}
@@ -0,0 +1,180 @@
+package gophermc_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/obeliskdev/gophermc"
+	"github.com/obeliskdev/gophermc/component"
+	"github.com/obeliskdev/gophermc/protocol"
+)
+
+func TestForgeHandshake(t *testing.T) {
+	modList := &protocol.ForgeModList{
+		Mods:       []string{"minecraft", "forge", "examplemod"},
+		Channels:   []protocol.ForgeChannel{{Name: "forge:tier_sorting", Version: "1.0"}},
+		Registries: []string{"minecraft:block", "minecraft:item"},
+	}
+	config := []byte{protocol.ForgeConfigDataID}
+
+	addr, done := acceptLogin(t, func(conn *protocol.ServerConn, _ *protocol.ServerboundLoginStart) error {
+		if !strings.HasSuffix(conn.Handshake.ServerAddress, protocol.ForgeMarkerFML2) {
+			return fmt.Errorf("missing FML2 marker in %q", conn.Handshake.ServerAddress)
+		}
+
+		exchange := func(id int32, channel string, payload []byte) ([]byte, error) {
+			request := &protocol.ClientboundLoginPluginRequest{
+				MessageID: id,
+				Channel:   protocol.ForgeLoginWrapperChannel,
+				Data:      protocol.ForgeLoginWrapper(channel, payload),
+			}
+			if err := conn.WritePacket(request); err != nil {
+				return nil, err
+			}
+
+			packet, err := conn.ReadPacket()
+			if err != nil {
+				return nil, err
+			}
+			response, ok := packet.(*protocol.ServerboundLoginPluginResponse)
+			if !ok || response.MessageID != id || !response.Successful {
+				return nil, fmt.Errorf("unexpected response %+v", packet)
+			}
+
+			inner, reply, err := protocol.ReadForgeLoginWrapper(response.Data)
+			if err != nil || inner != channel {
+				return nil, fmt.Errorf("unexpected inner channel %q (%v)", inner, err)
+			}
+			return reply, nil
+		}
+
+		reply, err := exchange(1, protocol.ForgeHandshakeChannel, modList.Bytes())
+		if err != nil {
+			return err
+		}
+		id, r, err := protocol.ReadForgeHandshake(reply)
+		if err != nil || id != protocol.ForgeModListReplyID {
+			return fmt.Errorf("expected a mod list reply, got %d (%v)", id, err)
+		}
+		got, err := protocol.ReadForgeModListReply(r)
+		if err != nil {
+			return err
+		}
+		want := &protocol.ForgeModListReply{
+			Mods:       modList.Mods,
+			Channels:   modList.Channels,
+			Registries: []protocol.ForgeRegistry{{Name: "minecraft:block"}, {Name: "minecraft:item"}},
+		}
+		if !reflect.DeepEqual(got, want) {
+			return fmt.Errorf("expected %+v, got %+v", want, got)
+		}
+
+		if reply, err = exchange(2, protocol.ForgeHandshakeChannel, config); err != nil {
+			return err
+		}
+		if !bytes.Equal(reply, protocol.ForgeAcknowledge()) {
+			return fmt.Errorf("expected an acknowledgement, got %v", reply)
+		}
+
+		if reply, err = exchange(3, "examplemod:login", []byte("hi")); err != nil {
+			return err
+		}
+		if string(reply) != "hello hi" {
+			return fmt.Errorf("unexpected mod reply %q", reply)
+		}
+
+		return conn.Disconnect(component.Text("checked"))
+	})
+
+	joinExpectingKick(t,
+		gophermc.WithAddr(addr),
+		gophermc.WithVersion(protocol.V1_16_2),
+		gophermc.WithForge(),
+		gophermc.WithLoginPluginHandler("examplemod:login", func(data []byte) ([]byte, bool, error) {
+			return append([]byte("hello "), data...), true, nil
+		}),
+	)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestForgeLegacyHandshake(t *testing.T) {
+	mods := []protocol.ForgeMod{{ID: "forge", Version: "14.23.5.2859"}}
+
+	addr, done := acceptLogin(t, func(conn *protocol.ServerConn, login *protocol.ServerboundLoginStart) error {
+		if !strings.HasSuffix(conn.Handshake.ServerAddress, protocol.ForgeMarkerFML) {
+			return fmt.Errorf("missing FML marker in %q", conn.Handshake.ServerAddress)
+		}
+
+		if err := conn.WritePacket(&protocol.ClientboundLoginSuccess{Username: login.Username, UUID: protocol.OfflineUUID(login.Username)}); err != nil {
+			return err
+		}
+		conn.SetState(protocol.StatePlay)
+
+		send := func(channel string, data ...byte) error {
+			return conn.WritePacket(&protocol.ClientboundCustomPayload{CustomPayloadData: protocol.CustomPayloadData{Channel: channel, Data: data}})
+		}
+		expect := func(channel string, data []byte) error {
+			packet, err := conn.ReadPacket()
+			if err != nil {
+				return err
+			}
+			p, ok := packet.(*protocol.ServerboundCustomPayload)
+			if !ok || p.Channel != channel || !bytes.Equal(p.Data, data) {
+				return fmt.Errorf("expected %s %v, got %+v", channel, data, packet)
+			}
+			return nil
+		}
+
+		steps := []func() error{
+			func() error { return send("FML|HS", protocol.ForgeLegacyServerHelloID, 2, 0, 0, 0, 0) },
+			func() error { return expect("REGISTER", []byte("FML|HS\x00FML\x00FML|MP\x00FORGE")) },
+			func() error { return expect("FML|HS", []byte{protocol.ForgeLegacyClientHelloID, 2}) },
+			func() error { return expect("FML|HS", protocol.ForgeLegacyModList(mods)) },
+			func() error { return send("FML|HS", protocol.ForgeLegacyModList(mods)...) },
+			func() error { return expect("FML|HS", []byte{protocol.ForgeLegacyHandshakeAckID, 2}) },
+			func() error { return send("FML|HS", protocol.ForgeLegacyRegistryDataID, 1) },
+			func() error { return send("FML|HS", protocol.ForgeLegacyRegistryDataID, 0) },
+			func() error { return expect("FML|HS", []byte{protocol.ForgeLegacyHandshakeAckID, 3}) },
+			func() error { return send("FML|HS", protocol.ForgeLegacyHandshakeAckID, 2) },
+			func() error { return expect("FML|HS", []byte{protocol.ForgeLegacyHandshakeAckID, 4}) },
+			func() error { return send("FML|HS", protocol.ForgeLegacyHandshakeAckID, 3) },
+			func() error { return expect("FML|HS", []byte{protocol.ForgeLegacyHandshakeAckID, 5}) },
+			func() error {
+				_, err := conn.ReadPacket()
+				return err
+			},
+		}
+		for i, step := range steps {
+			if err := step(); err != nil {
+				return fmt.Errorf("step %d: %w", i, err)
+			}
+		}
+		return nil
+	})
+
+	client, err := gophermc.NewClient(
+		gophermc.WithAddr(addr),
+		gophermc.WithVersion(protocol.V1_12_2),
+		gophermc.WithForge(mods...),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Destroy()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Join(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
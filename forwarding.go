@@ -0,0 +1,63 @@
+package gophermc
+
+import (
+	"github.com/google/uuid"
+	"github.com/obeliskdev/gophermc/protocol"
+)
+
+// forwarding is the player information a client hands to a backend server
+// the way a proxy would.
+type forwarding struct {
+	bungeeCord     bool
+	velocitySecret []byte
+
+	clientIP   string
+	properties []protocol.Property
+}
+
+// forwardedUUID is the UUID proxies forward: the configured one, or the
+// offline UUID of the username.
+func (c *Client) forwardedUUID() uuid.UUID {
+	if c.uniqueId != uuid.Nil {
+		return c.uniqueId
+	}
+	return protocol.OfflineUUID(c.username)
+}
+
+func (c *Client) bungeeCordAddress() (string, error) {
+	f := &protocol.BungeeCordForwarding{
+		Host:       c.ServerHostname(),
+		ClientIP:   c.forwarding.clientIP,
+		UUID:       c.forwardedUUID(),
+		Properties: c.forwarding.properties,
+	}
+	return f.ServerAddress()
+}
+
+// velocityPlayerInfo is the LoginPluginHandler WithVelocityForwarding
+// registers for VelocityPlayerInfoChannel. The request carries the highest
+// forwarding version the proxy supports.
+func (c *Client) velocityPlayerInfo(data []byte) ([]byte, bool, error) {
+	requested := protocol.VelocityForwardingDefault
+	if len(data) > 0 {
+		requested = int(data[0])
+	}
+
+	f := &protocol.VelocityForwarding{
+		Version:    protocol.VelocityForwardingVersion(requested, c.version, c.playerKey),
+		ClientIP:   c.forwarding.clientIP,
+		UUID:       c.forwardedUUID(),
+		Username:   c.username,
+		Properties: c.forwarding.properties,
+	}
+	if f.Version != protocol.VelocityForwardingDefault && f.Version != protocol.VelocityForwardingLazy {
+		f.PlayerKey = c.playerKey
+		f.KeyHolder = f.UUID
+	}
+
+	signed, err := f.Sign(c.forwarding.velocitySecret)
+	if err != nil {
+		return nil, false, err
+	}
+	return signed, true, nil
+}
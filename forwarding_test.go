@@ -0,0 +1,177 @@
+package gophermc_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/obeliskdev/gophermc"
+	"github.com/obeliskdev/gophermc/component"
+	"github.com/obeliskdev/gophermc/protocol"
+)
+
+// acceptLogin accepts one login and hands the connection to serve, which
+// should end by disconnecting the client.
+func acceptLogin(t *testing.T, serve func(conn *protocol.ServerConn, login *protocol.ServerboundLoginStart) error) (string, <-chan error) {
+	t.Helper()
+
+	l, err := protocol.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+
+	done := make(chan error, 1)
+	go func() {
+		done <- func() error {
+			conn, err := l.Accept()
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+			_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+			if _, err := conn.ReadHandshake(); err != nil {
+				return err
+			}
+
+			packet, err := conn.ReadPacket()
+			if err != nil {
+				return err
+			}
+			login, ok := packet.(*protocol.ServerboundLoginStart)
+			if !ok {
+				return errors.New("expected login start")
+			}
+
+			return serve(conn, login)
+		}()
+	}()
+
+	return l.Addr().String(), done
+}
+
+func joinExpectingKick(t *testing.T, opts ...gophermc.ClientOption) {
+	t.Helper()
+
+	client, err := gophermc.NewClient(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Destroy()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Join(ctx); err == nil || !strings.Contains(err.Error(), "checked") {
+		t.Fatalf("expected the test kick, got %v", err)
+	}
+}
+
+func TestBungeeCordForwarding(t *testing.T) {
+	textures := protocol.Property{Name: "textures", Value: "e30=", Signature: "c2ln"}
+
+	addr, done := acceptLogin(t, func(conn *protocol.ServerConn, login *protocol.ServerboundLoginStart) error {
+		forwarded, err := protocol.ParseBungeeCordForwarding(conn.Handshake.ServerAddress)
+		if err != nil {
+			return err
+		}
+		if forwarded.Host != "lobby.example.com" || forwarded.ClientIP != "203.0.113.7" || forwarded.UUID != protocol.OfflineUUID(login.Username) {
+			return errors.New("unexpected forwarding " + conn.Handshake.ServerAddress)
+		}
+		if len(forwarded.Properties) != 1 || forwarded.Properties[0] != textures {
+			return errors.New("unexpected properties")
+		}
+		return conn.Disconnect(component.Text("checked"))
+	})
+
+	joinExpectingKick(t,
+		gophermc.WithAddr(addr),
+		gophermc.WithServerHostname("lobby.example.com"),
+		gophermc.WithUsername("Steve"),
+		gophermc.WithBungeeCordForwarding("203.0.113.7", textures),
+	)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVelocityForwarding(t *testing.T) {
+	secret := []byte("forwarding-secret")
+	playerKey := newTestPlayerKey(t)
+
+	for _, tc := range []struct {
+		version protocol.Version
+		key     *protocol.PlayerKey
+		want    int
+	}{
+		{protocol.V1_13, nil, protocol.VelocityForwardingDefault},
+		{protocol.V1_19_2, nil, protocol.VelocityForwardingDefault},
+		{protocol.V1_19_2, playerKey, protocol.VelocityForwardingWithKeyV2},
+		{protocol.V1_19_4, playerKey, protocol.VelocityForwardingLazy},
+		{protocol.V1_21_4, nil, protocol.VelocityForwardingLazy},
+	} {
+		addr, done := acceptLogin(t, func(conn *protocol.ServerConn, login *protocol.ServerboundLoginStart) error {
+			requests := []*protocol.ClientboundLoginPluginRequest{
+				{MessageID: 1, Channel: "example:unknown"},
+				{MessageID: 2, Channel: protocol.VelocityPlayerInfoChannel, Data: []byte{protocol.VelocityForwardingLazy}},
+			}
+			for _, request := range requests {
+				if err := conn.WritePacket(request); err != nil {
+					return err
+				}
+			}
+
+			for _, request := range requests {
+				packet, err := conn.ReadPacket()
+				if err != nil {
+					return err
+				}
+				response, ok := packet.(*protocol.ServerboundLoginPluginResponse)
+				if !ok || response.MessageID != request.MessageID {
+					return errors.New("unexpected plugin response")
+				}
+
+				if request.Channel != protocol.VelocityPlayerInfoChannel {
+					if response.Successful {
+						return errors.New("unknown channel answered as understood")
+					}
+					continue
+				}
+
+				forwarded, err := protocol.VerifyVelocityForwarding(secret, response.Data)
+				if err != nil {
+					return err
+				}
+				if forwarded.Version != tc.want || forwarded.ClientIP != "203.0.113.7" || forwarded.Username != login.Username || forwarded.UUID != protocol.OfflineUUID("Steve") {
+					return fmt.Errorf("unexpected forwarding data %+v", forwarded)
+				}
+				// 1.19.1 keys are forwarded with the UUID they were signed for.
+				if tc.want == protocol.VelocityForwardingWithKeyV2 {
+					if forwarded.PlayerKey == nil || !bytes.Equal(forwarded.PlayerKey.SignatureV2, tc.key.SignatureV2) || forwarded.KeyHolder != forwarded.UUID {
+						return fmt.Errorf("unexpected forwarded key %+v", forwarded.PlayerKey)
+					}
+				}
+			}
+
+			return conn.Disconnect(component.Text("checked"))
+		})
+
+		opts := []gophermc.ClientOption{
+			gophermc.WithAddr(addr),
+			gophermc.WithVersion(tc.version),
+			gophermc.WithUsername("Steve"),
+			gophermc.WithVelocityForwarding(secret, "203.0.113.7"),
+		}
+		if tc.key != nil {
+			opts = append(opts, gophermc.WithPlayerKey(tc.key))
+		}
+		joinExpectingKick(t, opts...)
+		if err := <-done; err != nil {
+			t.Fatalf("%s: %v", tc.version, err)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+// Command lang downloads the vanilla en_us language file from Mojang's asset
+// server and writes it to component/lang/en_us.json.
+//
+//	go generate ./component
+package main
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+)
+
+const (
+	manifestURL = "https://piston-meta.mojang.com/mc/game/version_manifest_v2.json"
+	resourceURL = "https://resources.download.minecraft.net/%s/%s"
+	langObject  = "minecraft/lang/en_us.json"
+)
+
+func main() {
+	version := flag.String("version", "", "game version to take the language file from (default latest release)")
+	output := flag.String("o", "lang/en_us.json", "output file")
+	flag.Parse()
+
+	var manifest struct {
+		Latest struct {
+			Release string `json:"release"`
+		} `json:"latest"`
+		Versions []struct {
+			ID  string `json:"id"`
+			URL string `json:"url"`
+		} `json:"versions"`
+	}
+	if err := getJSON(manifestURL, &manifest); err != nil {
+		log.Fatalf("FATAL: version manifest: %v", err)
+	}
+	if *version == "" {
+		*version = manifest.Latest.Release
+	}
+
+	versionURL := ""
+	for _, v := range manifest.Versions {
+		if v.ID == *version {
+			versionURL = v.URL
+			break
+		}
+	}
+	if versionURL == "" {
+		log.Fatalf("FATAL: version %q not found in the manifest", *version)
+	}
+
+	var meta struct {
+		AssetIndex struct {
+			URL string `json:"url"`
+		} `json:"assetIndex"`
+	}
+	if err := getJSON(versionURL, &meta); err != nil {
+		log.Fatalf("FATAL: version %s: %v", *version, err)
+	}
+
+	var index struct {
+		Objects map[string]struct {
+			Hash string `json:"hash"`
+		} `json:"objects"`
+	}
+	if err := getJSON(meta.AssetIndex.URL, &index); err != nil {
+		log.Fatalf("FATAL: asset index: %v", err)
+	}
+	object, ok := index.Objects[langObject]
+	if !ok {
+		log.Fatalf("FATAL: %s missing from the %s asset index", langObject, *version)
+	}
+
+	data, err := get(fmt.Sprintf(resourceURL, object.Hash[:2], object.Hash))
+	if err != nil {
+		log.Fatalf("FATAL: %s: %v", langObject, err)
+	}
+	if sum := sha1.Sum(data); hex.EncodeToString(sum[:]) != object.Hash {
+		log.Fatalf("FATAL: %s: checksum mismatch", langObject)
+	}
+
+	var entries map[string]string
+	if err := json.Unmarshal(data, &entries); err != nil {
+		log.Fatalf("FATAL: %s: %v", langObject, err)
+	}
+
+	out, err := encode(entries)
+	if err != nil {
+		log.Fatalf("FATAL: encode: %v", err)
+	}
+	if err := os.WriteFile(*output, out, 0644); err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+	log.Printf("wrote %d %s keys to %s", len(entries), *version, *output)
+}
+
+// encode writes entries as an indented JSON object with sorted keys, leaving
+// characters such as < and & unescaped so the file diffs cleanly.
+func encode(entries map[string]string) ([]byte, error) {
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	buf.WriteString("{\n")
+	for i, key := range keys {
+		var line bytes.Buffer
+		enc := json.NewEncoder(&line)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(key); err != nil {
+			return nil, err
+		}
+		buf.WriteString("  ")
+		buf.Write(bytes.TrimSuffix(line.Bytes(), []byte("\n")))
+		buf.WriteString(": ")
+		line.Reset()
+		if err := enc.Encode(entries[key]); err != nil {
+			return nil, err
+		}
+		buf.Write(bytes.TrimSuffix(line.Bytes(), []byte("\n")))
+		if i < len(keys)-1 {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('\n')
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes(), nil
+}
+
+func getJSON(url string, v any) error {
+	data, err := get(url)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func get(url string) ([]byte, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
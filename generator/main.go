@@ -22,40 +22,51 @@ import (
 const (
 	versionsOutputFile = "../protocol/generated_versions.go"
 	registryOutputFile = "../protocol/generated_registry.go"
+	packetsOutputFile  = "../protocol/generated_packets.go"
 )
 
 //goland:noinspection SpellCheckingInspection
 var goNameToMcNames = map[string][]string{
-	"ServerboundHandshake":           {"set_protocol"},
-	"ClientboundStatusResponse":      {"server_info"},
-	"ClientboundPong":                {"ping"},
-	"ServerboundStatusRequest":       {"ping_start"},
-	"ServerboundPing":                {"ping"},
-	"ServerboundLoginStart":          {"login_start"},
-	"ClientboundLoginSuccess":        {"success"},
-	"ClientboundSetCompression":      {"compress"},
-	"ServerboundLoginAcknowledged":   {"login_acknowledged"},
-	"ClientboundKeepAlive":           {"keep_alive"},
-	"ServerboundKeepAlive":           {"keep_alive"},
-	"ServerboundChatMessage":         {"chat", "chat_message", "player_chat_message"},
-	"ServerboundClientSettings":      {"settings", "client_information"},
-	"ServerboundCustomPayload":       {"custom_payload"},
-	"ClientboundCustomPayload":       {"custom_payload"},
-	"ClientboundDisconnect":          {"kick_disconnect", "disconnect"},
-	"ServerboundFinishConfiguration": {"finish_configuration"},
-	"ClientboundFinishConfiguration": {"finish_configuration"},
-	"ServerboundConfigKeepAlive":     {"keep_alive"},
-	"ClientboundConfigKeepAlive":     {"keep_alive"},
-	"ServerboundSelectKnownPacks":    {"select_known_packs"},
-	"ClientboundSelectKnownPacks":    {"select_known_packs"},
-	"ClientboundCookieRequest":       {"cookie_request"},
-	"ServerboundCookieResponse":      {"cookie_response"},
-	"ClientboundConfigPing":          {"ping"},
-	"ServerboundConfigPong":          {"pong"},
-	"ClientboundJoinGame":            {"login"},
-	"ClientboundFeatureFlags":        {"feature_flags"},
-	"ClientboundUpdateTags":          {"update_tags"},
-	"ClientboundRegistryData":        {"registry_data"},
+	"ServerboundHandshake":              {"set_protocol"},
+	"ClientboundStatusResponse":         {"server_info"},
+	"ClientboundPong":                   {"ping"},
+	"ServerboundStatusRequest":          {"ping_start"},
+	"ServerboundPing":                   {"ping"},
+	"ServerboundLoginStart":             {"login_start"},
+	"ClientboundEncryptionRequest":      {"encryption_begin"},
+	"ServerboundEncryptionResponse":     {"encryption_begin"},
+	"ClientboundLoginSuccess":           {"success"},
+	"ClientboundSetCompression":         {"compress"},
+	"ServerboundLoginAcknowledged":      {"login_acknowledged"},
+	"ClientboundLoginPluginRequest":     {"login_plugin_request"},
+	"ServerboundLoginPluginResponse":    {"login_plugin_response"},
+	"ClientboundKeepAlive":              {"keep_alive"},
+	"ServerboundKeepAlive":              {"keep_alive"},
+	"ServerboundChatMessage":            {"chat", "chat_message", "player_chat_message"},
+	"ClientboundChatMessage":            {"chat"},
+	"ClientboundPlayerChat":             {"player_chat"},
+	"ClientboundSystemChat":             {"system_chat"},
+	"ClientboundDisguisedChat":          {"profileless_chat"},
+	"ServerboundChatSessionUpdate":      {"chat_session_update"},
+	"ServerboundMessageAcknowledgement": {"message_acknowledgement"},
+	"ServerboundClientSettings":         {"settings", "client_information"},
+	"ServerboundCustomPayload":          {"custom_payload"},
+	"ClientboundCustomPayload":          {"custom_payload"},
+	"ClientboundDisconnect":             {"kick_disconnect", "disconnect"},
+	"ServerboundFinishConfiguration":    {"finish_configuration"},
+	"ClientboundFinishConfiguration":    {"finish_configuration"},
+	"ServerboundConfigKeepAlive":        {"keep_alive"},
+	"ClientboundConfigKeepAlive":        {"keep_alive"},
+	"ServerboundSelectKnownPacks":       {"select_known_packs"},
+	"ClientboundSelectKnownPacks":       {"select_known_packs"},
+	"ClientboundCookieRequest":          {"cookie_request"},
+	"ServerboundCookieResponse":         {"cookie_response"},
+	"ClientboundConfigPing":             {"ping"},
+	"ServerboundConfigPong":             {"pong"},
+	"ClientboundJoinGame":               {"login"},
+	"ClientboundFeatureFlags":           {"feature_flags"},
+	"ClientboundUpdateTags":             {"update_tags"},
+	"ClientboundRegistryData":           {"registry_data"},
 }
 
 const versionsTemplate = `// Code generated by gophermc/generator. DO NOT EDIT.
@@ -170,6 +181,9 @@ type (
 		ProtocolVersion int32
 		PacketIDs       map[string]map[string]map[string]int32
 		PacketNames     map[string]map[string]map[int32]string
+
+		states map[string]mcState
+		types  map[string]interface{}
 	}
 
 	templateData struct {
@@ -219,6 +233,9 @@ func main() {
 		FirstVersionEnum:  parsedVersions[0].EnumName,
 	}
 
+	packets := generatePackets(parsedVersions, reservedIdents(filepath.Dir(packetsOutputFile), packetsOutputFile))
+	writePackets(packetsOutputFile, packets)
+
 	generateFile(versionsOutputFile, versionsTemplate, "versions", td)
 	generateFile(registryOutputFile, registryTemplate, "registry", td)
 }
@@ -245,24 +262,41 @@ func loadAndParseProtocols(baseDataDir string, versionToProtoNum map[string]int3
 			continue
 		}
 
-		var states map[string]mcState
-		if err := json.Unmarshal(protoData, &states); err != nil {
-			log.Printf("WARN: Could not parse protocol.json for %s: %v", versionStr, err)
-			continue
-		}
-
 		protoNum := findBestProtoNum(versionStr, versionToProtoNum)
 		if protoNum == 0 {
 			log.Printf("WARN: No protocol number found for version %s, skipping", versionStr)
 			continue
 		}
 
-		vi := processVersion(versionStr, protoNum, states)
+		vi, err := parseProtocol(versionStr, protoNum, protoData)
+		if err != nil {
+			log.Printf("WARN: Could not parse protocol.json for %s: %v", versionStr, err)
+			continue
+		}
 		parsedVersions = append(parsedVersions, vi)
 	}
 	return parsedVersions
 }
 
+// parseProtocol parses the protocol.json of a version and registers the
+// packets written by hand.
+func parseProtocol(versionStr string, protoNum int32, data []byte) (versionInfo, error) {
+	var states map[string]mcState
+	if err := json.Unmarshal(data, &states); err != nil {
+		return versionInfo{}, err
+	}
+	var global struct {
+		Types map[string]interface{} `json:"types"`
+	}
+	if err := json.Unmarshal(data, &global); err != nil {
+		return versionInfo{}, err
+	}
+
+	vi := processVersion(versionStr, protoNum, states)
+	vi.types = global.Types
+	return vi, nil
+}
+
 func processVersion(versionStr string, protoNum int32, states map[string]mcState) versionInfo {
 	vi := versionInfo{
 		VersionStr:      versionStr,
@@ -270,9 +304,10 @@ func processVersion(versionStr string, protoNum int32, states map[string]mcState
 		ProtocolVersion: protoNum,
 		PacketIDs:       make(map[string]map[string]map[string]int32),
 		PacketNames:     make(map[string]map[string]map[int32]string),
+		states:          states,
 	}
 
-	for _, stateName := range []string{"handshaking", "status", "login", "configuration", "play"} {
+	for _, stateName := range stateNames {
 		if stateData, ok := states[stateName]; ok {
 			processState(stateName, stateData, &vi)
 		}
@@ -289,37 +324,55 @@ func processState(stateName string, stateData mcState, vi *versionInfo) {
 }
 
 func processDirection(stateName, dirName string, idMap map[string]int32, vi *versionInfo) {
-	if len(idMap) == 0 {
-		return
+	for mcPacketName, id := range idMap {
+		if goName := handWrittenName(mcPacketName, stateName, dirName); goName != "" {
+			vi.addPacket(stateName, dirName, goName, id)
+		}
 	}
+}
+
+// handWrittenName returns the name of the packet written by hand for
+// mcPacketName, or "" if it has to be generated.
+func handWrittenName(mcPacketName, stateName, dirName string) string {
+	for goName, mcNames := range goNameToMcNames {
+		if contains(mcNames, mcPacketName) {
+			if finalGoName := resolveAmbiguousNames(goName, stateName, dirName); finalGoName != "" {
+				return finalGoName
+			}
+		}
+	}
+	return ""
+}
+
+func (vi *versionInfo) addPacket(stateName, dirName, goName string, id int32) {
 	title := cases.Title(language.English)
 
 	stateEnum := "State" + title.String(stateName)
 	dirEnum := "Direction" + title.String(dirName)
 
-	for mcPacketName, id := range idMap {
-		for goName, mcNames := range goNameToMcNames {
-			if contains(mcNames, mcPacketName) {
-				finalGoName := resolveAmbiguousNames(goName, stateName, dirName)
-				if finalGoName == "" {
-					continue
-				}
-
-				if vi.PacketIDs[stateEnum] == nil {
-					vi.PacketIDs[stateEnum] = make(map[string]map[string]int32)
-					vi.PacketNames[stateEnum] = make(map[string]map[int32]string)
-				}
-
-				if vi.PacketIDs[stateEnum][dirEnum] == nil {
-					vi.PacketIDs[stateEnum][dirEnum] = make(map[string]int32)
-					vi.PacketNames[stateEnum][dirEnum] = make(map[int32]string)
-				}
-
-				vi.PacketIDs[stateEnum][dirEnum][finalGoName] = id
-				vi.PacketNames[stateEnum][dirEnum][id] = finalGoName
-				break
-			}
-		}
+	if vi.PacketIDs[stateEnum] == nil {
+		vi.PacketIDs[stateEnum] = make(map[string]map[string]int32)
+		vi.PacketNames[stateEnum] = make(map[string]map[int32]string)
+	}
+
+	if vi.PacketIDs[stateEnum][dirEnum] == nil {
+		vi.PacketIDs[stateEnum][dirEnum] = make(map[string]int32)
+		vi.PacketNames[stateEnum][dirEnum] = make(map[int32]string)
+	}
+
+	vi.PacketIDs[stateEnum][dirEnum][goName] = id
+	vi.PacketNames[stateEnum][dirEnum][id] = goName
+}
+
+// namespaces returns the packet types of stateName by direction.
+func (vi *versionInfo) namespaces(stateName string) map[string]map[string]interface{} {
+	stateData, ok := vi.states[stateName]
+	if !ok {
+		return nil
+	}
+	return map[string]map[string]interface{}{
+		"serverbound": stateData.ToServer.Types,
+		"clientbound": stateData.ToClient.Types,
 	}
 }
 
@@ -418,7 +471,7 @@ func resolveAmbiguousNames(goName, stateName, dirName string) string {
 	switch goName {
 	case "ClientboundDisconnect":
 		if stateName == "login" {
-			return "ClientboundDisconnect"
+			return "ClientboundLoginDisconnect"
 		}
 		return "ClientboundDisconnect"
 	case "ServerboundCustomPayload", "ClientboundCustomPayload":
@@ -426,6 +479,16 @@ func resolveAmbiguousNames(goName, stateName, dirName string) string {
 			return "ServerboundCustomPayload"
 		}
 		return "ClientboundCustomPayload"
+	case "ServerboundChatMessage", "ClientboundChatMessage":
+		if dirName == "serverbound" {
+			return "ServerboundChatMessage"
+		}
+		return "ClientboundChatMessage"
+	case "ClientboundEncryptionRequest", "ServerboundEncryptionResponse":
+		if dirName == "serverbound" {
+			return "ServerboundEncryptionResponse"
+		}
+		return "ClientboundEncryptionRequest"
 	case "ServerboundSelectKnownPacks", "ClientboundSelectKnownPacks":
 		if dirName == "serverbound" {
 			return "ServerboundSelectKnownPacks"
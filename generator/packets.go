@@ -0,0 +1,1441 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var stateNames = []string{"handshaking", "status", "login", "configuration", "play"}
+
+// statePrefixes tell apart packets of the same name in different states.
+var statePrefixes = map[string]string{
+	"handshaking":   "Handshake",
+	"status":        "Status",
+	"login":         "Login",
+	"configuration": "Config",
+}
+
+// goType is a Go type declared in the generated file.
+type goType struct {
+	Name   string
+	Packet bool
+	Source string
+}
+
+// goField is a struct field generated for a container field. A switch
+// whose cases have different layouts gets one field per case, marked by
+// Case or Default.
+type goField struct {
+	Name    string
+	Type    string
+	Field   *field
+	Case    string
+	Default bool
+	// Ptr is set when Type was made a pointer because a switch may leave
+	// the field out.
+	Ptr bool
+}
+
+// packetGenerator declares the Go types of packets and the types they
+// use. Types are keyed by layout so that versions and packets sharing a
+// layout share the type.
+type packetGenerator struct {
+	// version is the enum name of the version being generated, which
+	// suffixes the name of a type whose layout changed.
+	version string
+
+	taken  map[string]bool
+	bySig  map[string]string
+	types  []*goType
+	fields map[*node][]goField
+
+	// journal records the keys declared for the packet being generated,
+	// to undo them if the packet turns out to be unsupported.
+	journal []string
+}
+
+func newPacketGenerator(reserved map[string]bool) *packetGenerator {
+	g := &packetGenerator{
+		taken:  make(map[string]bool, len(reserved)),
+		bySig:  make(map[string]string),
+		fields: make(map[*node][]goField),
+	}
+	for name := range reserved {
+		g.taken[name] = true
+	}
+	return g
+}
+
+// generatePackets generates every packet not written by hand and adds the
+// generated ones to the registries of versions.
+func generatePackets(versions []versionInfo, reserved map[string]bool) []*goType {
+	g := newPacketGenerator(reserved)
+
+	// A packet name found in several states is prefixed with its state
+	// outside of play.
+	packetStates := make(map[string]map[string]bool)
+	for _, vi := range versions {
+		for _, stateName := range stateNames {
+			for dirName, ns := range vi.namespaces(stateName) {
+				for mcName := range preParsePacketIDs(ns) {
+					key := dirName + "/" + mcName
+					if packetStates[key] == nil {
+						packetStates[key] = make(map[string]bool)
+					}
+					packetStates[key][stateName] = true
+				}
+			}
+		}
+	}
+
+	for i := len(versions) - 1; i >= 0; i-- {
+		vi := &versions[i]
+		g.version = vi.EnumName
+
+		for _, stateName := range stateNames {
+			namespaces := vi.namespaces(stateName)
+			for _, dirName := range []string{"serverbound", "clientbound"} {
+				ns, ok := namespaces[dirName]
+				if !ok {
+					continue
+				}
+
+				ids := preParsePacketIDs(ns)
+				typeNames := packetTypeNames(ns)
+				mcNames := make([]string, 0, len(ids))
+				for mcName := range ids {
+					mcNames = append(mcNames, mcName)
+				}
+				sort.Strings(mcNames)
+
+				for _, mcName := range mcNames {
+					if handWrittenName(mcName, stateName, dirName) != "" {
+						continue
+					}
+
+					prefix := ""
+					if len(packetStates[dirName+"/"+mcName]) > 1 {
+						prefix = statePrefixes[stateName]
+					}
+					base := goIdent(dirName) + prefix + goIdent(mcName)
+					if reserved[base] {
+						base += "Packet"
+					}
+
+					typeName, ok := typeNames[mcName]
+					if !ok {
+						typeName = "packet_" + mcName
+					}
+
+					rs := &resolver{global: vi.types, local: ns}
+					name, err := g.generatePacket(base, rs, typeName)
+					if err != nil {
+						log.Printf("WARN: Skipping %s %s %s %s: %v", vi.VersionStr, stateName, dirName, mcName, err)
+						continue
+					}
+					vi.addPacket(stateName, dirName, name, ids[mcName])
+				}
+			}
+		}
+	}
+	return g.types
+}
+
+func (g *packetGenerator) generatePacket(base string, rs *resolver, typeName string) (string, error) {
+	g.journal = g.journal[:0]
+	types := len(g.types)
+
+	name, err := g.declarePacket(base, rs, typeName)
+	if err != nil {
+		// Forget the types declared for the packet, which may be half
+		// generated.
+		for _, key := range g.journal {
+			delete(g.taken, g.bySig[key])
+			delete(g.bySig, key)
+		}
+		g.types = g.types[:types]
+		return "", err
+	}
+	return name, nil
+}
+
+func (g *packetGenerator) declarePacket(base string, rs *resolver, typeName string) (string, error) {
+	n, err := rs.resolve(typeName)
+	if err != nil {
+		return "", err
+	}
+	if n.Kind != "container" {
+		return "", fmt.Errorf("packet of kind %s", n.Kind)
+	}
+	if climbInside(n) > 0 {
+		return "", fmt.Errorf("packet fields refer outside of the packet")
+	}
+	return g.declare(base, "packet "+base+" "+n.signature(), n, true)
+}
+
+// declare returns the name of the type generated for n, generating it if
+// no type with the same key exists yet. Names already taken are suffixed
+// with the version.
+func (g *packetGenerator) declare(base, key string, n *node, packet bool) (string, error) {
+	if name, ok := g.bySig[key]; ok {
+		return name, nil
+	}
+
+	name := base
+	if g.taken[name] {
+		name = base + g.version
+		for i := 2; g.taken[name]; i++ {
+			name = fmt.Sprintf("%s%s_%d", base, g.version, i)
+		}
+	}
+	g.taken[name] = true
+	g.bySig[key] = name
+	g.journal = append(g.journal, key)
+
+	t := &goType{Name: name, Packet: packet}
+	g.types = append(g.types, t)
+
+	var err error
+	if t.Source, err = g.typeSource(name, n, packet); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// goType returns the Go type of n, declaring the named types it needs.
+// hint names the type when n has no name of its own.
+func (g *packetGenerator) goType(n *node, hint string) (string, error) {
+	switch n.Kind {
+	case "native":
+		if n.Native == "buffer" {
+			return "[]byte", nil
+		}
+		if t, ok := nativeGoTypes[n.Native]; ok {
+			return t, nil
+		}
+		return "", fmt.Errorf("native type %s has no Go type", n.Native)
+	case "option":
+		t, err := g.goType(n.Elem, hint)
+		return "*" + t, err
+	case "array", "entityMetadataLoop", "topBitSetTerminatedArray":
+		t, err := g.goType(n.Elem, hint)
+		return "[]" + t, err
+	case "container", "bitfield", "registryEntryHolder", "registryEntryHolderSet":
+		if n.Name != "" {
+			hint = goIdent(n.Name)
+		}
+		return g.declare(hint, n.signature(), n, false)
+	}
+	return "", fmt.Errorf("%s outside of a container", n.Kind)
+}
+
+// containerFields returns the struct fields generated for container n.
+func (g *packetGenerator) containerFields(n *node, typeName string) ([]goField, error) {
+	if fields, ok := g.fields[n]; ok {
+		return fields, nil
+	}
+
+	var fields []goField
+	// Fields can't share the names of the packet methods.
+	names := map[string]bool{"Encode": true, "Decode": true}
+	add := func(f goField) {
+		name := f.Name
+		for i := 2; names[name]; i++ {
+			name = fmt.Sprintf("%s%d", f.Name, i)
+		}
+		names[name] = true
+		f.Name = name
+		fields = append(fields, f)
+	}
+	// optional returns the type of a field a switch may leave out.
+	optional := func(n *node, hint string) (string, bool, error) {
+		t, err := g.goType(n, hint)
+		if err != nil || nilable(t) {
+			return t, false, err
+		}
+		return "*" + t, true, nil
+	}
+
+	for _, f := range n.Fields {
+		name := goIdent(f.Name)
+		fn := f.Node
+		if fn.Kind == "switch" && fn.CompareValue != nil {
+			fn = staticCase(fn)
+		}
+		if fn.isVoid() {
+			continue
+		}
+
+		if fn.Kind != "switch" {
+			t, err := g.goType(fn, typeName+name)
+			if err != nil {
+				return nil, err
+			}
+			add(goField{Name: name, Type: t, Field: f})
+			continue
+		}
+
+		maybeVoid := fn.Default.isVoid()
+		var branches []*node
+		for _, c := range fn.Cases {
+			if c.Node.isVoid() {
+				maybeVoid = true
+			} else {
+				branches = append(branches, c.Node)
+			}
+		}
+		if !fn.Default.isVoid() {
+			branches = append(branches, fn.Default)
+		}
+		if len(branches) == 0 {
+			continue
+		}
+
+		if uniform(branches) {
+			t, err := g.goType(branches[0], typeName+name)
+			if err != nil {
+				return nil, err
+			}
+			ptr := maybeVoid && !nilable(t)
+			if ptr {
+				t = "*" + t
+			}
+			add(goField{Name: name, Type: t, Field: f, Ptr: ptr})
+			continue
+		}
+
+		for _, c := range fn.Cases {
+			if c.Node.isVoid() {
+				continue
+			}
+			caseName := name + goIdent(c.Key)
+			t, ptr, err := optional(c.Node, typeName+caseName)
+			if err != nil {
+				return nil, err
+			}
+			add(goField{Name: caseName, Type: t, Field: f, Case: c.Key, Ptr: ptr})
+		}
+		if !fn.Default.isVoid() {
+			t, ptr, err := optional(fn.Default, typeName+name+"Default")
+			if err != nil {
+				return nil, err
+			}
+			add(goField{Name: name + "Default", Type: t, Field: f, Default: true, Ptr: ptr})
+		}
+	}
+
+	g.fields[n] = fields
+	return fields, nil
+}
+
+func (g *packetGenerator) fieldsOf(n *node, f *field) []goField {
+	var out []goField
+	for _, gf := range g.fields[n] {
+		if gf.Field == f {
+			out = append(out, gf)
+		}
+	}
+	return out
+}
+
+func uniform(branches []*node) bool {
+	sig := branches[0].signature()
+	for _, b := range branches[1:] {
+		if b.signature() != sig {
+			return false
+		}
+	}
+	return true
+}
+
+func nilable(t string) bool {
+	return strings.HasPrefix(t, "*") || strings.HasPrefix(t, "[]") || t == "nbt.Tag"
+}
+
+// staticCase returns the case a switch with a compareToValue selects.
+func staticCase(n *node) *node {
+	for _, c := range n.Cases {
+		if c.Key == *n.CompareValue {
+			return c.Node
+		}
+	}
+	return n.Default
+}
+
+// typeSource generates the declaration and codec of a named type.
+func (g *packetGenerator) typeSource(name string, n *node, packet bool) (string, error) {
+	var b strings.Builder
+	encode, decode := "encode", "decode"
+	if packet {
+		encode, decode = "Encode", "Decode"
+	}
+
+	switch n.Kind {
+	case "container":
+		fields, err := g.containerFields(n, name)
+		if err != nil {
+			return "", err
+		}
+		if len(fields) == 0 {
+			fmt.Fprintf(&b, "type %s struct{}\n\n", name)
+		} else {
+			fmt.Fprintf(&b, "type %s struct {\n", name)
+			for _, f := range fields {
+				fmt.Fprintf(&b, "%s %s\n", f.Name, f.Type)
+			}
+			b.WriteString("}\n\n")
+		}
+		if !packet && climbInside(n) > 0 {
+			// Encoded inline by the containers using it.
+			return b.String(), nil
+		}
+
+		enc := &emitter{g: g}
+		if err := enc.encodeFields(n, name, "p", nil); err != nil {
+			return "", err
+		}
+		dec := &emitter{g: g}
+		if err := dec.decodeFields(n, name, "p", nil); err != nil {
+			return "", err
+		}
+		writeMethods(&b, name, encode, decode, enc, dec)
+
+	case "bitfield":
+		total := 0
+		for _, bit := range n.Bits {
+			total += bit.Size
+		}
+		names := bitNames(n)
+
+		fmt.Fprintf(&b, "type %s struct {\n", name)
+		for i, bit := range n.Bits {
+			fmt.Fprintf(&b, "%s %s\n", names[i], bitType(bit))
+		}
+		b.WriteString("}\n\n")
+
+		enc := &emitter{g: g}
+		enc.printf("var bits uint64\n")
+		for i, bit := range n.Bits {
+			enc.printf("bits = bits<<%d | uint64(p.%s)&%#x\n", bit.Size, names[i], mask(bit.Size))
+		}
+		enc.check(fmt.Sprintf("binary.Write(w, binary.BigEndian, uint%d(bits))", total))
+
+		dec := &emitter{g: g}
+		dec.printf("var bits uint%d\n", total)
+		dec.check("binary.Read(r, binary.BigEndian, &bits)")
+		shift := total
+		for i, bit := range n.Bits {
+			shift -= bit.Size
+			value := fmt.Sprintf("uint64(bits>>%d) & %#x", shift, mask(bit.Size))
+			if shift == 0 {
+				value = fmt.Sprintf("uint64(bits) & %#x", mask(bit.Size))
+			}
+			if bit.Signed {
+				value = fmt.Sprintf("int64((%s)<<%d) >> %d", value, 64-bit.Size, 64-bit.Size)
+			}
+			dec.printf("p.%s = %s(%s)\n", names[i], bitType(bit), value)
+		}
+		writeMethods(&b, name, encode, decode, enc, dec)
+
+	case "registryEntryHolder":
+		if climb(n.Elem) >= 0 {
+			return "", fmt.Errorf("registry entry holder refers outside of itself")
+		}
+		id, value := holderNames(n.BaseName, "ID"), holderNames(n.OtherName, "Value")
+		elem, err := g.goType(n.Elem, name+value)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "type %s struct {\n%s int32\n%s *%s\n}\n\n", name, id, value, elem)
+
+		enc := &emitter{g: g}
+		enc.printf("if p.%s != nil {\n", value)
+		enc.check("WriteVarInt(w, 0)")
+		if err := enc.encodeValue(n.Elem, "*p."+value, nil); err != nil {
+			return "", err
+		}
+		enc.printf("return nil\n}\n")
+		enc.check(fmt.Sprintf("WriteVarInt(w, p.%s+1)", id))
+
+		dec := &emitter{g: g}
+		dec.printf("var id int32\n")
+		dec.assign("id", "ReadVarInt(r)")
+		dec.printf("if id != 0 {\np.%s, p.%s = id-1, nil\nreturn nil\n}\n", id, value)
+		dec.printf("p.%s = new(%s)\n", value, elem)
+		if err := dec.decodeValue(n.Elem, "*p."+value, nil); err != nil {
+			return "", err
+		}
+		writeMethods(&b, name, encode, decode, enc, dec)
+
+	case "registryEntryHolderSet":
+		if climb(n.Elem) >= 0 || climb(n.CountType) >= 0 {
+			return "", fmt.Errorf("registry entry holder set refers outside of itself")
+		}
+		base, ids := holderNames(n.BaseName, "Base"), holderNames(n.OtherName, "IDs")
+		baseType, err := g.goType(n.Elem, name+base)
+		if err != nil {
+			return "", err
+		}
+		idType, err := g.goType(n.CountType, name+ids)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "type %s struct {\n%s *%s\n%s []%s\n}\n\n", name, base, baseType, ids, idType)
+
+		enc := &emitter{g: g}
+		enc.printf("if p.%s != nil {\n", base)
+		enc.check("WriteVarInt(w, 0)")
+		if err := enc.encodeValue(n.Elem, "*p."+base, nil); err != nil {
+			return "", err
+		}
+		enc.printf("return nil\n}\n")
+		enc.check(fmt.Sprintf("WriteVarInt(w, int32(len(p.%s)+1))", ids))
+		enc.printf("for _, id := range p.%s {\n", ids)
+		if err := enc.encodeValue(n.CountType, "id", nil); err != nil {
+			return "", err
+		}
+		enc.printf("}\n")
+
+		dec := &emitter{g: g}
+		dec.printf("var count int32\n")
+		dec.assign("count", "ReadVarInt(r)")
+		dec.printf("p.%s, p.%s = nil, nil\n", base, ids)
+		dec.printf("if count == 0 {\np.%s = new(%s)\n", base, baseType)
+		if err := dec.decodeValue(n.Elem, "*p."+base, nil); err != nil {
+			return "", err
+		}
+		dec.printf("return nil\n}\n")
+		dec.printf("for i := int32(1); i < count; i++ {\nvar id %s\n", idType)
+		if err := dec.decodeValue(n.CountType, "id", nil); err != nil {
+			return "", err
+		}
+		dec.printf("p.%s = append(p.%s, id)\n}\n", ids, ids)
+		writeMethods(&b, name, encode, decode, enc, dec)
+
+	default:
+		return "", fmt.Errorf("named %s", n.Kind)
+	}
+	return b.String(), nil
+}
+
+func writeMethods(b *strings.Builder, name, encode, decode string, enc, dec *emitter) {
+	fmt.Fprintf(b, "func (p *%s) %s(w io.Writer, v Version) error {\n%sreturn nil\n}\n\n", name, encode, enc.buf.String())
+	fmt.Fprintf(b, "func (p *%s) %s(r io.Reader, v Version) error {\n", name, decode)
+	if dec.usesErr {
+		b.WriteString("var err error\n")
+	}
+	fmt.Fprintf(b, "%sreturn nil\n}\n\n", dec.buf.String())
+}
+
+func holderNames(name, fallback string) string {
+	if name == "" {
+		return fallback
+	}
+	return goIdent(name)
+}
+
+func bitNames(n *node) []string {
+	names := make([]string, len(n.Bits))
+	seen := make(map[string]bool)
+	for i, bit := range n.Bits {
+		name := goIdent(bit.Name)
+		for j := 2; seen[name]; j++ {
+			name = fmt.Sprintf("%s%d", goIdent(bit.Name), j)
+		}
+		seen[name] = true
+		names[i] = name
+	}
+	return names
+}
+
+func bitType(bit bitfield) string {
+	size := 8
+	for size < bit.Size {
+		size *= 2
+	}
+	if bit.Signed {
+		return fmt.Sprintf("int%d", size)
+	}
+	return fmt.Sprintf("uint%d", size)
+}
+
+func mask(size int) uint64 {
+	if size >= 64 {
+		return ^uint64(0)
+	}
+	return 1<<size - 1
+}
+
+// dots counts the leading ".." segments of a protodef field path.
+func dots(path string) int {
+	count := 0
+	for _, segment := range strings.Split(path, "/") {
+		if segment != ".." {
+			break
+		}
+		count++
+	}
+	return count
+}
+
+// climb returns how many containers above the one holding n the field
+// paths inside n reach, or -1 if none of them leave n.
+func climb(n *node) int {
+	if n == nil {
+		return -1
+	}
+
+	m := -1
+	switch n.Kind {
+	case "container":
+		return climbInside(n) - 1
+	case "switch":
+		if n.CompareValue == nil {
+			m = dots(n.Compare)
+		}
+		for _, c := range n.Cases {
+			m = max(m, climb(c.Node))
+		}
+		m = max(m, climb(n.Default))
+	default:
+		if n.CountRef != "" {
+			m = dots(n.CountRef)
+		}
+		m = max(m, climb(n.Elem), climb(n.CountType))
+	}
+	return m
+}
+
+// climbInside is climb for the fields of container n. A container whose
+// fields only refer to each other has a climbInside of at most 0 and gets
+// its own codec methods.
+func climbInside(n *node) int {
+	m := -1
+	for _, f := range n.Fields {
+		m = max(m, climb(f.Node))
+	}
+	return m
+}
+
+var identInitialisms = regexp.MustCompile(`(Id|Uuid|Url|Json)(s?)([A-Z0-9]|$)`)
+
+// goIdent turns a protodef name like "entity_id" or "entityId" into the
+// exported Go identifier "EntityID".
+func goIdent(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z':
+			if upper {
+				r -= 'a' - 'A'
+			}
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		default:
+			upper = true
+			continue
+		}
+		b.WriteRune(r)
+		upper = false
+	}
+
+	ident := identInitialisms.ReplaceAllStringFunc(b.String(), func(s string) string {
+		m := identInitialisms.FindStringSubmatch(s)
+		return strings.ToUpper(m[1]) + m[2] + m[3]
+	})
+	if ident == "" || ident[0] >= '0' && ident[0] <= '9' {
+		ident = "F" + ident
+	}
+	return ident
+}
+
+// packetTypeNames maps packet names to the types of their params, read
+// from the switch of the "packet" type of a namespace.
+func packetTypeNames(ns map[string]any) map[string]string {
+	names := make(map[string]string)
+	packet, ok := ns["packet"].([]any)
+	if !ok || len(packet) < 2 {
+		return names
+	}
+	fields, _ := packet[1].([]any)
+
+	for _, f := range fields {
+		f, _ := f.(map[string]any)
+		if f["name"] != "params" {
+			continue
+		}
+		sw, _ := f["type"].([]any)
+		if len(sw) < 2 || sw[0] != "switch" {
+			continue
+		}
+		opts, _ := sw[1].(map[string]any)
+		cases, _ := opts["fields"].(map[string]any)
+		for mcName, typeName := range cases {
+			if s, ok := typeName.(string); ok {
+				names[mcName] = s
+			}
+		}
+	}
+	return names
+}
+
+// reservedIdents returns the identifiers declared by the protocol package,
+// leaving out the file generatePackets writes.
+func reservedIdents(dir, generated string) map[string]bool {
+	fset := token.NewFileSet()
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		log.Fatalf("FATAL: Failed to list %s: %v", dir, err)
+	}
+
+	idents := make(map[string]bool)
+	for _, path := range paths {
+		if filepath.Base(path) == filepath.Base(generated) {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			log.Fatalf("FATAL: Failed to parse %s: %v", path, err)
+		}
+
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Recv == nil {
+					idents[decl.Name.Name] = true
+				}
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						idents[spec.Name.Name] = true
+					case *ast.ValueSpec:
+						for _, name := range spec.Names {
+							idents[name.Name] = true
+						}
+					}
+				}
+			}
+		}
+	}
+	return idents
+}
+
+func writePackets(path string, types []*goType) {
+	src, err := packetsSource(types)
+	if err != nil {
+		log.Fatalf("FATAL: Failed to format generated packets code: %v", err)
+	}
+	if err := os.WriteFile(path, src, 0644); err != nil {
+		log.Fatalf("FATAL: Failed to write packets output file: %v", err)
+	}
+}
+
+// packetsSource returns the formatted source of the generated packets file.
+func packetsSource(types []*goType) ([]byte, error) {
+	var body strings.Builder
+	for _, t := range types {
+		body.WriteString(t.Source)
+	}
+
+	body.WriteString("func init() {\n")
+	for _, t := range types {
+		if t.Packet {
+			fmt.Fprintf(&body, "registerPacket(%q, func() Packet { return &%s{} })\n", t.Name, t.Name)
+		}
+	}
+	body.WriteString("}\n")
+
+	src := body.String()
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by gophermc/generator. DO NOT EDIT.\n// See generator/main.go for more details.\npackage protocol\n\nimport (\n")
+	for _, imp := range []struct{ pkg, use string }{
+		{"bytes", "bytes."},
+		{"encoding/binary", "binary."},
+		{"fmt", "fmt."},
+		{"io", "io."},
+		{"github.com/google/uuid", "uuid."},
+		{"github.com/obeliskdev/gophermc/nbt", "nbt."},
+	} {
+		if strings.Contains(src, imp.use) {
+			fmt.Fprintf(&buf, "%q\n", imp.pkg)
+		}
+	}
+	buf.WriteString(")\n\n")
+	buf.WriteString(src)
+
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%w\n---\n%s", err, buf.String())
+	}
+	return formatted, nil
+}
+
+// scope is a container being encoded or decoded, for resolving field
+// paths. expr is the Go expression of its value.
+type scope struct {
+	expr     string
+	typeName string
+	node     *node
+	parent   *scope
+}
+
+// emitter writes the statements of one codec function.
+type emitter struct {
+	g       *packetGenerator
+	buf     strings.Builder
+	tmp     int
+	usesErr bool
+}
+
+func (e *emitter) printf(format string, args ...any) {
+	fmt.Fprintf(&e.buf, format, args...)
+}
+
+func (e *emitter) temp(prefix string) string {
+	e.tmp++
+	return fmt.Sprintf("%s%d", prefix, e.tmp)
+}
+
+func (e *emitter) check(call string) {
+	e.printf("if err := %s; err != nil {\nreturn err\n}\n", call)
+}
+
+func (e *emitter) assign(target, call string) {
+	e.usesErr = true
+	e.printf("if %s, err = %s; err != nil {\nreturn err\n}\n", target, call)
+}
+
+// paren wraps a dereference so that it can be selected from.
+func paren(expr string) string {
+	if strings.HasPrefix(expr, "*") {
+		return "(" + expr + ")"
+	}
+	return expr
+}
+
+// addr returns the address of expr, without taking the address of a
+// dereference.
+func addr(expr string) string {
+	if strings.HasPrefix(expr, "*") {
+		return expr[1:]
+	}
+	return "&" + expr
+}
+
+// method returns the receiver to call a pointer method of expr on.
+func method(expr string) string {
+	return paren(strings.TrimPrefix(expr, "*"))
+}
+
+// resolvePath resolves a protodef field path like "../flags/type" to a Go
+// expression and its type.
+func (e *emitter) resolvePath(path string, sc *scope) (string, *node, error) {
+	segments := strings.Split(path, "/")
+	for len(segments) > 0 && segments[0] == ".." {
+		segments = segments[1:]
+		if sc == nil || sc.parent == nil {
+			return "", nil, fmt.Errorf("path %s leaves the packet", path)
+		}
+		sc = sc.parent
+	}
+	if sc == nil {
+		return "", nil, fmt.Errorf("path %s outside of a container", path)
+	}
+
+	expr, cur := sc.expr, sc.node
+	typeName := sc.typeName
+	for _, segment := range segments {
+		switch cur.Kind {
+		case "container":
+			var found *field
+			for _, f := range cur.Fields {
+				if f.Name == segment {
+					found = f
+				}
+			}
+			if found == nil {
+				return "", nil, fmt.Errorf("path %s not found", path)
+			}
+			if _, err := e.g.containerFields(cur, typeName); err != nil {
+				return "", nil, err
+			}
+			fields := e.g.fieldsOf(cur, found)
+			if len(fields) != 1 || fields[0].Ptr || fields[0].Case != "" {
+				return "", nil, fmt.Errorf("path %s goes through a switch", path)
+			}
+			expr = paren(expr) + "." + fields[0].Name
+			typeName += fields[0].Name
+			cur = found.Node
+			if cur.Kind == "switch" {
+				return "", nil, fmt.Errorf("path %s goes through a switch", path)
+			}
+		case "bitfield":
+			names := bitNames(cur)
+			var found string
+			for i, bit := range cur.Bits {
+				if bit.Name == segment {
+					found = names[i]
+				}
+			}
+			if found == "" {
+				return "", nil, fmt.Errorf("path %s not found", path)
+			}
+			expr = paren(expr) + "." + found
+			cur = &node{Kind: "native", Native: "bits"}
+		default:
+			return "", nil, fmt.Errorf("path %s goes through a %s", path, cur.Kind)
+		}
+	}
+	return expr, cur, nil
+}
+
+// caseLiteral returns the Go literal of a switch case compared to a value
+// of type n.
+func caseLiteral(n *node, key string) (string, error) {
+	if n.Kind != "native" {
+		return "", fmt.Errorf("switch on a %s", n.Kind)
+	}
+	values := make([]string, 0, len(n.Mappings))
+	for value, name := range n.Mappings {
+		if name == key {
+			values = append(values, value)
+		}
+	}
+	if len(values) > 0 {
+		sort.Slice(values, func(i, j int) bool { return lessKey(values[i], values[j]) })
+		key = values[0]
+	}
+
+	switch n.Native {
+	case "string":
+		return strconv.Quote(key), nil
+	case "bool":
+		if key != "true" && key != "false" {
+			return "", fmt.Errorf("switch case %s on a bool", key)
+		}
+		return key, nil
+	case "f32", "f64":
+		if _, err := strconv.ParseFloat(key, 64); err != nil {
+			return "", fmt.Errorf("switch case %s on a float", key)
+		}
+		return key, nil
+	}
+
+	value, err := strconv.ParseInt(key, 0, 64)
+	if err != nil {
+		return "", fmt.Errorf("switch case %s is not a number", key)
+	}
+	return strconv.FormatInt(value, 10), nil
+}
+
+func (e *emitter) encodeFields(n *node, typeName, expr string, parent *scope) error {
+	sc := &scope{expr: expr, typeName: typeName, node: n, parent: parent}
+	if _, err := e.g.containerFields(n, typeName); err != nil {
+		return err
+	}
+
+	// Fields counting a sibling array are written from its length.
+	lengths := make(map[string]string)
+	for _, f := range n.Fields {
+		if f.Node.CountRef == "" || strings.Contains(f.Node.CountRef, "/") {
+			continue
+		}
+		if fields := e.g.fieldsOf(n, f); len(fields) == 1 {
+			if _, ok := lengths[f.Node.CountRef]; !ok {
+				lengths[f.Node.CountRef] = "len(" + paren(expr) + "." + fields[0].Name + ")"
+			}
+		}
+	}
+
+	for _, f := range n.Fields {
+		fields := e.g.fieldsOf(n, f)
+		if len(fields) == 0 {
+			continue
+		}
+		fn := f.Node
+		if fn.Kind == "switch" && fn.CompareValue != nil {
+			fn = staticCase(fn)
+		}
+		if fn.Kind == "switch" {
+			if err := e.encodeSwitch(fn, fields, sc); err != nil {
+				return err
+			}
+			continue
+		}
+
+		value := paren(expr) + "." + fields[0].Name
+		if length, ok := lengths[f.Name]; ok && fn.Kind == "native" {
+			if t, ok := nativeGoTypes[fn.Native]; ok && t != "bool" && t != "string" {
+				value = t + "(" + length + ")"
+			}
+		}
+		if err := e.encodeValue(fn, value, sc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *emitter) decodeFields(n *node, typeName, expr string, parent *scope) error {
+	sc := &scope{expr: expr, typeName: typeName, node: n, parent: parent}
+	if _, err := e.g.containerFields(n, typeName); err != nil {
+		return err
+	}
+
+	for _, f := range n.Fields {
+		fields := e.g.fieldsOf(n, f)
+		if len(fields) == 0 {
+			continue
+		}
+		fn := f.Node
+		if fn.Kind == "switch" && fn.CompareValue != nil {
+			fn = staticCase(fn)
+		}
+		if fn.Kind == "switch" {
+			if err := e.decodeSwitch(fn, fields, sc); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := e.decodeValue(fn, paren(expr)+"."+fields[0].Name, sc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// switchCase is a group of switch cases sharing a body.
+type switchCase struct {
+	literals []string
+	node     *node
+	field    goField
+	isVoid   bool
+}
+
+func (e *emitter) switchCases(n *node, fields []goField, sc *scope) (string, []switchCase, *switchCase, error) {
+	compare, target, err := e.resolvePath(n.Compare, sc)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	// literals returns the literal of each case key, or "" for a key
+	// sharing its literal with an earlier case.
+	literals := make([]string, len(n.Cases))
+	used := make(map[string]bool)
+	for i, c := range n.Cases {
+		l, err := caseLiteral(target, c.Key)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		if !used[l] {
+			used[l] = true
+			literals[i] = l
+		}
+	}
+
+	var cases []switchCase
+	var def *switchCase
+	if len(fields) == 1 && fields[0].Case == "" && !fields[0].Default {
+		body := switchCase{field: fields[0]}
+		var void switchCase
+		void.isVoid = true
+		for i, c := range n.Cases {
+			l := literals[i]
+			if l == "" {
+				continue
+			}
+			if c.Node.isVoid() {
+				void.literals = append(void.literals, l)
+			} else {
+				body.literals = append(body.literals, l)
+				body.node = c.Node
+			}
+		}
+		if len(body.literals) > 0 {
+			cases = append(cases, body)
+		}
+		if !n.Default.isVoid() {
+			if len(void.literals) > 0 {
+				cases = append(cases, void)
+			}
+			def = &switchCase{node: n.Default, field: fields[0]}
+		}
+		return compare, cases, def, nil
+	}
+
+	byCase := make(map[string]goField)
+	for _, f := range fields {
+		if f.Default {
+			def = &switchCase{node: n.Default, field: f}
+		} else {
+			byCase[f.Case] = f
+		}
+	}
+	for i, c := range n.Cases {
+		l := literals[i]
+		if l == "" {
+			continue
+		}
+		if c.Node.isVoid() {
+			if def != nil {
+				cases = append(cases, switchCase{literals: []string{l}, isVoid: true})
+			}
+			continue
+		}
+		cases = append(cases, switchCase{literals: []string{l}, node: c.Node, field: byCase[c.Key]})
+	}
+	return compare, cases, def, nil
+}
+
+// writeSwitch writes a switch over compare, with body writing the body of
+// each case.
+func (e *emitter) writeSwitch(compare string, cases []switchCase, def *switchCase, body func(c *switchCase) error) error {
+	e.printf("switch %s {\n", compare)
+	for i := range cases {
+		e.printf("case %s:\n", strings.Join(cases[i].literals, ", "))
+		if cases[i].isVoid {
+			continue
+		}
+		if err := body(&cases[i]); err != nil {
+			return err
+		}
+	}
+	if def != nil {
+		e.printf("default:\n")
+		if err := body(def); err != nil {
+			return err
+		}
+	}
+	e.printf("}\n")
+	return nil
+}
+
+func (e *emitter) encodeSwitch(n *node, fields []goField, sc *scope) error {
+	compare, cases, def, err := e.switchCases(n, fields, sc)
+	if err != nil || len(cases) == 0 && def == nil {
+		return err
+	}
+
+	return e.writeSwitch(compare, cases, def, func(c *switchCase) error {
+		value := paren(sc.expr) + "." + c.field.Name
+		if c.field.Ptr {
+			e.printf("if %s == nil {\nreturn fmt.Errorf(\"%s: missing %s\")\n}\n", value, sc.typeName, c.field.Name)
+			value = "*" + value
+		}
+		return e.encodeValue(c.node, value, sc)
+	})
+}
+
+func (e *emitter) decodeSwitch(n *node, fields []goField, sc *scope) error {
+	compare, cases, def, err := e.switchCases(n, fields, sc)
+	if err != nil || len(cases) == 0 && def == nil {
+		return err
+	}
+
+	// Fields of cases not taken are reset.
+	for _, f := range fields {
+		target := paren(sc.expr) + "." + f.Name
+		if nilable(f.Type) {
+			e.printf("%s = nil\n", target)
+		}
+	}
+
+	return e.writeSwitch(compare, cases, def, func(c *switchCase) error {
+		target := paren(sc.expr) + "." + c.field.Name
+		if c.field.Ptr {
+			e.printf("%s = new(%s)\n", target, strings.TrimPrefix(c.field.Type, "*"))
+			target = "*" + target
+		}
+		return e.decodeValue(c.node, target, sc)
+	})
+}
+
+func (e *emitter) encodeCount(n *node, length string) error {
+	if n.CountType != nil {
+		return e.encodeValue(n.CountType, nativeGoTypes[n.CountType.Native]+"("+length+")", nil)
+	}
+	if n.CountRef == "" {
+		e.printf("if %s != %d {\nreturn fmt.Errorf(\"expected %d elements, got %%d\", %s)\n}\n", length, n.Count, n.Count, length)
+	}
+	return nil
+}
+
+func (e *emitter) decodeCount(n *node, sc *scope) (string, error) {
+	switch {
+	case n.CountType != nil:
+		count := e.temp("n")
+		e.printf("var %s %s\n", count, nativeGoTypes[n.CountType.Native])
+		if err := e.decodeValue(n.CountType, count, nil); err != nil {
+			return "", err
+		}
+		return "int(" + count + ")", nil
+	case n.CountRef != "":
+		count, target, err := e.resolvePath(n.CountRef, sc)
+		if err != nil {
+			return "", err
+		}
+		if target.Kind != "native" || target.Native == "bool" || target.Native == "string" {
+			return "", fmt.Errorf("count %s is a %s", n.CountRef, target.Native)
+		}
+		return "int(" + count + ")", nil
+	default:
+		return strconv.Itoa(n.Count), nil
+	}
+}
+
+func (e *emitter) encodeValue(n *node, value string, sc *scope) error {
+	switch n.Kind {
+	case "native":
+		return e.encodeNative(n, value)
+
+	case "option":
+		e.check("WriteBool(w, " + value + " != nil)")
+		e.printf("if %s != nil {\n", value)
+		if err := e.encodeValue(n.Elem, "*"+value, sc); err != nil {
+			return err
+		}
+		e.printf("}\n")
+
+	case "array":
+		if err := e.encodeCount(n, "len("+value+")"); err != nil {
+			return err
+		}
+		elem := e.temp("e")
+		e.printf("for _, %s := range %s {\n", elem, value)
+		if err := e.encodeValue(n.Elem, elem, sc); err != nil {
+			return err
+		}
+		e.printf("}\n")
+
+	case "container":
+		if climbInside(n) > 0 {
+			typeName, err := e.g.goType(n, "")
+			if err != nil {
+				return err
+			}
+			return e.encodeFields(n, typeName, value, sc)
+		}
+		e.check(method(value) + ".encode(w, v)")
+
+	case "bitfield", "registryEntryHolder", "registryEntryHolderSet":
+		e.check(method(value) + ".encode(w, v)")
+
+	case "entityMetadataLoop":
+		elem := e.temp("e")
+		e.printf("for _, %s := range %s {\n", elem, value)
+		if err := e.encodeValue(n.Elem, elem, sc); err != nil {
+			return err
+		}
+		e.printf("}\n")
+		e.check(fmt.Sprintf("WriteByte(w, %#x)", n.EndVal))
+
+	case "topBitSetTerminatedArray":
+		i, elem, buf := e.temp("i"), e.temp("e"), e.temp("buf")
+		e.printf("for %s, %s := range %s {\nvar %s bytes.Buffer\n", i, elem, value, buf)
+		sub := &emitter{g: e.g, tmp: e.tmp}
+		if err := sub.encodeValue(n.Elem, elem, sc); err != nil {
+			return err
+		}
+		e.tmp = sub.tmp
+		e.check(fmt.Sprintf("func(w io.Writer) error {\n%sreturn nil\n}(&%s)", sub.buf.String(), buf))
+		e.printf("if %s.Len() == 0 {\nreturn fmt.Errorf(\"empty element in %%s\", %q)\n}\n", buf, value)
+		e.printf("if %s < len(%s)-1 {\n%s.Bytes()[0] |= 0x80\n}\n", i, value, buf)
+		e.printf("if _, err := w.Write(%s.Bytes()); err != nil {\nreturn err\n}\n}\n", buf)
+
+	default:
+		return fmt.Errorf("cannot encode %s", n.Kind)
+	}
+	return nil
+}
+
+func (e *emitter) encodeNative(n *node, value string) error {
+	switch n.Native {
+	case "varint":
+		e.check("WriteVarInt(w, " + value + ")")
+	case "varlong":
+		e.check("WriteVarLong(w, " + value + ")")
+	case "bool":
+		e.check("WriteBool(w, " + value + ")")
+	case "UUID":
+		e.check("WriteUUID(w, " + value + ")")
+	case "position":
+		e.check("WritePosition(w, v, " + value + ")")
+	case "nbt", "optionalNbt", "anonymousNbt", "anonOptionalNbt":
+		e.check("WriteNBT(w, v, " + value + ")")
+	case "restBuffer":
+		e.printf("if _, err := w.Write(%s); err != nil {\nreturn err\n}\n", value)
+	case "string", "buffer":
+		if n.CountType != nil && n.CountType.Native == "varint" {
+			if n.Native == "string" {
+				e.check("WriteString(w, " + value + ")")
+			} else {
+				e.check("WriteByteSlice(w, " + value + ")")
+			}
+			return nil
+		}
+		if err := e.encodeCount(n, "len("+value+")"); err != nil {
+			return err
+		}
+		if n.Native == "string" {
+			value = "[]byte(" + value + ")"
+		}
+		e.printf("if _, err := w.Write(%s); err != nil {\nreturn err\n}\n", value)
+	case "void":
+	default:
+		if _, ok := nativeGoTypes[n.Native]; !ok {
+			return fmt.Errorf("cannot encode %s", n.Native)
+		}
+		e.check("binary.Write(w, binary.BigEndian, " + value + ")")
+	}
+	return nil
+}
+
+func (e *emitter) decodeValue(n *node, target string, sc *scope) error {
+	switch n.Kind {
+	case "native":
+		return e.decodeNative(n, target, sc)
+
+	case "option":
+		elemType, err := e.g.goType(n.Elem, "")
+		if err != nil {
+			return err
+		}
+		present := e.temp("present")
+		e.printf("var %s bool\n", present)
+		e.assign(present, "ReadBool(r)")
+		e.printf("%s = nil\nif %s {\n%s = new(%s)\n", target, present, target, elemType)
+		if err := e.decodeValue(n.Elem, "*"+target, sc); err != nil {
+			return err
+		}
+		e.printf("}\n")
+
+	case "array":
+		count, err := e.decodeCount(n, sc)
+		if err != nil {
+			return err
+		}
+		elemType, err := e.g.goType(n.Elem, "")
+		if err != nil {
+			return err
+		}
+		i, elem := e.temp("i"), e.temp("e")
+		e.printf("%s = nil\nfor %s := 0; %s < %s; %s++ {\nvar %s %s\n", target, i, i, count, i, elem, elemType)
+		if err := e.decodeValue(n.Elem, elem, sc); err != nil {
+			return err
+		}
+		e.printf("%s = append(%s, %s)\n}\n", target, target, elem)
+
+	case "container":
+		if climbInside(n) > 0 {
+			typeName, err := e.g.goType(n, "")
+			if err != nil {
+				return err
+			}
+			return e.decodeFields(n, typeName, target, sc)
+		}
+		e.usesErr = true
+		e.printf("if err = %s.decode(r, v); err != nil {\nreturn err\n}\n", method(target))
+
+	case "bitfield", "registryEntryHolder", "registryEntryHolderSet":
+		e.usesErr = true
+		e.printf("if err = %s.decode(r, v); err != nil {\nreturn err\n}\n", method(target))
+
+	case "entityMetadataLoop", "topBitSetTerminatedArray":
+		elemType, err := e.g.goType(n.Elem, "")
+		if err != nil {
+			return err
+		}
+		b, elem := e.temp("b"), e.temp("e")
+		e.printf("%s = nil\nfor {\nvar %s byte\n", target, b)
+		e.assign(b, "ReadByte(r)")
+		first := b + " & 0x7f"
+		if n.Kind == "entityMetadataLoop" {
+			first = b
+			e.printf("if %s == %#x {\nbreak\n}\n", b, n.EndVal)
+		}
+		e.printf("var %s %s\n", elem, elemType)
+
+		// The byte read ahead is the first byte of the element.
+		sub := &emitter{g: e.g, tmp: e.tmp}
+		if err := sub.decodeValue(n.Elem, elem, sc); err != nil {
+			return err
+		}
+		e.tmp = sub.tmp
+		e.printf("if err := func(r io.Reader) error {\n")
+		if sub.usesErr {
+			e.printf("var err error\n")
+		}
+		e.printf("%sreturn nil\n}(io.MultiReader(bytes.NewReader([]byte{%s}), r)); err != nil {\nreturn err\n}\n", sub.buf.String(), first)
+
+		e.printf("%s = append(%s, %s)\n", target, target, elem)
+		if n.Kind == "topBitSetTerminatedArray" {
+			e.printf("if %s&0x80 == 0 {\nbreak\n}\n", b)
+		}
+		e.printf("}\n")
+
+	default:
+		return fmt.Errorf("cannot decode %s", n.Kind)
+	}
+	return nil
+}
+
+func (e *emitter) decodeNative(n *node, target string, sc *scope) error {
+	switch n.Native {
+	case "varint":
+		e.assign(target, "ReadVarInt(r)")
+	case "varlong":
+		e.assign(target, "ReadVarLong(r)")
+	case "bool":
+		e.assign(target, "ReadBool(r)")
+	case "UUID":
+		e.assign(target, "ReadUUID(r)")
+	case "position":
+		e.assign(target, "ReadPosition(r, v)")
+	case "nbt", "optionalNbt", "anonymousNbt", "anonOptionalNbt":
+		e.assign(target, "ReadNBT(r, v)")
+	case "restBuffer":
+		e.assign(target, "io.ReadAll(r)")
+	case "string", "buffer":
+		if n.CountType != nil && n.CountType.Native == "varint" {
+			if n.Native == "string" {
+				e.assign(target, "ReadString(r)")
+			} else {
+				e.assign(target, "ReadBytes(r)")
+			}
+			return nil
+		}
+		count, err := e.decodeCount(n, sc)
+		if err != nil {
+			return err
+		}
+		data := e.temp("data")
+		e.printf("var %s []byte\n", data)
+		e.assign(data, fmt.Sprintf("io.ReadAll(io.LimitReader(r, int64(%s)))", count))
+		e.printf("if len(%s) != %s {\nreturn io.ErrUnexpectedEOF\n}\n", data, count)
+		if n.Native == "string" {
+			e.printf("%s = string(%s)\n", target, data)
+		} else {
+			e.printf("%s = %s\n", target, data)
+		}
+	case "void":
+	default:
+		if _, ok := nativeGoTypes[n.Native]; !ok {
+			return fmt.Errorf("cannot decode %s", n.Native)
+		}
+		e.usesErr = true
+		e.printf("if err = binary.Read(r, binary.BigEndian, %s); err != nil {\nreturn err\n}\n", addr(target))
+	}
+	return nil
+}
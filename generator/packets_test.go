@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func TestGeneratePackets(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "protocol.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	vi, err := parseProtocol("1.21.4", 769, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions := []versionInfo{vi}
+
+	reserved := map[string]bool{
+		"ClientboundKeepAlive": true,
+		"ServerboundKeepAlive": true,
+		"ClientboundEffect":    true,
+	}
+	src, err := packetsSource(generatePackets(versions, reserved))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden := filepath.Join("testdata", "generated_packets.golden")
+	if *update {
+		if err := os.WriteFile(golden, src, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(src, want) {
+		t.Errorf("generated packets differ from %s, rerun with -update if the change is intended:\n%s", golden, src)
+	}
+
+	// The broken packet is skipped along with the Vec3 type it declared.
+	if bytes.Contains(src, []byte("Vec3")) {
+		t.Error("types of the skipped packet were generated")
+	}
+
+	for dir, want := range map[string]map[string]int32{
+		"DirectionClientbound": {
+			"ClientboundKeepAlive":    0x00,
+			"ClientboundEffectPacket": 0x01,
+			"ClientboundEntityMove":   0x02,
+		},
+		"DirectionServerbound": {
+			"ServerboundKeepAlive":   0x00,
+			"ServerboundTabComplete": 0x01,
+		},
+	} {
+		if got := versions[0].PacketIDs["StatePlay"][dir]; !maps.Equal(got, want) {
+			t.Errorf("%s: expected packet ids %v, got %v", dir, want, got)
+		}
+	}
+}
+
+const goldenRoundTripTest = `package protocol
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestGeneratedGoldenRoundTrip(t *testing.T) {
+	want := &ClientboundEntityMove{
+		EntityID: 7,
+		Flags:    ClientboundEntityMoveFlags{OnGround: 1, Unused: 0x55},
+		Deltas:   []int16{-1, 2, 300},
+		Time:     1 << 40,
+	}
+
+	var buf bytes.Buffer
+	if err := want.Encode(&buf, V1_21_4); err != nil {
+		t.Fatal(err)
+	}
+	got := &ClientboundEntityMove{}
+	if err := got.Decode(&buf, V1_21_4); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) || buf.Len() != 0 {
+		t.Fatalf("expected %+v, got %+v with %d bytes left", want, got, buf.Len())
+	}
+}
+`
+
+// TestGeneratedPacketsCompile builds the golden file into package protocol,
+// through an overlay so the source tree is left alone, and round-trips one
+// of its packets.
+func TestGeneratedPacketsCompile(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the protocol package")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found")
+	}
+
+	protocolDir, err := filepath.Abs(filepath.Join("..", "protocol"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden, err := filepath.Abs(filepath.Join("testdata", "generated_packets.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	roundTrip := filepath.Join(dir, "golden_test.go")
+	if err := os.WriteFile(roundTrip, []byte(goldenRoundTripTest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	overlay, err := json.Marshal(map[string]map[string]string{"Replace": {
+		filepath.Join(protocolDir, "zz_generated_packets_golden.go"):      golden,
+		filepath.Join(protocolDir, "zz_generated_packets_golden_test.go"): roundTrip,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	overlayFile := filepath.Join(dir, "overlay.json")
+	if err := os.WriteFile(overlayFile, overlay, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(goTool, "test", "-overlay", overlayFile, "-run", "^TestGeneratedGoldenRoundTrip$", "-count", "1", ".")
+	cmd.Dir = protocolDir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("golden packets do not build in package protocol: %v\n%s", err, out)
+	}
+}
@@ -0,0 +1,432 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// node is a protodef type with every reference to a named type resolved.
+// Its JSON form, without Name, identifies the wire layout: two nodes with
+// the same signature encode the same way.
+type node struct {
+	Kind   string `json:"k"`
+	Native string `json:"n,omitempty"`
+	// Name is the named type the node was resolved from, used to name the
+	// Go type generated for it.
+	Name string `json:"-"`
+
+	Elem      *node  `json:"e,omitempty"`
+	CountType *node  `json:"ct,omitempty"`
+	Count     int    `json:"c,omitempty"`
+	CountRef  string `json:"cr,omitempty"`
+
+	Fields []*field    `json:"f,omitempty"`
+	Bits   []bitfield  `json:"b,omitempty"`
+	Cases  []*caseNode `json:"cs,omitempty"`
+
+	Compare      string  `json:"cmp,omitempty"`
+	CompareValue *string `json:"cv,omitempty"`
+	Default      *node   `json:"d,omitempty"`
+
+	Mappings map[string]string `json:"m,omitempty"`
+	EndVal   int               `json:"end,omitempty"`
+
+	// BaseName and OtherName name the two halves of registry entry holders.
+	BaseName  string `json:"bn,omitempty"`
+	OtherName string `json:"on,omitempty"`
+}
+
+type field struct {
+	Name string `json:"n"`
+	Anon bool   `json:"a,omitempty"`
+	Node *node  `json:"t"`
+}
+
+type bitfield struct {
+	Name   string `json:"n"`
+	Size   int    `json:"s"`
+	Signed bool   `json:"sg,omitempty"`
+}
+
+type caseNode struct {
+	Key  string `json:"k"`
+	Node *node  `json:"t"`
+}
+
+func (n *node) signature() string {
+	// A node only holds strings, numbers and nodes, which always marshal.
+	data, _ := json.Marshal(n)
+	return string(data)
+}
+
+func (n *node) isVoid() bool {
+	return n == nil || n.Kind == "native" && n.Native == "void"
+}
+
+// nativeGoTypes are the Go types of the native protodef types.
+var nativeGoTypes = map[string]string{
+	"u8": "uint8", "u16": "uint16", "u32": "uint32", "u64": "uint64",
+	"i8": "int8", "i16": "int16", "i32": "int32", "i64": "int64",
+	"f32": "float32", "f64": "float64",
+	"varint": "int32", "varlong": "int64", "bool": "bool",
+	"UUID": "uuid.UUID", "string": "string",
+	"nbt": "nbt.Tag", "optionalNbt": "nbt.Tag", "anonymousNbt": "nbt.Tag", "anonOptionalNbt": "nbt.Tag",
+	"position": "BlockPosition", "restBuffer": "[]byte",
+}
+
+// resolver resolves the types of one namespace of a protocol.json, looking
+// up names in the namespace before the global types.
+type resolver struct {
+	global map[string]any
+	local  map[string]any
+	stack  []string
+}
+
+func (rs *resolver) lookup(name string) (any, bool) {
+	if def, ok := rs.local[name]; ok {
+		return def, true
+	}
+	def, ok := rs.global[name]
+	return def, ok
+}
+
+func (rs *resolver) resolve(t any) (*node, error) {
+	switch t := t.(type) {
+	case string:
+		return rs.resolveNamed(t, nil)
+	case []any:
+		if len(t) != 2 {
+			return nil, fmt.Errorf("malformed type %v", t)
+		}
+		name, ok := t[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("malformed type %v", t)
+		}
+		opts, _ := t[1].(map[string]any)
+		if opts == nil {
+			// Some types take a list of options, like bitfield.
+			return rs.resolveNamed(name, t[1])
+		}
+		return rs.resolveNamed(name, opts)
+	default:
+		return nil, fmt.Errorf("malformed type %v", t)
+	}
+}
+
+func (rs *resolver) resolveNamed(name string, opts any) (*node, error) {
+	// The position bitfield is read through BlockPosition, whose layout
+	// already follows the version.
+	if name == "position" {
+		return &node{Kind: "native", Native: "position"}, nil
+	}
+
+	def, ok := rs.lookup(name)
+	if !ok || def == "native" {
+		return rs.resolveNative(name, opts)
+	}
+
+	for _, seen := range rs.stack {
+		if seen == name {
+			return nil, fmt.Errorf("recursive type %s", name)
+		}
+	}
+	rs.stack = append(rs.stack, name)
+	defer func() { rs.stack = rs.stack[:len(rs.stack)-1] }()
+
+	if params, ok := opts.(map[string]any); ok {
+		def = substitute(def, params)
+	}
+
+	n, err := rs.resolve(def)
+	if err != nil {
+		return nil, err
+	}
+	if n.Name == "" && (n.Kind == "container" || n.Kind == "bitfield") {
+		n.Name = name
+	}
+	return n, nil
+}
+
+// substitute replaces the "$param" references of a parametrized type.
+func substitute(def any, params map[string]any) any {
+	switch def := def.(type) {
+	case string:
+		if strings.HasPrefix(def, "$") {
+			if value, ok := params[def[1:]]; ok {
+				return value
+			}
+		}
+		return def
+	case []any:
+		out := make([]any, len(def))
+		for i, v := range def {
+			out[i] = substitute(v, params)
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(def))
+		for k, v := range def {
+			out[k] = substitute(v, params)
+		}
+		return out
+	default:
+		return def
+	}
+}
+
+func (rs *resolver) resolveNative(name string, opts any) (*node, error) {
+	o, _ := opts.(map[string]any)
+
+	switch name {
+	case "optvarint":
+		return &node{Kind: "native", Native: "varint"}, nil
+
+	case "void", "varint", "varlong", "bool", "UUID", "restBuffer",
+		"nbt", "optionalNbt", "anonymousNbt", "anonOptionalNbt",
+		"u8", "u16", "u32", "u64", "i8", "i16", "i32", "i64", "f32", "f64":
+		return &node{Kind: "native", Native: name}, nil
+
+	case "string", "pstring":
+		n := &node{Kind: "native", Native: "string"}
+		if err := rs.readCount(n, o); err != nil {
+			return nil, err
+		}
+		return n, nil
+
+	case "buffer":
+		n := &node{Kind: "native", Native: "buffer"}
+		if err := rs.readCount(n, o); err != nil {
+			return nil, err
+		}
+		return n, nil
+
+	case "option":
+		elem, err := rs.resolve(opts)
+		if err != nil {
+			return nil, err
+		}
+		return &node{Kind: "option", Elem: elem}, nil
+
+	case "array":
+		elem, err := rs.resolve(o["type"])
+		if err != nil {
+			return nil, err
+		}
+		n := &node{Kind: "array", Elem: elem}
+		if err := rs.readCount(n, o); err != nil {
+			return nil, err
+		}
+		return n, nil
+
+	case "entityMetadataLoop":
+		elem, err := rs.resolve(o["type"])
+		if err != nil {
+			return nil, err
+		}
+		end, _ := o["endVal"].(float64)
+		return &node{Kind: "entityMetadataLoop", Elem: elem, EndVal: int(end)}, nil
+
+	case "topBitSetTerminatedArray":
+		elem, err := rs.resolve(o["type"])
+		if err != nil {
+			return nil, err
+		}
+		return &node{Kind: "topBitSetTerminatedArray", Elem: elem}, nil
+
+	case "container":
+		return rs.resolveContainer(opts)
+
+	case "bitfield":
+		return rs.resolveBitfield(opts)
+
+	case "switch":
+		return rs.resolveSwitch(o)
+
+	case "mapper":
+		n, err := rs.resolve(o["type"])
+		if err != nil {
+			return nil, err
+		}
+		if n.Kind != "native" {
+			return nil, fmt.Errorf("mapper over %s", n.Kind)
+		}
+		mappings, _ := o["mappings"].(map[string]any)
+		n.Mappings = make(map[string]string, len(mappings))
+		for value, name := range mappings {
+			n.Mappings[value], _ = name.(string)
+		}
+		return n, nil
+
+	case "bitflags":
+		n, err := rs.resolve(o["type"])
+		if err != nil {
+			return nil, err
+		}
+		if n.Kind != "native" {
+			return nil, fmt.Errorf("bitflags over %s", n.Kind)
+		}
+		return n, nil
+
+	case "registryEntryHolder":
+		otherwise, _ := o["otherwise"].(map[string]any)
+		elem, err := rs.resolve(otherwise["type"])
+		if err != nil {
+			return nil, err
+		}
+		base, _ := o["baseName"].(string)
+		other, _ := otherwise["name"].(string)
+		return &node{Kind: "registryEntryHolder", Elem: elem, BaseName: base, OtherName: other}, nil
+
+	case "registryEntryHolderSet":
+		base, _ := o["base"].(map[string]any)
+		otherwise, _ := o["otherwise"].(map[string]any)
+		elem, err := rs.resolve(base["type"])
+		if err != nil {
+			return nil, err
+		}
+		ids, err := rs.resolve(otherwise["type"])
+		if err != nil {
+			return nil, err
+		}
+		baseName, _ := base["name"].(string)
+		otherName, _ := otherwise["name"].(string)
+		return &node{
+			Kind:      "registryEntryHolderSet",
+			Elem:      elem,
+			CountType: ids,
+			BaseName:  baseName,
+			OtherName: otherName,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported native type %s", name)
+}
+
+// readCount reads the length options shared by arrays, buffers and strings.
+func (rs *resolver) readCount(n *node, o map[string]any) error {
+	if countType, ok := o["countType"]; ok {
+		var err error
+		if n.CountType, err = rs.resolve(countType); err != nil {
+			return err
+		}
+		if n.CountType.Kind != "native" || nativeGoTypes[n.CountType.Native] == "" || n.CountType.Native == "bool" {
+			return fmt.Errorf("count type %v", countType)
+		}
+		return nil
+	}
+
+	switch count := o["count"].(type) {
+	case float64:
+		n.Count = int(count)
+	case string:
+		n.CountRef = count
+	case nil:
+		if n.Kind == "native" && n.Native == "string" {
+			n.CountType = &node{Kind: "native", Native: "varint"}
+			return nil
+		}
+		return fmt.Errorf("missing count")
+	default:
+		return fmt.Errorf("count %v", count)
+	}
+	return nil
+}
+
+func (rs *resolver) resolveContainer(opts any) (*node, error) {
+	list, _ := opts.([]any)
+	n := &node{Kind: "container"}
+
+	for _, entry := range list {
+		entry, _ := entry.(map[string]any)
+		name, _ := entry["name"].(string)
+		anon, _ := entry["anon"].(bool)
+		child, err := rs.resolve(entry["type"])
+		if err != nil {
+			return nil, err
+		}
+
+		if anon && child.Kind == "container" {
+			n.Fields = append(n.Fields, child.Fields...)
+			continue
+		}
+		if anon {
+			name = "data"
+		}
+		if name == "" {
+			return nil, fmt.Errorf("unnamed container field")
+		}
+		n.Fields = append(n.Fields, &field{Name: name, Anon: anon, Node: child})
+	}
+	return n, nil
+}
+
+func (rs *resolver) resolveBitfield(opts any) (*node, error) {
+	list, _ := opts.([]any)
+	n := &node{Kind: "bitfield"}
+
+	total := 0
+	for _, entry := range list {
+		entry, _ := entry.(map[string]any)
+		name, _ := entry["name"].(string)
+		size, _ := entry["size"].(float64)
+		signed, _ := entry["signed"].(bool)
+		n.Bits = append(n.Bits, bitfield{Name: name, Size: int(size), Signed: signed})
+		total += int(size)
+	}
+
+	if total != 8 && total != 16 && total != 32 && total != 64 {
+		return nil, fmt.Errorf("bitfield of %d bits", total)
+	}
+	return n, nil
+}
+
+func (rs *resolver) resolveSwitch(o map[string]any) (*node, error) {
+	n := &node{Kind: "switch"}
+	n.Compare, _ = o["compareTo"].(string)
+	if value, ok := o["compareToValue"]; ok {
+		s := fmt.Sprint(value)
+		n.CompareValue = &s
+	} else if n.Compare == "" {
+		return nil, fmt.Errorf("switch without compareTo")
+	}
+
+	fields, _ := o["fields"].(map[string]any)
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return lessKey(keys[i], keys[j]) })
+
+	for _, key := range keys {
+		c, err := rs.resolve(fields[key])
+		if err != nil {
+			return nil, err
+		}
+		n.Cases = append(n.Cases, &caseNode{Key: key, Node: c})
+	}
+
+	n.Default = &node{Kind: "native", Native: "void"}
+	if def, ok := o["default"]; ok {
+		var err error
+		if n.Default, err = rs.resolve(def); err != nil {
+			return nil, err
+		}
+	}
+	return n, nil
+}
+
+// lessKey orders numeric switch keys by value and the rest by name.
+func lessKey(a, b string) bool {
+	x, errA := strconv.ParseInt(a, 0, 64)
+	y, errB := strconv.ParseInt(b, 0, 64)
+	if errA == nil && errB == nil {
+		return x < y
+	}
+	if (errA == nil) != (errB == nil) {
+		return errA == nil
+	}
+	return a < b
+}
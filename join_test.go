@@ -0,0 +1,310 @@
+package gophermc_test
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"fmt"
+	"github.com/obeliskdev/gophermc"
+	"github.com/obeliskdev/gophermc/protocol"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type fakeServer struct {
+	t        *testing.T
+	listener net.Listener
+	version  protocol.Version
+
+	conn   net.Conn
+	reader io.Reader
+	writer io.Writer
+}
+
+func newFakeServer(t *testing.T, version protocol.Version) *fakeServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+
+	t.Cleanup(func() { _ = listener.Close() })
+
+	return &fakeServer{t: t, listener: listener, version: version}
+}
+
+func (s *fakeServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *fakeServer) accept() error {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return err
+	}
+
+	_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+	s.conn, s.reader, s.writer = conn, conn, conn
+	s.t.Cleanup(func() { _ = conn.Close() })
+
+	return nil
+}
+
+func (s *fakeServer) enableEncryption(sharedSecret []byte) error {
+	block, err := aes.NewCipher(sharedSecret)
+	if err != nil {
+		return err
+	}
+
+	s.reader = &cipher.StreamReader{S: protocol.NewCFB8Decrypter(block, sharedSecret), R: s.conn}
+	s.writer = cipher.StreamWriter{S: protocol.NewCFB8Encrypter(block, sharedSecret), W: s.conn}
+
+	return nil
+}
+
+func (s *fakeServer) readPacket() (int32, *bytes.Reader, error) {
+	length, err := protocol.ReadVarInt(s.reader)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(s.reader, data); err != nil {
+		return 0, nil, err
+	}
+
+	r := bytes.NewReader(data)
+	id, err := protocol.ReadVarInt(r)
+	return id, r, err
+}
+
+func (s *fakeServer) expectPacket(state protocol.State, p protocol.Packet) error {
+	id, body, err := s.readPacket()
+	if err != nil {
+		return fmt.Errorf("read %T: %w", p, err)
+	}
+
+	expected, ok := protocol.GetPacketID(s.version, state, protocol.DirectionServerbound, p)
+	if !ok || id != expected {
+		return fmt.Errorf("expected %T (0x%02X), got packet 0x%02X", p, expected, id)
+	}
+
+	return p.Decode(body, s.version)
+}
+
+func (s *fakeServer) writePacket(state protocol.State, p protocol.Packet) error {
+	id, ok := protocol.GetPacketID(s.version, state, protocol.DirectionClientbound, p)
+	if !ok {
+		return fmt.Errorf("no id for %T", p)
+	}
+
+	var body bytes.Buffer
+	_ = protocol.WriteVarInt(&body, id)
+	if err := p.Encode(&body, s.version); err != nil {
+		return err
+	}
+
+	return s.writeRaw(body.Bytes())
+}
+
+func (s *fakeServer) writeRaw(data []byte) error {
+	var frame bytes.Buffer
+	_ = protocol.WriteVarInt(&frame, int32(len(data)))
+	frame.Write(data)
+
+	_, err := s.writer.Write(frame.Bytes())
+	return err
+}
+
+func (s *fakeServer) writeLoginSuccess(username string, id uuid.UUID) error {
+	var body bytes.Buffer
+	loginSuccessID, _ := protocol.GetPacketID(s.version, protocol.StateLogin, protocol.DirectionClientbound, &protocol.ClientboundLoginSuccess{})
+	_ = protocol.WriteVarInt(&body, loginSuccessID)
+
+	if s.version >= protocol.V1_16 {
+		body.Write(id[:])
+	} else {
+		_ = protocol.WriteString(&body, id.String())
+	}
+
+	_ = protocol.WriteString(&body, username)
+
+	if s.version >= protocol.V1_19 {
+		_ = protocol.WriteVarInt(&body, 0)
+	}
+
+	return s.writeRaw(body.Bytes())
+}
+
+// encryptedLogin performs the server side of an online-mode login up to the
+// point where the client has switched to the play state.
+func (s *fakeServer) encryptedLogin(key *rsa.PrivateKey, serverID string) ([]byte, error) {
+	_, sharedSecret, err := s.login(key, serverID)
+	return sharedSecret, err
+}
+
+func (s *fakeServer) offlineLogin() (*protocol.ServerboundLoginStart, error) {
+	loginStart, _, err := s.login(nil, "")
+	return loginStart, err
+}
+
+func (s *fakeServer) login(key *rsa.PrivateKey, serverID string) (*protocol.ServerboundLoginStart, []byte, error) {
+	if err := s.accept(); err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.expectPacket(protocol.StateHandshaking, &protocol.ServerboundHandshake{}); err != nil {
+		return nil, nil, err
+	}
+
+	loginStart := &protocol.ServerboundLoginStart{}
+	if err := s.expectPacket(protocol.StateLogin, loginStart); err != nil {
+		return nil, nil, err
+	}
+
+	var sharedSecret []byte
+	if key != nil {
+		var err error
+		if sharedSecret, err = s.exchangeKeys(key, serverID, loginStart.PlayerKey); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := s.writeLoginSuccess(loginStart.Username, protocol.OfflineUUID(loginStart.Username)); err != nil {
+		return nil, nil, err
+	}
+
+	settings := &protocol.ServerboundClientSettings{}
+	if err := s.expectPacket(protocol.StatePlay, settings); err != nil {
+		return nil, nil, err
+	}
+
+	return loginStart, sharedSecret, nil
+}
+
+// exchangeKeys answers the encryption request. Before 1.19.3, a client that
+// announced a player key signs the verify token instead of encrypting it.
+func (s *fakeServer) exchangeKeys(key *rsa.PrivateKey, serverID string, playerKey *protocol.PlayerKey) ([]byte, error) {
+	publicKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	verifyToken := []byte{0xCA, 0xFE, 0xBA, 0xBE}
+	request := &protocol.ClientboundEncryptionRequest{
+		ServerID:           serverID,
+		PublicKey:          publicKey,
+		VerifyToken:        verifyToken,
+		ShouldAuthenticate: true,
+	}
+	if err := s.writePacket(protocol.StateLogin, request); err != nil {
+		return nil, err
+	}
+
+	response := &protocol.ServerboundEncryptionResponse{}
+	if err := s.expectPacket(protocol.StateLogin, response); err != nil {
+		return nil, err
+	}
+
+	sharedSecret, err := rsa.DecryptPKCS1v15(rand.Reader, key, response.SharedSecret)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt shared secret: %w", err)
+	}
+
+	if playerKey != nil && s.version <= protocol.V1_19_2 {
+		if err := verifyTokenSignature(playerKey, verifyToken, response); err != nil {
+			return nil, err
+		}
+		return sharedSecret, s.enableEncryption(sharedSecret)
+	}
+
+	token, err := rsa.DecryptPKCS1v15(rand.Reader, key, response.VerifyToken)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt verify token: %w", err)
+	}
+
+	if !bytes.Equal(token, verifyToken) {
+		return nil, fmt.Errorf("verify token mismatch: %x", token)
+	}
+
+	return sharedSecret, s.enableEncryption(sharedSecret)
+}
+
+func verifyTokenSignature(playerKey *protocol.PlayerKey, verifyToken []byte, response *protocol.ServerboundEncryptionResponse) error {
+	if response.MessageSignature == nil {
+		return fmt.Errorf("client with a player key did not sign the verify token")
+	}
+
+	key, err := x509.ParsePKIXPublicKey(playerKey.PublicKey)
+	if err != nil {
+		return fmt.Errorf("parse player key: %w", err)
+	}
+
+	publicKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("unexpected player key type %T", key)
+	}
+
+	hash := sha256.Sum256(binary.BigEndian.AppendUint64(bytes.Clone(verifyToken), uint64(response.Salt)))
+	if err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, hash[:], response.MessageSignature); err != nil {
+		return fmt.Errorf("verify token signature: %w", err)
+	}
+
+	return nil
+}
+
+func TestJoinEncryption(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	for _, version := range []protocol.Version{protocol.V1_7, protocol.V1_12_2, protocol.V1_19, protocol.V1_19_4} {
+		t.Run(version.String(), func(t *testing.T) {
+			server := newFakeServer(t, version)
+
+			errChan := make(chan error, 1)
+			go func() {
+				_, err := server.encryptedLogin(key, "")
+				errChan <- err
+			}()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			client, err := gophermc.NewClient(
+				gophermc.WithAddr(server.Addr()),
+				gophermc.WithVersion(version),
+				gophermc.WithUsername("GopherMC"),
+			)
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+			defer client.Close()
+
+			if err := client.Join(ctx); err != nil {
+				t.Fatalf("Join failed: %v", err)
+			}
+
+			if err := <-errChan; err != nil {
+				t.Fatalf("fake server failed: %v", err)
+			}
+
+			if client.State() != protocol.StatePlay {
+				t.Fatalf("expected play state, got %s", client.State())
+			}
+		})
+	}
+}
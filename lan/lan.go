@@ -0,0 +1,294 @@
+// Package lan finds worlds opened to LAN and announces servers the same way,
+// by multicasting "[MOTD]motd[/MOTD][AD]port[/AD]" to 224.0.2.60:4445.
+package lan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Address is the multicast group the game announces LAN worlds on.
+const Address = "224.0.2.60:4445"
+
+const (
+	// DefaultInterval is how often the game announces a LAN world.
+	DefaultInterval = 1500 * time.Millisecond
+	// DefaultTTL is how long a world stays listed without an announcement.
+	DefaultTTL = 4 * DefaultInterval
+)
+
+var ErrInvalidAnnouncement = errors.New("lan: invalid announcement")
+
+type World struct {
+	MOTD string
+	// Addr is the world's host:port, ready for gophermc.WithAddr.
+	Addr     string
+	LastSeen time.Time
+	// Expired is set on the last update of a world that stopped announcing.
+	Expired bool
+}
+
+// Discovery listens for LAN announcements. Every world is reported once when
+// it is first seen, again when its MOTD changes and a last time when it
+// expires.
+type Discovery struct {
+	// TTL defaults to DefaultTTL.
+	TTL time.Duration
+	// Interface is the interface to join the group on; nil lets the system
+	// choose.
+	Interface *net.Interface
+
+	mu     sync.Mutex
+	worlds map[string]*World
+}
+
+// Discover listens with the default settings until ctx is canceled.
+func Discover(ctx context.Context) (<-chan World, error) {
+	return (&Discovery{}).Run(ctx)
+}
+
+// Run joins the multicast group and reports worlds on the returned channel,
+// which is closed after ctx is canceled.
+func (d *Discovery) Run(ctx context.Context) (<-chan World, error) {
+	group, err := net.ResolveUDPAddr("udp4", Address)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.ListenMulticastUDP("udp4", d.Interface, group)
+	if err != nil {
+		return nil, fmt.Errorf("lan: join %s: %w", Address, err)
+	}
+
+	return d.Serve(ctx, conn), nil
+}
+
+// Serve reads announcements from conn, which it closes once ctx is
+// canceled.
+func (d *Discovery) Serve(ctx context.Context, conn net.PacketConn) <-chan World {
+	d.mu.Lock()
+	d.worlds = make(map[string]*World)
+	d.mu.Unlock()
+
+	ttl := d.TTL
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+
+	worlds := make(chan World, 16)
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		d.read(ctx, conn, worlds)
+	}()
+
+	go func() {
+		defer wg.Done()
+		d.expire(ctx, ttl, worlds)
+	}()
+
+	go func() {
+		wg.Wait()
+		stop()
+		_ = conn.Close()
+		close(worlds)
+	}()
+
+	return worlds
+}
+
+func (d *Discovery) read(ctx context.Context, conn net.PacketConn, worlds chan<- World) {
+	buf := make([]byte, 1024)
+	for {
+		n, source, err := conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+
+		motd, ad, err := ParseAnnouncement(string(buf[:n]))
+		if err != nil {
+			continue
+		}
+
+		addr, err := worldAddr(source, ad)
+		if err != nil {
+			continue
+		}
+
+		d.mu.Lock()
+		world, known := d.worlds[addr]
+		if !known {
+			world = &World{Addr: addr}
+			d.worlds[addr] = world
+		}
+		changed := !known || world.MOTD != motd
+		world.MOTD = motd
+		world.LastSeen = time.Now()
+		update := *world
+		d.mu.Unlock()
+
+		if changed && !send(ctx, worlds, update) {
+			return
+		}
+	}
+}
+
+func (d *Discovery) expire(ctx context.Context, ttl time.Duration, worlds chan<- World) {
+	ticker := time.NewTicker(ttl / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			var expired []World
+
+			d.mu.Lock()
+			for addr, world := range d.worlds {
+				if now.Sub(world.LastSeen) > ttl {
+					delete(d.worlds, addr)
+					world.Expired = true
+					expired = append(expired, *world)
+				}
+			}
+			d.mu.Unlock()
+
+			for _, world := range expired {
+				if !send(ctx, worlds, world) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func send(ctx context.Context, worlds chan<- World, world World) bool {
+	select {
+	case worlds <- world:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Worlds returns the worlds currently announcing, sorted by address.
+func (d *Discovery) Worlds() []World {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	worlds := make([]World, 0, len(d.worlds))
+	for _, world := range d.worlds {
+		worlds = append(worlds, *world)
+	}
+	slices.SortFunc(worlds, func(a, b World) int { return strings.Compare(a.Addr, b.Addr) })
+	return worlds
+}
+
+// worldAddr combines the announced port with the sender's address. Old
+// versions announce host:port, which is used when the host is set.
+func worldAddr(source net.Addr, ad string) (string, error) {
+	udp, ok := source.(*net.UDPAddr)
+	if !ok {
+		return "", fmt.Errorf("lan: unexpected source %s", source)
+	}
+
+	host, port, err := net.SplitHostPort(ad)
+	if err != nil {
+		host, port = "", ad
+	}
+
+	if n, err := strconv.ParseUint(port, 10, 16); err != nil || n == 0 {
+		return "", fmt.Errorf("%w: port %q", ErrInvalidAnnouncement, port)
+	}
+
+	if ip := net.ParseIP(host); host == "" || ip != nil && ip.IsUnspecified() {
+		host = udp.IP.String()
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// ParseAnnouncement returns the MOTD and the [AD] value of an announcement.
+func ParseAnnouncement(message string) (motd, ad string, err error) {
+	motd, ok := between(message, "[MOTD]", "[/MOTD]")
+	if !ok {
+		return "", "", fmt.Errorf("%w: missing MOTD", ErrInvalidAnnouncement)
+	}
+
+	_, rest, _ := strings.Cut(message, "[/MOTD]")
+	if ad, ok = between(rest, "[AD]", "[/AD]"); !ok {
+		return "", "", fmt.Errorf("%w: missing address", ErrInvalidAnnouncement)
+	}
+	return motd, ad, nil
+}
+
+// FormatAnnouncement returns the announcement of a world on port.
+func FormatAnnouncement(motd string, port int) string {
+	return "[MOTD]" + motd + "[/MOTD][AD]" + strconv.Itoa(port) + "[/AD]"
+}
+
+func between(s, open, end string) (string, bool) {
+	_, s, ok := strings.Cut(s, open)
+	if !ok {
+		return "", false
+	}
+	s, _, ok = strings.Cut(s, end)
+	return s, ok
+}
+
+// Announcer announces a server to the LAN, making it appear in the
+// multiplayer screen of players on the same network.
+type Announcer struct {
+	MOTD string
+	Port int
+	// Interval defaults to DefaultInterval.
+	Interval time.Duration
+	// Addr defaults to Address.
+	Addr string
+}
+
+// Run announces until ctx is canceled and returns ctx.Err().
+func (a *Announcer) Run(ctx context.Context) error {
+	addr := a.Addr
+	if addr == "" {
+		addr = Address
+	}
+
+	interval := a.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp4", addr)
+	if err != nil {
+		return fmt.Errorf("lan: dial %s: %w", addr, err)
+	}
+	defer conn.Close()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		// A host that is down is not worth stopping for, the next
+		// announcement may get through.
+		_, _ = conn.Write([]byte(FormatAnnouncement(a.MOTD, a.Port)))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package lan
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestParseAnnouncement(t *testing.T) {
+	for message, want := range map[string][2]string{
+		FormatAnnouncement("Steve - World", 41235): {"Steve - World", "41235"},
+		"[MOTD]Old[/MOTD][AD]0.0.0.0:25565[/AD]":   {"Old", "0.0.0.0:25565"},
+		"[MOTD][AD]1[/AD][/MOTD][AD]2[/AD]":        {"[AD]1[/AD]", "2"},
+	} {
+		motd, ad, err := ParseAnnouncement(message)
+		if err != nil || motd != want[0] || ad != want[1] {
+			t.Errorf("%q: got %q, %q (%v)", message, motd, ad, err)
+		}
+	}
+
+	for _, message := range []string{"", "[MOTD]x", "[MOTD]x[/MOTD]", "[AD]1[/AD]"} {
+		if _, _, err := ParseAnnouncement(message); !errors.Is(err, ErrInvalidAnnouncement) {
+			t.Errorf("%q: expected an error, got %v", message, err)
+		}
+	}
+}
+
+func TestWorldAddr(t *testing.T) {
+	source := &net.UDPAddr{IP: net.IPv4(192, 168, 1, 20), Port: 50000}
+	for ad, want := range map[string]string{
+		"41235":          "192.168.1.20:41235",
+		"0.0.0.0:25565":  "192.168.1.20:25565",
+		"10.0.0.5:25565": "10.0.0.5:25565",
+	} {
+		if got, err := worldAddr(source, ad); err != nil || got != want {
+			t.Errorf("%q: expected %s, got %s (%v)", ad, want, got, err)
+		}
+	}
+
+	for _, ad := range []string{"", "0", "port", "70000"} {
+		if _, err := worldAddr(source, ad); err == nil {
+			t.Errorf("%q: expected an error", ad)
+		}
+	}
+}
+
+func receive(t *testing.T, worlds <-chan World) World {
+	t.Helper()
+
+	select {
+	case world, ok := <-worlds:
+		if !ok {
+			t.Fatal("channel closed")
+		}
+		return world
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for a world")
+	}
+	return World{}
+}
+
+func TestDiscovery(t *testing.T) {
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	discovery := &Discovery{TTL: 200 * time.Millisecond}
+	worlds := discovery.Serve(ctx, conn)
+
+	announce := func(motd string) <-chan struct{} {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			announcer := &Announcer{MOTD: motd, Port: 25565, Interval: 20 * time.Millisecond, Addr: conn.LocalAddr().String()}
+			announceCtx, stop := context.WithTimeout(ctx, 100*time.Millisecond)
+			defer stop()
+			_ = announcer.Run(announceCtx)
+		}()
+		return done
+	}
+
+	done := announce("A World")
+	world := receive(t, worlds)
+	if world.MOTD != "A World" || world.Addr != "127.0.0.1:25565" || world.Expired {
+		t.Fatalf("unexpected world %+v", world)
+	}
+
+	// Repeated announcements are not reported again, a new MOTD is.
+	<-done
+	announce("Renamed")
+	if world = receive(t, worlds); world.MOTD != "Renamed" {
+		t.Fatalf("unexpected world %+v", world)
+	}
+	if listed := discovery.Worlds(); len(listed) != 1 || listed[0].MOTD != "Renamed" {
+		t.Fatalf("unexpected worlds %+v", listed)
+	}
+
+	if world = receive(t, worlds); !world.Expired || world.Addr != "127.0.0.1:25565" {
+		t.Fatalf("expected the world to expire, got %+v", world)
+	}
+	if listed := discovery.Worlds(); len(listed) != 0 {
+		t.Fatalf("unexpected worlds %+v", listed)
+	}
+
+	cancel()
+	for range worlds {
+	}
+}
+
+func TestMulticast(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	worlds, err := Discover(ctx)
+	if err != nil {
+		t.Skipf("multicast unavailable: %v", err)
+	}
+
+	port := 20000 + time.Now().Nanosecond()%10000
+	go func() { _ = (&Announcer{MOTD: "Multicast", Port: port, Interval: 50 * time.Millisecond}).Run(ctx) }()
+
+	for world := range worlds {
+		if world.MOTD == "Multicast" && world.Addr[len(world.Addr)-5:] == strconv.Itoa(port) {
+			return
+		}
+	}
+	t.Skip("no multicast route")
+}
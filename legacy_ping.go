@@ -0,0 +1,66 @@
+package gophermc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/obeliskdev/gophermc/protocol"
+)
+
+// ErrLegacyServer is returned by GetStatus when the server kicked the modern
+// handshake with a pre-1.7 kick packet.
+var ErrLegacyServer = errors.New("server only answers the legacy ping")
+
+// LegacyStatus pings a pre-1.7 server with the given variant. The response
+// keeps the kick reason in Raw; legacy servers have no favicon or sample,
+// and beta servers no version. Servers from 1.4 understand every variant.
+func (c *Client) LegacyStatus(ctx context.Context, variant protocol.LegacyPingVariant) (*protocol.StatusResponse, time.Duration, error) {
+	if err := c.Connect(ctx); err != nil {
+		return nil, 0, err
+	}
+	defer c.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = c.Conn.SetDeadline(deadline)
+	}
+
+	ping := &protocol.LegacyPing{
+		Variant:  variant,
+		Protocol: protocol.LegacyProtocol16,
+		Hostname: c.ServerHostname(),
+		Port:     int32(c.serverPort()),
+	}
+
+	startTime := time.Now()
+	if err := ping.Write(c.Conn); err != nil {
+		return nil, 0, fmt.Errorf("sending legacy ping failed: %w", err)
+	}
+
+	kick, err := protocol.ReadLegacyKick(c.Conn)
+	if err != nil {
+		return nil, 0, fmt.Errorf("reading legacy status failed: %w", err)
+	}
+	latency := time.Since(startTime)
+
+	legacy, err := protocol.ParseLegacyStatus(kick)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	status := legacy.StatusResponse()
+	status.Raw = kick
+	return status, latency, nil
+}
+
+// isLegacyKick reports whether the reply to the modern handshake is a
+// legacy kick: 0xFF followed by the high byte of a short length. A modern
+// packet length never encodes as 0xFF 0x00.
+func (c *Client) isLegacyKick() (bool, error) {
+	head, err := c.Peek(2)
+	if err != nil {
+		return false, err
+	}
+	return head[0] == 0xFF && head[1] == 0x00, nil
+}
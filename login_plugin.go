@@ -0,0 +1,54 @@
+package gophermc
+
+import (
+	"fmt"
+
+	"github.com/obeliskdev/gophermc/protocol"
+)
+
+// LoginPluginHandler answers a login plugin request with the request's data.
+// Returning understood false answers it as not understood, like a vanilla
+// client does.
+type LoginPluginHandler func(data []byte) (response []byte, understood bool, err error)
+
+// HandleLoginPlugin registers handler for login plugin requests on channel,
+// replacing any previous handler. A nil handler removes it. Requests on
+// channels without a handler are answered as not understood, so the login
+// never waits on a response.
+func (c *Client) HandleLoginPlugin(channel string, handler LoginPluginHandler) {
+	c.loginPluginsMu.Lock()
+	defer c.loginPluginsMu.Unlock()
+
+	if handler == nil {
+		delete(c.loginPlugins, channel)
+		return
+	}
+	if c.loginPlugins == nil {
+		c.loginPlugins = make(map[string]LoginPluginHandler)
+	}
+	c.loginPlugins[channel] = handler
+}
+
+func (c *Client) handleLoginPluginRequest(p *protocol.ClientboundLoginPluginRequest) error {
+	handler := c.loginPluginHandler(p.Channel)
+
+	response := &protocol.ServerboundLoginPluginResponse{MessageID: p.MessageID}
+	if handler != nil {
+		data, understood, err := handler(p.Data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", p.Channel, err)
+		}
+		response.Successful = understood
+		if understood {
+			response.Data = data
+		}
+	}
+
+	return c.WritePacket(response)
+}
+
+func (c *Client) loginPluginHandler(channel string) LoginPluginHandler {
+	c.loginPluginsMu.RLock()
+	defer c.loginPluginsMu.RUnlock()
+	return c.loginPlugins[channel]
+}
@@ -0,0 +1,102 @@
+package gophermc_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/obeliskdev/gophermc"
+	"github.com/obeliskdev/gophermc/component"
+	"github.com/obeliskdev/gophermc/protocol"
+)
+
+func TestLoginPluginHandlers(t *testing.T) {
+	requests := []*protocol.ClientboundLoginPluginRequest{
+		{MessageID: 7, Channel: "example:echo", Data: []byte("ping")},
+		{MessageID: 8, Channel: "example:declined", Data: []byte("ping")},
+		{MessageID: 9, Channel: "example:unknown"},
+	}
+	expected := []protocol.ServerboundLoginPluginResponse{
+		{MessageID: 7, Successful: true, Data: []byte("pong ping")},
+		{MessageID: 8},
+		{MessageID: 9},
+	}
+
+	addr, done := acceptLogin(t, func(conn *protocol.ServerConn, _ *protocol.ServerboundLoginStart) error {
+		for _, request := range requests {
+			if err := conn.WritePacket(request); err != nil {
+				return err
+			}
+		}
+
+		for _, want := range expected {
+			packet, err := conn.ReadPacket()
+			if err != nil {
+				return err
+			}
+			got, ok := packet.(*protocol.ServerboundLoginPluginResponse)
+			if !ok || got.MessageID != want.MessageID || got.Successful != want.Successful || !bytes.Equal(got.Data, want.Data) {
+				return fmt.Errorf("expected %+v, got %+v", want, packet)
+			}
+		}
+
+		return conn.Disconnect(component.Text("checked"))
+	})
+
+	client, err := gophermc.NewClient(
+		gophermc.WithAddr(addr),
+		gophermc.WithLoginPluginHandler("example:echo", func(data []byte) ([]byte, bool, error) {
+			return append([]byte("pong "), data...), true, nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Destroy()
+
+	client.HandleLoginPlugin("example:declined", func([]byte) ([]byte, bool, error) {
+		return []byte("ignored"), false, nil
+	})
+	client.HandleLoginPlugin("example:unknown", func([]byte) ([]byte, bool, error) {
+		return nil, true, nil
+	})
+	client.HandleLoginPlugin("example:unknown", nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Join(ctx); err == nil || !strings.Contains(err.Error(), "checked") {
+		t.Fatalf("expected the test kick, got %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoginPluginHandlerError(t *testing.T) {
+	errBroken := errors.New("broken handler")
+
+	addr, done := acceptLogin(t, func(conn *protocol.ServerConn, _ *protocol.ServerboundLoginStart) error {
+		return conn.WritePacket(&protocol.ClientboundLoginPluginRequest{MessageID: 1, Channel: "example:broken"})
+	})
+
+	client, _ := gophermc.NewClient(
+		gophermc.WithAddr(addr),
+		gophermc.WithLoginPluginHandler("example:broken", func([]byte) ([]byte, bool, error) {
+			return nil, false, errBroken
+		}),
+	)
+	defer client.Destroy()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Join(ctx); !errors.Is(err, errBroken) {
+		t.Fatalf("expected the handler error, got %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,266 @@
+package nbt
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// MaxDepth is the deepest nesting of lists and compounds the decoder
+// accepts, matching the vanilla limit.
+const MaxDepth = 512
+
+var (
+	ErrMaxDepth      = errors.New("nbt: maximum nesting depth exceeded")
+	ErrInvalidLength = errors.New("nbt: invalid length")
+)
+
+type Decoder struct {
+	r       io.Reader
+	network bool
+}
+
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: r}
+}
+
+// NewNetworkDecoder reads the 1.20.2+ network variant, where the root tag
+// has no name.
+func NewNetworkDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: r, network: true}
+}
+
+// ReadTag reads one root tag. A TAG_End root, used on the wire for an absent
+// value, is returned as a nil tag.
+func (d *Decoder) ReadTag() (string, Tag, error) {
+	tagType, err := d.readByte()
+	if err != nil {
+		return "", nil, err
+	}
+
+	if TagType(tagType) == TagEnd {
+		return "", nil, nil
+	}
+
+	var name string
+	if !d.network {
+		if name, err = d.readString(); err != nil {
+			return "", nil, fmt.Errorf("nbt: read root name: %w", err)
+		}
+	}
+
+	tag, err := d.readPayload(TagType(tagType), 0)
+	return name, tag, err
+}
+
+// Decode reads one root tag into v, which may be a *Tag or any value
+// accepted by Unmarshal. It returns the root tag's name.
+func (d *Decoder) Decode(v any) (string, error) {
+	name, tag, err := d.ReadTag()
+	if err != nil {
+		return name, err
+	}
+
+	if target, ok := v.(*Tag); ok {
+		*target = tag
+		return name, nil
+	}
+
+	if tag == nil {
+		return name, nil
+	}
+
+	return name, FromTag(tag, v)
+}
+
+func (d *Decoder) readPayload(tagType TagType, depth int) (Tag, error) {
+	switch tagType {
+	case TagByte:
+		b, err := d.readByte()
+		return Byte(b), err
+	case TagShort:
+		var buf [2]byte
+		_, err := io.ReadFull(d.r, buf[:])
+		return Short(binary.BigEndian.Uint16(buf[:])), err
+	case TagInt:
+		v, err := d.readInt()
+		return Int(v), err
+	case TagLong:
+		v, err := d.readLong()
+		return Long(v), err
+	case TagFloat:
+		v, err := d.readInt()
+		return Float(math.Float32frombits(uint32(v))), err
+	case TagDouble:
+		v, err := d.readLong()
+		return Double(math.Float64frombits(uint64(v))), err
+	case TagByteArray:
+		n, err := d.readLength()
+		if err != nil {
+			return nil, err
+		}
+		data, err := readLimited(d.r, int64(n))
+		return ByteArray(data), err
+	case TagString:
+		s, err := d.readString()
+		return String(s), err
+	case TagList:
+		return d.readList(depth)
+	case TagCompound:
+		return d.readCompound(depth)
+	case TagIntArray:
+		n, err := d.readLength()
+		if err != nil {
+			return nil, err
+		}
+		values := make([]int32, 0, min(n, 1<<16))
+		for i := int32(0); i < n; i++ {
+			v, err := d.readInt()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		return IntArray(values), nil
+	case TagLongArray:
+		n, err := d.readLength()
+		if err != nil {
+			return nil, err
+		}
+		values := make([]int64, 0, min(n, 1<<15))
+		for i := int32(0); i < n; i++ {
+			v, err := d.readLong()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		return LongArray(values), nil
+	}
+
+	return nil, fmt.Errorf("nbt: unknown tag type %d", byte(tagType))
+}
+
+func (d *Decoder) readList(depth int) (Tag, error) {
+	if depth >= MaxDepth {
+		return nil, ErrMaxDepth
+	}
+
+	elementType, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := d.readLength()
+	if err != nil {
+		return nil, err
+	}
+
+	list := &List{ElementType: TagType(elementType)}
+	if n > 0 && list.ElementType == TagEnd {
+		return nil, errors.New("nbt: non-empty list of TAG_End")
+	}
+
+	if n > 0 {
+		list.Elements = make([]Tag, 0, min(n, 1<<12))
+	}
+	for i := int32(0); i < n; i++ {
+		element, err := d.readPayload(list.ElementType, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		list.Elements = append(list.Elements, element)
+	}
+
+	return list, nil
+}
+
+func (d *Decoder) readCompound(depth int) (Tag, error) {
+	if depth >= MaxDepth {
+		return nil, ErrMaxDepth
+	}
+
+	compound := Compound{}
+	for {
+		tagType, err := d.readByte()
+		if err != nil {
+			return nil, err
+		}
+
+		if TagType(tagType) == TagEnd {
+			return compound, nil
+		}
+
+		name, err := d.readString()
+		if err != nil {
+			return nil, err
+		}
+
+		if compound[name], err = d.readPayload(TagType(tagType), depth+1); err != nil {
+			return nil, fmt.Errorf("%q: %w", name, err)
+		}
+	}
+}
+
+func (d *Decoder) readByte() (byte, error) {
+	var buf [1]byte
+	_, err := io.ReadFull(d.r, buf[:])
+	return buf[0], err
+}
+
+func (d *Decoder) readInt() (int32, error) {
+	var buf [4]byte
+	_, err := io.ReadFull(d.r, buf[:])
+	return int32(binary.BigEndian.Uint32(buf[:])), err
+}
+
+func (d *Decoder) readLong() (int64, error) {
+	var buf [8]byte
+	_, err := io.ReadFull(d.r, buf[:])
+	return int64(binary.BigEndian.Uint64(buf[:])), err
+}
+
+func (d *Decoder) readLength() (int32, error) {
+	n, err := d.readInt()
+	if err != nil {
+		return 0, err
+	}
+
+	if n < 0 {
+		return 0, fmt.Errorf("%w: %d", ErrInvalidLength, n)
+	}
+
+	return n, nil
+}
+
+func (d *Decoder) readString() (string, error) {
+	var buf [2]byte
+	if _, err := io.ReadFull(d.r, buf[:]); err != nil {
+		return "", err
+	}
+
+	data := make([]byte, binary.BigEndian.Uint16(buf[:]))
+	if _, err := io.ReadFull(d.r, data); err != nil {
+		return "", err
+	}
+
+	return decodeModifiedUTF8(data), nil
+}
+
+// readLimited reads n bytes without trusting n for the initial allocation,
+// so a corrupt length cannot force a huge buffer before the data runs out.
+func readLimited(r io.Reader, n int64) ([]byte, error) {
+	if n <= 1<<20 {
+		data := make([]byte, n)
+		_, err := io.ReadFull(r, data)
+		return data, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, n))
+	if err == nil && int64(len(data)) < n {
+		err = io.ErrUnexpectedEOF
+	}
+	return data, err
+}
@@ -0,0 +1,204 @@
+package nbt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+type Encoder struct {
+	w       io.Writer
+	network bool
+}
+
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// NewNetworkEncoder writes the 1.20.2+ network variant, where the root tag
+// has no name.
+func NewNetworkEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w, network: true}
+}
+
+// WriteTag writes tag as the root with the given name. The name is dropped
+// by network encoders, and a nil tag is written as TAG_End.
+func (e *Encoder) WriteTag(name string, tag Tag) error {
+	if tag == nil {
+		return e.writeByte(byte(TagEnd))
+	}
+
+	if err := e.writeByte(byte(tag.Type())); err != nil {
+		return err
+	}
+
+	if !e.network {
+		if err := e.writeString(name); err != nil {
+			return err
+		}
+	}
+
+	return e.writePayload(tag, 0)
+}
+
+// Encode converts v with ToTag and writes it as the root tag.
+func (e *Encoder) Encode(name string, v any) error {
+	tag, err := ToTag(v)
+	if err != nil {
+		return err
+	}
+
+	return e.WriteTag(name, tag)
+}
+
+func (e *Encoder) writePayload(tag Tag, depth int) error {
+	switch t := tag.(type) {
+	case Byte:
+		return e.writeByte(byte(t))
+	case Short:
+		var buf [2]byte
+		binary.BigEndian.PutUint16(buf[:], uint16(t))
+		return e.write(buf[:])
+	case Int:
+		return e.writeInt(int32(t))
+	case Long:
+		return e.writeLong(int64(t))
+	case Float:
+		return e.writeInt(int32(math.Float32bits(float32(t))))
+	case Double:
+		return e.writeLong(int64(math.Float64bits(float64(t))))
+	case ByteArray:
+		if err := e.writeLength(len(t)); err != nil {
+			return err
+		}
+		return e.write(t)
+	case String:
+		return e.writeString(string(t))
+	case *List:
+		return e.writeList(t, depth)
+	case Compound:
+		return e.writeCompound(t, depth)
+	case IntArray:
+		if err := e.writeLength(len(t)); err != nil {
+			return err
+		}
+		buf := make([]byte, 4*len(t))
+		for i, v := range t {
+			binary.BigEndian.PutUint32(buf[4*i:], uint32(v))
+		}
+		return e.write(buf)
+	case LongArray:
+		if err := e.writeLength(len(t)); err != nil {
+			return err
+		}
+		buf := make([]byte, 8*len(t))
+		for i, v := range t {
+			binary.BigEndian.PutUint64(buf[8*i:], uint64(v))
+		}
+		return e.write(buf)
+	}
+
+	return fmt.Errorf("nbt: cannot encode %T", tag)
+}
+
+func (e *Encoder) writeList(list *List, depth int) error {
+	if depth >= MaxDepth {
+		return ErrMaxDepth
+	}
+
+	elementType := list.ElementType
+	if len(list.Elements) > 0 {
+		elementType = list.Elements[0].Type()
+	}
+
+	if err := e.writeByte(byte(elementType)); err != nil {
+		return err
+	}
+
+	if err := e.writeLength(len(list.Elements)); err != nil {
+		return err
+	}
+
+	for i, element := range list.Elements {
+		if element.Type() != elementType {
+			return fmt.Errorf("nbt: list element %d is %s, expected %s", i, element.Type(), elementType)
+		}
+
+		if err := e.writePayload(element, depth+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *Encoder) writeCompound(compound Compound, depth int) error {
+	if depth >= MaxDepth {
+		return ErrMaxDepth
+	}
+
+	for _, name := range compound.Keys() {
+		tag := compound[name]
+		if tag == nil {
+			continue
+		}
+
+		if err := e.writeByte(byte(tag.Type())); err != nil {
+			return err
+		}
+
+		if err := e.writeString(name); err != nil {
+			return err
+		}
+
+		if err := e.writePayload(tag, depth+1); err != nil {
+			return fmt.Errorf("%q: %w", name, err)
+		}
+	}
+
+	return e.writeByte(byte(TagEnd))
+}
+
+func (e *Encoder) write(data []byte) error {
+	_, err := e.w.Write(data)
+	return err
+}
+
+func (e *Encoder) writeByte(b byte) error {
+	return e.write([]byte{b})
+}
+
+func (e *Encoder) writeInt(v int32) error {
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], uint32(v))
+	return e.write(buf[:])
+}
+
+func (e *Encoder) writeLong(v int64) error {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(v))
+	return e.write(buf[:])
+}
+
+func (e *Encoder) writeLength(n int) error {
+	if n > math.MaxInt32 {
+		return fmt.Errorf("%w: %d", ErrInvalidLength, n)
+	}
+	return e.writeInt(int32(n))
+}
+
+func (e *Encoder) writeString(s string) error {
+	data := encodeModifiedUTF8(s)
+	if len(data) > math.MaxUint16 {
+		return fmt.Errorf("nbt: string of %d bytes is too long", len(data))
+	}
+
+	var buf [2]byte
+	binary.BigEndian.PutUint16(buf[:], uint16(len(data)))
+	if err := e.write(buf[:]); err != nil {
+		return err
+	}
+
+	return e.write(data)
+}
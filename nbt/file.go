@@ -0,0 +1,53 @@
+package nbt
+
+import (
+	"bufio"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"os"
+)
+
+// NewReader detects gzip or zlib compression from the first bytes of r and
+// returns a reader for the uncompressed stream. Uncompressed input, as used
+// by servers.dat, is passed through.
+func NewReader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+
+	magic, err := br.Peek(2)
+	if err != nil && len(magic) == 0 {
+		return nil, err
+	}
+
+	switch {
+	case len(magic) == 2 && magic[0] == 0x1F && magic[1] == 0x8B:
+		return gzip.NewReader(br)
+	case len(magic) == 2 && magic[0] == 0x78 && (uint16(magic[0])<<8|uint16(magic[1]))%31 == 0:
+		return zlib.NewReader(br)
+	}
+
+	return br, nil
+}
+
+// ReadFile reads a possibly compressed NBT file such as level.dat and returns
+// its root name and tag.
+func ReadFile(name string) (string, Tag, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+
+	r, err := NewReader(f)
+	if err != nil {
+		return "", nil, fmt.Errorf("nbt: %s: %w", name, err)
+	}
+
+	rootName, tag, err := NewDecoder(r).ReadTag()
+	if err != nil {
+		return "", nil, fmt.Errorf("nbt: %s: %w", name, err)
+	}
+
+	return rootName, tag, nil
+}
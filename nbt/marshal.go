@@ -0,0 +1,433 @@
+package nbt
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Marshaler is implemented by types that build their own tag.
+type Marshaler interface {
+	MarshalNBT() (Tag, error)
+}
+
+// Unmarshaler is implemented by types that decode themselves from a tag.
+type Unmarshaler interface {
+	UnmarshalNBT(tag Tag) error
+}
+
+var (
+	tagType         = reflect.TypeOf((*Tag)(nil)).Elem()
+	marshalerType   = reflect.TypeOf((*Marshaler)(nil)).Elem()
+	unmarshalerType = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+)
+
+// Marshal encodes v as a file-format NBT document with an empty root name.
+func Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode("", v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes a file-format NBT document into v.
+func Unmarshal(data []byte, v any) error {
+	_, err := NewDecoder(bytes.NewReader(data)).Decode(v)
+	return err
+}
+
+// ToTag converts a Go value into a tag tree. Structs and string-keyed maps
+// become compounds, []byte/[]int32/[]int64 the array tags, other slices
+// lists, and Go numbers the NBT type of the same width (int maps to
+// TAG_Int). Struct fields are named by their `nbt:"name,omitempty"` tag,
+// falling back to the field name; "-" skips a field.
+func ToTag(v any) (Tag, error) {
+	if tag, ok := v.(Tag); ok {
+		return tag, nil
+	}
+	return toTag(reflect.ValueOf(v))
+}
+
+// FromTag stores tag in the value pointed to by v, following the same
+// mapping as ToTag. Numeric tags convert to any Go number they fit in, and
+// compound keys without a matching field are ignored.
+func FromTag(tag Tag, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("nbt: FromTag needs a non-nil pointer, got %T", v)
+	}
+	return fromTag(tag, rv.Elem())
+}
+
+func toTag(rv reflect.Value) (Tag, error) {
+	if !rv.IsValid() {
+		return nil, nil
+	}
+
+	if rv.Kind() != reflect.Interface && rv.Type().Implements(marshalerType) {
+		if rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return nil, nil
+		}
+		return rv.Interface().(Marshaler).MarshalNBT()
+	}
+
+	if rv.CanAddr() && rv.Addr().Type().Implements(marshalerType) {
+		return rv.Addr().Interface().(Marshaler).MarshalNBT()
+	}
+
+	if rv.Type().Implements(tagType) && rv.Kind() != reflect.Interface {
+		if rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return nil, nil
+		}
+		return rv.Interface().(Tag), nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		if rv.Bool() {
+			return Byte(1), nil
+		}
+		return Byte(0), nil
+	case reflect.Int8:
+		return Byte(rv.Int()), nil
+	case reflect.Uint8:
+		return Byte(rv.Uint()), nil
+	case reflect.Int16:
+		return Short(rv.Int()), nil
+	case reflect.Uint16:
+		return Short(rv.Uint()), nil
+	case reflect.Int32, reflect.Int:
+		return Int(rv.Int()), nil
+	case reflect.Uint32, reflect.Uint:
+		return Int(rv.Uint()), nil
+	case reflect.Int64:
+		return Long(rv.Int()), nil
+	case reflect.Uint64:
+		return Long(rv.Uint()), nil
+	case reflect.Float32:
+		return Float(rv.Float()), nil
+	case reflect.Float64:
+		return Double(rv.Float()), nil
+	case reflect.String:
+		return String(rv.String()), nil
+	case reflect.Slice, reflect.Array:
+		return sliceToTag(rv)
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("nbt: unsupported map key type %s", rv.Type().Key())
+		}
+
+		compound := make(Compound, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			tag, err := toTag(iter.Value())
+			if err != nil {
+				return nil, fmt.Errorf("%q: %w", iter.Key().String(), err)
+			}
+			if tag != nil {
+				compound[iter.Key().String()] = tag
+			}
+		}
+		return compound, nil
+	case reflect.Struct:
+		compound := Compound{}
+		for _, field := range structFields(rv.Type()) {
+			value, err := rv.FieldByIndexErr(field.index)
+			if err != nil {
+				// Nil embedded pointer.
+				continue
+			}
+			if field.omitEmpty && value.IsZero() {
+				continue
+			}
+
+			tag, err := toTag(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", field.name, err)
+			}
+			if tag != nil {
+				compound[field.name] = tag
+			}
+		}
+		return compound, nil
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		return toTag(rv.Elem())
+	}
+
+	return nil, fmt.Errorf("nbt: unsupported type %s", rv.Type())
+}
+
+func sliceToTag(rv reflect.Value) (Tag, error) {
+	switch rv.Type().Elem().Kind() {
+	case reflect.Uint8, reflect.Int8:
+		data := make([]byte, rv.Len())
+		for i := range data {
+			data[i] = byte(rv.Index(i).Convert(reflect.TypeOf(byte(0))).Uint())
+		}
+		return ByteArray(data), nil
+	case reflect.Int32:
+		values := make([]int32, rv.Len())
+		for i := range values {
+			values[i] = int32(rv.Index(i).Int())
+		}
+		return IntArray(values), nil
+	case reflect.Int64:
+		values := make([]int64, rv.Len())
+		for i := range values {
+			values[i] = rv.Index(i).Int()
+		}
+		return LongArray(values), nil
+	}
+
+	elements := make([]Tag, rv.Len())
+	for i := range elements {
+		tag, err := toTag(rv.Index(i))
+		if err != nil {
+			return nil, fmt.Errorf("[%d]: %w", i, err)
+		}
+		if tag == nil {
+			return nil, fmt.Errorf("nbt: nil list element %d", i)
+		}
+		elements[i] = tag
+	}
+
+	return NewList(elements...)
+}
+
+func fromTag(tag Tag, rv reflect.Value) error {
+	if tag == nil {
+		return nil
+	}
+
+	if rv.CanAddr() && rv.Addr().Type().Implements(unmarshalerType) {
+		return rv.Addr().Interface().(Unmarshaler).UnmarshalNBT(tag)
+	}
+
+	if rv.Kind() == reflect.Interface {
+		if rv.Type() == tagType || rv.NumMethod() == 0 {
+			if rv.Type() == tagType {
+				rv.Set(reflect.ValueOf(tag))
+			} else {
+				rv.Set(reflect.ValueOf(Value(tag)))
+			}
+			return nil
+		}
+		return fmt.Errorf("nbt: cannot decode into %s", rv.Type())
+	}
+
+	if reflect.TypeOf(tag) == rv.Type() {
+		rv.Set(reflect.ValueOf(tag))
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return fromTag(tag, rv.Elem())
+	case reflect.Bool:
+		n, _, ok := numeric(tag)
+		if !ok {
+			return mismatch(tag, rv)
+		}
+		rv.SetBool(n != 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, _, ok := numeric(tag)
+		if !ok || rv.OverflowInt(n) {
+			return mismatch(tag, rv)
+		}
+		rv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, _, ok := numeric(tag)
+		if !ok {
+			return mismatch(tag, rv)
+		}
+		// Unsigned Go values were written with the matching signed width.
+		rv.SetUint(uint64(n) & (1<<rv.Type().Bits() - 1))
+	case reflect.Float32, reflect.Float64:
+		_, f, ok := numeric(tag)
+		if !ok {
+			return mismatch(tag, rv)
+		}
+		rv.SetFloat(f)
+	case reflect.String:
+		s, ok := tag.(String)
+		if !ok {
+			return mismatch(tag, rv)
+		}
+		rv.SetString(string(s))
+	case reflect.Slice, reflect.Array:
+		return sliceFromTag(tag, rv)
+	case reflect.Map:
+		compound, ok := tag.(Compound)
+		if !ok || rv.Type().Key().Kind() != reflect.String {
+			return mismatch(tag, rv)
+		}
+
+		if rv.IsNil() {
+			rv.Set(reflect.MakeMapWithSize(rv.Type(), len(compound)))
+		}
+
+		for name, child := range compound {
+			value := reflect.New(rv.Type().Elem()).Elem()
+			if err := fromTag(child, value); err != nil {
+				return fmt.Errorf("%q: %w", name, err)
+			}
+			rv.SetMapIndex(reflect.ValueOf(name).Convert(rv.Type().Key()), value)
+		}
+	case reflect.Struct:
+		compound, ok := tag.(Compound)
+		if !ok {
+			return mismatch(tag, rv)
+		}
+
+		for _, field := range structFields(rv.Type()) {
+			child, ok := compound[field.name]
+			if !ok {
+				continue
+			}
+
+			if err := fromTag(child, fieldByIndexAlloc(rv, field.index)); err != nil {
+				return fmt.Errorf("%s: %w", field.name, err)
+			}
+		}
+	default:
+		return mismatch(tag, rv)
+	}
+
+	return nil
+}
+
+func sliceFromTag(tag Tag, rv reflect.Value) error {
+	var elements []Tag
+	switch t := tag.(type) {
+	case ByteArray:
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+			rv.SetBytes(append([]byte(nil), t...))
+			return nil
+		}
+		for _, b := range t {
+			elements = append(elements, Byte(b))
+		}
+	case IntArray:
+		for _, v := range t {
+			elements = append(elements, Int(v))
+		}
+	case LongArray:
+		for _, v := range t {
+			elements = append(elements, Long(v))
+		}
+	case *List:
+		elements = t.Elements
+	default:
+		return mismatch(tag, rv)
+	}
+
+	if rv.Kind() == reflect.Array {
+		if len(elements) > rv.Len() {
+			return fmt.Errorf("nbt: %d elements do not fit in %s", len(elements), rv.Type())
+		}
+	} else {
+		rv.Set(reflect.MakeSlice(rv.Type(), len(elements), len(elements)))
+	}
+
+	for i, element := range elements {
+		if err := fromTag(element, rv.Index(i)); err != nil {
+			return fmt.Errorf("[%d]: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+func numeric(tag Tag) (int64, float64, bool) {
+	switch t := tag.(type) {
+	case Byte:
+		return int64(t), float64(t), true
+	case Short:
+		return int64(t), float64(t), true
+	case Int:
+		return int64(t), float64(t), true
+	case Long:
+		return int64(t), float64(t), true
+	case Float:
+		return int64(t), float64(t), true
+	case Double:
+		return int64(t), float64(t), true
+	}
+	return 0, 0, false
+}
+
+func mismatch(tag Tag, rv reflect.Value) error {
+	return fmt.Errorf("nbt: cannot decode %s into %s", tag.Type(), rv.Type())
+}
+
+type field struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+func structFields(t reflect.Type) []field {
+	var fields []field
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("nbt")
+		if tag == "-" {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				for _, embedded := range structFields(ft) {
+					embedded.index = append([]int{i}, embedded.index...)
+					fields = append(fields, embedded)
+				}
+				continue
+			}
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		fields = append(fields, field{
+			name:      name,
+			index:     []int{i},
+			omitEmpty: options == "omitempty",
+		})
+	}
+
+	return fields
+}
+
+// fieldByIndexAlloc is FieldByIndex that allocates nil embedded pointers.
+func fieldByIndexAlloc(rv reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv
+}
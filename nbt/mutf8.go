@@ -0,0 +1,82 @@
+package nbt
+
+import (
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// NBT strings use Java's modified UTF-8: NUL is written as two bytes and
+// characters outside the BMP are written as encoded surrogate pairs.
+
+func encodeModifiedUTF8(s string) []byte {
+	plain := true
+	for i := 0; i < len(s); i++ {
+		if s[i] == 0 || s[i] >= 0x80 {
+			plain = false
+			break
+		}
+	}
+
+	if plain {
+		return []byte(s)
+	}
+
+	out := make([]byte, 0, len(s)+4)
+	for _, r := range s {
+		if r >= 0x10000 {
+			high, low := utf16.EncodeRune(r)
+			out = appendModifiedRune(out, high)
+			out = appendModifiedRune(out, low)
+			continue
+		}
+		out = appendModifiedRune(out, r)
+	}
+
+	return out
+}
+
+func appendModifiedRune(out []byte, r rune) []byte {
+	switch {
+	case r != 0 && r < 0x80:
+		return append(out, byte(r))
+	case r < 0x800:
+		return append(out, 0xC0|byte(r>>6), 0x80|byte(r&0x3F))
+	default:
+		return append(out, 0xE0|byte(r>>12), 0x80|byte(r>>6&0x3F), 0x80|byte(r&0x3F))
+	}
+}
+
+func decodeModifiedUTF8(data []byte) string {
+	plain := true
+	for _, b := range data {
+		if b >= 0x80 {
+			plain = false
+			break
+		}
+	}
+
+	if plain {
+		return string(data)
+	}
+
+	units := make([]uint16, 0, len(data))
+	for i := 0; i < len(data); {
+		b := data[i]
+		switch {
+		case b < 0x80:
+			units = append(units, uint16(b))
+			i++
+		case b&0xE0 == 0xC0 && i+1 < len(data):
+			units = append(units, uint16(b&0x1F)<<6|uint16(data[i+1]&0x3F))
+			i += 2
+		case b&0xF0 == 0xE0 && i+2 < len(data):
+			units = append(units, uint16(b&0x0F)<<12|uint16(data[i+1]&0x3F)<<6|uint16(data[i+2]&0x3F))
+			i += 3
+		default:
+			units = append(units, utf8.RuneError)
+			i++
+		}
+	}
+
+	return string(utf16.Decode(units))
+}
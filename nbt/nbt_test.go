@@ -0,0 +1,291 @@
+package nbt
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// helloWorld is the hello_world.nbt sample from the original NBT spec.
+var helloWorld = []byte{
+	0x0A, 0x00, 0x0B, 'h', 'e', 'l', 'l', 'o', ' ', 'w', 'o', 'r', 'l', 'd',
+	0x08, 0x00, 0x04, 'n', 'a', 'm', 'e', 0x00, 0x09, 'B', 'a', 'n', 'a', 'n', 'r', 'a', 'm', 'a',
+	0x00,
+}
+
+func TestDecodeHelloWorld(t *testing.T) {
+	name, tag, err := NewDecoder(bytes.NewReader(helloWorld)).ReadTag()
+	if err != nil {
+		t.Fatalf("ReadTag: %v", err)
+	}
+
+	if name != "hello world" {
+		t.Fatalf("expected root name %q, got %q", "hello world", name)
+	}
+
+	if !reflect.DeepEqual(tag, Compound{"name": String("Bananrama")}) {
+		t.Fatalf("unexpected tag %s", Stringify(tag))
+	}
+
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).WriteTag(name, tag); err != nil {
+		t.Fatalf("WriteTag: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), helloWorld) {
+		t.Fatalf("re-encoding differs:\n%x\n%x", buf.Bytes(), helloWorld)
+	}
+}
+
+func sampleTree() Compound {
+	return Compound{
+		"byte":   Byte(-1),
+		"short":  Short(300),
+		"int":    Int(-70000),
+		"long":   Long(1 << 40),
+		"float":  Float(0.5),
+		"double": Double(-2.25),
+		"bytes":  ByteArray{1, 2, 255},
+		"string": String("nul\x00 and \U0001F600"),
+		"ints":   IntArray{1, -2, 3},
+		"longs":  LongArray{-1, 1 << 62},
+		"empty":  &List{ElementType: TagString},
+		"list":   &List{ElementType: TagCompound, Elements: []Tag{Compound{"a": Int(1)}, Compound{}}},
+		"nested": Compound{"inner": &List{ElementType: TagList, Elements: []Tag{&List{ElementType: TagByte, Elements: []Tag{Byte(1)}}}}},
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, network := range []bool{false, true} {
+		var buf bytes.Buffer
+		encoder, decoder := NewEncoder(&buf), NewDecoder(&buf)
+		if network {
+			encoder, decoder = NewNetworkEncoder(&buf), NewNetworkDecoder(&buf)
+		}
+
+		if err := encoder.WriteTag("root", sampleTree()); err != nil {
+			t.Fatalf("network=%v: WriteTag: %v", network, err)
+		}
+
+		name, tag, err := decoder.ReadTag()
+		if err != nil {
+			t.Fatalf("network=%v: ReadTag: %v", network, err)
+		}
+
+		if network && name != "" || !network && name != "root" {
+			t.Fatalf("network=%v: unexpected root name %q", network, name)
+		}
+
+		if !reflect.DeepEqual(tag, sampleTree()) {
+			t.Fatalf("network=%v: round trip mismatch\n%s\n%s", network, Stringify(tag), Stringify(sampleTree()))
+		}
+
+		if buf.Len() != 0 {
+			t.Fatalf("network=%v: %d bytes left unread", network, buf.Len())
+		}
+	}
+}
+
+func TestNetworkAbsentRoot(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewNetworkEncoder(&buf).WriteTag("", nil); err != nil {
+		t.Fatalf("WriteTag: %v", err)
+	}
+
+	if !bytes.Equal(buf.Bytes(), []byte{0}) {
+		t.Fatalf("expected a lone TAG_End, got %x", buf.Bytes())
+	}
+
+	if _, tag, err := NewNetworkDecoder(&buf).ReadTag(); err != nil || tag != nil {
+		t.Fatalf("expected nil tag, got %v (%v)", tag, err)
+	}
+}
+
+func TestModifiedUTF8(t *testing.T) {
+	encoded := encodeModifiedUTF8("a\x00\U0001F600")
+	expected := []byte{'a', 0xC0, 0x80, 0xED, 0xA0, 0xBD, 0xED, 0xB8, 0x80}
+	if !bytes.Equal(encoded, expected) {
+		t.Fatalf("expected %x, got %x", expected, encoded)
+	}
+
+	if decoded := decodeModifiedUTF8(encoded); decoded != "a\x00\U0001F600" {
+		t.Fatalf("unexpected decoded string %q", decoded)
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteByte(byte(TagList))
+	for i := 0; i <= MaxDepth; i++ {
+		buf.Write([]byte{byte(TagList), 0, 0, 0, 1})
+	}
+	buf.Write([]byte{byte(TagEnd), 0, 0, 0, 0})
+
+	if _, _, err := NewNetworkDecoder(&buf).ReadTag(); !errors.Is(err, ErrMaxDepth) {
+		t.Fatalf("expected ErrMaxDepth, got %v", err)
+	}
+}
+
+func TestNegativeLength(t *testing.T) {
+	data := []byte{byte(TagIntArray), 0xFF, 0xFF, 0xFF, 0xFF}
+	if _, _, err := NewNetworkDecoder(bytes.NewReader(data)).ReadTag(); !errors.Is(err, ErrInvalidLength) {
+		t.Fatalf("expected ErrInvalidLength, got %v", err)
+	}
+}
+
+type Base struct {
+	ID string `nbt:"id"`
+}
+
+type dimension struct {
+	Base
+	Height      int32             `nbt:"height"`
+	Natural     bool              `nbt:"natural"`
+	Scale       float64           `nbt:"coordinate_scale"`
+	FixedTime   *int64            `nbt:"fixed_time,omitempty"`
+	Effects     string            `nbt:"effects,omitempty"`
+	Seeds       []int64           `nbt:"seeds"`
+	Tags        []string          `nbt:"tags"`
+	Properties  map[string]string `nbt:"properties"`
+	Raw         Tag               `nbt:"raw"`
+	Ignored     string            `nbt:"-"`
+	Unannotated uint8
+}
+
+func TestMarshalStruct(t *testing.T) {
+	fixed := int64(6000)
+	in := dimension{
+		Base:        Base{ID: "minecraft:overworld"},
+		Height:      384,
+		Natural:     true,
+		Scale:       1,
+		FixedTime:   &fixed,
+		Seeds:       []int64{1, 2},
+		Tags:        []string{"a", "b"},
+		Properties:  map[string]string{"k": "v"},
+		Raw:         Compound{"x": Byte(1)},
+		Ignored:     "skip me",
+		Unannotated: 200,
+	}
+
+	tag, err := ToTag(in)
+	if err != nil {
+		t.Fatalf("ToTag: %v", err)
+	}
+
+	compound := tag.(Compound)
+	if compound["id"] != String("minecraft:overworld") || compound["natural"] != Byte(1) || compound["fixed_time"] != Long(6000) {
+		t.Fatalf("unexpected compound %s", Stringify(compound))
+	}
+	if _, ok := compound["effects"]; ok {
+		t.Fatal("omitempty field was written")
+	}
+	if _, ok := compound["Ignored"]; ok {
+		t.Fatal("skipped field was written")
+	}
+	if compound["Unannotated"] != Byte(-56) {
+		t.Fatalf("expected field name fallback, got %s", Stringify(compound))
+	}
+
+	data, err := Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var out dimension
+	if err := Unmarshal(data, &out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	in.Ignored = ""
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("struct round trip mismatch\n%+v\n%+v", in, out)
+	}
+}
+
+func TestUnmarshalConversions(t *testing.T) {
+	var out struct {
+		Wide   int64   `nbt:"wide"`
+		Narrow int8    `nbt:"narrow"`
+		Float  float64 `nbt:"float"`
+		Any    any     `nbt:"any"`
+		Ints   []int   `nbt:"ints"`
+	}
+
+	tag := Compound{
+		"wide":   Byte(7),
+		"narrow": Int(8),
+		"float":  Int(3),
+		"any":    &List{ElementType: TagShort, Elements: []Tag{Short(1)}},
+		"ints":   IntArray{4, 5},
+	}
+
+	if err := FromTag(tag, &out); err != nil {
+		t.Fatalf("FromTag: %v", err)
+	}
+
+	if out.Wide != 7 || out.Narrow != 8 || out.Float != 3 || !reflect.DeepEqual(out.Any, []any{int16(1)}) || !reflect.DeepEqual(out.Ints, []int{4, 5}) {
+		t.Fatalf("unexpected result %+v", out)
+	}
+
+	if err := FromTag(Compound{"narrow": Int(1000)}, &out); err == nil {
+		t.Fatal("expected overflow error")
+	}
+
+	if err := FromTag(Compound{"wide": String("x")}, &out); err == nil {
+		t.Fatal("expected type mismatch error")
+	}
+}
+
+func TestStringify(t *testing.T) {
+	tag := Compound{
+		"b":        Byte(1),
+		"f":        Float(2),
+		"d":        Double(0.5),
+		"l":        Long(3),
+		"s":        String(`say "hi"`),
+		"arr":      ByteArray{1, 0xFF},
+		"ints":     IntArray{1, 2},
+		"list":     &List{ElementType: TagShort, Elements: []Tag{Short(1), Short(2)}},
+		"odd key!": LongArray{4},
+	}
+
+	expected := `{arr:[B;1B,-1B],b:1b,d:0.5d,f:2.0f,ints:[I;1,2],l:3L,list:[1s,2s],"odd key!":[L;4L],s:'say "hi"'}`
+	if got := Stringify(tag); got != expected {
+		t.Fatalf("expected %s, got %s", expected, got)
+	}
+}
+
+func TestReadFileCompressed(t *testing.T) {
+	dir := t.TempDir()
+
+	var gz bytes.Buffer
+	gw := gzip.NewWriter(&gz)
+	_, _ = gw.Write(helloWorld)
+	_ = gw.Close()
+
+	var zl bytes.Buffer
+	zw := zlib.NewWriter(&zl)
+	_, _ = zw.Write(helloWorld)
+	_ = zw.Close()
+
+	for name, data := range map[string][]byte{"raw.dat": helloWorld, "gzip.dat": gz.Bytes(), "zlib.dat": zl.Bytes()} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		rootName, tag, err := ReadFile(path)
+		if err != nil {
+			t.Fatalf("%s: ReadFile: %v", name, err)
+		}
+
+		if rootName != "hello world" || !reflect.DeepEqual(tag, Compound{"name": String("Bananrama")}) {
+			t.Fatalf("%s: unexpected result %q %s", name, rootName, Stringify(tag))
+		}
+	}
+}
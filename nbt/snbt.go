@@ -0,0 +1,138 @@
+package nbt
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Stringify renders tag as SNBT, the format used by /data and command
+// arguments. Compound keys are sorted so the output is stable.
+func Stringify(tag Tag) string {
+	var sb strings.Builder
+	writeSNBT(&sb, tag)
+	return sb.String()
+}
+
+func writeSNBT(sb *strings.Builder, tag Tag) {
+	switch t := tag.(type) {
+	case nil:
+		sb.WriteString("END")
+	case Byte:
+		sb.WriteString(strconv.FormatInt(int64(t), 10))
+		sb.WriteByte('b')
+	case Short:
+		sb.WriteString(strconv.FormatInt(int64(t), 10))
+		sb.WriteByte('s')
+	case Int:
+		sb.WriteString(strconv.FormatInt(int64(t), 10))
+	case Long:
+		sb.WriteString(strconv.FormatInt(int64(t), 10))
+		sb.WriteByte('L')
+	case Float:
+		sb.WriteString(formatFloat(float64(t), 32))
+		sb.WriteByte('f')
+	case Double:
+		sb.WriteString(formatFloat(float64(t), 64))
+		sb.WriteByte('d')
+	case String:
+		sb.WriteString(quoteSNBT(string(t)))
+	case ByteArray:
+		sb.WriteString("[B;")
+		for i, b := range t {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			sb.WriteString(strconv.Itoa(int(int8(b))))
+			sb.WriteByte('B')
+		}
+		sb.WriteByte(']')
+	case IntArray:
+		sb.WriteString("[I;")
+		for i, v := range t {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			sb.WriteString(strconv.FormatInt(int64(v), 10))
+		}
+		sb.WriteByte(']')
+	case LongArray:
+		sb.WriteString("[L;")
+		for i, v := range t {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			sb.WriteString(strconv.FormatInt(v, 10))
+			sb.WriteByte('L')
+		}
+		sb.WriteByte(']')
+	case *List:
+		sb.WriteByte('[')
+		for i, element := range t.Elements {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			writeSNBT(sb, element)
+		}
+		sb.WriteByte(']')
+	case Compound:
+		sb.WriteByte('{')
+		for i, key := range t.Keys() {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			if isBareKey(key) {
+				sb.WriteString(key)
+			} else {
+				sb.WriteString(quoteSNBT(key))
+			}
+			sb.WriteByte(':')
+			writeSNBT(sb, t[key])
+		}
+		sb.WriteByte('}')
+	}
+}
+
+func formatFloat(f float64, bits int) string {
+	s := strconv.FormatFloat(f, 'g', -1, bits)
+	if !strings.ContainsAny(s, ".eEnI") {
+		s += ".0"
+	}
+	return s
+}
+
+func isBareKey(key string) bool {
+	if key == "" {
+		return false
+	}
+
+	for _, r := range key {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '_', r == '-', r == '.', r == '+':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// quoteSNBT picks whichever quote needs no escaping, preferring double
+// quotes, as the vanilla writer does.
+func quoteSNBT(s string) string {
+	quote := byte('"')
+	if strings.IndexByte(s, '"') >= 0 && strings.IndexByte(s, '\'') < 0 {
+		quote = '\''
+	}
+
+	var sb strings.Builder
+	sb.WriteByte(quote)
+	for i := 0; i < len(s); i++ {
+		if s[i] == quote || s[i] == '\\' {
+			sb.WriteByte('\\')
+		}
+		sb.WriteByte(s[i])
+	}
+	sb.WriteByte(quote)
+	return sb.String()
+}
@@ -0,0 +1,169 @@
+package nbt
+
+import (
+	"fmt"
+	"sort"
+)
+
+type TagType byte
+
+const (
+	TagEnd TagType = iota
+	TagByte
+	TagShort
+	TagInt
+	TagLong
+	TagFloat
+	TagDouble
+	TagByteArray
+	TagString
+	TagList
+	TagCompound
+	TagIntArray
+	TagLongArray
+)
+
+var tagNames = [...]string{
+	TagEnd:       "TAG_End",
+	TagByte:      "TAG_Byte",
+	TagShort:     "TAG_Short",
+	TagInt:       "TAG_Int",
+	TagLong:      "TAG_Long",
+	TagFloat:     "TAG_Float",
+	TagDouble:    "TAG_Double",
+	TagByteArray: "TAG_Byte_Array",
+	TagString:    "TAG_String",
+	TagList:      "TAG_List",
+	TagCompound:  "TAG_Compound",
+	TagIntArray:  "TAG_Int_Array",
+	TagLongArray: "TAG_Long_Array",
+}
+
+func (t TagType) String() string {
+	if int(t) < len(tagNames) {
+		return tagNames[t]
+	}
+	return fmt.Sprintf("TAG_Unknown(%d)", byte(t))
+}
+
+// Tag is a node in an NBT tree. The concrete types below are the only
+// implementations.
+type Tag interface {
+	Type() TagType
+}
+
+type (
+	Byte      int8
+	Short     int16
+	Int       int32
+	Long      int64
+	Float     float32
+	Double    float64
+	ByteArray []byte
+	String    string
+	IntArray  []int32
+	LongArray []int64
+	Compound  map[string]Tag
+)
+
+// List is a homogeneous sequence of tags. ElementType is kept so that empty
+// lists round-trip with the type they were read with.
+type List struct {
+	ElementType TagType
+	Elements    []Tag
+}
+
+func (Byte) Type() TagType      { return TagByte }
+func (Short) Type() TagType     { return TagShort }
+func (Int) Type() TagType       { return TagInt }
+func (Long) Type() TagType      { return TagLong }
+func (Float) Type() TagType     { return TagFloat }
+func (Double) Type() TagType    { return TagDouble }
+func (ByteArray) Type() TagType { return TagByteArray }
+func (String) Type() TagType    { return TagString }
+func (*List) Type() TagType     { return TagList }
+func (Compound) Type() TagType  { return TagCompound }
+func (IntArray) Type() TagType  { return TagIntArray }
+func (LongArray) Type() TagType { return TagLongArray }
+
+func (t Byte) String() string      { return Stringify(t) }
+func (t Short) String() string     { return Stringify(t) }
+func (t Int) String() string       { return Stringify(t) }
+func (t Long) String() string      { return Stringify(t) }
+func (t Float) String() string     { return Stringify(t) }
+func (t Double) String() string    { return Stringify(t) }
+func (t ByteArray) String() string { return Stringify(t) }
+func (t *List) String() string     { return Stringify(t) }
+func (t Compound) String() string  { return Stringify(t) }
+func (t IntArray) String() string  { return Stringify(t) }
+func (t LongArray) String() string { return Stringify(t) }
+
+// NewList builds a list from elements, which must all share a type.
+func NewList(elements ...Tag) (*List, error) {
+	list := &List{ElementType: TagEnd, Elements: elements}
+
+	for i, element := range elements {
+		if i == 0 {
+			list.ElementType = element.Type()
+			continue
+		}
+
+		if element.Type() != list.ElementType {
+			return nil, fmt.Errorf("nbt: list element %d is %s, expected %s", i, element.Type(), list.ElementType)
+		}
+	}
+
+	return list, nil
+}
+
+// Keys returns the compound's keys in sorted order.
+func (t Compound) Keys() []string {
+	keys := make([]string, 0, len(t))
+	for key := range t {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Value converts a tag tree into plain Go values: numbers keep their NBT
+// width (int8, int16, ...), lists become []any and compounds
+// map[string]any.
+func Value(tag Tag) any {
+	switch t := tag.(type) {
+	case Byte:
+		return int8(t)
+	case Short:
+		return int16(t)
+	case Int:
+		return int32(t)
+	case Long:
+		return int64(t)
+	case Float:
+		return float32(t)
+	case Double:
+		return float64(t)
+	case ByteArray:
+		return []byte(t)
+	case String:
+		return string(t)
+	case IntArray:
+		return []int32(t)
+	case LongArray:
+		return []int64(t)
+	case *List:
+		values := make([]any, len(t.Elements))
+		for i, element := range t.Elements {
+			values[i] = Value(element)
+		}
+		return values
+	case Compound:
+		values := make(map[string]any, len(t))
+		for key, element := range t {
+			values[key] = Value(element)
+		}
+		return values
+	}
+
+	return nil
+}
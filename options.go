@@ -2,9 +2,13 @@ package gophermc
 
 import (
 	"crypto/rsa"
+	"fmt"
+	"github.com/obeliskdev/gophermc/auth"
+	"github.com/obeliskdev/gophermc/component"
 	"github.com/obeliskdev/gophermc/protocol"
 	"github.com/google/uuid"
 	"net"
+	"time"
 )
 
 type ClientOption func(*Client)
@@ -18,28 +22,104 @@ func WithVersion(version protocol.Version) ClientOption {
 func WithTCPAddr(tcpAddr *net.TCPAddr) ClientOption {
 	return func(c *Client) {
 		c.addr = tcpAddr
+		c.host, c.port = "", 0
 	}
 }
 
+// WithAddr sets the server as typed into the multiplayer screen: a host name
+// or IP literal with an optional port. Host names are resolved by Connect,
+// following the _minecraft._tcp SRV record when no port is given. The host is
+// sent in the handshake. An invalid address makes NewClient fail.
 func WithAddr(addr string) ClientOption {
 	return func(c *Client) {
-		host, port, _ := net.SplitHostPort(addr)
-		if host == "" {
-			host = addr
+		host, port, err := parseAddr(addr)
+		if err != nil {
+			c.optionErr = fmt.Errorf("invalid address %q: %w", addr, err)
+			return
 		}
 
 		WithServerHostname(host)(c)
+		c.host, c.port = host, port
+		c.addr = nil
+	}
+}
 
-		if port == "" {
-			addr = net.JoinHostPort(addr, "25565")
-		}
+// WithResolver replaces the resolver WithAddr host names are looked up with.
+func WithResolver(resolver Resolver) ClientOption {
+	return func(c *Client) {
+		c.resolver = resolver
+	}
+}
 
-		tcpAddr, err := net.ResolveTCPAddr("tcp", addr)
-		if err != nil {
-			return
-		}
+// WithDialer routes the connection through dialer, such as a proxy.SOCKS5 or
+// proxy.HTTP. Host names given to WithAddr are passed to the dialer as is,
+// after any SRV lookup, so that the proxy resolves them.
+func WithDialer(dialer Dialer) ClientOption {
+	return func(c *Client) {
+		c.dialer = dialer
+	}
+}
+
+// WithSourceIP binds the connection to a local address. It has no effect
+// with WithDialer; bind the dialer itself instead, such as a proxy's Forward.
+func WithSourceIP(ip net.IP) ClientOption {
+	return func(c *Client) {
+		c.sourceIP = ip
+	}
+}
+
+// WithConnectTimeout limits resolving and dialing in Connect, including any
+// proxy handshake.
+func WithConnectTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.connectTimeout = timeout
+	}
+}
+
+// WithProxyHeader sends a PROXY protocol header before the handshake, for
+// servers behind a load balancer that expect one. Source and Destination
+// default to the connection's own addresses.
+func WithProxyHeader(header protocol.ProxyHeader) ClientOption {
+	return func(c *Client) {
+		c.proxyHeader = &header
+	}
+}
+
+// WithBungeeCordForwarding logs in the way BungeeCord does with ip_forward
+// enabled, for backend servers expecting it. clientIP and properties are
+// forwarded as the player's address and profile.
+func WithBungeeCordForwarding(clientIP string, properties ...protocol.Property) ClientOption {
+	return func(c *Client) {
+		c.forwarding = &forwarding{bungeeCord: true, clientIP: clientIP, properties: properties}
+	}
+}
+
+// WithVelocityForwarding answers Velocity's modern forwarding request with
+// data signed by secret, the proxy's forwarding secret.
+func WithVelocityForwarding(secret []byte, clientIP string, properties ...protocol.Property) ClientOption {
+	return func(c *Client) {
+		c.forwarding = &forwarding{velocitySecret: secret, clientIP: clientIP, properties: properties}
+		c.HandleLoginPlugin(protocol.VelocityPlayerInfoChannel, c.velocityPlayerInfo)
+	}
+}
+
+// WithForge makes the client join Forge servers, claiming to have mods.
+// With no mods, FML2 and FML3 servers are answered with their own mod list;
+// FML1 servers (before 1.13) are sent an empty one, so declare their mods,
+// for example from StatusResponse.ForgeMods. Forge from 1.20.2 negotiates in
+// the configuration state and is not supported.
+func WithForge(mods ...protocol.ForgeMod) ClientOption {
+	return func(c *Client) {
+		c.forge = &forge{mods: mods}
+		c.HandleLoginPlugin(protocol.ForgeLoginWrapperChannel, c.forgeLoginWrapper)
+	}
+}
 
-		WithTCPAddr(tcpAddr)(c)
+// WithLoginPluginHandler registers handler for login plugin requests on
+// channel. See Client.HandleLoginPlugin.
+func WithLoginPluginHandler(channel string, handler LoginPluginHandler) ClientOption {
+	return func(c *Client) {
+		c.HandleLoginPlugin(channel, handler)
 	}
 }
 
@@ -49,6 +129,22 @@ func WithServerHostname(serverHostname string) ClientOption {
 	}
 }
 
+// WithLegacyPingFallback makes Status retry with the legacy ping when the
+// server only understands the pre-1.7 protocol.
+func WithLegacyPingFallback(enabled bool) ClientOption {
+	return func(c *Client) {
+		c.legacyPingFallback = enabled
+	}
+}
+
+// WithQueryPort sets the UDP port used by Query when the server's
+// query.port differs from its game port.
+func WithQueryPort(port int) ClientOption {
+	return func(c *Client) {
+		c.queryPort = port
+	}
+}
+
 func WithBrand(brand string) ClientOption {
 	return func(c *Client) {
 		c.brand = brand
@@ -83,3 +179,64 @@ func WithUUID(uuid uuid.UUID) ClientOption {
 		c.uniqueId = uuid
 	}
 }
+
+// WithAuthenticator replaces the SessionAuthenticator used to join
+// online-mode servers.
+func WithAuthenticator(authenticator Authenticator) ClientOption {
+	return func(c *Client) {
+		c.authenticator = authenticator
+	}
+}
+
+// WithAccessToken sets the Minecraft access token used to join online-mode
+// servers and to fetch the chat signing key.
+func WithAccessToken(accessToken string) ClientOption {
+	return func(c *Client) {
+		c.accessToken = accessToken
+	}
+}
+
+// WithSessionServerURL overrides DefaultSessionServerURL for the default
+// authenticator.
+func WithSessionServerURL(url string) ClientOption {
+	return func(c *Client) {
+		c.sessionServerURL = url
+	}
+}
+
+// WithAccount logs in as account, such as one from auth.Client.Login,
+// setting the username, UUID and access token.
+func WithAccount(account *auth.Account) ClientOption {
+	return func(c *Client) {
+		c.username = account.Profile.Name
+		c.uniqueId = account.Profile.ID
+		c.accessToken = account.AccessToken
+	}
+}
+
+// WithPlayerKey sets the chat signing key instead of fetching one with the
+// access token.
+func WithPlayerKey(key *protocol.PlayerKey) ClientOption {
+	return func(c *Client) {
+		c.playerKey = key
+		if key != nil {
+			c.privateKey = key.PrivateKey
+		}
+	}
+}
+
+// WithCertificatesURL overrides the endpoint the chat signing key is fetched
+// from.
+func WithCertificatesURL(url string) ClientOption {
+	return func(c *Client) {
+		c.certificatesURL = url
+	}
+}
+
+// WithTranslations sets the language used to render chat events. The
+// bundled English table is used by default.
+func WithTranslations(lang *component.Translations) ClientOption {
+	return func(c *Client) {
+		c.translations = lang
+	}
+}
@@ -0,0 +1,114 @@
+package gophermc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/obeliskdev/gophermc/protocol"
+)
+
+// PluginMessageHandler handles the data of a plugin message received on the
+// channel it is registered for.
+type PluginMessageHandler func(data []byte)
+
+// RegisterChannel registers handler for plugin messages on channel and
+// announces the channel to the server, right away when connected or else on
+// entering the configuration or play state. Channels are namespaced from
+// 1.13, like "bungeecord:main". The handler may be nil when the messages
+// are only read as PluginMessageEvents.
+func (c *Client) RegisterChannel(channel string, handler PluginMessageHandler) error {
+	c.pluginChannelsMu.Lock()
+	if c.pluginChannels == nil {
+		c.pluginChannels = make(map[string]PluginMessageHandler)
+	}
+	c.pluginChannels[channel] = handler
+	c.pluginChannelsMu.Unlock()
+
+	if !c.canSendPluginMessage() {
+		return nil
+	}
+	return c.SendPluginMessage(c.registerChannel("register"), []byte(channel))
+}
+
+// UnregisterChannel removes the handler of channel and tells the server the
+// client stopped listening on it.
+func (c *Client) UnregisterChannel(channel string) error {
+	c.pluginChannelsMu.Lock()
+	_, ok := c.pluginChannels[channel]
+	delete(c.pluginChannels, channel)
+	c.pluginChannelsMu.Unlock()
+
+	if !ok || !c.canSendPluginMessage() {
+		return nil
+	}
+	return c.SendPluginMessage(c.registerChannel("unregister"), []byte(channel))
+}
+
+// SendPluginMessage sends data to the server on channel. The client must be
+// in the configuration or play state.
+func (c *Client) SendPluginMessage(channel string, data []byte) error {
+	if c.Conn == nil {
+		return fmt.Errorf("client not connected")
+	}
+	if !c.canSendPluginMessage() {
+		return errors.New("client is not in the configuration or play state")
+	}
+
+	return c.WritePacket(&protocol.ServerboundCustomPayload{
+		CustomPayloadData: protocol.CustomPayloadData{Channel: channel, Data: data},
+	})
+}
+
+func (c *Client) canSendPluginMessage() bool {
+	if c.Conn == nil {
+		return false
+	}
+	state := c.State()
+	return state == protocol.StateConfiguration || state == protocol.StatePlay
+}
+
+// registerChannel names the register and unregister channels, which lost
+// their upper case names in 1.13.
+func (c *Client) registerChannel(name string) string {
+	if c.version < protocol.V1_13 {
+		return strings.ToUpper(name)
+	}
+	return "minecraft:" + name
+}
+
+// announceChannels registers every channel with the server, once the
+// connection reaches a state plugin messages can be sent in.
+func (c *Client) announceChannels() error {
+	c.pluginChannelsMu.RLock()
+	channels := make([]string, 0, len(c.pluginChannels))
+	for channel := range c.pluginChannels {
+		channels = append(channels, channel)
+	}
+	c.pluginChannelsMu.RUnlock()
+
+	if len(channels) == 0 {
+		return nil
+	}
+	return c.SendPluginMessage(c.registerChannel("register"), []byte(strings.Join(channels, "\x00")))
+}
+
+// handlePluginMessage passes a plugin message to the handler of its channel
+// and emits it as a PluginMessageEvent.
+func (c *Client) handlePluginMessage(p *protocol.ClientboundCustomPayload) {
+	c.pluginChannelsMu.RLock()
+	handler := c.pluginChannels[p.Channel]
+	c.pluginChannelsMu.RUnlock()
+
+	if handler != nil {
+		handler(p.Data)
+	}
+
+	event := PluginMessageEvent{Channel: p.Channel, Data: p.Data, State: c.State()}
+	switch {
+	case c.eventChan != nil:
+		c.eventChan <- event
+	case c.queueEvents:
+		c.joinEvents = append(c.joinEvents, event)
+	}
+}
@@ -0,0 +1,159 @@
+package gophermc_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/obeliskdev/gophermc"
+	"github.com/obeliskdev/gophermc/protocol"
+)
+
+func TestPluginChannels(t *testing.T) {
+	for _, tc := range []struct {
+		version              protocol.Version
+		register, unregister string
+	}{
+		{protocol.V1_12_2, "REGISTER", "UNREGISTER"},
+		{protocol.V1_20_2, "minecraft:register", "minecraft:unregister"},
+	} {
+		t.Run(tc.version.String(), func(t *testing.T) {
+			var server *protocol.ServerConn
+			send := func(channel string, data string) error {
+				return server.WritePacket(&protocol.ClientboundCustomPayload{CustomPayloadData: protocol.CustomPayloadData{Channel: channel, Data: []byte(data)}})
+			}
+			expect := func(channel string, data string) error {
+				for {
+					packet, err := server.ReadPacket()
+					if err != nil {
+						return err
+					}
+					if _, ok := packet.(*protocol.ServerboundClientSettings); ok {
+						continue
+					}
+					p, ok := packet.(*protocol.ServerboundCustomPayload)
+					if !ok || p.Channel != channel || string(p.Data) != data {
+						return fmt.Errorf("expected %s %q, got %+v", channel, data, packet)
+					}
+					return nil
+				}
+			}
+
+			joined := make(chan error, 1)
+			addr, done := acceptLogin(t, func(conn *protocol.ServerConn, login *protocol.ServerboundLoginStart) error {
+				server = conn
+				if err := conn.WritePacket(&protocol.ClientboundLoginSuccess{Username: login.Username, UUID: protocol.OfflineUUID(login.Username)}); err != nil {
+					return err
+				}
+
+				if tc.version >= protocol.V1_20_2 {
+					if _, err := conn.ReadPacket(); err != nil {
+						return err
+					}
+					conn.SetState(protocol.StateConfiguration)
+
+					if err := expect(tc.register, "example:config"); err != nil {
+						return err
+					}
+					if err := send("example:config", "from config"); err != nil {
+						return err
+					}
+					if err := conn.WritePacket(&protocol.ClientboundFinishConfiguration{}); err != nil {
+						return err
+					}
+					if _, err := conn.ReadPacket(); err != nil {
+						return err
+					}
+					conn.SetState(protocol.StatePlay)
+				} else {
+					conn.SetState(protocol.StatePlay)
+					if err := expect(tc.register, "example:config"); err != nil {
+						return err
+					}
+				}
+				joined <- nil
+
+				if err := expect(tc.register, "example:play"); err != nil {
+					return err
+				}
+				if err := expect("example:play", "hello"); err != nil {
+					return err
+				}
+				if err := send("example:play", "from play"); err != nil {
+					return err
+				}
+				return expect(tc.unregister, "example:play")
+			})
+
+			configured := make(chan []byte, 1)
+			client, err := gophermc.NewClient(gophermc.WithAddr(addr), gophermc.WithVersion(tc.version))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Destroy()
+
+			if err := client.RegisterChannel("example:config", func(data []byte) { configured <- data }); err != nil {
+				t.Fatal(err)
+			}
+			if err := client.SendPluginMessage("example:config", nil); err == nil {
+				t.Fatal("expected sending before joining to fail")
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			events, err := client.JoinAndListen(ctx, 16)
+			if err != nil {
+				t.Fatal(err)
+			}
+			select {
+			case <-joined:
+			case err := <-done:
+				t.Fatalf("server stopped early: %v", err)
+			}
+
+			if tc.version >= protocol.V1_20_2 {
+				if data := <-configured; string(data) != "from config" {
+					t.Fatalf("unexpected configuration message %q", data)
+				}
+			}
+
+			if err := client.RegisterChannel("example:play", nil); err != nil {
+				t.Fatal(err)
+			}
+			if err := client.SendPluginMessage("example:play", []byte("hello")); err != nil {
+				t.Fatal(err)
+			}
+
+			// Messages received during configuration, before JoinAndListen
+			// returned, are emitted too.
+			want := []gophermc.PluginMessageEvent{{Channel: "example:play", Data: []byte("from play"), State: protocol.StatePlay}}
+			if tc.version >= protocol.V1_20_2 {
+				want = append([]gophermc.PluginMessageEvent{{Channel: "example:config", Data: []byte("from config"), State: protocol.StateConfiguration}}, want...)
+			}
+			for event := range events {
+				e, ok := event.(gophermc.PluginMessageEvent)
+				if !ok {
+					continue
+				}
+				if e.Channel != want[0].Channel || !bytes.Equal(e.Data, want[0].Data) || e.State != want[0].State {
+					t.Fatalf("expected %+v, got %+v", want[0], e)
+				}
+				if want = want[1:]; len(want) == 0 {
+					break
+				}
+			}
+			if len(want) != 0 {
+				t.Fatalf("events closed before %+v", want[0])
+			}
+
+			if err := client.UnregisterChannel("example:play"); err != nil {
+				t.Fatal(err)
+			}
+			if err := <-done; err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
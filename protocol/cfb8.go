@@ -0,0 +1,55 @@
+package protocol
+
+import "crypto/cipher"
+
+type cfb8 struct {
+	block   cipher.Block
+	iv      []byte
+	tmp     []byte
+	decrypt bool
+}
+
+// NewCFB8Encrypter returns a cipher.Stream implementing the 8-bit cipher
+// feedback mode used by the Minecraft protocol once encryption is enabled.
+func NewCFB8Encrypter(block cipher.Block, iv []byte) cipher.Stream {
+	return newCFB8(block, iv, false)
+}
+
+// NewCFB8Decrypter returns a cipher.Stream decrypting what a
+// NewCFB8Encrypter stream with the same key and IV encrypted.
+func NewCFB8Decrypter(block cipher.Block, iv []byte) cipher.Stream {
+	return newCFB8(block, iv, true)
+}
+
+func newCFB8(block cipher.Block, iv []byte, decrypt bool) *cfb8 {
+	if len(iv) != block.BlockSize() {
+		panic("protocol: cfb8 IV length must equal block size")
+	}
+
+	return &cfb8{
+		block:   block,
+		iv:      append([]byte(nil), iv...),
+		tmp:     make([]byte, block.BlockSize()),
+		decrypt: decrypt,
+	}
+}
+
+func (x *cfb8) XORKeyStream(dst, src []byte) {
+	if len(dst) < len(src) {
+		panic("protocol: cfb8 output smaller than input")
+	}
+
+	last := len(x.iv) - 1
+	for i, in := range src {
+		x.block.Encrypt(x.tmp, x.iv)
+		out := in ^ x.tmp[0]
+		dst[i] = out
+
+		copy(x.iv, x.iv[1:])
+		if x.decrypt {
+			x.iv[last] = in
+		} else {
+			x.iv[last] = out
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package protocol
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"net"
+	"testing"
+)
+
+func TestCFB8KnownAnswer(t *testing.T) {
+	key, _ := hex.DecodeString("2b7e151628aed2a6abf7158809cf4f3c")
+	iv, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	plaintext, _ := hex.DecodeString("6bc1bee22e409f96e93d7e117393172aae2d")
+	expected, _ := hex.DecodeString("3b79424c9c0dd436bace9e0ed4586a4f32b9")
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatalf("aes.NewCipher failed: %v", err)
+	}
+
+	ciphertext := make([]byte, len(plaintext))
+	NewCFB8Encrypter(block, iv).XORKeyStream(ciphertext, plaintext)
+	if !bytes.Equal(ciphertext, expected) {
+		t.Fatalf("unexpected ciphertext: %x", ciphertext)
+	}
+
+	decrypted := make([]byte, len(ciphertext))
+	NewCFB8Decrypter(block, iv).XORKeyStream(decrypted, ciphertext)
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Fatalf("unexpected plaintext: %x", decrypted)
+	}
+}
+
+func TestCFB8StreamingInPlace(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	block, _ := aes.NewCipher(key)
+
+	plaintext := []byte("the quick brown fox jumps over the lazy dog")
+	ciphertext := make([]byte, len(plaintext))
+	NewCFB8Encrypter(block, key).XORKeyStream(ciphertext, plaintext)
+
+	dec := NewCFB8Decrypter(block, key)
+	buf := append([]byte(nil), ciphertext...)
+	for i := 0; i < len(buf); i += 7 {
+		end := min(i+7, len(buf))
+		dec.XORKeyStream(buf[i:end], buf[i:end])
+	}
+
+	if !bytes.Equal(buf, plaintext) {
+		t.Fatalf("chunked in-place decryption mismatch: %q", buf)
+	}
+}
+
+// recordConn records what is written to it.
+type recordConn struct {
+	net.Conn
+	written bytes.Buffer
+}
+
+func (c *recordConn) Write(p []byte) (int, error) { return c.written.Write(p) }
+
+func TestConnEncryptionKeepsBufferedBytes(t *testing.T) {
+	secret := []byte("0123456789abcdef")
+
+	out := &recordConn{}
+	client := NewConn(out, Latest)
+	for _, p := range []Packet{
+		&ServerboundHandshake{ProtocolVersion: Latest.Protocol(), ServerAddress: "localhost", ServerPort: 25565, NextState: StateLogin},
+		&ServerboundLoginStart{Username: "Steve"},
+		&ServerboundLoginAcknowledged{},
+	} {
+		if _, ok := p.(*ServerboundLoginAcknowledged); ok {
+			if err := client.EnableEncryption(secret); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := client.WritePacket(p); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := p.(*ServerboundHandshake); ok {
+			client.SetState(StateLogin)
+		}
+	}
+
+	// Everything arrives in the read that looks for a legacy ping, so the
+	// encrypted packet is buffered before encryption is enabled.
+	local, remote := net.Pipe()
+	defer local.Close()
+	go func() {
+		_, _ = remote.Write(out.written.Bytes())
+		_ = remote.Close()
+	}()
+
+	server := NewServerConn(local)
+	if _, err := server.ReadHandshake(); err != nil {
+		t.Fatal(err)
+	}
+	if packet, err := server.ReadPacket(); err != nil {
+		t.Fatal(err)
+	} else if _, ok := packet.(*ServerboundLoginStart); !ok {
+		t.Fatalf("expected login start, got %T", packet)
+	}
+
+	if err := server.EnableEncryption(secret); err != nil {
+		t.Fatal(err)
+	}
+	if packet, err := server.ReadPacket(); err != nil {
+		t.Fatal(err)
+	} else if _, ok := packet.(*ServerboundLoginAcknowledged); !ok {
+		t.Fatalf("expected login acknowledged, got %T", packet)
+	}
+}
@@ -0,0 +1,292 @@
+package protocol
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/obeliskdev/fastrand"
+)
+
+const (
+	lastSeenCapacity = 20
+	// lastSeenListCapacity is the number of senders 1.19.1 and 1.19.2
+	// acknowledge.
+	lastSeenListCapacity = 5
+)
+
+// PlayerKey is the Mojang-certified chat signing key pair of a player.
+type PlayerKey struct {
+	PrivateKey *rsa.PrivateKey
+	// PublicKey is the DER encoded (X.509 SubjectPublicKeyInfo) public key.
+	PublicKey []byte
+	ExpiresAt time.Time
+
+	Signature   []byte
+	SignatureV2 []byte
+}
+
+func (k *PlayerKey) signatureFor(v Version) []byte {
+	if v >= V1_19_2 && k.SignatureV2 != nil {
+		return k.SignatureV2
+	}
+	return k.Signature
+}
+
+// SignVerifyToken signs the login verify token with salt, which 1.19 to
+// 1.19.2 clients holding a key send instead of the encrypted token.
+func (k *PlayerKey) SignVerifyToken(token []byte, salt int64) ([]byte, error) {
+	data := binary.BigEndian.AppendUint64(append([]byte(nil), token...), uint64(salt))
+
+	hash := sha256.Sum256(data)
+	signature, err := rsa.SignPKCS1v15(fastrand.FastReader, k.PrivateKey, crypto.SHA256, hash[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign verify token: %w", err)
+	}
+
+	return signature, nil
+}
+
+// LastSeenTracker keeps the window of signed chat messages the client has
+// received, mirroring the acknowledgement state the server validates.
+type LastSeenTracker struct {
+	mu       sync.Mutex
+	entries  [lastSeenCapacity][]byte
+	tail     int
+	offset   int32
+	lastSeen []byte
+}
+
+// Track records a received message signature and returns the number of
+// messages not yet acknowledged to the server.
+func (t *LastSeenTracker) Track(signature []byte) int32 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if signature == nil || string(signature) == string(t.lastSeen) {
+		return t.offset
+	}
+
+	t.lastSeen = signature
+	t.entries[t.tail] = signature
+	t.tail = (t.tail + 1) % lastSeenCapacity
+	t.offset++
+
+	return t.offset
+}
+
+func (t *LastSeenTracker) TakeOffset() int32 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	offset := t.offset
+	t.offset = 0
+	return offset
+}
+
+type LastSeenUpdate struct {
+	Offset       int32
+	Acknowledged [3]byte
+	Checksum     byte
+	Signatures   [][]byte
+}
+
+func (t *LastSeenTracker) Update() LastSeenUpdate {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	update := LastSeenUpdate{Offset: t.offset}
+	t.offset = 0
+
+	for i := 0; i < lastSeenCapacity; i++ {
+		entry := t.entries[(t.tail+i)%lastSeenCapacity]
+		if entry == nil {
+			continue
+		}
+
+		update.Acknowledged[i/8] |= 1 << (i % 8)
+		update.Signatures = append(update.Signatures, entry)
+	}
+
+	update.Checksum = lastSeenChecksum(update.Signatures)
+
+	return update
+}
+
+func lastSeenChecksum(signatures [][]byte) byte {
+	var sum int32 = 1
+	for _, signature := range signatures {
+		var hash int32 = 1
+		for _, b := range signature {
+			hash = 31*hash + int32(int8(b))
+		}
+		sum = 31*sum + hash
+	}
+
+	if b := byte(sum); b != 0 {
+		return b
+	}
+	return 1
+}
+
+// LastSeenMessage is a signed message acknowledged on 1.19.1 and 1.19.2,
+// identified by its sender and header signature.
+type LastSeenMessage struct {
+	Sender    uuid.UUID
+	Signature []byte
+}
+
+// LastSeenList keeps the 1.19.1-1.19.2 acknowledgement state: the last
+// message of each of the most recent senders, newest first, and the last
+// message received since the previous acknowledgement.
+type LastSeenList struct {
+	mu           sync.Mutex
+	entries      []LastSeenMessage
+	lastReceived *LastSeenMessage
+	pending      int32
+}
+
+// Track records a received message and returns the number of messages not
+// yet acknowledged to the server.
+func (l *LastSeenList) Track(sender uuid.UUID, signature []byte) int32 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if signature == nil {
+		return l.pending
+	}
+
+	entry := LastSeenMessage{Sender: sender, Signature: signature}
+	entries := make([]LastSeenMessage, 1, lastSeenListCapacity)
+	entries[0] = entry
+	for _, e := range l.entries {
+		if e.Sender != sender && len(entries) < lastSeenListCapacity {
+			entries = append(entries, e)
+		}
+	}
+
+	l.entries = entries
+	l.lastReceived = &entry
+	l.pending++
+
+	return l.pending
+}
+
+// Update returns the acknowledgement to send with the next chat message or
+// ServerboundMessageAcknowledgement, and resets the pending count.
+func (l *LastSeenList) Update() (lastSeen []LastSeenMessage, lastReceived *LastSeenMessage) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	lastSeen, lastReceived = slices.Clone(l.entries), l.lastReceived
+	l.lastReceived = nil
+	l.pending = 0
+
+	return lastSeen, lastReceived
+}
+
+// MessageChain signs chat messages for 1.19.1 and 1.19.2, where the header
+// of each message refers to the signature of the player's previous one.
+type MessageChain struct {
+	Key *PlayerKey
+
+	mu       sync.Mutex
+	previous []byte
+}
+
+func NewMessageChain(key *PlayerKey) *MessageChain {
+	return &MessageChain{Key: key}
+}
+
+// Sign signs p, carrying its LastSeen acknowledgements, as the next message
+// of the chain. Messages must be sent in the order they are signed.
+func (c *MessageChain) Sign(sender uuid.UUID, p *ServerboundChatMessage) error {
+	body := sha256.New()
+	_ = binary.Write(body, binary.BigEndian, p.Salt)
+	_ = binary.Write(body, binary.BigEndian, p.Timestamp.Unix())
+	// The parts of the body are separated by the byte 70.
+	body.Write([]byte(p.Message))
+	body.Write([]byte{'F'})
+	for _, entry := range p.LastSeen {
+		body.Write([]byte{'F'})
+		body.Write(entry.Sender[:])
+		body.Write(entry.Signature)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := make([]byte, 0, len(c.previous)+16+sha256.Size)
+	data = append(data, c.previous...)
+	data = append(data, sender[:]...)
+	data = body.Sum(data)
+
+	hash := sha256.Sum256(data)
+	signature, err := rsa.SignPKCS1v15(fastrand.FastReader, c.Key.PrivateKey, crypto.SHA256, hash[:])
+	if err != nil {
+		return fmt.Errorf("failed to sign chat message: %w", err)
+	}
+
+	p.Signature = signature
+	c.previous = signature
+	return nil
+}
+
+// ChatSession signs chat messages for the 1.19.3+ message chain.
+type ChatSession struct {
+	ID  uuid.UUID
+	Key *PlayerKey
+
+	mu    sync.Mutex
+	index int32
+}
+
+func NewChatSession(key *PlayerKey) *ChatSession {
+	return &ChatSession{ID: uuid.New(), Key: key}
+}
+
+func (s *ChatSession) UpdatePacket(v Version) *ServerboundChatSessionUpdate {
+	return &ServerboundChatSessionUpdate{
+		SessionID:    s.ID,
+		ExpiresAt:    s.Key.ExpiresAt.UnixMilli(),
+		PublicKey:    s.Key.PublicKey,
+		KeySignature: s.Key.signatureFor(v),
+	}
+}
+
+func (s *ChatSession) Sign(sender uuid.UUID, p *ServerboundChatMessage, lastSeen [][]byte) error {
+	s.mu.Lock()
+	index := s.index
+	s.index++
+	s.mu.Unlock()
+
+	message := []byte(p.Message)
+	data := make([]byte, 0, 4+16+16+4+8+8+4+len(message)+4+len(lastSeen)*256)
+	data = binary.BigEndian.AppendUint32(data, 1)
+	data = append(data, sender[:]...)
+	data = append(data, s.ID[:]...)
+	data = binary.BigEndian.AppendUint32(data, uint32(index))
+	data = binary.BigEndian.AppendUint64(data, uint64(p.Salt))
+	data = binary.BigEndian.AppendUint64(data, uint64(p.Timestamp.Unix()))
+	data = binary.BigEndian.AppendUint32(data, uint32(len(message)))
+	data = append(data, message...)
+	data = binary.BigEndian.AppendUint32(data, uint32(len(lastSeen)))
+	for _, signature := range lastSeen {
+		data = append(data, signature...)
+	}
+
+	hash := sha256.Sum256(data)
+	signature, err := rsa.SignPKCS1v15(fastrand.FastReader, s.Key.PrivateKey, crypto.SHA256, hash[:])
+	if err != nil {
+		return fmt.Errorf("failed to sign chat message: %w", err)
+	}
+
+	p.Signature = signature
+	return nil
+}
@@ -0,0 +1,236 @@
+package protocol
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestLastSeenTracker(t *testing.T) {
+	var tracker LastSeenTracker
+
+	for i := 0; i < 25; i++ {
+		tracker.Track([]byte{byte(i)})
+	}
+
+	if offset := tracker.Track([]byte{24}); offset != 25 {
+		t.Fatalf("duplicate signature should not be tracked, offset %d", offset)
+	}
+
+	update := tracker.Update()
+	if update.Offset != 25 {
+		t.Fatalf("expected offset 25, got %d", update.Offset)
+	}
+	if update.Acknowledged != [3]byte{0xFF, 0xFF, 0x0F} {
+		t.Fatalf("expected 20 acknowledged bits, got %08b", update.Acknowledged)
+	}
+	if len(update.Signatures) != 20 || update.Signatures[0][0] != 5 || update.Signatures[19][0] != 24 {
+		t.Fatalf("expected the 20 most recent signatures oldest first, got %v", update.Signatures)
+	}
+
+	if next := tracker.Update(); next.Offset != 0 || len(next.Signatures) != 20 {
+		t.Fatalf("expected offset to reset while keeping the window, got %+v", next)
+	}
+}
+
+func TestLastSeenChecksum(t *testing.T) {
+	if got := lastSeenChecksum(nil); got != 1 {
+		t.Fatalf("expected checksum 1 for empty window, got %d", got)
+	}
+
+	// 31*1 + (31*1 + -1) = 61
+	if got := lastSeenChecksum([][]byte{{0xFF}}); got != 61 {
+		t.Fatalf("expected checksum 61, got %d", got)
+	}
+}
+
+func TestChatSessionSign(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	session := NewChatSession(&PlayerKey{PrivateKey: key})
+	sender := uuid.New()
+	lastSeen := [][]byte{bytes.Repeat([]byte{7}, 256)}
+
+	for index := uint32(0); index < 2; index++ {
+		packet := &ServerboundChatMessage{Message: "hello", Timestamp: time.Unix(1700000000, 0), Salt: 42}
+		if err := session.Sign(sender, packet, lastSeen); err != nil {
+			t.Fatalf("Sign failed: %v", err)
+		}
+
+		var data bytes.Buffer
+		_ = binary.Write(&data, binary.BigEndian, int32(1))
+		data.Write(sender[:])
+		data.Write(session.ID[:])
+		_ = binary.Write(&data, binary.BigEndian, index)
+		_ = binary.Write(&data, binary.BigEndian, int64(42))
+		_ = binary.Write(&data, binary.BigEndian, int64(1700000000))
+		_ = binary.Write(&data, binary.BigEndian, int32(5))
+		data.WriteString("hello")
+		_ = binary.Write(&data, binary.BigEndian, int32(1))
+		data.Write(lastSeen[0])
+
+		hash := sha256.Sum256(data.Bytes())
+		if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, hash[:], packet.Signature); err != nil {
+			t.Fatalf("signature %d does not verify: %v", index, err)
+		}
+	}
+}
+
+func TestChatMessageSignature119(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	sender := uuid.New()
+	var buf bytes.Buffer
+	packet := &ServerboundChatMessage{Message: "a<b", PrivateKey: key, UUID: sender, Timestamp: time.Unix(1700000000, 0), Salt: 42}
+	if err := packet.Encode(&buf, V1_19); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	decoded := &ServerboundChatMessage{}
+	if err := decoded.Decode(&buf, V1_19); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	var data bytes.Buffer
+	_ = binary.Write(&data, binary.BigEndian, int64(42))
+	data.Write(sender[:])
+	_ = binary.Write(&data, binary.BigEndian, int64(1700000000))
+	data.WriteString(`{"text":"a<b"}`)
+
+	hash := sha256.Sum256(data.Bytes())
+	if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, hash[:], decoded.Signature); err != nil {
+		t.Fatalf("signature does not verify: %v", err)
+	}
+}
+
+func TestLastSeenList(t *testing.T) {
+	var list LastSeenList
+
+	senders := make([]uuid.UUID, 7)
+	for i := range senders {
+		senders[i] = uuid.New()
+		list.Track(senders[i], []byte{byte(i)})
+	}
+	if pending := list.Track(senders[5], []byte{7}); pending != 8 {
+		t.Fatalf("expected 8 pending messages, got %d", pending)
+	}
+
+	lastSeen, lastReceived := list.Update()
+	if lastReceived == nil || lastReceived.Sender != senders[5] || lastReceived.Signature[0] != 7 {
+		t.Fatalf("unexpected last received %+v", lastReceived)
+	}
+	want := []LastSeenMessage{
+		{senders[5], []byte{7}},
+		{senders[6], []byte{6}},
+		{senders[4], []byte{4}},
+		{senders[3], []byte{3}},
+		{senders[2], []byte{2}},
+	}
+	if !reflect.DeepEqual(lastSeen, want) {
+		t.Fatalf("expected the newest message of the last 5 senders, got %v", lastSeen)
+	}
+
+	if lastSeen, lastReceived := list.Update(); lastReceived != nil || len(lastSeen) != 5 {
+		t.Fatalf("expected last received to reset while keeping the list, got %v %v", lastSeen, lastReceived)
+	}
+}
+
+func TestMessageChainSign(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	chain := NewMessageChain(&PlayerKey{PrivateKey: key})
+	sender := uuid.New()
+	seen := LastSeenMessage{Sender: uuid.New(), Signature: bytes.Repeat([]byte{7}, 256)}
+
+	var previous []byte
+	for i := 0; i < 2; i++ {
+		packet := &ServerboundChatMessage{
+			Message:      "hello",
+			Timestamp:    time.Unix(1700000000, 0),
+			Salt:         42,
+			LastSeen:     []LastSeenMessage{seen},
+			LastReceived: &seen,
+		}
+		if err := chain.Sign(sender, packet); err != nil {
+			t.Fatalf("Sign failed: %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := packet.Encode(&buf, V1_19_2); err != nil {
+			t.Fatalf("Encode failed: %v", err)
+		}
+		decoded := &ServerboundChatMessage{}
+		if err := decoded.Decode(&buf, V1_19_2); err != nil {
+			t.Fatalf("Decode failed: %v", err)
+		}
+		if !reflect.DeepEqual(decoded.LastSeen, packet.LastSeen) || !reflect.DeepEqual(decoded.LastReceived, packet.LastReceived) {
+			t.Fatalf("acknowledgements not preserved: %+v", decoded)
+		}
+
+		var body bytes.Buffer
+		_ = binary.Write(&body, binary.BigEndian, int64(42))
+		_ = binary.Write(&body, binary.BigEndian, int64(1700000000))
+		body.WriteString("hello")
+		body.WriteByte(70)
+		body.WriteByte(70)
+		body.Write(seen.Sender[:])
+		body.Write(seen.Signature)
+		bodyHash := sha256.Sum256(body.Bytes())
+
+		header := append(append(append([]byte(nil), previous...), sender[:]...), bodyHash[:]...)
+		hash := sha256.Sum256(header)
+		if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, hash[:], decoded.Signature); err != nil {
+			t.Fatalf("signature %d does not verify: %v", i, err)
+		}
+		previous = decoded.Signature
+	}
+}
+
+func TestLoginStartPlayerKeyRoundTrip(t *testing.T) {
+	key := &PlayerKey{
+		PublicKey:   []byte{1, 2, 3},
+		ExpiresAt:   time.UnixMilli(1700000000000),
+		Signature:   []byte{4},
+		SignatureV2: []byte{5},
+	}
+	id := uuid.New()
+
+	for _, v := range []Version{V1_19, V1_19_2} {
+		var buf bytes.Buffer
+		if err := (&ServerboundLoginStart{Username: "Gopher", UUID: id, PlayerKey: key}).Encode(&buf, v); err != nil {
+			t.Fatalf("Encode failed: %v", err)
+		}
+
+		decoded := &ServerboundLoginStart{}
+		if err := decoded.Decode(&buf, v); err != nil {
+			t.Fatalf("Decode failed: %v", err)
+		}
+
+		if decoded.PlayerKey == nil || !bytes.Equal(decoded.PlayerKey.PublicKey, key.PublicKey) {
+			t.Fatalf("%s: player key not preserved: %+v", v, decoded.PlayerKey)
+		}
+		if !bytes.Equal(decoded.PlayerKey.signatureFor(v), key.signatureFor(v)) {
+			t.Fatalf("%s: wrong key signature variant", v)
+		}
+		if v == V1_19_2 && decoded.UUID != id {
+			t.Fatalf("%s: uuid not preserved", v)
+		}
+	}
+}
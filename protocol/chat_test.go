@@ -0,0 +1,236 @@
+package protocol
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/obeliskdev/gophermc/nbt"
+)
+
+func writeNetworkNBT(buf *bytes.Buffer, tag nbt.Tag) {
+	_ = nbt.NewNetworkEncoder(buf).WriteTag("", tag)
+}
+
+func TestReadChatComponentNBT(t *testing.T) {
+	var buf bytes.Buffer
+	writeNetworkNBT(&buf, nbt.Compound{
+		"translate": nbt.String("chat.type.text"),
+		"with": &nbt.List{ElementType: nbt.TagCompound, Elements: []nbt.Tag{
+			nbt.Compound{"text": nbt.String("Steve")},
+			nbt.Compound{"": nbt.String("hello")},
+		}},
+	})
+
+	chat, err := ReadChatComponent(&buf, V1_20_3)
+	if err != nil {
+		t.Fatalf("ReadChatComponent: %v", err)
+	}
+
+	if chat.Translate != "chat.type.text" || len(chat.With) != 2 {
+		t.Fatalf("unexpected component %+v", chat)
+	}
+	if chat.With[0].Text != "Steve" || chat.With[1].Text != "hello" {
+		t.Fatalf("unexpected arguments %+v", chat.With)
+	}
+
+	buf.Reset()
+	writeNetworkNBT(&buf, nbt.String("plain"))
+	if chat, err = ReadChatComponent(&buf, V1_21_5); err != nil || chat.Text != "plain" {
+		t.Fatalf("expected plain string component, got %+v (%v)", chat, err)
+	}
+}
+
+func TestClientboundChatMessageDecode(t *testing.T) {
+	sender := uuid.New()
+
+	var buf bytes.Buffer
+	_ = WriteString(&buf, `{"translate":"chat.type.text","with":["Steve","hi"]}`)
+	_ = WriteByte(&buf, ChatPositionChat)
+	buf.Write(sender[:])
+
+	var p ClientboundChatMessage
+	if err := p.Decode(&buf, V1_16); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if p.Sender != sender || p.Position != ChatPositionChat || p.Component.With[1].Text != "hi" {
+		t.Fatalf("unexpected packet %+v", p)
+	}
+
+	buf.Reset()
+	_ = WriteString(&buf, `"plain"`)
+	p = ClientboundChatMessage{}
+	if err := p.Decode(&buf, V1_7); err != nil || p.Component.Text != "plain" {
+		t.Fatalf("expected 1.7 chat to decode, got %+v (%v)", p, err)
+	}
+}
+
+func TestClientboundPlayerChatDecode119(t *testing.T) {
+	sender := uuid.New()
+
+	var buf bytes.Buffer
+	_ = WriteString(&buf, `{"text":"signed"}`)
+	_ = WriteBool(&buf, true)
+	_ = WriteString(&buf, `{"text":"unsigned"}`)
+	_ = WriteVarInt(&buf, 0)
+	buf.Write(sender[:])
+	_ = WriteString(&buf, `{"text":"Steve"}`)
+	_ = WriteBool(&buf, false)
+	_ = WriteLong(&buf, 1000)
+	_ = WriteLong(&buf, 42)
+	_ = WriteByteSlice(&buf, []byte{1, 2, 3})
+
+	var p ClientboundPlayerChat
+	if err := p.Decode(&buf, V1_19); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+
+	if p.Sender != sender || p.SenderName.Text != "Steve" || p.Salt != 42 || !p.Timestamp.Equal(time.UnixMilli(1000)) {
+		t.Fatalf("unexpected packet %+v", p)
+	}
+	if content := p.Content(); content.Text != "unsigned" {
+		t.Fatalf("expected unsigned content to take precedence, got %+v", content)
+	}
+	if !bytes.Equal(p.Signature, []byte{1, 2, 3}) || buf.Len() != 0 {
+		t.Fatalf("unexpected signature %v with %d bytes left", p.Signature, buf.Len())
+	}
+}
+
+func TestClientboundPlayerChatDecode1192(t *testing.T) {
+	sender := uuid.New()
+
+	var buf bytes.Buffer
+	_ = WriteBool(&buf, true)
+	_ = WriteByteSlice(&buf, []byte{9})
+	buf.Write(sender[:])
+	_ = WriteByteSlice(&buf, []byte{7, 7})
+	_ = WriteString(&buf, "hello")
+	_ = WriteBool(&buf, false)
+	_ = WriteLong(&buf, 1000)
+	_ = WriteLong(&buf, 5)
+	_ = WriteVarInt(&buf, 1)
+	buf.Write(sender[:])
+	_ = WriteByteSlice(&buf, []byte{9})
+	_ = WriteBool(&buf, false)
+	_ = WriteVarInt(&buf, 2)
+	_ = WriteVarInt(&buf, 1)
+	_ = WriteLong(&buf, 3)
+	_ = WriteVarInt(&buf, 1)
+	_ = WriteString(&buf, `{"text":"Steve"}`)
+	_ = WriteBool(&buf, true)
+	_ = WriteString(&buf, `{"text":"Alex"}`)
+
+	var p ClientboundPlayerChat
+	if err := p.Decode(&buf, V1_19_2); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+
+	if p.Message != "hello" || p.ChatType != 1 || p.FilterType != 2 || p.TargetName == nil || p.TargetName.Text != "Alex" {
+		t.Fatalf("unexpected packet %+v", p)
+	}
+	if content := p.Content(); content.Text != "hello" || buf.Len() != 0 {
+		t.Fatalf("unexpected content %+v with %d bytes left", content, buf.Len())
+	}
+}
+
+func TestClientboundPlayerChatDecodeChained(t *testing.T) {
+	sender := uuid.New()
+	signature := bytes.Repeat([]byte{0xAB}, 256)
+
+	write := func(v Version, inlineType bool) *bytes.Buffer {
+		var buf bytes.Buffer
+		if v >= V1_21_5 {
+			_ = WriteVarInt(&buf, 12)
+		}
+		buf.Write(sender[:])
+		_ = WriteVarInt(&buf, 3)
+		_ = WriteBool(&buf, true)
+		buf.Write(signature)
+		_ = WriteString(&buf, "hello")
+		_ = WriteLong(&buf, 1000)
+		_ = WriteLong(&buf, 5)
+		_ = WriteVarInt(&buf, 2)
+		_ = WriteVarInt(&buf, 4)
+		_ = WriteVarInt(&buf, 0)
+		buf.Write(signature)
+		_ = WriteBool(&buf, false)
+		_ = WriteVarInt(&buf, 0)
+
+		switch {
+		case inlineType:
+			_ = WriteVarInt(&buf, 0)
+			for i := 0; i < 2; i++ {
+				_ = WriteString(&buf, "chat.type.text")
+				_ = WriteVarInt(&buf, 2)
+				_ = WriteVarInt(&buf, 0)
+				_ = WriteVarInt(&buf, 2)
+				writeNetworkNBT(&buf, nbt.Compound{})
+			}
+		case v >= V1_20_5:
+			_ = WriteVarInt(&buf, 1)
+		default:
+			_ = WriteVarInt(&buf, 0)
+		}
+
+		if v >= V1_20_3 {
+			writeNetworkNBT(&buf, nbt.String("Steve"))
+		} else {
+			_ = WriteString(&buf, `{"text":"Steve"}`)
+		}
+		_ = WriteBool(&buf, false)
+		return &buf
+	}
+
+	for _, tc := range []struct {
+		version  Version
+		inline   bool
+		chatType int32
+	}{
+		{V1_19_3, false, 0},
+		{V1_20_3, false, 0},
+		{V1_20_5, false, 0},
+		{V1_21_5, false, 0},
+		{V1_21_5, true, -1},
+	} {
+		buf := write(tc.version, tc.inline)
+
+		var p ClientboundPlayerChat
+		if err := p.Decode(buf, tc.version); err != nil {
+			t.Fatalf("%s: Decode: %v", tc.version, err)
+		}
+
+		if p.Sender != sender || p.Index != 3 || !bytes.Equal(p.Signature, signature) {
+			t.Fatalf("%s: unexpected header %+v", tc.version, p)
+		}
+		if p.Message != "hello" || p.ChatType != tc.chatType || p.SenderName.Text != "Steve" {
+			t.Fatalf("%s: unexpected body %+v", tc.version, p)
+		}
+		if tc.version >= V1_21_5 && p.GlobalIndex != 12 {
+			t.Fatalf("%s: expected global index 12, got %d", tc.version, p.GlobalIndex)
+		}
+		if buf.Len() != 0 {
+			t.Fatalf("%s: %d bytes left unread", tc.version, buf.Len())
+		}
+	}
+}
+
+func TestClientboundSystemChatDecode(t *testing.T) {
+	var buf bytes.Buffer
+	_ = WriteString(&buf, `{"text":"bar"}`)
+	_ = WriteVarInt(&buf, 2)
+
+	var p ClientboundSystemChat
+	if err := p.Decode(&buf, V1_19); err != nil || !p.Overlay || p.Component.Text != "bar" {
+		t.Fatalf("expected 1.19 game info to be an overlay, got %+v (%v)", p, err)
+	}
+
+	buf.Reset()
+	writeNetworkNBT(&buf, nbt.String("server"))
+	_ = WriteBool(&buf, false)
+
+	p = ClientboundSystemChat{}
+	if err := p.Decode(&buf, V1_21_5); err != nil || p.Overlay || p.Component.Text != "server" {
+		t.Fatalf("unexpected system chat %+v (%v)", p, err)
+	}
+}
@@ -1,8 +1,11 @@
 package protocol
 
 import (
+	"bufio"
 	"bytes"
 	"compress/zlib"
+	"crypto/aes"
+	"crypto/cipher"
 	"errors"
 	"fmt"
 	"io"
@@ -21,8 +24,19 @@ type Conn struct {
 	version Version
 	state   State
 
+	// inbound and outbound are the directions of packets read and written;
+	// a client reads clientbound packets, a server the serverbound ones.
+	inbound  Direction
+	outbound Direction
+
 	compressionThreshold int
 
+	// buffered holds the raw bytes received but not consumed yet, so that
+	// they can be peeked at and are decrypted once encryption is enabled.
+	buffered *bufio.Reader
+	reader   io.Reader
+	writer   io.Writer
+
 	readerLock sync.Mutex
 	writerLock sync.Mutex
 
@@ -30,14 +44,28 @@ type Conn struct {
 }
 
 func NewConn(conn net.Conn, version Version) *Conn {
+	buffered := bufio.NewReader(conn)
 	return &Conn{
 		Conn:                 conn,
 		version:              version,
 		state:                StateHandshaking,
+		inbound:              DirectionClientbound,
+		outbound:             DirectionServerbound,
 		compressionThreshold: -1,
+		buffered:             buffered,
+		reader:               buffered,
+		writer:               conn,
 	}
 }
 
+func (c *Conn) Version() Version {
+	return c.version
+}
+
+func (c *Conn) SetVersion(v Version) {
+	c.version = v
+}
+
 func (c *Conn) State() State {
 	return c.state
 }
@@ -50,13 +78,40 @@ func (c *Conn) SetCompression(threshold int) {
 	c.compressionThreshold = threshold
 }
 
+func (c *Conn) EnableEncryption(sharedSecret []byte) error {
+	block, err := aes.NewCipher(sharedSecret)
+	if err != nil {
+		return fmt.Errorf("create aes cipher: %w", err)
+	}
+
+	c.readerLock.Lock()
+	defer c.readerLock.Unlock()
+	c.writerLock.Lock()
+	defer c.writerLock.Unlock()
+
+	c.reader = &cipher.StreamReader{S: NewCFB8Decrypter(block, sharedSecret), R: c.buffered}
+	c.writer = cipher.StreamWriter{S: NewCFB8Encrypter(block, sharedSecret), W: c.Conn}
+
+	return nil
+}
+
 var ErrUnknownPacket = errors.New("unknown packet")
 
+// Peek returns the next n raw bytes without consuming them. It is meant for
+// the start of a connection, before encryption is enabled.
+func (c *Conn) Peek(n int) ([]byte, error) {
+	c.readerLock.Lock()
+	defer c.readerLock.Unlock()
+
+	head, err := c.buffered.Peek(n)
+	return bytes.Clone(head), err
+}
+
 func (c *Conn) ReadPacket() (Packet, error) {
 	c.readerLock.Lock()
 	defer c.readerLock.Unlock()
 
-	packetLength, err := ReadVarInt(c.Conn)
+	packetLength, err := ReadVarInt(c.reader)
 	if err != nil {
 		return nil, fmt.Errorf("read packet length: %w", err)
 	}
@@ -64,7 +119,7 @@ func (c *Conn) ReadPacket() (Packet, error) {
 	packetBuffer := bytebufferpool.Get()
 	defer bytebufferpool.Put(packetBuffer)
 
-	if _, err := io.CopyN(packetBuffer, c.Conn, int64(packetLength)); err != nil {
+	if _, err := io.CopyN(packetBuffer, c.reader, int64(packetLength)); err != nil {
 		return nil, fmt.Errorf("read packet data: %w", err)
 	}
 
@@ -100,11 +155,11 @@ func (c *Conn) ReadPacket() (Packet, error) {
 		return nil, fmt.Errorf("read packet ID: %w", err)
 	}
 
-	packet, err := NewPacket(c.version, c.state, DirectionClientbound, packetID)
+	packet, err := NewPacket(c.version, c.state, c.inbound, packetID)
 	if err != nil {
 		_, _ = io.Copy(io.Discard, dataReader)
 		if debugMinecraft {
-			log.Printf("[DEBUG] S -> C | State: %-12s | ID: 0x%02X | Type: %s (IGNORED)", c.state, packetID, "Unknown")
+			log.Printf("[DEBUG] %s | State: %-12s | ID: 0x%02X | Type: %s (IGNORED)", c.inbound, c.state, packetID, "Unknown")
 		}
 		return nil, ErrUnknownPacket
 	}
@@ -114,7 +169,7 @@ func (c *Conn) ReadPacket() (Packet, error) {
 	}
 
 	if debugMinecraft {
-		log.Printf("[DEBUG] S -> C | State: %-12s | ID: 0x%02X | Type: %T", c.state, packetID, packet)
+		log.Printf("[DEBUG] %s | State: %-12s | ID: 0x%02X | Type: %T", c.inbound, c.state, packetID, packet)
 	}
 
 	return packet, nil
@@ -124,7 +179,7 @@ func (c *Conn) WritePacket(p Packet) error {
 	c.writerLock.Lock()
 	defer c.writerLock.Unlock()
 
-	packetID, ok := GetPacketID(c.version, c.state, DirectionServerbound, p)
+	packetID, ok := GetPacketID(c.version, c.state, c.outbound, p)
 	if !ok {
 		return fmt.Errorf("no id for packet %T in state %s (version %s)", p, c.state, c.version)
 	}
@@ -154,13 +209,19 @@ func (c *Conn) WritePacket(p Packet) error {
 		_, _ = finalPayload.Write(dataBuf.B)
 	}
 
-	_ = WriteVarInt(c.Conn, int32(finalPayload.Len()))
-	if _, err := c.Conn.Write(finalPayload.B); err != nil {
+	// Length and payload go out in one write, so the peer never sees a
+	// partial packet header on its own.
+	frame := bytebufferpool.Get()
+	defer bytebufferpool.Put(frame)
+
+	_ = WriteVarInt(frame, int32(finalPayload.Len()))
+	_, _ = frame.Write(finalPayload.B)
+	if _, err := c.writer.Write(frame.B); err != nil {
 		return fmt.Errorf("write final payload to network: %w", err)
 	}
 
 	if debugMinecraft {
-		log.Printf("[DEBUG] C -> S | State: %-12s | ID: 0x%02X | Type: %T", c.state, packetID, p)
+		log.Printf("[DEBUG] %s | State: %-12s | ID: 0x%02X | Type: %T", c.outbound, c.state, packetID, p)
 	}
 
 	return nil
@@ -5,18 +5,23 @@ import "reflect"
 type PacketFactory func() Packet
 
 var packetConstructors = map[string]PacketFactory{
-	"ServerboundHandshake":  func() Packet { return &ServerboundHandshake{} },
-	"ClientboundDisconnect": func() Packet { return &ClientboundDisconnect{} },
+	"ServerboundHandshake":       func() Packet { return &ServerboundHandshake{} },
+	"ClientboundDisconnect":      func() Packet { return &ClientboundDisconnect{} },
+	"ClientboundLoginDisconnect": func() Packet { return &ClientboundLoginDisconnect{} },
 
 	"ServerboundStatusRequest":  func() Packet { return &ServerboundStatusRequest{} },
 	"ClientboundStatusResponse": func() Packet { return &ClientboundStatusResponse{} },
 	"ServerboundPing":           func() Packet { return &ServerboundPing{} },
 	"ClientboundPong":           func() Packet { return &ClientboundPong{} },
 
-	"ServerboundLoginStart":        func() Packet { return &ServerboundLoginStart{} },
-	"ClientboundLoginSuccess":      func() Packet { return &ClientboundLoginSuccess{} },
-	"ClientboundSetCompression":    func() Packet { return &ClientboundSetCompression{} },
-	"ServerboundLoginAcknowledged": func() Packet { return &ServerboundLoginAcknowledged{} },
+	"ServerboundLoginStart":          func() Packet { return &ServerboundLoginStart{} },
+	"ClientboundEncryptionRequest":   func() Packet { return &ClientboundEncryptionRequest{} },
+	"ServerboundEncryptionResponse":  func() Packet { return &ServerboundEncryptionResponse{} },
+	"ClientboundLoginSuccess":        func() Packet { return &ClientboundLoginSuccess{} },
+	"ClientboundSetCompression":      func() Packet { return &ClientboundSetCompression{} },
+	"ServerboundLoginAcknowledged":   func() Packet { return &ServerboundLoginAcknowledged{} },
+	"ClientboundLoginPluginRequest":  func() Packet { return &ClientboundLoginPluginRequest{} },
+	"ServerboundLoginPluginResponse": func() Packet { return &ServerboundLoginPluginResponse{} },
 
 	"ServerboundFinishConfiguration": func() Packet { return &ServerboundFinishConfiguration{} },
 	"ClientboundFinishConfiguration": func() Packet { return &ClientboundFinishConfiguration{} },
@@ -29,10 +34,16 @@ var packetConstructors = map[string]PacketFactory{
 	"ClientboundConfigPing":          func() Packet { return &ClientboundConfigPing{} },
 	"ServerboundConfigPong":          func() Packet { return &ServerboundConfigPong{} },
 
-	"ServerboundChatMessage": func() Packet { return &ServerboundChatMessage{} },
-	"ClientboundKeepAlive":   func() Packet { return &ClientboundKeepAlive{} },
-	"ServerboundKeepAlive":   func() Packet { return &ServerboundKeepAlive{} },
-	"ClientboundJoinGame":    func() Packet { return &ClientboundJoinGame{} },
+	"ServerboundChatMessage":            func() Packet { return &ServerboundChatMessage{} },
+	"ClientboundChatMessage":            func() Packet { return &ClientboundChatMessage{} },
+	"ClientboundPlayerChat":             func() Packet { return &ClientboundPlayerChat{} },
+	"ClientboundSystemChat":             func() Packet { return &ClientboundSystemChat{} },
+	"ClientboundDisguisedChat":          func() Packet { return &ClientboundDisguisedChat{} },
+	"ServerboundChatSessionUpdate":      func() Packet { return &ServerboundChatSessionUpdate{} },
+	"ServerboundMessageAcknowledgement": func() Packet { return &ServerboundMessageAcknowledgement{} },
+	"ClientboundKeepAlive":              func() Packet { return &ClientboundKeepAlive{} },
+	"ServerboundKeepAlive":              func() Packet { return &ServerboundKeepAlive{} },
+	"ClientboundJoinGame":               func() Packet { return &ClientboundJoinGame{} },
 
 	"ServerboundClientSettings": func() Packet { return &ServerboundClientSettings{} },
 	"ServerboundCustomPayload":  func() Packet { return &ServerboundCustomPayload{} },
@@ -49,3 +60,10 @@ func init() {
 		packetTypes[reflect.TypeOf(factory())] = name
 	}
 }
+
+// registerPacket adds a packet type outside of packetConstructors, like the
+// generated ones.
+func registerPacket(name string, factory PacketFactory) {
+	packetConstructors[name] = factory
+	packetTypes[reflect.TypeOf(factory())] = name
+}
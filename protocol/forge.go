@@ -0,0 +1,274 @@
+package protocol
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Forge network markers. A Forge client appends one to the handshake
+// ServerAddress; Forge servers reject clients without it.
+const (
+	ForgeMarkerFML  = "\x00FML\x00"
+	ForgeMarkerFML2 = "\x00FML2\x00"
+	ForgeMarkerFML3 = "\x00FML3\x00"
+)
+
+// ForgeMarker returns the marker Forge uses for v: FML before 1.13, FML2
+// until 1.18.2 and FML3 after.
+func ForgeMarker(v Version) string {
+	switch {
+	case v < V1_13:
+		return ForgeMarkerFML
+	case v < V1_18_2:
+		return ForgeMarkerFML2
+	default:
+		return ForgeMarkerFML3
+	}
+}
+
+// FML2 and FML3 negotiate with login plugin requests on
+// ForgeLoginWrapperChannel, each wrapping a message for an inner channel,
+// ForgeHandshakeChannel for the handshake itself.
+const (
+	ForgeLoginWrapperChannel = "fml:loginwrapper"
+	ForgeHandshakeChannel    = "fml:handshake"
+)
+
+// fml:handshake message IDs.
+const (
+	ForgeModListID         = 1
+	ForgeModListReplyID    = 2
+	ForgeRegistryID        = 3
+	ForgeConfigDataID      = 4
+	ForgeModDataID         = 5
+	ForgeChannelMismatchID = 6
+	ForgeAcknowledgeID     = 99
+)
+
+var ErrInvalidForgeMessage = errors.New("invalid forge handshake message")
+
+// ReadForgeLoginWrapper unwraps the data of a fml:loginwrapper request or
+// response into its inner channel and payload.
+func ReadForgeLoginWrapper(data []byte) (channel string, payload []byte, err error) {
+	r := bytes.NewReader(data)
+	if channel, err = ReadString(r); err != nil {
+		return "", nil, err
+	}
+	if payload, err = ReadBytes(r); err != nil {
+		return "", nil, err
+	}
+	return channel, payload, nil
+}
+
+// ForgeLoginWrapper wraps payload for channel in fml:loginwrapper data.
+func ForgeLoginWrapper(channel string, payload []byte) []byte {
+	var buf bytes.Buffer
+	_ = WriteString(&buf, channel)
+	_ = WriteByteSlice(&buf, payload)
+	return buf.Bytes()
+}
+
+// ForgeModList is the server's mod list, the first fml:handshake message.
+type ForgeModList struct {
+	Mods       []string
+	Channels   []ForgeChannel
+	Registries []string
+	// DataPackRegistries is only sent by FML3.
+	DataPackRegistries []string
+}
+
+// ForgeRegistry is a registry the client has, with the marker of its
+// contents.
+type ForgeRegistry struct {
+	Name   string
+	Marker string
+}
+
+// ForgeModListReply answers ForgeModList with the client's mods.
+type ForgeModListReply struct {
+	Mods       []string
+	Channels   []ForgeChannel
+	Registries []ForgeRegistry
+}
+
+// ReadForgeHandshake splits a fml:handshake payload into its message ID
+// and body.
+func ReadForgeHandshake(payload []byte) (int32, *bytes.Reader, error) {
+	r := bytes.NewReader(payload)
+	id, err := ReadVarInt(r)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: %w", ErrInvalidForgeMessage, err)
+	}
+	return id, r, nil
+}
+
+// ForgeAcknowledge is the payload of the client's acknowledgement of every
+// fml:handshake message but the mod list.
+func ForgeAcknowledge() []byte {
+	var buf bytes.Buffer
+	_ = WriteVarInt(&buf, ForgeAcknowledgeID)
+	return buf.Bytes()
+}
+
+// Bytes encodes m as a fml:handshake payload.
+func (m *ForgeModList) Bytes() []byte {
+	var buf bytes.Buffer
+	_ = WriteVarInt(&buf, ForgeModListID)
+	_ = writeStrings(&buf, m.Mods)
+	writeForgeChannels(&buf, m.Channels)
+	_ = writeStrings(&buf, m.Registries)
+	if m.DataPackRegistries != nil {
+		_ = writeStrings(&buf, m.DataPackRegistries)
+	}
+	return buf.Bytes()
+}
+
+// ReadForgeModList decodes the body of a ForgeModListID message.
+func ReadForgeModList(r io.Reader) (*ForgeModList, error) {
+	m := &ForgeModList{}
+	var err error
+	if m.Mods, err = readStrings(r); err != nil {
+		return nil, err
+	}
+	if m.Channels, err = readForgeChannels(r); err != nil {
+		return nil, err
+	}
+	if m.Registries, err = readStrings(r); err != nil {
+		return nil, err
+	}
+
+	m.DataPackRegistries, err = readStrings(r)
+	if errors.Is(err, io.EOF) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Bytes encodes m as a fml:handshake payload.
+func (m *ForgeModListReply) Bytes() []byte {
+	var buf bytes.Buffer
+	_ = WriteVarInt(&buf, ForgeModListReplyID)
+	_ = writeStrings(&buf, m.Mods)
+	writeForgeChannels(&buf, m.Channels)
+	_ = WriteVarInt(&buf, int32(len(m.Registries)))
+	for _, registry := range m.Registries {
+		_ = WriteString(&buf, registry.Name)
+		_ = WriteString(&buf, registry.Marker)
+	}
+	return buf.Bytes()
+}
+
+// ReadForgeModListReply decodes the body of a ForgeModListReplyID message.
+func ReadForgeModListReply(r io.Reader) (*ForgeModListReply, error) {
+	m := &ForgeModListReply{}
+	var err error
+	if m.Mods, err = readStrings(r); err != nil {
+		return nil, err
+	}
+	if m.Channels, err = readForgeChannels(r); err != nil {
+		return nil, err
+	}
+
+	count, err := ReadVarInt(r)
+	if err != nil {
+		return nil, err
+	}
+	if count < 0 {
+		return nil, fmt.Errorf("%w: registry count %d", ErrInvalidForgeMessage, count)
+	}
+	for i := int32(0); i < count; i++ {
+		var registry ForgeRegistry
+		if registry.Name, err = ReadString(r); err != nil {
+			return nil, err
+		}
+		if registry.Marker, err = ReadString(r); err != nil {
+			return nil, err
+		}
+		m.Registries = append(m.Registries, registry)
+	}
+	return m, nil
+}
+
+func writeForgeChannels(w io.Writer, channels []ForgeChannel) {
+	_ = WriteVarInt(w, int32(len(channels)))
+	for _, channel := range channels {
+		_ = WriteString(w, channel.Name)
+		_ = WriteString(w, channel.Version)
+	}
+}
+
+func readForgeChannels(r io.Reader) ([]ForgeChannel, error) {
+	count, err := ReadVarInt(r)
+	if err != nil {
+		return nil, err
+	}
+	if count < 0 {
+		return nil, fmt.Errorf("%w: channel count %d", ErrInvalidForgeMessage, count)
+	}
+	channels := make([]ForgeChannel, 0, min(count, 1024))
+	for i := int32(0); i < count; i++ {
+		var channel ForgeChannel
+		if channel.Name, err = ReadString(r); err != nil {
+			return nil, err
+		}
+		if channel.Version, err = ReadString(r); err != nil {
+			return nil, err
+		}
+		channels = append(channels, channel)
+	}
+	return channels, nil
+}
+
+// FML1, used before 1.13, runs its handshake over play state plugin
+// messages on ForgeLegacyHandshakeChannel. Each message starts with one of
+// the discriminators below.
+const (
+	ForgeLegacyHandshakeChannel = "FML|HS"
+
+	ForgeLegacyServerHelloID    = 0x00
+	ForgeLegacyClientHelloID    = 0x01
+	ForgeLegacyModListID        = 0x02
+	ForgeLegacyRegistryDataID   = 0x03
+	ForgeLegacyHandshakeResetID = 0xFE
+	ForgeLegacyHandshakeAckID   = 0xFF
+)
+
+// ForgeLegacyModList encodes an FML1 ModList message.
+func ForgeLegacyModList(mods []ForgeMod) []byte {
+	buf := bytes.NewBuffer([]byte{ForgeLegacyModListID})
+	_ = WriteVarInt(buf, int32(len(mods)))
+	for _, mod := range mods {
+		_ = WriteString(buf, mod.ID)
+		_ = WriteString(buf, mod.Version)
+	}
+	return buf.Bytes()
+}
+
+// ReadForgeLegacyModList decodes the body of an FML1 ModList message,
+// after its discriminator.
+func ReadForgeLegacyModList(r io.Reader) ([]ForgeMod, error) {
+	count, err := ReadVarInt(r)
+	if err != nil {
+		return nil, err
+	}
+	if count < 0 {
+		return nil, fmt.Errorf("%w: mod count %d", ErrInvalidForgeMessage, count)
+	}
+	mods := make([]ForgeMod, 0, min(count, 1024))
+	for i := int32(0); i < count; i++ {
+		var mod ForgeMod
+		if mod.ID, err = ReadString(r); err != nil {
+			return nil, err
+		}
+		if mod.Version, err = ReadString(r); err != nil {
+			return nil, err
+		}
+		mods = append(mods, mod)
+	}
+	return mods, nil
+}
@@ -0,0 +1,124 @@
+package protocol
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestForgeMarker(t *testing.T) {
+	for v, want := range map[Version]string{
+		V1_12_2: ForgeMarkerFML,
+		V1_13:   ForgeMarkerFML2,
+		V1_17:   ForgeMarkerFML2,
+		V1_18_2: ForgeMarkerFML3,
+		Latest:  ForgeMarkerFML3,
+	} {
+		if got := ForgeMarker(v); got != want {
+			t.Errorf("%s: expected %q, got %q", v, want, got)
+		}
+	}
+}
+
+func TestForgeLoginWrapper(t *testing.T) {
+	data := ForgeLoginWrapper(ForgeHandshakeChannel, ForgeAcknowledge())
+
+	channel, payload, err := ReadForgeLoginWrapper(data)
+	if err != nil || channel != ForgeHandshakeChannel {
+		t.Fatalf("unexpected channel %q (%v)", channel, err)
+	}
+
+	id, r, err := ReadForgeHandshake(payload)
+	if err != nil || id != ForgeAcknowledgeID || r.Len() != 0 {
+		t.Fatalf("expected an empty acknowledgement, got %d with %d bytes (%v)", id, r.Len(), err)
+	}
+}
+
+func TestForgeModList(t *testing.T) {
+	for _, list := range []*ForgeModList{
+		{
+			Mods:       []string{"minecraft", "forge"},
+			Channels:   []ForgeChannel{{Name: "fml:handshake", Version: "FML2"}},
+			Registries: []string{"minecraft:block"},
+		},
+		{
+			Mods:               []string{"forge"},
+			Channels:           []ForgeChannel{},
+			Registries:         []string{},
+			DataPackRegistries: []string{"minecraft:dimension_type"},
+		},
+	} {
+		id, r, err := ReadForgeHandshake(list.Bytes())
+		if err != nil || id != ForgeModListID {
+			t.Fatalf("unexpected message %d (%v)", id, err)
+		}
+
+		got, err := ReadForgeModList(r)
+		if err != nil || !reflect.DeepEqual(got, list) {
+			t.Errorf("expected %+v, got %+v (%v)", list, got, err)
+		}
+	}
+
+	reply := &ForgeModListReply{
+		Mods:       []string{"forge"},
+		Channels:   []ForgeChannel{{Name: "forge:tier_sorting", Version: "1.0"}},
+		Registries: []ForgeRegistry{{Name: "minecraft:item", Marker: "1.0"}},
+	}
+	id, r, _ := ReadForgeHandshake(reply.Bytes())
+	if got, err := ReadForgeModListReply(r); id != ForgeModListReplyID || err != nil || !reflect.DeepEqual(got, reply) {
+		t.Errorf("expected %+v, got %+v (%v)", reply, got, err)
+	}
+}
+
+func TestForgeLegacyModList(t *testing.T) {
+	mods := []ForgeMod{{ID: "FML", Version: "8.0.99.99"}, {ID: "forge", Version: "14.23.5.2859"}}
+
+	data := ForgeLegacyModList(mods)
+	if data[0] != ForgeLegacyModListID {
+		t.Fatalf("unexpected discriminator %d", data[0])
+	}
+
+	got, err := ReadForgeLegacyModList(bytes.NewReader(data[1:]))
+	if err != nil || !reflect.DeepEqual(got, mods) {
+		t.Fatalf("expected %+v, got %+v (%v)", mods, got, err)
+	}
+}
+
+func TestForgeNegativeCounts(t *testing.T) {
+	var negative bytes.Buffer
+	_ = WriteVarInt(&negative, -1)
+
+	if _, err := ReadForgeLegacyModList(bytes.NewReader(negative.Bytes())); !errors.Is(err, ErrInvalidForgeMessage) {
+		t.Errorf("mod list: expected ErrInvalidForgeMessage, got %v", err)
+	}
+
+	var list bytes.Buffer
+	_ = WriteVarInt(&list, 0)
+	list.Write(negative.Bytes())
+	if _, err := ReadForgeModList(bytes.NewReader(list.Bytes())); !errors.Is(err, ErrInvalidForgeMessage) {
+		t.Errorf("channels: expected ErrInvalidForgeMessage, got %v", err)
+	}
+
+	var reply bytes.Buffer
+	_ = WriteVarInt(&reply, 0)
+	_ = WriteVarInt(&reply, 0)
+	reply.Write(negative.Bytes())
+	if _, err := ReadForgeModListReply(bytes.NewReader(reply.Bytes())); !errors.Is(err, ErrInvalidForgeMessage) {
+		t.Errorf("registries: expected ErrInvalidForgeMessage, got %v", err)
+	}
+}
+
+func TestStatusForgeMods(t *testing.T) {
+	status, err := ParseStatusResponse(`{"description":"","modinfo":{"type":"FML","modList":[{"modid":"forge","version":"14.23.5.2859"}]}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mods := status.ForgeMods(); len(mods) != 1 || mods[0] != (ForgeMod{ID: "forge", Version: "14.23.5.2859"}) {
+		t.Fatalf("unexpected mods %+v", mods)
+	}
+
+	if mods := (&StatusResponse{}).ForgeMods(); mods != nil {
+		t.Fatalf("expected no mods for a vanilla server, got %+v", mods)
+	}
+}
@@ -0,0 +1,240 @@
+package protocol
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BungeeCordForwarding is the player information BungeeCord's legacy IP
+// forwarding appends to the handshake ServerAddress, separated by NULs.
+type BungeeCordForwarding struct {
+	Host       string
+	ClientIP   string
+	UUID       uuid.UUID
+	Properties []Property
+}
+
+// ServerAddress returns the handshake ServerAddress carrying f.
+func (f *BungeeCordForwarding) ServerAddress() (string, error) {
+	fields := []string{f.Host, f.ClientIP, strings.ReplaceAll(f.UUID.String(), "-", "")}
+
+	if f.Properties != nil {
+		properties, err := json.Marshal(f.Properties)
+		if err != nil {
+			return "", err
+		}
+		fields = append(fields, string(properties))
+	}
+
+	return strings.Join(fields, "\x00"), nil
+}
+
+// ParseBungeeCordForwarding reads forwarding information from a handshake
+// ServerAddress the way Spigot does.
+func ParseBungeeCordForwarding(serverAddress string) (*BungeeCordForwarding, error) {
+	fields := strings.Split(serverAddress, "\x00")
+	if len(fields) != 3 && len(fields) != 4 {
+		return nil, errors.New("handshake carries no BungeeCord forwarding")
+	}
+
+	id, err := uuid.Parse(fields[2])
+	if err != nil {
+		return nil, fmt.Errorf("forwarded uuid: %w", err)
+	}
+
+	f := &BungeeCordForwarding{Host: fields[0], ClientIP: fields[1], UUID: id}
+	if len(fields) == 4 {
+		if err := json.Unmarshal([]byte(fields[3]), &f.Properties); err != nil {
+			return nil, fmt.Errorf("forwarded properties: %w", err)
+		}
+	}
+	return f, nil
+}
+
+// VelocityPlayerInfoChannel is the login plugin channel Velocity requests
+// modern forwarding data on.
+const VelocityPlayerInfoChannel = "velocity:player_info"
+
+// Velocity modern forwarding versions. Versions 2 and 3 carry the player's
+// chat signing key, which 1.19.3 stopped sending at login.
+const (
+	VelocityForwardingDefault   = 1
+	VelocityForwardingWithKey   = 2
+	VelocityForwardingWithKeyV2 = 3
+	VelocityForwardingLazy      = 4
+)
+
+var ErrInvalidForwardingSignature = errors.New("invalid forwarding signature")
+
+// VelocityForwarding is the payload of a velocity:player_info response.
+type VelocityForwarding struct {
+	Version    int
+	ClientIP   string
+	UUID       uuid.UUID
+	Username   string
+	Properties []Property
+	// PlayerKey is sent with VelocityForwardingWithKey and
+	// VelocityForwardingWithKeyV2.
+	PlayerKey *PlayerKey
+	// KeyHolder is the UUID the key was signed for, sent with
+	// VelocityForwardingWithKeyV2.
+	KeyHolder uuid.UUID
+}
+
+// VelocityForwardingVersion picks the version to answer a request for
+// requested with for a client speaking v, like Velocity does. 1.19.3 and
+// later clients get VelocityForwardingLazy or VelocityForwardingDefault.
+// Older clients with a key get the key version matching its revision: 1.19
+// keys VelocityForwardingWithKey and 1.19.1 keys VelocityForwardingWithKeyV2,
+// falling back to VelocityForwardingDefault if the proxy does not support it.
+func VelocityForwardingVersion(requested int, v Version, key *PlayerKey) int {
+	requested = min(requested, VelocityForwardingLazy)
+	switch {
+	case requested <= VelocityForwardingDefault:
+		return VelocityForwardingDefault
+	case v >= V1_19_3:
+		if requested >= VelocityForwardingLazy {
+			return VelocityForwardingLazy
+		}
+		return VelocityForwardingDefault
+	case key == nil || v < V1_19:
+		return VelocityForwardingDefault
+	case v < V1_19_2:
+		return VelocityForwardingWithKey
+	case requested >= VelocityForwardingWithKeyV2:
+		return VelocityForwardingWithKeyV2
+	default:
+		return VelocityForwardingDefault
+	}
+}
+
+// Sign encodes f and prefixes it with its HMAC-SHA256 signature under
+// secret, the forwarding secret shared by the proxy and the server.
+func (f *VelocityForwarding) Sign(secret []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := f.encode(&buf); err != nil {
+		return nil, err
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(buf.Bytes())
+	return append(mac.Sum(nil), buf.Bytes()...), nil
+}
+
+func (f *VelocityForwarding) encode(w io.Writer) error {
+	withKey := f.Version == VelocityForwardingWithKey || f.Version == VelocityForwardingWithKeyV2
+	if withKey && f.PlayerKey == nil {
+		return fmt.Errorf("velocity forwarding version %d needs a player key", f.Version)
+	}
+
+	_ = WriteVarInt(w, int32(f.Version))
+	_ = WriteString(w, f.ClientIP)
+	_ = WriteUUID(w, f.UUID)
+	_ = WriteString(w, f.Username)
+	if err := writeProperties(w, f.Properties); err != nil {
+		return err
+	}
+
+	if !withKey {
+		return nil
+	}
+
+	// VelocityForwardingWithKey forwards 1.19 keys, VelocityForwardingWithKeyV2
+	// the 1.19.1 ones signed together with the player's UUID.
+	signatureVersion := V1_19
+	if f.Version == VelocityForwardingWithKeyV2 {
+		signatureVersion = V1_19_2
+	}
+
+	_ = WriteLong(w, f.PlayerKey.ExpiresAt.UnixMilli())
+	_ = WriteByteSlice(w, f.PlayerKey.PublicKey)
+	if err := WriteByteSlice(w, f.PlayerKey.signatureFor(signatureVersion)); err != nil {
+		return err
+	}
+
+	if f.Version == VelocityForwardingWithKeyV2 {
+		_ = WriteBool(w, f.KeyHolder != uuid.Nil)
+		if f.KeyHolder != uuid.Nil {
+			return WriteUUID(w, f.KeyHolder)
+		}
+	}
+	return nil
+}
+
+// VerifyVelocityForwarding checks the signature of a velocity:player_info
+// response and decodes it.
+func VerifyVelocityForwarding(secret, data []byte) (*VelocityForwarding, error) {
+	if len(data) < sha256.Size {
+		return nil, ErrInvalidForwardingSignature
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(data[sha256.Size:])
+	if !hmac.Equal(mac.Sum(nil), data[:sha256.Size]) {
+		return nil, ErrInvalidForwardingSignature
+	}
+
+	r := bytes.NewReader(data[sha256.Size:])
+	f := &VelocityForwarding{}
+
+	version, err := ReadVarInt(r)
+	if err != nil {
+		return nil, err
+	}
+	f.Version = int(version)
+
+	if f.ClientIP, err = ReadString(r); err != nil {
+		return nil, err
+	}
+	if f.UUID, err = ReadUUID(r); err != nil {
+		return nil, err
+	}
+	if f.Username, err = ReadString(r); err != nil {
+		return nil, err
+	}
+	if f.Properties, err = readProperties(r); err != nil {
+		return nil, err
+	}
+
+	if f.Version != VelocityForwardingWithKey && f.Version != VelocityForwardingWithKeyV2 {
+		return f, nil
+	}
+
+	expiresAt, err := ReadLong(r)
+	if err != nil {
+		return nil, err
+	}
+	f.PlayerKey = &PlayerKey{ExpiresAt: time.UnixMilli(expiresAt)}
+	if f.PlayerKey.PublicKey, err = ReadBytes(r); err != nil {
+		return nil, err
+	}
+	signature, err := ReadBytes(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.Version == VelocityForwardingWithKey {
+		f.PlayerKey.Signature = signature
+	} else {
+		f.PlayerKey.SignatureV2 = signature
+		hasHolder, err := ReadBool(r)
+		if err != nil {
+			return nil, err
+		}
+		if hasHolder {
+			if f.KeyHolder, err = ReadUUID(r); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return f, nil
+}
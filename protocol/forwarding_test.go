@@ -0,0 +1,99 @@
+package protocol
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestBungeeCordForwarding(t *testing.T) {
+	id := uuid.MustParse("069a79f4-44e9-4726-a5be-fca90e38aaf5")
+	f := &BungeeCordForwarding{Host: "mc.example.com", ClientIP: "203.0.113.7", UUID: id}
+
+	address, err := f.ServerAddress()
+	if err != nil || address != "mc.example.com\x00203.0.113.7\x00069a79f444e94726a5befca90e38aaf5" {
+		t.Fatalf("unexpected address %q (%v)", address, err)
+	}
+
+	f.Properties = []Property{{Name: "textures", Value: "e30=", Signature: "c2ln"}, {Name: "plain", Value: "x"}}
+	address, _ = f.ServerAddress()
+
+	parsed, err := ParseBungeeCordForwarding(address)
+	if err != nil || !reflect.DeepEqual(parsed, f) {
+		t.Fatalf("expected %+v, got %+v (%v)", f, parsed, err)
+	}
+
+	if _, err := ParseBungeeCordForwarding("mc.example.com"); err == nil {
+		t.Fatal("expected a plain address to fail")
+	}
+}
+
+func TestVelocityForwarding(t *testing.T) {
+	secret := []byte("secret")
+	id := uuid.MustParse("069a79f4-44e9-4726-a5be-fca90e38aaf5")
+	key := &PlayerKey{PublicKey: []byte{1, 2, 3}, ExpiresAt: time.UnixMilli(1700000000000), Signature: []byte{4, 5}}
+	keyV2 := &PlayerKey{PublicKey: []byte{1, 2, 3}, ExpiresAt: time.UnixMilli(1700000000000), SignatureV2: []byte{6, 7}}
+
+	for _, f := range []*VelocityForwarding{
+		{Version: VelocityForwardingDefault, ClientIP: "203.0.113.7", UUID: id, Username: "Steve"},
+		{Version: VelocityForwardingLazy, ClientIP: "2001:db8::7", UUID: id, Username: "Steve", Properties: []Property{{Name: "textures", Value: "e30=", Signature: "c2ln"}}},
+		{Version: VelocityForwardingWithKey, ClientIP: "203.0.113.7", UUID: id, Username: "Steve", PlayerKey: key},
+		{Version: VelocityForwardingWithKeyV2, ClientIP: "203.0.113.7", UUID: id, Username: "Steve", PlayerKey: keyV2, KeyHolder: id},
+	} {
+		data, err := f.Sign(secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := VerifyVelocityForwarding(secret, data)
+		if err != nil || !reflect.DeepEqual(got, f) {
+			t.Errorf("version %d: expected %+v, got %+v (%v)", f.Version, f, got, err)
+		}
+
+		data[len(data)-1] ^= 1
+		if _, err := VerifyVelocityForwarding(secret, data); !errors.Is(err, ErrInvalidForwardingSignature) {
+			t.Errorf("version %d: expected a signature error, got %v", f.Version, err)
+		}
+	}
+
+	if _, err := (&VelocityForwarding{Version: VelocityForwardingWithKey}).Sign(secret); err == nil {
+		t.Error("expected an error without a player key")
+	}
+
+	// A key carrying both signatures forwards the one matching the version.
+	both := &PlayerKey{PublicKey: key.PublicKey, ExpiresAt: key.ExpiresAt, Signature: key.Signature, SignatureV2: keyV2.SignatureV2}
+	for version, want := range map[int]*PlayerKey{VelocityForwardingWithKey: key, VelocityForwardingWithKeyV2: keyV2} {
+		data, _ := (&VelocityForwarding{Version: version, PlayerKey: both}).Sign(secret)
+		got, err := VerifyVelocityForwarding(secret, data)
+		if err != nil || !reflect.DeepEqual(got.PlayerKey, want) {
+			t.Errorf("version %d: expected key %+v, got %+v (%v)", version, want, got, err)
+		}
+	}
+
+	for _, tc := range []struct {
+		requested int
+		version   Version
+		key       *PlayerKey
+		want      int
+	}{
+		{1, V1_19_2, keyV2, VelocityForwardingDefault},
+		{2, V1_19, key, VelocityForwardingWithKey},
+		{4, V1_19, key, VelocityForwardingWithKey},
+		{2, V1_19_2, keyV2, VelocityForwardingDefault},
+		{3, V1_19_2, keyV2, VelocityForwardingWithKeyV2},
+		{4, V1_19_2, keyV2, VelocityForwardingWithKeyV2},
+		{4, V1_19_2, nil, VelocityForwardingDefault},
+		{3, V1_19_3, keyV2, VelocityForwardingDefault},
+		{4, V1_19_3, keyV2, VelocityForwardingLazy},
+		{9, V1_20_2, keyV2, VelocityForwardingLazy},
+		{9, V1_20_2, nil, VelocityForwardingLazy},
+		{4, V1_18_2, nil, VelocityForwardingDefault},
+	} {
+		if got := VelocityForwardingVersion(tc.requested, tc.version, tc.key); got != tc.want {
+			t.Errorf("requested %d by %s: expected %d, got %d", tc.requested, tc.version, tc.want, got)
+		}
+	}
+}
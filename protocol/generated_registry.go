@@ -14,20 +14,22 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundDisconnect":   0,
-					"ClientboundLoginSuccess": 2,
+					"ClientboundEncryptionRequest": 1,
+					"ClientboundLoginDisconnect":   0,
+					"ClientboundLoginSuccess":      2,
 				},
 				DirectionServerbound: {
-					"ServerboundLoginStart": 0,
+					"ServerboundEncryptionResponse": 1,
+					"ServerboundLoginStart":         0,
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
+					"ClientboundChatMessage":   2,
 					"ClientboundCustomPayload": 63,
 					"ClientboundDisconnect":    64,
 					"ClientboundJoinGame":      1,
 					"ClientboundKeepAlive":     0,
-					"ServerboundChatMessage":   2,
 				},
 				DirectionServerbound: {
 					"ServerboundChatMessage":    1,
@@ -55,18 +57,20 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
 					0:  "ClientboundKeepAlive",
 					1:  "ClientboundJoinGame",
-					2:  "ServerboundChatMessage",
+					2:  "ClientboundChatMessage",
 					63: "ClientboundCustomPayload",
 					64: "ClientboundDisconnect",
 				},
@@ -99,21 +103,23 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundEncryptionRequest": 1,
+					"ClientboundLoginDisconnect":   0,
+					"ClientboundLoginSuccess":      2,
+					"ClientboundSetCompression":    3,
 				},
 				DirectionServerbound: {
-					"ServerboundLoginStart": 0,
+					"ServerboundEncryptionResponse": 1,
+					"ServerboundLoginStart":         0,
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
+					"ClientboundChatMessage":   2,
 					"ClientboundCustomPayload": 63,
 					"ClientboundDisconnect":    64,
 					"ClientboundJoinGame":      1,
 					"ClientboundKeepAlive":     0,
-					"ServerboundChatMessage":   2,
 				},
 				DirectionServerbound: {
 					"ServerboundChatMessage":    1,
@@ -141,19 +147,21 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
 					0:  "ClientboundKeepAlive",
 					1:  "ClientboundJoinGame",
-					2:  "ServerboundChatMessage",
+					2:  "ClientboundChatMessage",
 					63: "ClientboundCustomPayload",
 					64: "ClientboundDisconnect",
 				},
@@ -186,21 +194,23 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundEncryptionRequest": 1,
+					"ClientboundLoginDisconnect":   0,
+					"ClientboundLoginSuccess":      2,
+					"ClientboundSetCompression":    3,
 				},
 				DirectionServerbound: {
-					"ServerboundLoginStart": 0,
+					"ServerboundEncryptionResponse": 1,
+					"ServerboundLoginStart":         0,
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
+					"ClientboundChatMessage":   15,
 					"ClientboundCustomPayload": 24,
 					"ClientboundDisconnect":    26,
 					"ClientboundJoinGame":      35,
 					"ClientboundKeepAlive":     31,
-					"ServerboundChatMessage":   15,
 				},
 				DirectionServerbound: {
 					"ServerboundChatMessage":    2,
@@ -228,17 +238,19 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
-					15: "ServerboundChatMessage",
+					15: "ClientboundChatMessage",
 					24: "ClientboundCustomPayload",
 					26: "ClientboundDisconnect",
 					31: "ClientboundKeepAlive",
@@ -273,21 +285,23 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundEncryptionRequest": 1,
+					"ClientboundLoginDisconnect":   0,
+					"ClientboundLoginSuccess":      2,
+					"ClientboundSetCompression":    3,
 				},
 				DirectionServerbound: {
-					"ServerboundLoginStart": 0,
+					"ServerboundEncryptionResponse": 1,
+					"ServerboundLoginStart":         0,
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
+					"ClientboundChatMessage":   15,
 					"ClientboundCustomPayload": 24,
 					"ClientboundDisconnect":    26,
 					"ClientboundJoinGame":      35,
 					"ClientboundKeepAlive":     31,
-					"ServerboundChatMessage":   15,
 				},
 				DirectionServerbound: {
 					"ServerboundChatMessage":    2,
@@ -315,17 +329,19 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
-					15: "ServerboundChatMessage",
+					15: "ClientboundChatMessage",
 					24: "ClientboundCustomPayload",
 					26: "ClientboundDisconnect",
 					31: "ClientboundKeepAlive",
@@ -360,21 +376,23 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundEncryptionRequest": 1,
+					"ClientboundLoginDisconnect":   0,
+					"ClientboundLoginSuccess":      2,
+					"ClientboundSetCompression":    3,
 				},
 				DirectionServerbound: {
-					"ServerboundLoginStart": 0,
+					"ServerboundEncryptionResponse": 1,
+					"ServerboundLoginStart":         0,
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
+					"ClientboundChatMessage":   15,
 					"ClientboundCustomPayload": 24,
 					"ClientboundDisconnect":    26,
 					"ClientboundJoinGame":      35,
 					"ClientboundKeepAlive":     31,
-					"ServerboundChatMessage":   15,
 				},
 				DirectionServerbound: {
 					"ServerboundChatMessage":    2,
@@ -402,17 +420,19 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
-					15: "ServerboundChatMessage",
+					15: "ClientboundChatMessage",
 					24: "ClientboundCustomPayload",
 					26: "ClientboundDisconnect",
 					31: "ClientboundKeepAlive",
@@ -447,21 +467,23 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundEncryptionRequest": 1,
+					"ClientboundLoginDisconnect":   0,
+					"ClientboundLoginSuccess":      2,
+					"ClientboundSetCompression":    3,
 				},
 				DirectionServerbound: {
-					"ServerboundLoginStart": 0,
+					"ServerboundEncryptionResponse": 1,
+					"ServerboundLoginStart":         0,
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
+					"ClientboundChatMessage":   15,
 					"ClientboundCustomPayload": 24,
 					"ClientboundDisconnect":    26,
 					"ClientboundJoinGame":      35,
 					"ClientboundKeepAlive":     31,
-					"ServerboundChatMessage":   15,
 				},
 				DirectionServerbound: {
 					"ServerboundChatMessage":    2,
@@ -489,17 +511,19 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
-					15: "ServerboundChatMessage",
+					15: "ClientboundChatMessage",
 					24: "ClientboundCustomPayload",
 					26: "ClientboundDisconnect",
 					31: "ClientboundKeepAlive",
@@ -534,21 +558,23 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundEncryptionRequest": 1,
+					"ClientboundLoginDisconnect":   0,
+					"ClientboundLoginSuccess":      2,
+					"ClientboundSetCompression":    3,
 				},
 				DirectionServerbound: {
-					"ServerboundLoginStart": 0,
+					"ServerboundEncryptionResponse": 1,
+					"ServerboundLoginStart":         0,
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
+					"ClientboundChatMessage":   15,
 					"ClientboundCustomPayload": 24,
 					"ClientboundDisconnect":    26,
 					"ClientboundJoinGame":      35,
 					"ClientboundKeepAlive":     31,
-					"ServerboundChatMessage":   15,
 				},
 				DirectionServerbound: {
 					"ServerboundChatMessage":    2,
@@ -576,17 +602,19 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
-					15: "ServerboundChatMessage",
+					15: "ClientboundChatMessage",
 					24: "ClientboundCustomPayload",
 					26: "ClientboundDisconnect",
 					31: "ClientboundKeepAlive",
@@ -621,21 +649,23 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundEncryptionRequest": 1,
+					"ClientboundLoginDisconnect":   0,
+					"ClientboundLoginSuccess":      2,
+					"ClientboundSetCompression":    3,
 				},
 				DirectionServerbound: {
-					"ServerboundLoginStart": 0,
+					"ServerboundEncryptionResponse": 1,
+					"ServerboundLoginStart":         0,
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
+					"ClientboundChatMessage":   15,
 					"ClientboundCustomPayload": 24,
 					"ClientboundDisconnect":    26,
 					"ClientboundJoinGame":      35,
 					"ClientboundKeepAlive":     31,
-					"ServerboundChatMessage":   15,
 				},
 				DirectionServerbound: {
 					"ServerboundChatMessage":    3,
@@ -663,17 +693,19 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
-					15: "ServerboundChatMessage",
+					15: "ClientboundChatMessage",
 					24: "ClientboundCustomPayload",
 					26: "ClientboundDisconnect",
 					31: "ClientboundKeepAlive",
@@ -708,21 +740,23 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundEncryptionRequest": 1,
+					"ClientboundLoginDisconnect":   0,
+					"ClientboundLoginSuccess":      2,
+					"ClientboundSetCompression":    3,
 				},
 				DirectionServerbound: {
-					"ServerboundLoginStart": 0,
+					"ServerboundEncryptionResponse": 1,
+					"ServerboundLoginStart":         0,
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
+					"ClientboundChatMessage":   15,
 					"ClientboundCustomPayload": 24,
 					"ClientboundDisconnect":    26,
 					"ClientboundJoinGame":      35,
 					"ClientboundKeepAlive":     31,
-					"ServerboundChatMessage":   15,
 				},
 				DirectionServerbound: {
 					"ServerboundChatMessage":    2,
@@ -750,17 +784,19 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
-					15: "ServerboundChatMessage",
+					15: "ClientboundChatMessage",
 					24: "ClientboundCustomPayload",
 					26: "ClientboundDisconnect",
 					31: "ClientboundKeepAlive",
@@ -795,21 +831,23 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundEncryptionRequest": 1,
+					"ClientboundLoginDisconnect":   0,
+					"ClientboundLoginSuccess":      2,
+					"ClientboundSetCompression":    3,
 				},
 				DirectionServerbound: {
-					"ServerboundLoginStart": 0,
+					"ServerboundEncryptionResponse": 1,
+					"ServerboundLoginStart":         0,
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
+					"ClientboundChatMessage":   15,
 					"ClientboundCustomPayload": 24,
 					"ClientboundDisconnect":    26,
 					"ClientboundJoinGame":      35,
 					"ClientboundKeepAlive":     31,
-					"ServerboundChatMessage":   15,
 				},
 				DirectionServerbound: {
 					"ServerboundChatMessage":    2,
@@ -837,17 +875,19 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
-					15: "ServerboundChatMessage",
+					15: "ClientboundChatMessage",
 					24: "ClientboundCustomPayload",
 					26: "ClientboundDisconnect",
 					31: "ClientboundKeepAlive",
@@ -882,21 +922,25 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundEncryptionRequest":  1,
+					"ClientboundLoginDisconnect":    0,
+					"ClientboundLoginPluginRequest": 4,
+					"ClientboundLoginSuccess":       2,
+					"ClientboundSetCompression":     3,
 				},
 				DirectionServerbound: {
-					"ServerboundLoginStart": 0,
+					"ServerboundEncryptionResponse":  1,
+					"ServerboundLoginPluginResponse": 2,
+					"ServerboundLoginStart":          0,
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
+					"ClientboundChatMessage":   14,
 					"ClientboundCustomPayload": 25,
 					"ClientboundDisconnect":    27,
 					"ClientboundJoinGame":      37,
 					"ClientboundKeepAlive":     33,
-					"ServerboundChatMessage":   14,
 				},
 				DirectionServerbound: {
 					"ServerboundChatMessage":    2,
@@ -924,17 +968,21 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
+					4: "ClientboundLoginPluginRequest",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
+					2: "ServerboundLoginPluginResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
-					14: "ServerboundChatMessage",
+					14: "ClientboundChatMessage",
 					25: "ClientboundCustomPayload",
 					27: "ClientboundDisconnect",
 					33: "ClientboundKeepAlive",
@@ -969,21 +1017,25 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundEncryptionRequest":  1,
+					"ClientboundLoginDisconnect":    0,
+					"ClientboundLoginPluginRequest": 4,
+					"ClientboundLoginSuccess":       2,
+					"ClientboundSetCompression":     3,
 				},
 				DirectionServerbound: {
-					"ServerboundLoginStart": 0,
+					"ServerboundEncryptionResponse":  1,
+					"ServerboundLoginPluginResponse": 2,
+					"ServerboundLoginStart":          0,
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
+					"ClientboundChatMessage":   14,
 					"ClientboundCustomPayload": 25,
 					"ClientboundDisconnect":    27,
 					"ClientboundJoinGame":      37,
 					"ClientboundKeepAlive":     33,
-					"ServerboundChatMessage":   14,
 				},
 				DirectionServerbound: {
 					"ServerboundChatMessage":    2,
@@ -1011,17 +1063,21 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
+					4: "ClientboundLoginPluginRequest",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
+					2: "ServerboundLoginPluginResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
-					14: "ServerboundChatMessage",
+					14: "ClientboundChatMessage",
 					25: "ClientboundCustomPayload",
 					27: "ClientboundDisconnect",
 					33: "ClientboundKeepAlive",
@@ -1056,21 +1112,25 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundEncryptionRequest":  1,
+					"ClientboundLoginDisconnect":    0,
+					"ClientboundLoginPluginRequest": 4,
+					"ClientboundLoginSuccess":       2,
+					"ClientboundSetCompression":     3,
 				},
 				DirectionServerbound: {
-					"ServerboundLoginStart": 0,
+					"ServerboundEncryptionResponse":  1,
+					"ServerboundLoginPluginResponse": 2,
+					"ServerboundLoginStart":          0,
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
+					"ClientboundChatMessage":   14,
 					"ClientboundCustomPayload": 25,
 					"ClientboundDisconnect":    27,
 					"ClientboundJoinGame":      37,
 					"ClientboundKeepAlive":     33,
-					"ServerboundChatMessage":   14,
 				},
 				DirectionServerbound: {
 					"ServerboundChatMessage":    2,
@@ -1098,17 +1158,21 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
+					4: "ClientboundLoginPluginRequest",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
+					2: "ServerboundLoginPluginResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
-					14: "ServerboundChatMessage",
+					14: "ClientboundChatMessage",
 					25: "ClientboundCustomPayload",
 					27: "ClientboundDisconnect",
 					33: "ClientboundKeepAlive",
@@ -1143,21 +1207,25 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundEncryptionRequest":  1,
+					"ClientboundLoginDisconnect":    0,
+					"ClientboundLoginPluginRequest": 4,
+					"ClientboundLoginSuccess":       2,
+					"ClientboundSetCompression":     3,
 				},
 				DirectionServerbound: {
-					"ServerboundLoginStart": 0,
+					"ServerboundEncryptionResponse":  1,
+					"ServerboundLoginPluginResponse": 2,
+					"ServerboundLoginStart":          0,
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
+					"ClientboundChatMessage":   14,
 					"ClientboundCustomPayload": 24,
 					"ClientboundDisconnect":    26,
 					"ClientboundJoinGame":      37,
 					"ClientboundKeepAlive":     32,
-					"ServerboundChatMessage":   14,
 				},
 				DirectionServerbound: {
 					"ServerboundChatMessage":    3,
@@ -1185,17 +1253,21 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
+					4: "ClientboundLoginPluginRequest",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
+					2: "ServerboundLoginPluginResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
-					14: "ServerboundChatMessage",
+					14: "ClientboundChatMessage",
 					24: "ClientboundCustomPayload",
 					26: "ClientboundDisconnect",
 					32: "ClientboundKeepAlive",
@@ -1230,21 +1302,25 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundEncryptionRequest":  1,
+					"ClientboundLoginDisconnect":    0,
+					"ClientboundLoginPluginRequest": 4,
+					"ClientboundLoginSuccess":       2,
+					"ClientboundSetCompression":     3,
 				},
 				DirectionServerbound: {
-					"ServerboundLoginStart": 0,
+					"ServerboundEncryptionResponse":  1,
+					"ServerboundLoginPluginResponse": 2,
+					"ServerboundLoginStart":          0,
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
+					"ClientboundChatMessage":   14,
 					"ClientboundCustomPayload": 24,
 					"ClientboundDisconnect":    26,
 					"ClientboundJoinGame":      37,
 					"ClientboundKeepAlive":     32,
-					"ServerboundChatMessage":   14,
 				},
 				DirectionServerbound: {
 					"ServerboundChatMessage":    3,
@@ -1272,17 +1348,21 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
+					4: "ClientboundLoginPluginRequest",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
+					2: "ServerboundLoginPluginResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
-					14: "ServerboundChatMessage",
+					14: "ClientboundChatMessage",
 					24: "ClientboundCustomPayload",
 					26: "ClientboundDisconnect",
 					32: "ClientboundKeepAlive",
@@ -1317,21 +1397,25 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundEncryptionRequest":  1,
+					"ClientboundLoginDisconnect":    0,
+					"ClientboundLoginPluginRequest": 4,
+					"ClientboundLoginSuccess":       2,
+					"ClientboundSetCompression":     3,
 				},
 				DirectionServerbound: {
-					"ServerboundLoginStart": 0,
+					"ServerboundEncryptionResponse":  1,
+					"ServerboundLoginPluginResponse": 2,
+					"ServerboundLoginStart":          0,
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
+					"ClientboundChatMessage":   14,
 					"ClientboundCustomPayload": 24,
 					"ClientboundDisconnect":    26,
 					"ClientboundJoinGame":      37,
 					"ClientboundKeepAlive":     32,
-					"ServerboundChatMessage":   14,
 				},
 				DirectionServerbound: {
 					"ServerboundChatMessage":    3,
@@ -1359,17 +1443,21 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
+					4: "ClientboundLoginPluginRequest",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
+					2: "ServerboundLoginPluginResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
-					14: "ServerboundChatMessage",
+					14: "ClientboundChatMessage",
 					24: "ClientboundCustomPayload",
 					26: "ClientboundDisconnect",
 					32: "ClientboundKeepAlive",
@@ -1404,21 +1492,25 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundEncryptionRequest":  1,
+					"ClientboundLoginDisconnect":    0,
+					"ClientboundLoginPluginRequest": 4,
+					"ClientboundLoginSuccess":       2,
+					"ClientboundSetCompression":     3,
 				},
 				DirectionServerbound: {
-					"ServerboundLoginStart": 0,
+					"ServerboundEncryptionResponse":  1,
+					"ServerboundLoginPluginResponse": 2,
+					"ServerboundLoginStart":          0,
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
+					"ClientboundChatMessage":   14,
 					"ClientboundCustomPayload": 24,
 					"ClientboundDisconnect":    26,
 					"ClientboundJoinGame":      37,
 					"ClientboundKeepAlive":     32,
-					"ServerboundChatMessage":   14,
 				},
 				DirectionServerbound: {
 					"ServerboundChatMessage":    3,
@@ -1446,17 +1538,21 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
+					4: "ClientboundLoginPluginRequest",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
+					2: "ServerboundLoginPluginResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
-					14: "ServerboundChatMessage",
+					14: "ClientboundChatMessage",
 					24: "ClientboundCustomPayload",
 					26: "ClientboundDisconnect",
 					32: "ClientboundKeepAlive",
@@ -1491,21 +1587,25 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundEncryptionRequest":  1,
+					"ClientboundLoginDisconnect":    0,
+					"ClientboundLoginPluginRequest": 4,
+					"ClientboundLoginSuccess":       2,
+					"ClientboundSetCompression":     3,
 				},
 				DirectionServerbound: {
-					"ServerboundLoginStart": 0,
+					"ServerboundEncryptionResponse":  1,
+					"ServerboundLoginPluginResponse": 2,
+					"ServerboundLoginStart":          0,
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
+					"ClientboundChatMessage":   15,
 					"ClientboundCustomPayload": 25,
 					"ClientboundDisconnect":    27,
 					"ClientboundJoinGame":      38,
 					"ClientboundKeepAlive":     33,
-					"ServerboundChatMessage":   15,
 				},
 				DirectionServerbound: {
 					"ServerboundChatMessage":    3,
@@ -1533,17 +1633,21 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
+					4: "ClientboundLoginPluginRequest",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
+					2: "ServerboundLoginPluginResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
-					15: "ServerboundChatMessage",
+					15: "ClientboundChatMessage",
 					25: "ClientboundCustomPayload",
 					27: "ClientboundDisconnect",
 					33: "ClientboundKeepAlive",
@@ -1578,21 +1682,25 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundEncryptionRequest":  1,
+					"ClientboundLoginDisconnect":    0,
+					"ClientboundLoginPluginRequest": 4,
+					"ClientboundLoginSuccess":       2,
+					"ClientboundSetCompression":     3,
 				},
 				DirectionServerbound: {
-					"ServerboundLoginStart": 0,
+					"ServerboundEncryptionResponse":  1,
+					"ServerboundLoginPluginResponse": 2,
+					"ServerboundLoginStart":          0,
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
+					"ClientboundChatMessage":   15,
 					"ClientboundCustomPayload": 25,
 					"ClientboundDisconnect":    27,
 					"ClientboundJoinGame":      38,
 					"ClientboundKeepAlive":     33,
-					"ServerboundChatMessage":   15,
 				},
 				DirectionServerbound: {
 					"ServerboundChatMessage":    3,
@@ -1620,17 +1728,21 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
+					4: "ClientboundLoginPluginRequest",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
+					2: "ServerboundLoginPluginResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
-					15: "ServerboundChatMessage",
+					15: "ClientboundChatMessage",
 					25: "ClientboundCustomPayload",
 					27: "ClientboundDisconnect",
 					33: "ClientboundKeepAlive",
@@ -1665,21 +1777,25 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundEncryptionRequest":  1,
+					"ClientboundLoginDisconnect":    0,
+					"ClientboundLoginPluginRequest": 4,
+					"ClientboundLoginSuccess":       2,
+					"ClientboundSetCompression":     3,
 				},
 				DirectionServerbound: {
-					"ServerboundLoginStart": 0,
+					"ServerboundEncryptionResponse":  1,
+					"ServerboundLoginPluginResponse": 2,
+					"ServerboundLoginStart":          0,
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
+					"ClientboundChatMessage":   15,
 					"ClientboundCustomPayload": 25,
 					"ClientboundDisconnect":    27,
 					"ClientboundJoinGame":      38,
 					"ClientboundKeepAlive":     33,
-					"ServerboundChatMessage":   15,
 				},
 				DirectionServerbound: {
 					"ServerboundChatMessage":    3,
@@ -1707,17 +1823,21 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
+					4: "ClientboundLoginPluginRequest",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
+					2: "ServerboundLoginPluginResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
-					15: "ServerboundChatMessage",
+					15: "ClientboundChatMessage",
 					25: "ClientboundCustomPayload",
 					27: "ClientboundDisconnect",
 					33: "ClientboundKeepAlive",
@@ -1752,21 +1872,25 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundEncryptionRequest":  1,
+					"ClientboundLoginDisconnect":    0,
+					"ClientboundLoginPluginRequest": 4,
+					"ClientboundLoginSuccess":       2,
+					"ClientboundSetCompression":     3,
 				},
 				DirectionServerbound: {
-					"ServerboundLoginStart": 0,
+					"ServerboundEncryptionResponse":  1,
+					"ServerboundLoginPluginResponse": 2,
+					"ServerboundLoginStart":          0,
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
+					"ClientboundChatMessage":   14,
 					"ClientboundCustomPayload": 24,
 					"ClientboundDisconnect":    26,
 					"ClientboundJoinGame":      37,
 					"ClientboundKeepAlive":     32,
-					"ServerboundChatMessage":   14,
 				},
 				DirectionServerbound: {
 					"ServerboundChatMessage":    3,
@@ -1794,17 +1918,21 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
+					4: "ClientboundLoginPluginRequest",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
+					2: "ServerboundLoginPluginResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
-					14: "ServerboundChatMessage",
+					14: "ClientboundChatMessage",
 					24: "ClientboundCustomPayload",
 					26: "ClientboundDisconnect",
 					32: "ClientboundKeepAlive",
@@ -1839,21 +1967,25 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundEncryptionRequest":  1,
+					"ClientboundLoginDisconnect":    0,
+					"ClientboundLoginPluginRequest": 4,
+					"ClientboundLoginSuccess":       2,
+					"ClientboundSetCompression":     3,
 				},
 				DirectionServerbound: {
-					"ServerboundLoginStart": 0,
+					"ServerboundEncryptionResponse":  1,
+					"ServerboundLoginPluginResponse": 2,
+					"ServerboundLoginStart":          0,
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
+					"ClientboundChatMessage":   14,
 					"ClientboundCustomPayload": 24,
 					"ClientboundDisconnect":    26,
 					"ClientboundJoinGame":      37,
 					"ClientboundKeepAlive":     32,
-					"ServerboundChatMessage":   14,
 				},
 				DirectionServerbound: {
 					"ServerboundChatMessage":    3,
@@ -1881,17 +2013,21 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
+					4: "ClientboundLoginPluginRequest",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
+					2: "ServerboundLoginPluginResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
-					14: "ServerboundChatMessage",
+					14: "ClientboundChatMessage",
 					24: "ClientboundCustomPayload",
 					26: "ClientboundDisconnect",
 					32: "ClientboundKeepAlive",
@@ -1926,21 +2062,25 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundEncryptionRequest":  1,
+					"ClientboundLoginDisconnect":    0,
+					"ClientboundLoginPluginRequest": 4,
+					"ClientboundLoginSuccess":       2,
+					"ClientboundSetCompression":     3,
 				},
 				DirectionServerbound: {
-					"ServerboundLoginStart": 0,
+					"ServerboundEncryptionResponse":  1,
+					"ServerboundLoginPluginResponse": 2,
+					"ServerboundLoginStart":          0,
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
+					"ClientboundChatMessage":   14,
 					"ClientboundCustomPayload": 23,
 					"ClientboundDisconnect":    25,
 					"ClientboundJoinGame":      36,
 					"ClientboundKeepAlive":     31,
-					"ServerboundChatMessage":   14,
 				},
 				DirectionServerbound: {
 					"ServerboundChatMessage":    3,
@@ -1968,17 +2108,21 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
+					4: "ClientboundLoginPluginRequest",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
+					2: "ServerboundLoginPluginResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
-					14: "ServerboundChatMessage",
+					14: "ClientboundChatMessage",
 					23: "ClientboundCustomPayload",
 					25: "ClientboundDisconnect",
 					31: "ClientboundKeepAlive",
@@ -2013,22 +2157,26 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundEncryptionRequest":  1,
+					"ClientboundLoginDisconnect":    0,
+					"ClientboundLoginPluginRequest": 4,
+					"ClientboundLoginSuccess":       2,
+					"ClientboundSetCompression":     3,
 				},
 				DirectionServerbound: {
-					"ServerboundLoginStart": 0,
+					"ServerboundEncryptionResponse":  1,
+					"ServerboundLoginPluginResponse": 2,
+					"ServerboundLoginStart":          0,
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
+					"ClientboundChatMessage":   15,
 					"ClientboundCustomPayload": 24,
 					"ClientboundDisconnect":    26,
 					"ClientboundJoinGame":      38,
 					"ClientboundKeepAlive":     33,
 					"ClientboundPong":          48,
-					"ServerboundChatMessage":   15,
 				},
 				DirectionServerbound: {
 					"ServerboundChatMessage":    3,
@@ -2056,17 +2204,21 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
+					4: "ClientboundLoginPluginRequest",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
+					2: "ServerboundLoginPluginResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
-					15: "ServerboundChatMessage",
+					15: "ClientboundChatMessage",
 					24: "ClientboundCustomPayload",
 					26: "ClientboundDisconnect",
 					33: "ClientboundKeepAlive",
@@ -2102,22 +2254,26 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundEncryptionRequest":  1,
+					"ClientboundLoginDisconnect":    0,
+					"ClientboundLoginPluginRequest": 4,
+					"ClientboundLoginSuccess":       2,
+					"ClientboundSetCompression":     3,
 				},
 				DirectionServerbound: {
-					"ServerboundLoginStart": 0,
+					"ServerboundEncryptionResponse":  1,
+					"ServerboundLoginPluginResponse": 2,
+					"ServerboundLoginStart":          0,
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
+					"ClientboundChatMessage":   15,
 					"ClientboundCustomPayload": 24,
 					"ClientboundDisconnect":    26,
 					"ClientboundJoinGame":      38,
 					"ClientboundKeepAlive":     33,
 					"ClientboundPong":          48,
-					"ServerboundChatMessage":   15,
 				},
 				DirectionServerbound: {
 					"ServerboundChatMessage":    3,
@@ -2145,17 +2301,21 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
+					4: "ClientboundLoginPluginRequest",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
+					2: "ServerboundLoginPluginResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
-					15: "ServerboundChatMessage",
+					15: "ClientboundChatMessage",
 					24: "ClientboundCustomPayload",
 					26: "ClientboundDisconnect",
 					33: "ClientboundKeepAlive",
@@ -2191,22 +2351,26 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundEncryptionRequest":  1,
+					"ClientboundLoginDisconnect":    0,
+					"ClientboundLoginPluginRequest": 4,
+					"ClientboundLoginSuccess":       2,
+					"ClientboundSetCompression":     3,
 				},
 				DirectionServerbound: {
-					"ServerboundLoginStart": 0,
+					"ServerboundEncryptionResponse":  1,
+					"ServerboundLoginPluginResponse": 2,
+					"ServerboundLoginStart":          0,
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
+					"ClientboundChatMessage":   15,
 					"ClientboundCustomPayload": 24,
 					"ClientboundDisconnect":    26,
 					"ClientboundJoinGame":      38,
 					"ClientboundKeepAlive":     33,
 					"ClientboundPong":          48,
-					"ServerboundChatMessage":   15,
 				},
 				DirectionServerbound: {
 					"ServerboundChatMessage":    3,
@@ -2234,17 +2398,21 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
+					4: "ClientboundLoginPluginRequest",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
+					2: "ServerboundLoginPluginResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
-					15: "ServerboundChatMessage",
+					15: "ClientboundChatMessage",
 					24: "ClientboundCustomPayload",
 					26: "ClientboundDisconnect",
 					33: "ClientboundKeepAlive",
@@ -2280,22 +2448,26 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundEncryptionRequest":  1,
+					"ClientboundLoginDisconnect":    0,
+					"ClientboundLoginPluginRequest": 4,
+					"ClientboundLoginSuccess":       2,
+					"ClientboundSetCompression":     3,
 				},
 				DirectionServerbound: {
-					"ServerboundLoginStart": 0,
+					"ServerboundEncryptionResponse":  1,
+					"ServerboundLoginPluginResponse": 2,
+					"ServerboundLoginStart":          0,
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
+					"ClientboundChatMessage":   15,
 					"ClientboundCustomPayload": 24,
 					"ClientboundDisconnect":    26,
 					"ClientboundJoinGame":      38,
 					"ClientboundKeepAlive":     33,
 					"ClientboundPong":          48,
-					"ServerboundChatMessage":   15,
 				},
 				DirectionServerbound: {
 					"ServerboundChatMessage":    3,
@@ -2323,17 +2495,21 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
+					4: "ClientboundLoginPluginRequest",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
+					2: "ServerboundLoginPluginResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
-					15: "ServerboundChatMessage",
+					15: "ClientboundChatMessage",
 					24: "ClientboundCustomPayload",
 					26: "ClientboundDisconnect",
 					33: "ClientboundKeepAlive",
@@ -2369,12 +2545,16 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundEncryptionRequest":  1,
+					"ClientboundLoginDisconnect":    0,
+					"ClientboundLoginPluginRequest": 4,
+					"ClientboundLoginSuccess":       2,
+					"ClientboundSetCompression":     3,
 				},
 				DirectionServerbound: {
-					"ServerboundLoginStart": 0,
+					"ServerboundEncryptionResponse":  1,
+					"ServerboundLoginPluginResponse": 2,
+					"ServerboundLoginStart":          0,
 				},
 			},
 			StatePlay: {
@@ -2383,7 +2563,9 @@ func init() {
 					"ClientboundDisconnect":    23,
 					"ClientboundJoinGame":      35,
 					"ClientboundKeepAlive":     30,
+					"ClientboundPlayerChat":    48,
 					"ClientboundPong":          45,
+					"ClientboundSystemChat":    95,
 				},
 				DirectionServerbound: {
 					"ServerboundChatMessage":    4,
@@ -2411,12 +2593,16 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
+					4: "ClientboundLoginPluginRequest",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
+					2: "ServerboundLoginPluginResponse",
 				},
 			},
 			StatePlay: {
@@ -2426,6 +2612,8 @@ func init() {
 					30: "ClientboundKeepAlive",
 					35: "ClientboundJoinGame",
 					45: "ClientboundPong",
+					48: "ClientboundPlayerChat",
+					95: "ClientboundSystemChat",
 				},
 				DirectionServerbound: {
 					4:  "ServerboundChatMessage",
@@ -2456,12 +2644,16 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundEncryptionRequest":  1,
+					"ClientboundLoginDisconnect":    0,
+					"ClientboundLoginPluginRequest": 4,
+					"ClientboundLoginSuccess":       2,
+					"ClientboundSetCompression":     3,
 				},
 				DirectionServerbound: {
-					"ServerboundLoginStart": 0,
+					"ServerboundEncryptionResponse":  1,
+					"ServerboundLoginPluginResponse": 2,
+					"ServerboundLoginStart":          0,
 				},
 			},
 			StatePlay: {
@@ -2470,13 +2662,16 @@ func init() {
 					"ClientboundDisconnect":    25,
 					"ClientboundJoinGame":      37,
 					"ClientboundKeepAlive":     32,
+					"ClientboundPlayerChat":    51,
 					"ClientboundPong":          47,
+					"ClientboundSystemChat":    98,
 				},
 				DirectionServerbound: {
-					"ServerboundChatMessage":    5,
-					"ServerboundClientSettings": 8,
-					"ServerboundCustomPayload":  13,
-					"ServerboundKeepAlive":      18,
+					"ServerboundChatMessage":            5,
+					"ServerboundClientSettings":         8,
+					"ServerboundCustomPayload":          13,
+					"ServerboundKeepAlive":              18,
+					"ServerboundMessageAcknowledgement": 3,
 				},
 			},
 			StateStatus: {
@@ -2498,12 +2693,16 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
+					4: "ClientboundLoginPluginRequest",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
+					2: "ServerboundLoginPluginResponse",
 				},
 			},
 			StatePlay: {
@@ -2513,8 +2712,11 @@ func init() {
 					32: "ClientboundKeepAlive",
 					37: "ClientboundJoinGame",
 					47: "ClientboundPong",
+					51: "ClientboundPlayerChat",
+					98: "ClientboundSystemChat",
 				},
 				DirectionServerbound: {
+					3:  "ServerboundMessageAcknowledgement",
 					5:  "ServerboundChatMessage",
 					8:  "ServerboundClientSettings",
 					13: "ServerboundCustomPayload",
@@ -2543,28 +2745,37 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundEncryptionRequest":  1,
+					"ClientboundLoginDisconnect":    0,
+					"ClientboundLoginPluginRequest": 4,
+					"ClientboundLoginSuccess":       2,
+					"ClientboundSetCompression":     3,
 				},
 				DirectionServerbound: {
-					"ServerboundLoginStart": 0,
+					"ServerboundEncryptionResponse":  1,
+					"ServerboundLoginPluginResponse": 2,
+					"ServerboundLoginStart":          0,
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
 					"ClientboundCustomPayload": 21,
 					"ClientboundDisconnect":    23,
+					"ClientboundDisguisedChat": 24,
 					"ClientboundFeatureFlags":  103,
 					"ClientboundJoinGame":      36,
 					"ClientboundKeepAlive":     31,
+					"ClientboundPlayerChat":    49,
 					"ClientboundPong":          46,
+					"ClientboundSystemChat":    96,
 				},
 				DirectionServerbound: {
-					"ServerboundChatMessage":    5,
-					"ServerboundClientSettings": 7,
-					"ServerboundCustomPayload":  12,
-					"ServerboundKeepAlive":      17,
+					"ServerboundChatMessage":            5,
+					"ServerboundChatSessionUpdate":      32,
+					"ServerboundClientSettings":         7,
+					"ServerboundCustomPayload":          12,
+					"ServerboundKeepAlive":              17,
+					"ServerboundMessageAcknowledgement": 3,
 				},
 			},
 			StateStatus: {
@@ -2586,28 +2797,37 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
+					4: "ClientboundLoginPluginRequest",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
+					2: "ServerboundLoginPluginResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
 					21:  "ClientboundCustomPayload",
 					23:  "ClientboundDisconnect",
+					24:  "ClientboundDisguisedChat",
 					31:  "ClientboundKeepAlive",
 					36:  "ClientboundJoinGame",
 					46:  "ClientboundPong",
+					49:  "ClientboundPlayerChat",
+					96:  "ClientboundSystemChat",
 					103: "ClientboundFeatureFlags",
 				},
 				DirectionServerbound: {
+					3:  "ServerboundMessageAcknowledgement",
 					5:  "ServerboundChatMessage",
 					7:  "ServerboundClientSettings",
 					12: "ServerboundCustomPayload",
 					17: "ServerboundKeepAlive",
+					32: "ServerboundChatSessionUpdate",
 				},
 			},
 			StateStatus: {
@@ -2632,28 +2852,37 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundEncryptionRequest":  1,
+					"ClientboundLoginDisconnect":    0,
+					"ClientboundLoginPluginRequest": 4,
+					"ClientboundLoginSuccess":       2,
+					"ClientboundSetCompression":     3,
 				},
 				DirectionServerbound: {
-					"ServerboundLoginStart": 0,
+					"ServerboundEncryptionResponse":  1,
+					"ServerboundLoginPluginResponse": 2,
+					"ServerboundLoginStart":          0,
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
 					"ClientboundCustomPayload": 23,
 					"ClientboundDisconnect":    26,
+					"ClientboundDisguisedChat": 27,
 					"ClientboundFeatureFlags":  107,
 					"ClientboundJoinGame":      40,
 					"ClientboundKeepAlive":     35,
+					"ClientboundPlayerChat":    53,
 					"ClientboundPong":          50,
+					"ClientboundSystemChat":    100,
 				},
 				DirectionServerbound: {
-					"ServerboundChatMessage":    5,
-					"ServerboundClientSettings": 8,
-					"ServerboundCustomPayload":  13,
-					"ServerboundKeepAlive":      18,
+					"ServerboundChatMessage":            5,
+					"ServerboundChatSessionUpdate":      6,
+					"ServerboundClientSettings":         8,
+					"ServerboundCustomPayload":          13,
+					"ServerboundKeepAlive":              18,
+					"ServerboundMessageAcknowledgement": 3,
 				},
 			},
 			StateStatus: {
@@ -2675,25 +2904,34 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
+					4: "ClientboundLoginPluginRequest",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
+					2: "ServerboundLoginPluginResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
 					23:  "ClientboundCustomPayload",
 					26:  "ClientboundDisconnect",
+					27:  "ClientboundDisguisedChat",
 					35:  "ClientboundKeepAlive",
 					40:  "ClientboundJoinGame",
 					50:  "ClientboundPong",
+					53:  "ClientboundPlayerChat",
+					100: "ClientboundSystemChat",
 					107: "ClientboundFeatureFlags",
 				},
 				DirectionServerbound: {
+					3:  "ServerboundMessageAcknowledgement",
 					5:  "ServerboundChatMessage",
+					6:  "ServerboundChatSessionUpdate",
 					8:  "ServerboundClientSettings",
 					13: "ServerboundCustomPayload",
 					18: "ServerboundKeepAlive",
@@ -2721,28 +2959,37 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundEncryptionRequest":  1,
+					"ClientboundLoginDisconnect":    0,
+					"ClientboundLoginPluginRequest": 4,
+					"ClientboundLoginSuccess":       2,
+					"ClientboundSetCompression":     3,
 				},
 				DirectionServerbound: {
-					"ServerboundLoginStart": 0,
+					"ServerboundEncryptionResponse":  1,
+					"ServerboundLoginPluginResponse": 2,
+					"ServerboundLoginStart":          0,
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
 					"ClientboundCustomPayload": 23,
 					"ClientboundDisconnect":    26,
+					"ClientboundDisguisedChat": 27,
 					"ClientboundFeatureFlags":  107,
 					"ClientboundJoinGame":      40,
 					"ClientboundKeepAlive":     35,
+					"ClientboundPlayerChat":    53,
 					"ClientboundPong":          50,
+					"ClientboundSystemChat":    100,
 				},
 				DirectionServerbound: {
-					"ServerboundChatMessage":    5,
-					"ServerboundClientSettings": 8,
-					"ServerboundCustomPayload":  13,
-					"ServerboundKeepAlive":      18,
+					"ServerboundChatMessage":            5,
+					"ServerboundChatSessionUpdate":      6,
+					"ServerboundClientSettings":         8,
+					"ServerboundCustomPayload":          13,
+					"ServerboundKeepAlive":              18,
+					"ServerboundMessageAcknowledgement": 3,
 				},
 			},
 			StateStatus: {
@@ -2764,25 +3011,34 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
+					4: "ClientboundLoginPluginRequest",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
+					2: "ServerboundLoginPluginResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
 					23:  "ClientboundCustomPayload",
 					26:  "ClientboundDisconnect",
+					27:  "ClientboundDisguisedChat",
 					35:  "ClientboundKeepAlive",
 					40:  "ClientboundJoinGame",
 					50:  "ClientboundPong",
+					53:  "ClientboundPlayerChat",
+					100: "ClientboundSystemChat",
 					107: "ClientboundFeatureFlags",
 				},
 				DirectionServerbound: {
+					3:  "ServerboundMessageAcknowledgement",
 					5:  "ServerboundChatMessage",
+					6:  "ServerboundChatSessionUpdate",
 					8:  "ServerboundClientSettings",
 					13: "ServerboundCustomPayload",
 					18: "ServerboundKeepAlive",
@@ -2828,28 +3084,37 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundEncryptionRequest":  1,
+					"ClientboundLoginDisconnect":    0,
+					"ClientboundLoginPluginRequest": 4,
+					"ClientboundLoginSuccess":       2,
+					"ClientboundSetCompression":     3,
 				},
 				DirectionServerbound: {
-					"ServerboundLoginAcknowledged": 3,
-					"ServerboundLoginStart":        0,
+					"ServerboundEncryptionResponse":  1,
+					"ServerboundLoginAcknowledged":   3,
+					"ServerboundLoginPluginResponse": 2,
+					"ServerboundLoginStart":          0,
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
 					"ClientboundCustomPayload": 24,
 					"ClientboundDisconnect":    27,
+					"ClientboundDisguisedChat": 28,
 					"ClientboundJoinGame":      41,
 					"ClientboundKeepAlive":     36,
+					"ClientboundPlayerChat":    55,
 					"ClientboundPong":          51,
+					"ClientboundSystemChat":    103,
 				},
 				DirectionServerbound: {
-					"ServerboundChatMessage":    5,
-					"ServerboundClientSettings": 9,
-					"ServerboundCustomPayload":  15,
-					"ServerboundKeepAlive":      20,
+					"ServerboundChatMessage":            5,
+					"ServerboundChatSessionUpdate":      6,
+					"ServerboundClientSettings":         9,
+					"ServerboundCustomPayload":          15,
+					"ServerboundKeepAlive":              20,
+					"ServerboundMessageAcknowledgement": 3,
 				},
 			},
 			StateStatus: {
@@ -2889,25 +3154,34 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
+					4: "ClientboundLoginPluginRequest",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
+					2: "ServerboundLoginPluginResponse",
 					3: "ServerboundLoginAcknowledged",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
-					24: "ClientboundCustomPayload",
-					27: "ClientboundDisconnect",
-					36: "ClientboundKeepAlive",
-					41: "ClientboundJoinGame",
-					51: "ClientboundPong",
+					24:  "ClientboundCustomPayload",
+					27:  "ClientboundDisconnect",
+					28:  "ClientboundDisguisedChat",
+					36:  "ClientboundKeepAlive",
+					41:  "ClientboundJoinGame",
+					51:  "ClientboundPong",
+					55:  "ClientboundPlayerChat",
+					103: "ClientboundSystemChat",
 				},
 				DirectionServerbound: {
+					3:  "ServerboundMessageAcknowledgement",
 					5:  "ServerboundChatMessage",
+					6:  "ServerboundChatSessionUpdate",
 					9:  "ServerboundClientSettings",
 					15: "ServerboundCustomPayload",
 					20: "ServerboundKeepAlive",
@@ -2953,28 +3227,37 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundEncryptionRequest":  1,
+					"ClientboundLoginDisconnect":    0,
+					"ClientboundLoginPluginRequest": 4,
+					"ClientboundLoginSuccess":       2,
+					"ClientboundSetCompression":     3,
 				},
 				DirectionServerbound: {
-					"ServerboundLoginAcknowledged": 3,
-					"ServerboundLoginStart":        0,
+					"ServerboundEncryptionResponse":  1,
+					"ServerboundLoginAcknowledged":   3,
+					"ServerboundLoginPluginResponse": 2,
+					"ServerboundLoginStart":          0,
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
 					"ClientboundCustomPayload": 24,
 					"ClientboundDisconnect":    27,
+					"ClientboundDisguisedChat": 28,
 					"ClientboundJoinGame":      41,
 					"ClientboundKeepAlive":     36,
+					"ClientboundPlayerChat":    55,
 					"ClientboundPong":          51,
+					"ClientboundSystemChat":    105,
 				},
 				DirectionServerbound: {
-					"ServerboundChatMessage":    5,
-					"ServerboundClientSettings": 9,
-					"ServerboundCustomPayload":  16,
-					"ServerboundKeepAlive":      21,
+					"ServerboundChatMessage":            5,
+					"ServerboundChatSessionUpdate":      6,
+					"ServerboundClientSettings":         9,
+					"ServerboundCustomPayload":          16,
+					"ServerboundKeepAlive":              21,
+					"ServerboundMessageAcknowledgement": 3,
 				},
 			},
 			StateStatus: {
@@ -3014,25 +3297,34 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
+					4: "ClientboundLoginPluginRequest",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
+					2: "ServerboundLoginPluginResponse",
 					3: "ServerboundLoginAcknowledged",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
-					24: "ClientboundCustomPayload",
-					27: "ClientboundDisconnect",
-					36: "ClientboundKeepAlive",
-					41: "ClientboundJoinGame",
-					51: "ClientboundPong",
+					24:  "ClientboundCustomPayload",
+					27:  "ClientboundDisconnect",
+					28:  "ClientboundDisguisedChat",
+					36:  "ClientboundKeepAlive",
+					41:  "ClientboundJoinGame",
+					51:  "ClientboundPong",
+					55:  "ClientboundPlayerChat",
+					105: "ClientboundSystemChat",
 				},
 				DirectionServerbound: {
+					3:  "ServerboundMessageAcknowledgement",
 					5:  "ServerboundChatMessage",
+					6:  "ServerboundChatSessionUpdate",
 					9:  "ServerboundClientSettings",
 					16: "ServerboundCustomPayload",
 					21: "ServerboundKeepAlive",
@@ -3082,15 +3374,19 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundCookieRequest":  5,
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundCookieRequest":      5,
+					"ClientboundEncryptionRequest":  1,
+					"ClientboundLoginDisconnect":    0,
+					"ClientboundLoginPluginRequest": 4,
+					"ClientboundLoginSuccess":       2,
+					"ClientboundSetCompression":     3,
 				},
 				DirectionServerbound: {
-					"ServerboundCookieResponse":    4,
-					"ServerboundLoginAcknowledged": 3,
-					"ServerboundLoginStart":        0,
+					"ServerboundCookieResponse":      4,
+					"ServerboundEncryptionResponse":  1,
+					"ServerboundLoginAcknowledged":   3,
+					"ServerboundLoginPluginResponse": 2,
+					"ServerboundLoginStart":          0,
 				},
 			},
 			StatePlay: {
@@ -3098,16 +3394,21 @@ func init() {
 					"ClientboundCookieRequest": 22,
 					"ClientboundCustomPayload": 25,
 					"ClientboundDisconnect":    29,
+					"ClientboundDisguisedChat": 30,
 					"ClientboundJoinGame":      43,
 					"ClientboundKeepAlive":     38,
+					"ClientboundPlayerChat":    57,
 					"ClientboundPong":          53,
+					"ClientboundSystemChat":    108,
 				},
 				DirectionServerbound: {
-					"ServerboundChatMessage":    6,
-					"ServerboundClientSettings": 10,
-					"ServerboundCookieResponse": 17,
-					"ServerboundCustomPayload":  18,
-					"ServerboundKeepAlive":      24,
+					"ServerboundChatMessage":            6,
+					"ServerboundChatSessionUpdate":      7,
+					"ServerboundClientSettings":         10,
+					"ServerboundCookieResponse":         17,
+					"ServerboundCustomPayload":          18,
+					"ServerboundKeepAlive":              24,
+					"ServerboundMessageAcknowledgement": 3,
 				},
 			},
 			StateStatus: {
@@ -3151,28 +3452,37 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
+					4: "ClientboundLoginPluginRequest",
 					5: "ClientboundCookieRequest",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
+					2: "ServerboundLoginPluginResponse",
 					3: "ServerboundLoginAcknowledged",
 					4: "ServerboundCookieResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
-					22: "ClientboundCookieRequest",
-					25: "ClientboundCustomPayload",
-					29: "ClientboundDisconnect",
-					38: "ClientboundKeepAlive",
-					43: "ClientboundJoinGame",
-					53: "ClientboundPong",
+					22:  "ClientboundCookieRequest",
+					25:  "ClientboundCustomPayload",
+					29:  "ClientboundDisconnect",
+					30:  "ClientboundDisguisedChat",
+					38:  "ClientboundKeepAlive",
+					43:  "ClientboundJoinGame",
+					53:  "ClientboundPong",
+					57:  "ClientboundPlayerChat",
+					108: "ClientboundSystemChat",
 				},
 				DirectionServerbound: {
+					3:  "ServerboundMessageAcknowledgement",
 					6:  "ServerboundChatMessage",
+					7:  "ServerboundChatSessionUpdate",
 					10: "ServerboundClientSettings",
 					17: "ServerboundCookieResponse",
 					18: "ServerboundCustomPayload",
@@ -3223,15 +3533,19 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundCookieRequest":  5,
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundCookieRequest":      5,
+					"ClientboundEncryptionRequest":  1,
+					"ClientboundLoginDisconnect":    0,
+					"ClientboundLoginPluginRequest": 4,
+					"ClientboundLoginSuccess":       2,
+					"ClientboundSetCompression":     3,
 				},
 				DirectionServerbound: {
-					"ServerboundCookieResponse":    4,
-					"ServerboundLoginAcknowledged": 3,
-					"ServerboundLoginStart":        0,
+					"ServerboundCookieResponse":      4,
+					"ServerboundEncryptionResponse":  1,
+					"ServerboundLoginAcknowledged":   3,
+					"ServerboundLoginPluginResponse": 2,
+					"ServerboundLoginStart":          0,
 				},
 			},
 			StatePlay: {
@@ -3239,16 +3553,21 @@ func init() {
 					"ClientboundCookieRequest": 22,
 					"ClientboundCustomPayload": 25,
 					"ClientboundDisconnect":    29,
+					"ClientboundDisguisedChat": 30,
 					"ClientboundJoinGame":      43,
 					"ClientboundKeepAlive":     38,
+					"ClientboundPlayerChat":    57,
 					"ClientboundPong":          53,
+					"ClientboundSystemChat":    108,
 				},
 				DirectionServerbound: {
-					"ServerboundChatMessage":    6,
-					"ServerboundClientSettings": 10,
-					"ServerboundCookieResponse": 17,
-					"ServerboundCustomPayload":  18,
-					"ServerboundKeepAlive":      24,
+					"ServerboundChatMessage":            6,
+					"ServerboundChatSessionUpdate":      7,
+					"ServerboundClientSettings":         10,
+					"ServerboundCookieResponse":         17,
+					"ServerboundCustomPayload":          18,
+					"ServerboundKeepAlive":              24,
+					"ServerboundMessageAcknowledgement": 3,
 				},
 			},
 			StateStatus: {
@@ -3292,28 +3611,37 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
+					4: "ClientboundLoginPluginRequest",
 					5: "ClientboundCookieRequest",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
+					2: "ServerboundLoginPluginResponse",
 					3: "ServerboundLoginAcknowledged",
 					4: "ServerboundCookieResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
-					22: "ClientboundCookieRequest",
-					25: "ClientboundCustomPayload",
-					29: "ClientboundDisconnect",
-					38: "ClientboundKeepAlive",
-					43: "ClientboundJoinGame",
-					53: "ClientboundPong",
+					22:  "ClientboundCookieRequest",
+					25:  "ClientboundCustomPayload",
+					29:  "ClientboundDisconnect",
+					30:  "ClientboundDisguisedChat",
+					38:  "ClientboundKeepAlive",
+					43:  "ClientboundJoinGame",
+					53:  "ClientboundPong",
+					57:  "ClientboundPlayerChat",
+					108: "ClientboundSystemChat",
 				},
 				DirectionServerbound: {
+					3:  "ServerboundMessageAcknowledgement",
 					6:  "ServerboundChatMessage",
+					7:  "ServerboundChatSessionUpdate",
 					10: "ServerboundClientSettings",
 					17: "ServerboundCookieResponse",
 					18: "ServerboundCustomPayload",
@@ -3364,15 +3692,19 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundCookieRequest":  5,
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundCookieRequest":      5,
+					"ClientboundEncryptionRequest":  1,
+					"ClientboundLoginDisconnect":    0,
+					"ClientboundLoginPluginRequest": 4,
+					"ClientboundLoginSuccess":       2,
+					"ClientboundSetCompression":     3,
 				},
 				DirectionServerbound: {
-					"ServerboundCookieResponse":    4,
-					"ServerboundLoginAcknowledged": 3,
-					"ServerboundLoginStart":        0,
+					"ServerboundCookieResponse":      4,
+					"ServerboundEncryptionResponse":  1,
+					"ServerboundLoginAcknowledged":   3,
+					"ServerboundLoginPluginResponse": 2,
+					"ServerboundLoginStart":          0,
 				},
 			},
 			StatePlay: {
@@ -3380,16 +3712,21 @@ func init() {
 					"ClientboundCookieRequest": 22,
 					"ClientboundCustomPayload": 25,
 					"ClientboundDisconnect":    29,
+					"ClientboundDisguisedChat": 30,
 					"ClientboundJoinGame":      44,
 					"ClientboundKeepAlive":     39,
+					"ClientboundPlayerChat":    59,
 					"ClientboundPong":          55,
+					"ClientboundSystemChat":    115,
 				},
 				DirectionServerbound: {
-					"ServerboundChatMessage":    7,
-					"ServerboundClientSettings": 12,
-					"ServerboundCookieResponse": 19,
-					"ServerboundCustomPayload":  20,
-					"ServerboundKeepAlive":      26,
+					"ServerboundChatMessage":            7,
+					"ServerboundChatSessionUpdate":      8,
+					"ServerboundClientSettings":         12,
+					"ServerboundCookieResponse":         19,
+					"ServerboundCustomPayload":          20,
+					"ServerboundKeepAlive":              26,
+					"ServerboundMessageAcknowledgement": 4,
 				},
 			},
 			StateStatus: {
@@ -3433,28 +3770,37 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
+					4: "ClientboundLoginPluginRequest",
 					5: "ClientboundCookieRequest",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
+					2: "ServerboundLoginPluginResponse",
 					3: "ServerboundLoginAcknowledged",
 					4: "ServerboundCookieResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
-					22: "ClientboundCookieRequest",
-					25: "ClientboundCustomPayload",
-					29: "ClientboundDisconnect",
-					39: "ClientboundKeepAlive",
-					44: "ClientboundJoinGame",
-					55: "ClientboundPong",
+					22:  "ClientboundCookieRequest",
+					25:  "ClientboundCustomPayload",
+					29:  "ClientboundDisconnect",
+					30:  "ClientboundDisguisedChat",
+					39:  "ClientboundKeepAlive",
+					44:  "ClientboundJoinGame",
+					55:  "ClientboundPong",
+					59:  "ClientboundPlayerChat",
+					115: "ClientboundSystemChat",
 				},
 				DirectionServerbound: {
+					4:  "ServerboundMessageAcknowledgement",
 					7:  "ServerboundChatMessage",
+					8:  "ServerboundChatSessionUpdate",
 					12: "ServerboundClientSettings",
 					19: "ServerboundCookieResponse",
 					20: "ServerboundCustomPayload",
@@ -3505,15 +3851,19 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundCookieRequest":  5,
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundCookieRequest":      5,
+					"ClientboundEncryptionRequest":  1,
+					"ClientboundLoginDisconnect":    0,
+					"ClientboundLoginPluginRequest": 4,
+					"ClientboundLoginSuccess":       2,
+					"ClientboundSetCompression":     3,
 				},
 				DirectionServerbound: {
-					"ServerboundCookieResponse":    4,
-					"ServerboundLoginAcknowledged": 3,
-					"ServerboundLoginStart":        0,
+					"ServerboundCookieResponse":      4,
+					"ServerboundEncryptionResponse":  1,
+					"ServerboundLoginAcknowledged":   3,
+					"ServerboundLoginPluginResponse": 2,
+					"ServerboundLoginStart":          0,
 				},
 			},
 			StatePlay: {
@@ -3521,16 +3871,21 @@ func init() {
 					"ClientboundCookieRequest": 22,
 					"ClientboundCustomPayload": 25,
 					"ClientboundDisconnect":    29,
+					"ClientboundDisguisedChat": 30,
 					"ClientboundJoinGame":      44,
 					"ClientboundKeepAlive":     39,
+					"ClientboundPlayerChat":    59,
 					"ClientboundPong":          55,
+					"ClientboundSystemChat":    115,
 				},
 				DirectionServerbound: {
-					"ServerboundChatMessage":    7,
-					"ServerboundClientSettings": 12,
-					"ServerboundCookieResponse": 19,
-					"ServerboundCustomPayload":  20,
-					"ServerboundKeepAlive":      26,
+					"ServerboundChatMessage":            7,
+					"ServerboundChatSessionUpdate":      8,
+					"ServerboundClientSettings":         12,
+					"ServerboundCookieResponse":         19,
+					"ServerboundCustomPayload":          20,
+					"ServerboundKeepAlive":              26,
+					"ServerboundMessageAcknowledgement": 4,
 				},
 			},
 			StateStatus: {
@@ -3574,28 +3929,37 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
+					4: "ClientboundLoginPluginRequest",
 					5: "ClientboundCookieRequest",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
+					2: "ServerboundLoginPluginResponse",
 					3: "ServerboundLoginAcknowledged",
 					4: "ServerboundCookieResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
-					22: "ClientboundCookieRequest",
-					25: "ClientboundCustomPayload",
-					29: "ClientboundDisconnect",
-					39: "ClientboundKeepAlive",
-					44: "ClientboundJoinGame",
-					55: "ClientboundPong",
+					22:  "ClientboundCookieRequest",
+					25:  "ClientboundCustomPayload",
+					29:  "ClientboundDisconnect",
+					30:  "ClientboundDisguisedChat",
+					39:  "ClientboundKeepAlive",
+					44:  "ClientboundJoinGame",
+					55:  "ClientboundPong",
+					59:  "ClientboundPlayerChat",
+					115: "ClientboundSystemChat",
 				},
 				DirectionServerbound: {
+					4:  "ServerboundMessageAcknowledgement",
 					7:  "ServerboundChatMessage",
+					8:  "ServerboundChatSessionUpdate",
 					12: "ServerboundClientSettings",
 					19: "ServerboundCookieResponse",
 					20: "ServerboundCustomPayload",
@@ -3646,15 +4010,19 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundCookieRequest":  5,
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundCookieRequest":      5,
+					"ClientboundEncryptionRequest":  1,
+					"ClientboundLoginDisconnect":    0,
+					"ClientboundLoginPluginRequest": 4,
+					"ClientboundLoginSuccess":       2,
+					"ClientboundSetCompression":     3,
 				},
 				DirectionServerbound: {
-					"ServerboundCookieResponse":    4,
-					"ServerboundLoginAcknowledged": 3,
-					"ServerboundLoginStart":        0,
+					"ServerboundCookieResponse":      4,
+					"ServerboundEncryptionResponse":  1,
+					"ServerboundLoginAcknowledged":   3,
+					"ServerboundLoginPluginResponse": 2,
+					"ServerboundLoginStart":          0,
 				},
 			},
 			StatePlay: {
@@ -3662,16 +4030,21 @@ func init() {
 					"ClientboundCookieRequest": 21,
 					"ClientboundCustomPayload": 24,
 					"ClientboundDisconnect":    28,
+					"ClientboundDisguisedChat": 29,
 					"ClientboundJoinGame":      43,
 					"ClientboundKeepAlive":     38,
+					"ClientboundPlayerChat":    58,
 					"ClientboundPong":          54,
+					"ClientboundSystemChat":    114,
 				},
 				DirectionServerbound: {
-					"ServerboundChatMessage":    7,
-					"ServerboundClientSettings": 12,
-					"ServerboundCookieResponse": 19,
-					"ServerboundCustomPayload":  20,
-					"ServerboundKeepAlive":      26,
+					"ServerboundChatMessage":            7,
+					"ServerboundChatSessionUpdate":      8,
+					"ServerboundClientSettings":         12,
+					"ServerboundCookieResponse":         19,
+					"ServerboundCustomPayload":          20,
+					"ServerboundKeepAlive":              26,
+					"ServerboundMessageAcknowledgement": 4,
 				},
 			},
 			StateStatus: {
@@ -3715,28 +4088,37 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
+					4: "ClientboundLoginPluginRequest",
 					5: "ClientboundCookieRequest",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
+					2: "ServerboundLoginPluginResponse",
 					3: "ServerboundLoginAcknowledged",
 					4: "ServerboundCookieResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
-					21: "ClientboundCookieRequest",
-					24: "ClientboundCustomPayload",
-					28: "ClientboundDisconnect",
-					38: "ClientboundKeepAlive",
-					43: "ClientboundJoinGame",
-					54: "ClientboundPong",
+					21:  "ClientboundCookieRequest",
+					24:  "ClientboundCustomPayload",
+					28:  "ClientboundDisconnect",
+					29:  "ClientboundDisguisedChat",
+					38:  "ClientboundKeepAlive",
+					43:  "ClientboundJoinGame",
+					54:  "ClientboundPong",
+					58:  "ClientboundPlayerChat",
+					114: "ClientboundSystemChat",
 				},
 				DirectionServerbound: {
+					4:  "ServerboundMessageAcknowledgement",
 					7:  "ServerboundChatMessage",
+					8:  "ServerboundChatSessionUpdate",
 					12: "ServerboundClientSettings",
 					19: "ServerboundCookieResponse",
 					20: "ServerboundCustomPayload",
@@ -3787,15 +4169,19 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundCookieRequest":  5,
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundCookieRequest":      5,
+					"ClientboundEncryptionRequest":  1,
+					"ClientboundLoginDisconnect":    0,
+					"ClientboundLoginPluginRequest": 4,
+					"ClientboundLoginSuccess":       2,
+					"ClientboundSetCompression":     3,
 				},
 				DirectionServerbound: {
-					"ServerboundCookieResponse":    4,
-					"ServerboundLoginAcknowledged": 3,
-					"ServerboundLoginStart":        0,
+					"ServerboundCookieResponse":      4,
+					"ServerboundEncryptionResponse":  1,
+					"ServerboundLoginAcknowledged":   3,
+					"ServerboundLoginPluginResponse": 2,
+					"ServerboundLoginStart":          0,
 				},
 			},
 			StatePlay: {
@@ -3803,16 +4189,21 @@ func init() {
 					"ClientboundCookieRequest": 21,
 					"ClientboundCustomPayload": 24,
 					"ClientboundDisconnect":    28,
+					"ClientboundDisguisedChat": 29,
 					"ClientboundJoinGame":      43,
 					"ClientboundKeepAlive":     38,
+					"ClientboundPlayerChat":    58,
 					"ClientboundPong":          54,
+					"ClientboundSystemChat":    114,
 				},
 				DirectionServerbound: {
-					"ServerboundChatMessage":    8,
-					"ServerboundClientSettings": 13,
-					"ServerboundCookieResponse": 20,
-					"ServerboundCustomPayload":  21,
-					"ServerboundKeepAlive":      27,
+					"ServerboundChatMessage":            8,
+					"ServerboundChatSessionUpdate":      9,
+					"ServerboundClientSettings":         13,
+					"ServerboundCookieResponse":         20,
+					"ServerboundCustomPayload":          21,
+					"ServerboundKeepAlive":              27,
+					"ServerboundMessageAcknowledgement": 5,
 				},
 			},
 			StateStatus: {
@@ -3856,28 +4247,37 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
+					4: "ClientboundLoginPluginRequest",
 					5: "ClientboundCookieRequest",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
+					2: "ServerboundLoginPluginResponse",
 					3: "ServerboundLoginAcknowledged",
 					4: "ServerboundCookieResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
-					21: "ClientboundCookieRequest",
-					24: "ClientboundCustomPayload",
-					28: "ClientboundDisconnect",
-					38: "ClientboundKeepAlive",
-					43: "ClientboundJoinGame",
-					54: "ClientboundPong",
+					21:  "ClientboundCookieRequest",
+					24:  "ClientboundCustomPayload",
+					28:  "ClientboundDisconnect",
+					29:  "ClientboundDisguisedChat",
+					38:  "ClientboundKeepAlive",
+					43:  "ClientboundJoinGame",
+					54:  "ClientboundPong",
+					58:  "ClientboundPlayerChat",
+					114: "ClientboundSystemChat",
 				},
 				DirectionServerbound: {
+					5:  "ServerboundMessageAcknowledgement",
 					8:  "ServerboundChatMessage",
+					9:  "ServerboundChatSessionUpdate",
 					13: "ServerboundClientSettings",
 					20: "ServerboundCookieResponse",
 					21: "ServerboundCustomPayload",
@@ -3928,15 +4328,19 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundCookieRequest":  5,
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundCookieRequest":      5,
+					"ClientboundEncryptionRequest":  1,
+					"ClientboundLoginDisconnect":    0,
+					"ClientboundLoginPluginRequest": 4,
+					"ClientboundLoginSuccess":       2,
+					"ClientboundSetCompression":     3,
 				},
 				DirectionServerbound: {
-					"ServerboundCookieResponse":    4,
-					"ServerboundLoginAcknowledged": 3,
-					"ServerboundLoginStart":        0,
+					"ServerboundCookieResponse":      4,
+					"ServerboundEncryptionResponse":  1,
+					"ServerboundLoginAcknowledged":   3,
+					"ServerboundLoginPluginResponse": 2,
+					"ServerboundLoginStart":          0,
 				},
 			},
 			StatePlay: {
@@ -3944,16 +4348,21 @@ func init() {
 					"ClientboundCookieRequest": 21,
 					"ClientboundCustomPayload": 24,
 					"ClientboundDisconnect":    28,
+					"ClientboundDisguisedChat": 29,
 					"ClientboundJoinGame":      43,
 					"ClientboundKeepAlive":     38,
+					"ClientboundPlayerChat":    58,
 					"ClientboundPong":          54,
+					"ClientboundSystemChat":    114,
 				},
 				DirectionServerbound: {
-					"ServerboundChatMessage":    8,
-					"ServerboundClientSettings": 13,
-					"ServerboundCookieResponse": 20,
-					"ServerboundCustomPayload":  21,
-					"ServerboundKeepAlive":      27,
+					"ServerboundChatMessage":            8,
+					"ServerboundChatSessionUpdate":      9,
+					"ServerboundClientSettings":         13,
+					"ServerboundCookieResponse":         20,
+					"ServerboundCustomPayload":          21,
+					"ServerboundKeepAlive":              27,
+					"ServerboundMessageAcknowledgement": 5,
 				},
 			},
 			StateStatus: {
@@ -3997,28 +4406,37 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
+					4: "ClientboundLoginPluginRequest",
 					5: "ClientboundCookieRequest",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
+					2: "ServerboundLoginPluginResponse",
 					3: "ServerboundLoginAcknowledged",
 					4: "ServerboundCookieResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
-					21: "ClientboundCookieRequest",
-					24: "ClientboundCustomPayload",
-					28: "ClientboundDisconnect",
-					38: "ClientboundKeepAlive",
-					43: "ClientboundJoinGame",
-					54: "ClientboundPong",
+					21:  "ClientboundCookieRequest",
+					24:  "ClientboundCustomPayload",
+					28:  "ClientboundDisconnect",
+					29:  "ClientboundDisguisedChat",
+					38:  "ClientboundKeepAlive",
+					43:  "ClientboundJoinGame",
+					54:  "ClientboundPong",
+					58:  "ClientboundPlayerChat",
+					114: "ClientboundSystemChat",
 				},
 				DirectionServerbound: {
+					5:  "ServerboundMessageAcknowledgement",
 					8:  "ServerboundChatMessage",
+					9:  "ServerboundChatSessionUpdate",
 					13: "ServerboundClientSettings",
 					20: "ServerboundCookieResponse",
 					21: "ServerboundCustomPayload",
@@ -4069,15 +4487,19 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundCookieRequest":  5,
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundCookieRequest":      5,
+					"ClientboundEncryptionRequest":  1,
+					"ClientboundLoginDisconnect":    0,
+					"ClientboundLoginPluginRequest": 4,
+					"ClientboundLoginSuccess":       2,
+					"ClientboundSetCompression":     3,
 				},
 				DirectionServerbound: {
-					"ServerboundCookieResponse":    4,
-					"ServerboundLoginAcknowledged": 3,
-					"ServerboundLoginStart":        0,
+					"ServerboundCookieResponse":      4,
+					"ServerboundEncryptionResponse":  1,
+					"ServerboundLoginAcknowledged":   3,
+					"ServerboundLoginPluginResponse": 2,
+					"ServerboundLoginStart":          0,
 				},
 			},
 			StatePlay: {
@@ -4085,16 +4507,21 @@ func init() {
 					"ClientboundCookieRequest": 21,
 					"ClientboundCustomPayload": 24,
 					"ClientboundDisconnect":    32,
+					"ClientboundDisguisedChat": 33,
 					"ClientboundJoinGame":      48,
 					"ClientboundKeepAlive":     43,
+					"ClientboundPlayerChat":    63,
 					"ClientboundPong":          59,
+					"ClientboundSystemChat":    119,
 				},
 				DirectionServerbound: {
-					"ServerboundChatMessage":    8,
-					"ServerboundClientSettings": 13,
-					"ServerboundCookieResponse": 20,
-					"ServerboundCustomPayload":  21,
-					"ServerboundKeepAlive":      27,
+					"ServerboundChatMessage":            8,
+					"ServerboundChatSessionUpdate":      9,
+					"ServerboundClientSettings":         13,
+					"ServerboundCookieResponse":         20,
+					"ServerboundCustomPayload":          21,
+					"ServerboundKeepAlive":              27,
+					"ServerboundMessageAcknowledgement": 5,
 				},
 			},
 			StateStatus: {
@@ -4138,28 +4565,37 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
+					4: "ClientboundLoginPluginRequest",
 					5: "ClientboundCookieRequest",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
+					2: "ServerboundLoginPluginResponse",
 					3: "ServerboundLoginAcknowledged",
 					4: "ServerboundCookieResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
-					21: "ClientboundCookieRequest",
-					24: "ClientboundCustomPayload",
-					32: "ClientboundDisconnect",
-					43: "ClientboundKeepAlive",
-					48: "ClientboundJoinGame",
-					59: "ClientboundPong",
+					21:  "ClientboundCookieRequest",
+					24:  "ClientboundCustomPayload",
+					32:  "ClientboundDisconnect",
+					33:  "ClientboundDisguisedChat",
+					43:  "ClientboundKeepAlive",
+					48:  "ClientboundJoinGame",
+					59:  "ClientboundPong",
+					63:  "ClientboundPlayerChat",
+					119: "ClientboundSystemChat",
 				},
 				DirectionServerbound: {
+					5:  "ServerboundMessageAcknowledgement",
 					8:  "ServerboundChatMessage",
+					9:  "ServerboundChatSessionUpdate",
 					13: "ServerboundClientSettings",
 					20: "ServerboundCookieResponse",
 					21: "ServerboundCustomPayload",
@@ -4210,15 +4646,19 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					"ClientboundCookieRequest":  5,
-					"ClientboundDisconnect":     0,
-					"ClientboundLoginSuccess":   2,
-					"ClientboundSetCompression": 3,
+					"ClientboundCookieRequest":      5,
+					"ClientboundEncryptionRequest":  1,
+					"ClientboundLoginDisconnect":    0,
+					"ClientboundLoginPluginRequest": 4,
+					"ClientboundLoginSuccess":       2,
+					"ClientboundSetCompression":     3,
 				},
 				DirectionServerbound: {
-					"ServerboundCookieResponse":    4,
-					"ServerboundLoginAcknowledged": 3,
-					"ServerboundLoginStart":        0,
+					"ServerboundCookieResponse":      4,
+					"ServerboundEncryptionResponse":  1,
+					"ServerboundLoginAcknowledged":   3,
+					"ServerboundLoginPluginResponse": 2,
+					"ServerboundLoginStart":          0,
 				},
 			},
 			StatePlay: {
@@ -4226,16 +4666,21 @@ func init() {
 					"ClientboundCookieRequest": 21,
 					"ClientboundCustomPayload": 24,
 					"ClientboundDisconnect":    32,
+					"ClientboundDisguisedChat": 33,
 					"ClientboundJoinGame":      48,
 					"ClientboundKeepAlive":     43,
+					"ClientboundPlayerChat":    63,
 					"ClientboundPong":          59,
+					"ClientboundSystemChat":    119,
 				},
 				DirectionServerbound: {
-					"ServerboundChatMessage":    8,
-					"ServerboundClientSettings": 13,
-					"ServerboundCookieResponse": 20,
-					"ServerboundCustomPayload":  21,
-					"ServerboundKeepAlive":      27,
+					"ServerboundChatMessage":            8,
+					"ServerboundChatSessionUpdate":      9,
+					"ServerboundClientSettings":         13,
+					"ServerboundCookieResponse":         20,
+					"ServerboundCustomPayload":          21,
+					"ServerboundKeepAlive":              27,
+					"ServerboundMessageAcknowledgement": 5,
 				},
 			},
 			StateStatus: {
@@ -4279,28 +4724,37 @@ func init() {
 			},
 			StateLogin: {
 				DirectionClientbound: {
-					0: "ClientboundDisconnect",
+					0: "ClientboundLoginDisconnect",
+					1: "ClientboundEncryptionRequest",
 					2: "ClientboundLoginSuccess",
 					3: "ClientboundSetCompression",
+					4: "ClientboundLoginPluginRequest",
 					5: "ClientboundCookieRequest",
 				},
 				DirectionServerbound: {
 					0: "ServerboundLoginStart",
+					1: "ServerboundEncryptionResponse",
+					2: "ServerboundLoginPluginResponse",
 					3: "ServerboundLoginAcknowledged",
 					4: "ServerboundCookieResponse",
 				},
 			},
 			StatePlay: {
 				DirectionClientbound: {
-					21: "ClientboundCookieRequest",
-					24: "ClientboundCustomPayload",
-					32: "ClientboundDisconnect",
-					43: "ClientboundKeepAlive",
-					48: "ClientboundJoinGame",
-					59: "ClientboundPong",
+					21:  "ClientboundCookieRequest",
+					24:  "ClientboundCustomPayload",
+					32:  "ClientboundDisconnect",
+					33:  "ClientboundDisguisedChat",
+					43:  "ClientboundKeepAlive",
+					48:  "ClientboundJoinGame",
+					59:  "ClientboundPong",
+					63:  "ClientboundPlayerChat",
+					119: "ClientboundSystemChat",
 				},
 				DirectionServerbound: {
+					5:  "ServerboundMessageAcknowledgement",
 					8:  "ServerboundChatMessage",
+					9:  "ServerboundChatSessionUpdate",
 					13: "ServerboundClientSettings",
 					20: "ServerboundCookieResponse",
 					21: "ServerboundCustomPayload",
@@ -0,0 +1,257 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/obeliskdev/gophermc/component"
+)
+
+// LegacyPingVariant is one of the server list pings used before 1.7. Every
+// variant is answered with a 0xFF kick packet carrying the status.
+type LegacyPingVariant int
+
+const (
+	// LegacyPingBeta is a lone 0xFE, sent from beta 1.8 to 1.3.
+	LegacyPingBeta LegacyPingVariant = iota
+	// LegacyPing14 is 0xFE 0x01, sent by 1.4 and 1.5.
+	LegacyPing14
+	// LegacyPing16 follows 0xFE 0x01 with an MC|PingHost plugin message
+	// naming the protocol, host and port, as 1.6 does.
+	LegacyPing16
+)
+
+const (
+	legacyPingID = 0xFE
+	legacyKickID = 0xFF
+
+	legacyPingHostID      = 0xFA
+	legacyPingHostChannel = "MC|PingHost"
+
+	// LegacyProtocol16 is the protocol number of 1.6.4.
+	LegacyProtocol16 = 78
+)
+
+var ErrLegacyPing = errors.New("legacy server list ping")
+
+type LegacyPing struct {
+	Variant LegacyPingVariant
+	// Protocol, Hostname and Port are only sent by LegacyPing16.
+	Protocol byte
+	Hostname string
+	Port     int32
+}
+
+func (p *LegacyPing) Write(w io.Writer) error {
+	var buf bytes.Buffer
+	buf.WriteByte(legacyPingID)
+
+	if p.Variant >= LegacyPing14 {
+		buf.WriteByte(0x01)
+	}
+
+	if p.Variant >= LegacyPing16 {
+		buf.WriteByte(legacyPingHostID)
+		_ = writeLegacyString(&buf, legacyPingHostChannel)
+
+		host := utf16.Encode([]rune(p.Hostname))
+		_ = binary.Write(&buf, binary.BigEndian, uint16(7+2*len(host)))
+		buf.WriteByte(p.Protocol)
+		_ = writeLegacyString(&buf, p.Hostname)
+		_ = binary.Write(&buf, binary.BigEndian, p.Port)
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// readLegacyPing reads a ping from r, whose first bytes head were peeked
+// without being consumed and start with 0xFE. Like the vanilla server, a lone 0xFE is taken
+// as a beta ping. It returns nil if head is a modern packet instead.
+func readLegacyPing(head []byte, r io.Reader) (*LegacyPing, error) {
+	switch {
+	case len(head) == 1:
+		return &LegacyPing{Variant: LegacyPingBeta}, nil
+	case head[1] != 0x01:
+		return nil, nil
+	case len(head) == 2:
+		return &LegacyPing{Variant: LegacyPing14}, nil
+	case head[2] != legacyPingHostID:
+		// 0xFE 0x01 is also a VarInt length of 254, followed by a packet id.
+		if head[2] == 0x00 {
+			return nil, nil
+		}
+		return &LegacyPing{Variant: LegacyPing14}, nil
+	}
+
+	if _, err := io.CopyN(io.Discard, r, 3); err != nil {
+		return nil, err
+	}
+	channel, err := readLegacyString(r)
+	if err != nil {
+		return nil, err
+	}
+	if channel != legacyPingHostChannel {
+		return nil, fmt.Errorf("unexpected legacy ping channel %q", channel)
+	}
+
+	if _, err := ReadUShort(r); err != nil {
+		return nil, err
+	}
+
+	ping := &LegacyPing{Variant: LegacyPing16}
+	if ping.Protocol, err = ReadByte(r); err != nil {
+		return nil, err
+	}
+	if ping.Hostname, err = readLegacyString(r); err != nil {
+		return nil, err
+	}
+	err = binary.Read(r, binary.BigEndian, &ping.Port)
+	return ping, err
+}
+
+// LegacyStatus is the status as carried in a legacy kick. Beta servers only
+// send the MOTD and player counts.
+type LegacyStatus struct {
+	Protocol int32
+	Version  string
+	MOTD     string
+	Online   int
+	Max      int
+}
+
+// String formats the status for the kick answering variant. The beta
+// format uses § as separator, so formatting codes are removed from the MOTD.
+func (s LegacyStatus) String(variant LegacyPingVariant) string {
+	if variant == LegacyPingBeta {
+		return fmt.Sprintf("%s§%d§%d", component.StripCodes(s.MOTD), s.Online, s.Max)
+	}
+
+	return strings.Join([]string{
+		"§1",
+		strconv.Itoa(int(s.Protocol)),
+		s.Version,
+		s.MOTD,
+		strconv.Itoa(s.Online),
+		strconv.Itoa(s.Max),
+	}, "\x00")
+}
+
+// ParseLegacyStatus parses the reason of a kick sent in reply to a legacy
+// ping, in either format.
+func ParseLegacyStatus(kick string) (LegacyStatus, error) {
+	var status LegacyStatus
+
+	if rest, ok := strings.CutPrefix(kick, "§1\x00"); ok {
+		fields := strings.Split(rest, "\x00")
+		if len(fields) != 5 {
+			return status, fmt.Errorf("legacy status has %d fields", len(fields)+1)
+		}
+
+		protocol, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return status, fmt.Errorf("legacy status protocol: %w", err)
+		}
+		status.Protocol = int32(protocol)
+		status.Version = fields[1]
+		status.MOTD = fields[2]
+		return status, parseLegacyCounts(&status, fields[3], fields[4])
+	}
+
+	fields := strings.Split(kick, "§")
+	if len(fields) < 3 {
+		return status, errors.New("legacy status is missing player counts")
+	}
+
+	status.MOTD = strings.Join(fields[:len(fields)-2], "§")
+	return status, parseLegacyCounts(&status, fields[len(fields)-2], fields[len(fields)-1])
+}
+
+func parseLegacyCounts(status *LegacyStatus, online, maxPlayers string) (err error) {
+	if status.Online, err = strconv.Atoi(online); err != nil {
+		return fmt.Errorf("legacy status online count: %w", err)
+	}
+	if status.Max, err = strconv.Atoi(maxPlayers); err != nil {
+		return fmt.Errorf("legacy status max count: %w", err)
+	}
+	return nil
+}
+
+// StatusResponse converts the status to the modern document.
+func (s LegacyStatus) StatusResponse() *StatusResponse {
+	return &StatusResponse{
+		Version:     StatusVersion{Name: s.Version, Protocol: s.Protocol},
+		Players:     StatusPlayers{Max: s.Max, Online: s.Online},
+		Description: component.ParseLegacy(s.MOTD, component.SectionSign),
+	}
+}
+
+// Legacy converts the response for legacy clients, which lose everything
+// but the MOTD text and the player counts.
+func (s *StatusResponse) Legacy() LegacyStatus {
+	return LegacyStatus{
+		Protocol: s.Version.Protocol,
+		Version:  s.Version.Name,
+		MOTD:     component.LegacyRenderer{}.Render(s.Description),
+		Online:   s.Players.Online,
+		Max:      s.Players.Max,
+	}
+}
+
+// WriteLegacyKick writes a 0xFF kick packet with reason.
+func WriteLegacyKick(w io.Writer, reason string) error {
+	var buf bytes.Buffer
+	buf.WriteByte(legacyKickID)
+	if err := writeLegacyString(&buf, reason); err != nil {
+		return err
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// ReadLegacyKick reads a 0xFF kick packet and returns its reason.
+func ReadLegacyKick(r io.Reader) (string, error) {
+	id, err := ReadByte(r)
+	if err != nil {
+		return "", err
+	}
+	if id != legacyKickID {
+		return "", fmt.Errorf("expected legacy kick, got packet 0x%02X", id)
+	}
+
+	return readLegacyString(r)
+}
+
+// Legacy strings are a UTF-16 length followed by big endian UTF-16.
+func writeLegacyString(w io.Writer, s string) error {
+	chars := utf16.Encode([]rune(s))
+	if len(chars) > math.MaxUint16 {
+		return fmt.Errorf("legacy string too long: %d", len(chars))
+	}
+
+	if err := binary.Write(w, binary.BigEndian, uint16(len(chars))); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.BigEndian, chars)
+}
+
+func readLegacyString(r io.Reader) (string, error) {
+	length, err := ReadUShort(r)
+	if err != nil {
+		return "", err
+	}
+
+	chars := make([]uint16, length)
+	if err := binary.Read(r, binary.BigEndian, chars); err != nil {
+		return "", err
+	}
+	return string(utf16.Decode(chars)), nil
+}
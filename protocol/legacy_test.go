@@ -0,0 +1,130 @@
+package protocol
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+)
+
+// readPing feeds data to a fresh ServerConn in a single write.
+func readPing(t *testing.T, data []byte) (*ServerConn, error) {
+	t.Helper()
+
+	server, client := net.Pipe()
+	t.Cleanup(func() {
+		_ = server.Close()
+		_ = client.Close()
+	})
+
+	go func() { _, _ = client.Write(data) }()
+
+	conn := NewServerConn(server)
+	_, err := conn.ReadHandshake()
+	return conn, err
+}
+
+func TestLegacyPingDetection(t *testing.T) {
+	for _, ping := range []LegacyPing{
+		{Variant: LegacyPingBeta},
+		{Variant: LegacyPing14},
+		{Variant: LegacyPing16, Protocol: LegacyProtocol16, Hostname: "mc.example.com", Port: 25565},
+	} {
+		var buf bytes.Buffer
+		if err := ping.Write(&buf); err != nil {
+			t.Fatal(err)
+		}
+
+		conn, err := readPing(t, buf.Bytes())
+		if !errors.Is(err, ErrLegacyPing) {
+			t.Fatalf("variant %d: expected legacy ping, got %v", ping.Variant, err)
+		}
+		if *conn.LegacyPing != ping {
+			t.Errorf("expected %+v, got %+v", ping, *conn.LegacyPing)
+		}
+	}
+}
+
+func TestLegacyPingDetectionModern254(t *testing.T) {
+	// A handshake whose length encodes as 0xFE 0x01 must not look legacy.
+	handshake := &ServerboundHandshake{ProtocolVersion: Latest.Protocol(), ServerPort: 25565, NextState: StateStatus}
+	for {
+		var body bytes.Buffer
+		_ = WriteVarInt(&body, 0)
+		_ = handshake.Encode(&body, Latest)
+		if body.Len() == 254 {
+			break
+		}
+		handshake.ServerAddress += "a"
+	}
+
+	var frame bytes.Buffer
+	conn := NewConn(nil, Latest)
+	conn.writer = &frame
+	conn.SetState(StateHandshaking)
+	if err := conn.WritePacket(handshake); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(frame.Bytes(), []byte{0xFE, 0x01, 0x00}) {
+		t.Fatalf("unexpected frame prefix %x", frame.Bytes()[:3])
+	}
+
+	server, err := readPing(t, frame.Bytes())
+	if err != nil || server.Handshake.ServerAddress != handshake.ServerAddress {
+		t.Fatalf("expected a modern handshake, got %v", err)
+	}
+}
+
+func TestLegacyStatusFormats(t *testing.T) {
+	status := LegacyStatus{Protocol: 78, Version: "1.6.4", MOTD: "§aA §lserver", Online: 3, Max: 20}
+
+	modern := status.String(LegacyPing16)
+	if modern != "§1\x0078\x001.6.4\x00§aA §lserver\x003\x0020" {
+		t.Fatalf("unexpected 1.6 format %q", modern)
+	}
+	if parsed, err := ParseLegacyStatus(modern); err != nil || parsed != status {
+		t.Fatalf("expected %+v, got %+v (%v)", status, parsed, err)
+	}
+
+	beta := status.String(LegacyPingBeta)
+	if beta != "A server§3§20" {
+		t.Fatalf("unexpected beta format %q", beta)
+	}
+	parsed, err := ParseLegacyStatus(beta)
+	if err != nil || parsed != (LegacyStatus{MOTD: "A server", Online: 3, Max: 20}) {
+		t.Fatalf("unexpected beta status %+v (%v)", parsed, err)
+	}
+
+	if _, err := ParseLegacyStatus("Outdated server!"); err == nil {
+		t.Fatal("expected a plain kick to fail")
+	}
+
+	response := status.StatusResponse()
+	if response.Version.Name != "1.6.4" || response.Players.Online != 3 || response.Description.String() != "A server" {
+		t.Fatalf("unexpected response %+v", response)
+	}
+	if back := response.Legacy(); back.MOTD != "§aA §a§lserver" {
+		t.Fatalf("unexpected MOTD %q", back.MOTD)
+	}
+}
+
+func TestLegacyKick(t *testing.T) {
+	var buf bytes.Buffer
+	reason := "§1\x0078\x001.6.4\x00Ünïcödé 🎮\x000\x001"
+	if err := WriteLegacyKick(&buf, reason); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte{0xFF, 0x00}) {
+		t.Fatalf("unexpected kick header %x", buf.Bytes()[:2])
+	}
+
+	got, err := ReadLegacyKick(&buf)
+	if err != nil || got != reason {
+		t.Fatalf("expected %q, got %q (%v)", reason, got, err)
+	}
+
+	if _, err := ReadLegacyKick(strings.NewReader("\x00")); err == nil {
+		t.Fatal("expected an error for a non-kick packet")
+	}
+}
@@ -1,13 +1,15 @@
 package protocol
 
 import (
+	"bytes"
 	"crypto"
 	"crypto/rsa"
 	"crypto/sha256"
 	"encoding/binary"
-	"errors"
+	"encoding/json"
 	"fmt"
 	"github.com/obeliskdev/gophermc/component"
+	"github.com/obeliskdev/gophermc/nbt"
 	"github.com/obeliskdev/fastrand"
 	"io"
 	"math"
@@ -35,9 +37,15 @@ func (p *ServerboundHandshake) Encode(w io.Writer, _ Version) error {
 	return WriteVarInt(w, int32(p.NextState))
 }
 func (p *ServerboundHandshake) Decode(r io.Reader, _ Version) (err error) {
-	p.ProtocolVersion, _ = ReadVarInt(r)
-	p.ServerAddress, _ = ReadString(r)
-	p.ServerPort, _ = ReadUShort(r)
+	if p.ProtocolVersion, err = ReadVarInt(r); err != nil {
+		return err
+	}
+	if p.ServerAddress, err = ReadString(r); err != nil {
+		return err
+	}
+	if p.ServerPort, err = ReadUShort(r); err != nil {
+		return err
+	}
 	var ns int32
 	ns, err = ReadVarInt(r)
 	p.NextState = State(ns)
@@ -78,6 +86,10 @@ func (p *ClientboundPong) Decode(r io.Reader, _ Version) (err error) {
 type ServerboundLoginStart struct {
 	Username string
 	UUID     uuid.UUID
+
+	// PlayerKey is sent on 1.19-1.19.2, where the chat signing key is
+	// announced during login instead of through a chat session.
+	PlayerKey *PlayerKey
 }
 
 func (p *ServerboundLoginStart) Encode(w io.Writer, v Version) error {
@@ -86,17 +98,31 @@ func (p *ServerboundLoginStart) Encode(w io.Writer, v Version) error {
 	}
 
 	if v >= V1_19 && v <= V1_19_2 {
-		if err := WriteBool(w, false); err != nil {
+		if err := WriteBool(w, p.PlayerKey != nil); err != nil {
 			return err
 		}
+
+		if p.PlayerKey != nil {
+			if err := WriteLong(w, p.PlayerKey.ExpiresAt.UnixMilli()); err != nil {
+				return err
+			}
+
+			if err := WriteByteSlice(w, p.PlayerKey.PublicKey); err != nil {
+				return err
+			}
+
+			if err := WriteByteSlice(w, p.PlayerKey.signatureFor(v)); err != nil {
+				return err
+			}
+		}
 	}
 
 	if v >= V1_20_2 {
 		if _, err := w.Write(p.UUID[:]); err != nil {
 			return err
 		}
-	} else if v >= V1_19_2 && p.UUID != uuid.Nil {
-		if err := WriteBool(w, true); err != nil {
+	} else if v >= V1_19_2 {
+		if err := WriteBool(w, p.UUID != uuid.Nil); err != nil || p.UUID == uuid.Nil {
 			return err
 		}
 
@@ -107,9 +133,197 @@ func (p *ServerboundLoginStart) Encode(w io.Writer, v Version) error {
 	return nil
 }
 
-func (p *ServerboundLoginStart) Decode(r io.Reader, _ Version) (err error) {
-	p.Username, err = ReadString(r)
-	return
+func (p *ServerboundLoginStart) Decode(r io.Reader, v Version) (err error) {
+	if p.Username, err = ReadString(r); err != nil {
+		return err
+	}
+
+	if v >= V1_19 && v <= V1_19_2 {
+		hasKey, err := ReadBool(r)
+		if err != nil {
+			return err
+		}
+
+		if hasKey {
+			p.PlayerKey = &PlayerKey{}
+
+			expiresAt, err := ReadLong(r)
+			if err != nil {
+				return err
+			}
+			p.PlayerKey.ExpiresAt = time.UnixMilli(expiresAt)
+
+			if p.PlayerKey.PublicKey, err = ReadBytes(r); err != nil {
+				return err
+			}
+
+			signature, err := ReadBytes(r)
+			if err != nil {
+				return err
+			}
+
+			if v >= V1_19_2 {
+				p.PlayerKey.SignatureV2 = signature
+			} else {
+				p.PlayerKey.Signature = signature
+			}
+		}
+	}
+
+	if v >= V1_20_2 {
+		p.UUID, err = ReadUUID(r)
+		return err
+	}
+
+	if v >= V1_19_2 {
+		hasUUID, err := ReadBool(r)
+		if err != nil || !hasUUID {
+			return err
+		}
+		p.UUID, err = ReadUUID(r)
+		return err
+	}
+
+	return nil
+}
+
+type ClientboundEncryptionRequest struct {
+	ServerID           string
+	PublicKey          []byte
+	VerifyToken        []byte
+	ShouldAuthenticate bool
+}
+
+func (p *ClientboundEncryptionRequest) Encode(w io.Writer, v Version) error {
+	if err := WriteString(w, p.ServerID); err != nil {
+		return err
+	}
+
+	if err := writeEncryptionBytes(w, v, p.PublicKey); err != nil {
+		return err
+	}
+
+	if err := writeEncryptionBytes(w, v, p.VerifyToken); err != nil {
+		return err
+	}
+
+	if v >= V1_20_5 {
+		return WriteBool(w, p.ShouldAuthenticate)
+	}
+
+	return nil
+}
+
+func (p *ClientboundEncryptionRequest) Decode(r io.Reader, v Version) (err error) {
+	if p.ServerID, err = ReadString(r); err != nil {
+		return err
+	}
+
+	if p.PublicKey, err = readEncryptionBytes(r, v); err != nil {
+		return err
+	}
+
+	if p.VerifyToken, err = readEncryptionBytes(r, v); err != nil {
+		return err
+	}
+
+	p.ShouldAuthenticate = true
+	if v >= V1_20_5 {
+		p.ShouldAuthenticate, err = ReadBool(r)
+	}
+
+	return err
+}
+
+type ServerboundEncryptionResponse struct {
+	SharedSecret []byte
+	VerifyToken  []byte
+
+	// Salt and MessageSignature replace VerifyToken on 1.19-1.19.2 when the
+	// client proves possession of its chat signing key instead.
+	Salt             int64
+	MessageSignature []byte
+}
+
+func (p *ServerboundEncryptionResponse) Encode(w io.Writer, v Version) error {
+	if err := writeEncryptionBytes(w, v, p.SharedSecret); err != nil {
+		return err
+	}
+
+	if v >= V1_19 && v <= V1_19_2 {
+		hasVerifyToken := p.MessageSignature == nil
+		if err := WriteBool(w, hasVerifyToken); err != nil {
+			return err
+		}
+
+		if !hasVerifyToken {
+			if err := WriteLong(w, p.Salt); err != nil {
+				return err
+			}
+
+			return WriteByteSlice(w, p.MessageSignature)
+		}
+	}
+
+	return writeEncryptionBytes(w, v, p.VerifyToken)
+}
+
+func (p *ServerboundEncryptionResponse) Decode(r io.Reader, v Version) (err error) {
+	if p.SharedSecret, err = readEncryptionBytes(r, v); err != nil {
+		return err
+	}
+
+	if v >= V1_19 && v <= V1_19_2 {
+		hasVerifyToken, err := ReadBool(r)
+		if err != nil {
+			return err
+		}
+
+		if !hasVerifyToken {
+			if p.Salt, err = ReadLong(r); err != nil {
+				return err
+			}
+
+			p.MessageSignature, err = ReadBytes(r)
+			return err
+		}
+	}
+
+	p.VerifyToken, err = readEncryptionBytes(r, v)
+	return err
+}
+
+// 1.7 prefixes the encryption byte arrays with a short instead of a VarInt.
+func readEncryptionBytes(r io.Reader, v Version) ([]byte, error) {
+	if v >= V1_8 {
+		return ReadBytes(r)
+	}
+
+	length, err := ReadUShort(r)
+	if err != nil {
+		return nil, fmt.Errorf("read byte array length: %w", err)
+	}
+
+	buf := make([]byte, length)
+	_, err = io.ReadFull(r, buf)
+	return buf, err
+}
+
+func writeEncryptionBytes(w io.Writer, v Version, data []byte) error {
+	if v >= V1_8 {
+		return WriteByteSlice(w, data)
+	}
+
+	if len(data) > math.MaxUint16 {
+		return fmt.Errorf("byte array length %d exceeds max size", len(data))
+	}
+
+	if err := WriteUShort(w, uint16(len(data))); err != nil {
+		return err
+	}
+
+	_, err := w.Write(data)
+	return err
 }
 
 type ServerboundLoginAcknowledged struct{}
@@ -127,13 +341,112 @@ func (p *ClientboundSetCompression) Decode(r io.Reader, _ Version) (err error) {
 	return
 }
 
+// Property is a game profile property such as the signed "textures" blob.
+type Property struct {
+	Name      string `json:"name"`
+	Value     string `json:"value"`
+	Signature string `json:"signature,omitempty"`
+}
+
+func writeProperties(w io.Writer, properties []Property) error {
+	if err := WriteVarInt(w, int32(len(properties))); err != nil {
+		return err
+	}
+
+	for _, prop := range properties {
+		if err := WriteString(w, prop.Name); err != nil {
+			return err
+		}
+
+		if err := WriteString(w, prop.Value); err != nil {
+			return err
+		}
+
+		if err := WriteBool(w, prop.Signature != ""); err != nil {
+			return err
+		}
+
+		if prop.Signature != "" {
+			if err := WriteString(w, prop.Signature); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func readProperties(r io.Reader) ([]Property, error) {
+	propCount, err := ReadVarInt(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if propCount < 0 {
+		return nil, fmt.Errorf("invalid property count %d", propCount)
+	}
+
+	var properties []Property
+	for i := int32(0); i < propCount; i++ {
+		var prop Property
+		if prop.Name, err = ReadString(r); err != nil {
+			return nil, err
+		}
+
+		if prop.Value, err = ReadString(r); err != nil {
+			return nil, err
+		}
+
+		hasSig, err := ReadBool(r)
+		if err != nil {
+			return nil, err
+		}
+
+		if hasSig {
+			if prop.Signature, err = ReadString(r); err != nil {
+				return nil, err
+			}
+		}
+
+		properties = append(properties, prop)
+	}
+
+	return properties, nil
+}
+
 type ClientboundLoginSuccess struct {
-	Username string
-	UUID     uuid.UUID
+	Username   string
+	UUID       uuid.UUID
+	Properties []Property
+
+	// StrictErrorHandling only exists on 1.20.5-1.21.1.
+	StrictErrorHandling bool
 }
 
-func (p *ClientboundLoginSuccess) Encode(_ io.Writer, _ Version) error {
-	panic("client should not send LoginSuccess")
+func (p *ClientboundLoginSuccess) Encode(w io.Writer, v Version) error {
+	if v >= V1_16 {
+		if _, err := w.Write(p.UUID[:]); err != nil {
+			return err
+		}
+	} else if err := WriteString(w, p.UUID.String()); err != nil {
+		return err
+	}
+
+	if err := WriteString(w, p.Username); err != nil {
+		return err
+	}
+
+	if v >= V1_19 {
+		if err := writeProperties(w, p.Properties); err != nil {
+			return err
+		}
+	}
+
+	if v >= V1_20_5 && v <= V1_21_1 {
+		return WriteBool(w, p.StrictErrorHandling)
+	}
+
+	return nil
 }
 
 func (p *ClientboundLoginSuccess) Decode(r io.Reader, v Version) (err error) {
@@ -152,36 +465,16 @@ func (p *ClientboundLoginSuccess) Decode(r io.Reader, v Version) (err error) {
 	}
 
 	if v >= V1_19 {
-		propCount, err := ReadVarInt(r)
-		if err != nil {
+		if p.Properties, err = readProperties(r); err != nil {
 			return err
 		}
-
-		for i := int32(0); i < propCount; i++ {
-			if _, err := ReadString(r); err != nil {
-				return err
-			}
-
-			if _, err := ReadString(r); err != nil {
-				return err
-			}
-
-			hasSig, _ := ReadBool(r)
-			if hasSig {
-				if _, err := ReadString(r); err != nil {
-					return err
-				}
-			}
-		}
 	}
 
 	if v >= V1_20_5 && v <= V1_21_1 {
-		if _, err := ReadBool(r); err != nil {
-			return err
-		}
+		p.StrictErrorHandling, err = ReadBool(r)
 	}
 
-	return nil
+	return err
 }
 
 type ServerboundFinishConfiguration struct{}
@@ -215,11 +508,33 @@ type ServerboundSelectKnownPacks struct {
 }
 
 func (p *ServerboundSelectKnownPacks) Encode(w io.Writer, _ Version) error {
-	if err := WriteVarInt(w, int32(len(p.Packs))); err != nil {
+	return writeKnownPacks(w, p.Packs)
+}
+
+func (p *ServerboundSelectKnownPacks) Decode(r io.Reader, _ Version) (err error) {
+	p.Packs, err = readKnownPacks(r)
+	return err
+}
+
+type ClientboundSelectKnownPacks struct {
+	Packs []KnownPack
+}
+
+func (p *ClientboundSelectKnownPacks) Encode(w io.Writer, _ Version) error {
+	return writeKnownPacks(w, p.Packs)
+}
+
+func (p *ClientboundSelectKnownPacks) Decode(r io.Reader, _ Version) (err error) {
+	p.Packs, err = readKnownPacks(r)
+	return err
+}
+
+func writeKnownPacks(w io.Writer, packs []KnownPack) error {
+	if err := WriteVarInt(w, int32(len(packs))); err != nil {
 		return err
 	}
 
-	for _, pk := range p.Packs {
+	for _, pk := range packs {
 		if err := WriteString(w, pk.Namespace); err != nil {
 			return err
 		}
@@ -235,48 +550,108 @@ func (p *ServerboundSelectKnownPacks) Encode(w io.Writer, _ Version) error {
 	return nil
 }
 
-func (p *ServerboundSelectKnownPacks) Decode(_ io.Reader, _ Version) error { return nil }
-
-type ClientboundSelectKnownPacks struct {
-	Packs []KnownPack
-}
-
-func (p *ClientboundSelectKnownPacks) Encode(_ io.Writer, _ Version) error {
-	return nil
-}
-
-func (p *ClientboundSelectKnownPacks) Decode(r io.Reader, _ Version) error {
+func readKnownPacks(r io.Reader) ([]KnownPack, error) {
 	count, err := ReadVarInt(r)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	p.Packs = make([]KnownPack, count)
+	if count < 0 {
+		return nil, fmt.Errorf("invalid known pack count %d", count)
+	}
 
+	packs := make([]KnownPack, 0, min(count, 64))
 	for i := int32(0); i < count; i++ {
-		p.Packs[i].Namespace, err = ReadString(r)
-		if err != nil {
-			return err
+		var pk KnownPack
+		if pk.Namespace, err = ReadString(r); err != nil {
+			return nil, err
 		}
-		p.Packs[i].ID, err = ReadString(r)
-		if err != nil {
-			return err
+		if pk.ID, err = ReadString(r); err != nil {
+			return nil, err
 		}
-		p.Packs[i].Version, err = ReadString(r)
-		if err != nil {
-			return err
+		if pk.Version, err = ReadString(r); err != nil {
+			return nil, err
 		}
+		packs = append(packs, pk)
 	}
 
-	return nil
+	return packs, nil
 }
 
-type ClientboundCookieRequest struct {
-	Key string
+// ClientboundLoginPluginRequest asks the client for custom login data,
+// such as a proxy's forwarding information. It exists since 1.13.
+type ClientboundLoginPluginRequest struct {
+	MessageID int32
+	Channel   string
+	Data      []byte
 }
 
-func (p *ClientboundCookieRequest) Encode(w io.Writer, _ Version) error {
-	return WriteString(w, p.Key)
+func (p *ClientboundLoginPluginRequest) Encode(w io.Writer, _ Version) error {
+	if err := WriteVarInt(w, p.MessageID); err != nil {
+		return err
+	}
+
+	if err := WriteString(w, p.Channel); err != nil {
+		return err
+	}
+
+	_, err := w.Write(p.Data)
+	return err
+}
+
+func (p *ClientboundLoginPluginRequest) Decode(r io.Reader, _ Version) (err error) {
+	if p.MessageID, err = ReadVarInt(r); err != nil {
+		return err
+	}
+
+	if p.Channel, err = ReadString(r); err != nil {
+		return err
+	}
+
+	p.Data, err = io.ReadAll(r)
+	return err
+}
+
+// ServerboundLoginPluginResponse answers a login plugin request. Clients
+// that do not understand the channel answer with Successful unset.
+type ServerboundLoginPluginResponse struct {
+	MessageID  int32
+	Successful bool
+	Data       []byte
+}
+
+func (p *ServerboundLoginPluginResponse) Encode(w io.Writer, _ Version) error {
+	if err := WriteVarInt(w, p.MessageID); err != nil {
+		return err
+	}
+
+	if err := WriteBool(w, p.Successful); err != nil || !p.Successful {
+		return err
+	}
+
+	_, err := w.Write(p.Data)
+	return err
+}
+
+func (p *ServerboundLoginPluginResponse) Decode(r io.Reader, _ Version) (err error) {
+	if p.MessageID, err = ReadVarInt(r); err != nil {
+		return err
+	}
+
+	if p.Successful, err = ReadBool(r); err != nil || !p.Successful {
+		return err
+	}
+
+	p.Data, err = io.ReadAll(r)
+	return err
+}
+
+type ClientboundCookieRequest struct {
+	Key string
+}
+
+func (p *ClientboundCookieRequest) Encode(w io.Writer, _ Version) error {
+	return WriteString(w, p.Key)
 }
 
 func (p *ClientboundCookieRequest) Decode(r io.Reader, _ Version) error {
@@ -300,8 +675,17 @@ func (p *ServerboundCookieResponse) Encode(w io.Writer, _ Version) error {
 	return nil
 }
 
-func (p *ServerboundCookieResponse) Decode(_ io.Reader, _ Version) error {
-	return nil
+func (p *ServerboundCookieResponse) Decode(r io.Reader, _ Version) (err error) {
+	if p.Key, err = ReadString(r); err != nil {
+		return err
+	}
+
+	if p.HasData, err = ReadBool(r); err != nil || !p.HasData {
+		return err
+	}
+
+	p.Data, err = ReadBytes(r)
+	return err
 }
 
 type ClientboundConfigPing struct{ ID int32 }
@@ -318,408 +702,1903 @@ type ServerboundConfigPong struct{ ID int32 }
 func (p *ServerboundConfigPong) Encode(w io.Writer, _ Version) error {
 	return binary.Write(w, binary.BigEndian, p.ID)
 }
-func (p *ServerboundConfigPong) Decode(_ io.Reader, _ Version) error { return nil }
-
-type ClientboundJoinGame struct{}
-
-func (p *ClientboundJoinGame) Encode(_ io.Writer, _ Version) error {
-	panic("client should not send JoinGame")
-}
-
-func (p *ClientboundJoinGame) Decode(r io.Reader, _ Version) error {
-	_, err := io.Copy(io.Discard, r)
-	return err
-}
-
-type ClientboundDisconnect struct{ Reason string }
-
-func (p *ClientboundDisconnect) Encode(w io.Writer, _ Version) error {
-	return WriteString(w, p.Reason)
-}
-func (p *ClientboundDisconnect) Decode(r io.Reader, _ Version) (err error) {
-	p.Reason, err = ReadString(r)
-	return
+func (p *ServerboundConfigPong) Decode(r io.Reader, _ Version) error {
+	return binary.Read(r, binary.BigEndian, &p.ID)
 }
 
-type ServerboundChatMessage struct {
-	Message    string
-	PrivateKey *rsa.PrivateKey
-	UUID       uuid.UUID
-}
+// ClientboundJoinGame is the play state "login" packet. Which fields are on
+// the wire depends heavily on the version; see the field comments.
+type ClientboundJoinGame struct {
+	EntityID int32
+	// Hardcore is folded into bit 3 of the game mode before 1.16.2.
+	Hardcore         bool
+	GameMode         byte
+	PreviousGameMode int8 // 1.16+, -1 for none
+
+	// Dimension is the numeric dimension before 1.16.
+	Dimension int32
+	// DimensionNames lists every world on the server, from 1.16.
+	DimensionNames []string
+	// DimensionCodec is the registry codec sent from 1.16 until it moved to
+	// the configuration state in 1.20.2.
+	DimensionCodec nbt.Tag
+	// DimensionType names the dimension type on 1.16-1.16.1 and
+	// 1.19-1.20.3. On 1.16.2-1.18.2 the type is sent inline as
+	// DimensionTypeData, and from 1.20.5 as the registry id DimensionTypeID.
+	DimensionType     string
+	DimensionTypeData nbt.Tag
+	DimensionTypeID   int32
+	DimensionName     string // 1.16+
+
+	HashedSeed          int64 // 1.15+
+	Difficulty          byte  // before 1.14
+	MaxPlayers          int32
+	LevelType           string // before 1.16
+	ViewDistance        int32  // 1.14+
+	SimulationDistance  int32  // 1.18+
+	ReducedDebugInfo    bool   // 1.8+
+	EnableRespawnScreen bool   // 1.15+
+	DoLimitedCrafting   bool   // 1.20.2+
+	IsDebug             bool   // 1.16+
+	IsFlat              bool   // 1.16+
+	DeathLocation       *DeathLocation
+	PortalCooldown      int32 // 1.20+
+	SeaLevel            int32 // 1.21.2+
+	EnforcesSecureChat  bool  // 1.20.5+
+}
+
+// DeathLocation is where the player last died, sent from 1.19.
+type DeathLocation struct {
+	Dimension string
+	Position  BlockPosition
+}
+
+func (p *ClientboundJoinGame) Encode(w io.Writer, v Version) error {
+	if err := binary.Write(w, binary.BigEndian, p.EntityID); err != nil {
+		return err
+	}
 
-func (p *ServerboundChatMessage) Encode(w io.Writer, v Version) error {
-	if v < V1_19 {
-		return WriteString(w, p.Message)
+	switch {
+	case v >= V1_20_2:
+		return p.encodeConfigured(w, v)
+	case v >= V1_16:
+		return p.encodeDimensionCodec(w, v)
 	}
 
-	timestamp := time.Now().UnixMilli()
-	salt := fastrand.NumberN[int64](math.MaxInt64)
+	gameMode := p.GameMode
+	if p.Hardcore {
+		gameMode |= 0x8
+	}
 
-	signature, err := p.signMessage(v, p.Message, timestamp, salt)
-	if err != nil {
+	if err := WriteByte(w, gameMode); err != nil {
 		return err
 	}
 
-	_ = WriteString(w, p.Message)
-	_ = WriteLong(w, timestamp)
-	_ = WriteLong(w, salt)
-
-	if v >= V1_19_3 {
-		if signature != nil {
-			_ = WriteBool(w, true)
-			_, _ = w.Write(signature)
-		} else {
-			_ = WriteBool(w, false)
+	if v >= V1_9_2 {
+		if err := binary.Write(w, binary.BigEndian, p.Dimension); err != nil {
+			return err
 		}
+	} else if err := WriteByte(w, byte(int8(p.Dimension))); err != nil {
+		return err
+	}
 
-		_ = WriteVarInt(w, 0)
-		_, _ = w.Write(make([]byte, 3))
-
-		if v >= V1_21_5 {
-			_ = WriteByte(w, 0)
+	if v >= V1_15 {
+		if err := WriteLong(w, p.HashedSeed); err != nil {
+			return err
 		}
-	} else if v >= V1_19_2 {
-		_ = WriteByteSlice(w, signature)
-		_ = WriteBool(w, false)
-		_ = WriteVarInt(w, 0)
-		_ = WriteBool(w, false)
-	} else {
-		_ = WriteByteSlice(w, signature)
-		_ = WriteBool(w, false)
 	}
 
-	return nil
-}
-
-func (p *ServerboundChatMessage) signMessage(v Version, message string, timestamp int64, salt int64) ([]byte, error) {
-	if p.PrivateKey == nil {
-		return nil, nil
+	if v < V1_14 {
+		if err := WriteByte(w, p.Difficulty); err != nil {
+			return err
+		}
 	}
 
-	var signBuf []byte
-	if v >= V1_19_2 {
-		signBuf = make([]byte, 8+8+16+len(message))
-		binary.BigEndian.PutUint64(signBuf[0:8], uint64(salt))
-		binary.BigEndian.PutUint64(signBuf[8:16], uint64(timestamp))
-		copy(signBuf[16:32], p.UUID[:])
-		copy(signBuf[32:], message)
-	} else {
-		signBuf = make([]byte, 8+8+len(message))
-		binary.BigEndian.PutUint64(signBuf[0:8], uint64(timestamp))
-		binary.BigEndian.PutUint64(signBuf[8:16], uint64(salt))
-		copy(signBuf[16:], message)
+	if err := WriteByte(w, byte(p.MaxPlayers)); err != nil {
+		return err
 	}
 
-	hasher := sha256.New()
-	hasher.Write(signBuf)
-	hash := hasher.Sum(nil)
+	if err := WriteString(w, p.LevelType); err != nil {
+		return err
+	}
 
-	signature, err := rsa.SignPKCS1v15(fastrand.FastReader, p.PrivateKey, crypto.SHA256, hash)
-	if err != nil {
-		return nil, fmt.Errorf("failed to sign chat message: %w", err)
+	if v >= V1_14 {
+		if err := WriteVarInt(w, p.ViewDistance); err != nil {
+			return err
+		}
 	}
 
-	if v >= V1_19_3 {
-		signed := make([]byte, 256)
-		copy(signed, signature)
-		return signed, nil
+	if v >= V1_8 {
+		if err := WriteBool(w, p.ReducedDebugInfo); err != nil {
+			return err
+		}
 	}
 
-	return signature, nil
-}
+	if v >= V1_15 {
+		return WriteBool(w, p.EnableRespawnScreen)
+	}
 
-func (p *ServerboundChatMessage) Decode(r io.Reader, _ Version) (err error) {
-	p.Message, err = ReadString(r)
-	return
+	return nil
 }
 
-type ClientboundKeepAlive struct{ ID int64 }
-
-func (p *ClientboundKeepAlive) Encode(_ io.Writer, _ Version) error {
-	panic("client should not send ClientboundKeepAlive")
-}
-func (p *ClientboundKeepAlive) Decode(r io.Reader, v Version) (err error) {
-	if v >= V1_12_2 {
-		p.ID, err = ReadLong(r)
-	} else {
-		var id int32
-		id, err = ReadVarInt(r)
-		p.ID = int64(id)
+func (p *ClientboundJoinGame) encodeDimensionCodec(w io.Writer, v Version) error {
+	gameMode := p.GameMode
+	if v >= V1_16_2 {
+		if err := WriteBool(w, p.Hardcore); err != nil {
+			return err
+		}
+	} else if p.Hardcore {
+		gameMode |= 0x8
 	}
-	return
-}
-
-type ServerboundKeepAlive struct{ ID int64 }
 
-func (p *ServerboundKeepAlive) Encode(w io.Writer, v Version) error {
-	if v >= V1_12_2 {
-		return WriteLong(w, p.ID)
+	if err := WriteByte(w, gameMode); err != nil {
+		return err
 	}
-	return WriteVarInt(w, int32(p.ID))
-}
-func (p *ServerboundKeepAlive) Decode(_ io.Reader, _ Version) error {
-	panic("server should not decode ServerboundKeepAlive")
-}
 
-type ServerboundClientSettings struct {
-	ClientSettings
-}
+	if err := WriteByte(w, byte(p.PreviousGameMode)); err != nil {
+		return err
+	}
 
-func (p *ServerboundClientSettings) Encode(w io.Writer, v Version) error {
-	if err := WriteString(w, p.Locale); err != nil {
+	if err := writeStrings(w, p.DimensionNames); err != nil {
 		return err
 	}
 
-	if err := WriteByte(w, p.View); err != nil {
+	if err := WriteNBT(w, v, p.DimensionCodec); err != nil {
 		return err
 	}
 
-	if v <= V1_7 {
-		if err := WriteByte(w, 0x1); err != nil {
-			return err
-		}
-	} else if v >= V1_8 {
-		if err := WriteVarInt(w, p.ChatMode); err != nil {
+	if v >= V1_16_2 && v < V1_19 {
+		if err := WriteNBT(w, v, p.DimensionTypeData); err != nil {
 			return err
 		}
+	} else if err := WriteString(w, p.DimensionType); err != nil {
+		return err
 	}
 
-	if err := WriteBool(w, p.ChatColors); err != nil {
+	if err := WriteString(w, p.DimensionName); err != nil {
 		return err
 	}
 
-	if v <= V1_7 {
-		if err := WriteByte(w, p.SkinParts&0x01); err != nil {
-			return err
-		}
-		if err := WriteBool(w, true); err != nil {
-			return err
-		}
+	if err := WriteLong(w, p.HashedSeed); err != nil {
+		return err
 	}
 
-	if v >= V1_8 {
-		if err := WriteByte(w, p.SkinParts); err != nil {
+	if v >= V1_16_2 {
+		if err := WriteVarInt(w, p.MaxPlayers); err != nil {
 			return err
 		}
+	} else if err := WriteByte(w, byte(p.MaxPlayers)); err != nil {
+		return err
 	}
 
-	if v >= V1_9 {
-		if err := WriteVarInt(w, p.MainHand); err != nil {
-			return err
-		}
+	if err := WriteVarInt(w, p.ViewDistance); err != nil {
+		return err
 	}
 
-	if v >= V1_17_1 {
-		if err := WriteBool(w, false); err != nil {
+	if v >= V1_18 {
+		if err := WriteVarInt(w, p.SimulationDistance); err != nil {
 			return err
 		}
 	}
 
-	if v >= V1_18 {
-		if err := WriteBool(w, true); err != nil {
+	for _, flag := range []bool{p.ReducedDebugInfo, p.EnableRespawnScreen, p.IsDebug, p.IsFlat} {
+		if err := WriteBool(w, flag); err != nil {
 			return err
 		}
 	}
 
-	if v >= V1_21_3 {
-		if err := WriteVarInt(w, 0); err != nil {
+	if v >= V1_19 {
+		if err := writeDeathLocation(w, v, p.DeathLocation); err != nil {
 			return err
 		}
 	}
 
+	if v >= V1_20 {
+		return WriteVarInt(w, p.PortalCooldown)
+	}
+
 	return nil
 }
 
-func (p *ServerboundClientSettings) Decode(_ io.Reader, _ Version) error { return nil }
-
-type CustomPayload struct {
-	Channel string
-	Data    []byte
-}
+func (p *ClientboundJoinGame) encodeConfigured(w io.Writer, v Version) error {
+	if err := WriteBool(w, p.Hardcore); err != nil {
+		return err
+	}
 
-func (p *CustomPayload) Encode(w io.Writer, _ Version) error {
-	_ = WriteString(w, p.Channel)
-	_, err := w.Write(p.Data)
-	return err
-}
-func (p *CustomPayload) Decode(r io.Reader, _ Version) (err error) {
-	p.Channel, err = ReadString(r)
-	if err != nil {
+	if err := writeStrings(w, p.DimensionNames); err != nil {
 		return err
 	}
-	p.Data, err = io.ReadAll(r)
-	return err
-}
 
-type CustomPayloadData struct {
-	Channel string
-	Data    []byte
-}
+	for _, n := range []int32{p.MaxPlayers, p.ViewDistance, p.SimulationDistance} {
+		if err := WriteVarInt(w, n); err != nil {
+			return err
+		}
+	}
 
-func (p *CustomPayloadData) encode(w io.Writer, _ Version) error {
-	_ = WriteString(w, p.Channel)
-	_, err := w.Write(p.Data)
-	return err
-}
+	for _, flag := range []bool{p.ReducedDebugInfo, p.EnableRespawnScreen, p.DoLimitedCrafting} {
+		if err := WriteBool(w, flag); err != nil {
+			return err
+		}
+	}
 
-func (p *CustomPayloadData) decode(r io.Reader, _ Version) (err error) {
-	p.Channel, err = ReadString(r)
-	if err != nil {
+	if v >= V1_20_5 {
+		if err := WriteVarInt(w, p.DimensionTypeID); err != nil {
+			return err
+		}
+	} else if err := WriteString(w, p.DimensionType); err != nil {
 		return err
 	}
 
-	p.Data, err = io.ReadAll(r)
-	return err
-}
-
-type ServerboundCustomPayload struct{ CustomPayloadData }
+	if err := WriteString(w, p.DimensionName); err != nil {
+		return err
+	}
 
-func (p *ServerboundCustomPayload) Encode(w io.Writer, v Version) error { return p.encode(w, v) }
-func (p *ServerboundCustomPayload) Decode(r io.Reader, v Version) error { return p.decode(r, v) }
+	if err := WriteLong(w, p.HashedSeed); err != nil {
+		return err
+	}
 
-type ClientboundCustomPayload struct{ CustomPayloadData }
+	if err := WriteByte(w, p.GameMode); err != nil {
+		return err
+	}
 
-func (p *ClientboundCustomPayload) Encode(w io.Writer, v Version) error { return p.encode(w, v) }
-func (p *ClientboundCustomPayload) Decode(r io.Reader, v Version) error { return p.decode(r, v) }
+	if err := WriteByte(w, byte(p.PreviousGameMode)); err != nil {
+		return err
+	}
 
-type ClientboundFeatureFlags struct {
-	Features []string
-}
+	for _, flag := range []bool{p.IsDebug, p.IsFlat} {
+		if err := WriteBool(w, flag); err != nil {
+			return err
+		}
+	}
 
-func (p *ClientboundFeatureFlags) Encode(_ io.Writer, _ Version) error {
-	panic("client should not send FeatureFlags")
-}
+	if err := writeDeathLocation(w, v, p.DeathLocation); err != nil {
+		return err
+	}
 
-func (p *ClientboundFeatureFlags) Decode(r io.Reader, _ Version) error {
-	count, err := ReadVarInt(r)
-	if err != nil {
+	if err := WriteVarInt(w, p.PortalCooldown); err != nil {
 		return err
 	}
 
-	p.Features = make([]string, count)
-	for i := 0; i < int(count); i++ {
-		p.Features[i], err = ReadString(r)
-		if err != nil {
+	if v >= V1_21_3 {
+		if err := WriteVarInt(w, p.SeaLevel); err != nil {
 			return err
 		}
 	}
 
-	return nil
-}
-
-type ClientboundUpdateTags struct {
-	Tags []RegistryTag
-}
+	if v >= V1_20_5 {
+		return WriteBool(w, p.EnforcesSecureChat)
+	}
 
-type RegistryTag struct {
-	Registry string
-	Tags     []Tag
+	return nil
 }
 
-type Tag struct {
-	Name    string
-	Entries []int32
-}
+func (p *ClientboundJoinGame) Decode(r io.Reader, v Version) (err error) {
+	if err = binary.Read(r, binary.BigEndian, &p.EntityID); err != nil {
+		return err
+	}
 
-func (p *ClientboundUpdateTags) Encode(_ io.Writer, _ Version) error {
-	panic("client should not send UpdateTags")
-}
+	switch {
+	case v >= V1_20_2:
+		return p.decodeConfigured(r, v)
+	case v >= V1_16:
+		return p.decodeDimensionCodec(r, v)
+	}
 
-func (p *ClientboundUpdateTags) Decode(r io.Reader, _ Version) error {
-	count, err := ReadVarInt(r)
+	gameMode, err := ReadByte(r)
+	if err != nil {
+		return err
+	}
+	p.Hardcore, p.GameMode = gameMode&0x8 != 0, gameMode&^0x8
+
+	if v >= V1_9_2 {
+		if err := binary.Read(r, binary.BigEndian, &p.Dimension); err != nil {
+			return err
+		}
+	} else {
+		dimension, err := ReadByte(r)
+		if err != nil {
+			return err
+		}
+		p.Dimension = int32(int8(dimension))
+	}
+
+	if v >= V1_15 {
+		if p.HashedSeed, err = ReadLong(r); err != nil {
+			return err
+		}
+	}
+
+	if v < V1_14 {
+		if p.Difficulty, err = ReadByte(r); err != nil {
+			return err
+		}
+	}
+
+	maxPlayers, err := ReadByte(r)
+	if err != nil {
+		return err
+	}
+	p.MaxPlayers = int32(maxPlayers)
+
+	if p.LevelType, err = ReadString(r); err != nil {
+		return err
+	}
+
+	if v >= V1_14 {
+		if p.ViewDistance, err = ReadVarInt(r); err != nil {
+			return err
+		}
+	}
+
+	if v >= V1_8 {
+		if p.ReducedDebugInfo, err = ReadBool(r); err != nil {
+			return err
+		}
+	}
+
+	if v >= V1_15 {
+		p.EnableRespawnScreen, err = ReadBool(r)
+	}
+
+	return err
+}
+
+func (p *ClientboundJoinGame) decodeDimensionCodec(r io.Reader, v Version) (err error) {
+	if v >= V1_16_2 {
+		if p.Hardcore, err = ReadBool(r); err != nil {
+			return err
+		}
+	}
+
+	if p.GameMode, err = ReadByte(r); err != nil {
+		return err
+	}
+
+	if v < V1_16_2 {
+		p.Hardcore, p.GameMode = p.GameMode&0x8 != 0, p.GameMode&^0x8
+	}
+
+	previous, err := ReadByte(r)
+	if err != nil {
+		return err
+	}
+	p.PreviousGameMode = int8(previous)
+
+	if p.DimensionNames, err = readStrings(r); err != nil {
+		return err
+	}
+
+	if p.DimensionCodec, err = ReadNBT(r, v); err != nil {
+		return fmt.Errorf("read dimension codec: %w", err)
+	}
+
+	if v >= V1_16_2 && v < V1_19 {
+		if p.DimensionTypeData, err = ReadNBT(r, v); err != nil {
+			return fmt.Errorf("read dimension type: %w", err)
+		}
+	} else if p.DimensionType, err = ReadString(r); err != nil {
+		return err
+	}
+
+	if p.DimensionName, err = ReadString(r); err != nil {
+		return err
+	}
+
+	if p.HashedSeed, err = ReadLong(r); err != nil {
+		return err
+	}
+
+	if v >= V1_16_2 {
+		if p.MaxPlayers, err = ReadVarInt(r); err != nil {
+			return err
+		}
+	} else {
+		maxPlayers, err := ReadByte(r)
+		if err != nil {
+			return err
+		}
+		p.MaxPlayers = int32(maxPlayers)
+	}
+
+	if p.ViewDistance, err = ReadVarInt(r); err != nil {
+		return err
+	}
+
+	if v >= V1_18 {
+		if p.SimulationDistance, err = ReadVarInt(r); err != nil {
+			return err
+		}
+	}
+
+	for _, flag := range []*bool{&p.ReducedDebugInfo, &p.EnableRespawnScreen, &p.IsDebug, &p.IsFlat} {
+		if *flag, err = ReadBool(r); err != nil {
+			return err
+		}
+	}
+
+	if v >= V1_19 {
+		if p.DeathLocation, err = readDeathLocation(r, v); err != nil {
+			return err
+		}
+	}
+
+	if v >= V1_20 {
+		p.PortalCooldown, err = ReadVarInt(r)
+	}
+
+	return err
+}
+
+func (p *ClientboundJoinGame) decodeConfigured(r io.Reader, v Version) (err error) {
+	if p.Hardcore, err = ReadBool(r); err != nil {
+		return err
+	}
+
+	if p.DimensionNames, err = readStrings(r); err != nil {
+		return err
+	}
+
+	for _, n := range []*int32{&p.MaxPlayers, &p.ViewDistance, &p.SimulationDistance} {
+		if *n, err = ReadVarInt(r); err != nil {
+			return err
+		}
+	}
+
+	for _, flag := range []*bool{&p.ReducedDebugInfo, &p.EnableRespawnScreen, &p.DoLimitedCrafting} {
+		if *flag, err = ReadBool(r); err != nil {
+			return err
+		}
+	}
+
+	if v >= V1_20_5 {
+		if p.DimensionTypeID, err = ReadVarInt(r); err != nil {
+			return err
+		}
+	} else if p.DimensionType, err = ReadString(r); err != nil {
+		return err
+	}
+
+	if p.DimensionName, err = ReadString(r); err != nil {
+		return err
+	}
+
+	if p.HashedSeed, err = ReadLong(r); err != nil {
+		return err
+	}
+
+	if p.GameMode, err = ReadByte(r); err != nil {
+		return err
+	}
+
+	previous, err := ReadByte(r)
+	if err != nil {
+		return err
+	}
+	p.PreviousGameMode = int8(previous)
+
+	for _, flag := range []*bool{&p.IsDebug, &p.IsFlat} {
+		if *flag, err = ReadBool(r); err != nil {
+			return err
+		}
+	}
+
+	if p.DeathLocation, err = readDeathLocation(r, v); err != nil {
+		return err
+	}
+
+	if p.PortalCooldown, err = ReadVarInt(r); err != nil {
+		return err
+	}
+
+	if v >= V1_21_3 {
+		if p.SeaLevel, err = ReadVarInt(r); err != nil {
+			return err
+		}
+	}
+
+	if v >= V1_20_5 {
+		p.EnforcesSecureChat, err = ReadBool(r)
+	}
+
+	return err
+}
+
+func writeDeathLocation(w io.Writer, v Version, location *DeathLocation) error {
+	if err := WriteBool(w, location != nil); err != nil || location == nil {
+		return err
+	}
+
+	if err := WriteString(w, location.Dimension); err != nil {
+		return err
+	}
+
+	return WritePosition(w, v, location.Position)
+}
+
+func readDeathLocation(r io.Reader, v Version) (*DeathLocation, error) {
+	present, err := ReadBool(r)
+	if err != nil || !present {
+		return nil, err
+	}
+
+	location := &DeathLocation{}
+	if location.Dimension, err = ReadString(r); err != nil {
+		return nil, err
+	}
+
+	location.Position, err = ReadPosition(r, v)
+	return location, err
+}
+
+// ClientboundLoginDisconnect is sent during login, where the reason is always
+// a JSON component.
+type ClientboundLoginDisconnect struct{ Reason component.ChatComponent }
+
+func (p *ClientboundLoginDisconnect) Encode(w io.Writer, v Version) error {
+	return writeJSONChatComponent(w, v, p.Reason)
+}
+
+func (p *ClientboundLoginDisconnect) Decode(r io.Reader, _ Version) (err error) {
+	p.Reason, err = readJSONChatComponent(r)
+	return err
+}
+
+// ClientboundDisconnect kicks the player in the configuration and play
+// states.
+type ClientboundDisconnect struct{ Reason component.ChatComponent }
+
+func (p *ClientboundDisconnect) Encode(w io.Writer, v Version) error {
+	return WriteChatComponent(w, v, p.Reason)
+}
+func (p *ClientboundDisconnect) Decode(r io.Reader, v Version) (err error) {
+	p.Reason, err = ReadChatComponent(r, v)
+	return
+}
+
+type ServerboundChatMessage struct {
+	Message    string
+	PrivateKey *rsa.PrivateKey
+	UUID       uuid.UUID
+
+	Timestamp time.Time
+	Salt      int64
+
+	// Signature, Offset, Acknowledged and Checksum carry the 1.19.3+ message
+	// chain state; see ChatSession.Sign and LastSeenTracker.Update.
+	Signature    []byte
+	Offset       int32
+	Acknowledged [3]byte
+	Checksum     byte
+
+	// LastSeen and LastReceived acknowledge received messages on 1.19.1 and
+	// 1.19.2, where Signature comes from MessageChain.Sign; see
+	// LastSeenList.Update.
+	LastSeen     []LastSeenMessage
+	LastReceived *LastSeenMessage
+}
+
+func (p *ServerboundChatMessage) Encode(w io.Writer, v Version) error {
+	if v < V1_19 {
+		return WriteString(w, p.Message)
+	}
+
+	if p.Timestamp.IsZero() {
+		p.Timestamp = time.Now()
+	}
+
+	if p.Salt == 0 {
+		p.Salt = fastrand.NumberN[int64](math.MaxInt64)
+	}
+
+	timestamp := p.Timestamp.UnixMilli()
+
+	_ = WriteString(w, p.Message)
+	_ = WriteLong(w, timestamp)
+	_ = WriteLong(w, p.Salt)
+
+	if v >= V1_19_3 {
+		if p.Signature != nil {
+			signature := make([]byte, 256)
+			copy(signature, p.Signature)
+			_ = WriteBool(w, true)
+			_, _ = w.Write(signature)
+		} else {
+			_ = WriteBool(w, false)
+		}
+
+		_ = WriteVarInt(w, p.Offset)
+		_, _ = w.Write(p.Acknowledged[:])
+
+		if v >= V1_21_5 {
+			_ = WriteByte(w, p.Checksum)
+		}
+
+		return nil
+	}
+
+	// 1.19.1 and 1.19.2 chain every signature to the previous message, so
+	// the signature is made by MessageChain.Sign instead.
+	signature := p.Signature
+	if p.PrivateKey != nil && v < V1_19_2 {
+		var err error
+		if signature, err = p.signMessage(); err != nil {
+			return err
+		}
+	}
+
+	_ = WriteByteSlice(w, signature)
+	// Signed preview was never used.
+	_ = WriteBool(w, false)
+
+	if v >= V1_19_2 {
+		return writeLastSeenMessages(w, p.LastSeen, p.LastReceived)
+	}
+
+	return nil
+}
+
+// signMessage signs the message the way 1.19 does, as the JSON of a text
+// component.
+func (p *ServerboundChatMessage) signMessage() ([]byte, error) {
+	var content bytes.Buffer
+	encoder := json.NewEncoder(&content)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(map[string]string{"text": p.Message}); err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, 0, 32+content.Len())
+	data = binary.BigEndian.AppendUint64(data, uint64(p.Salt))
+	data = append(data, p.UUID[:]...)
+	data = binary.BigEndian.AppendUint64(data, uint64(p.Timestamp.Unix()))
+	data = append(data, bytes.TrimSuffix(content.Bytes(), []byte("\n"))...)
+
+	hash := sha256.Sum256(data)
+	signature, err := rsa.SignPKCS1v15(fastrand.FastReader, p.PrivateKey, crypto.SHA256, hash[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign chat message: %w", err)
+	}
+
+	return signature, nil
+}
+
+// writeLastSeenMessages writes the 1.19.1-1.19.2 acknowledgement of the last
+// message of each recent sender and of the last message received.
+func writeLastSeenMessages(w io.Writer, lastSeen []LastSeenMessage, lastReceived *LastSeenMessage) error {
+	_ = WriteVarInt(w, int32(len(lastSeen)))
+	for _, entry := range lastSeen {
+		_ = WriteUUID(w, entry.Sender)
+		_ = WriteByteSlice(w, entry.Signature)
+	}
+
+	if lastReceived == nil {
+		return WriteBool(w, false)
+	}
+
+	_ = WriteBool(w, true)
+	_ = WriteUUID(w, lastReceived.Sender)
+	return WriteByteSlice(w, lastReceived.Signature)
+}
+
+func readLastSeenMessages(r io.Reader) (lastSeen []LastSeenMessage, lastReceived *LastSeenMessage, err error) {
+	count, err := ReadVarInt(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if count < 0 || count > lastSeenListCapacity {
+		return nil, nil, fmt.Errorf("invalid last seen message count %d", count)
+	}
+
+	for i := int32(0); i <= count; i++ {
+		if i == count {
+			// The optional last received entry follows the list.
+			present, err := ReadBool(r)
+			if err != nil || !present {
+				return lastSeen, nil, err
+			}
+		}
+
+		var entry LastSeenMessage
+		if entry.Sender, err = ReadUUID(r); err != nil {
+			return nil, nil, err
+		}
+		if entry.Signature, err = ReadBytes(r); err != nil {
+			return nil, nil, err
+		}
+
+		if i == count {
+			return lastSeen, &entry, nil
+		}
+		lastSeen = append(lastSeen, entry)
+	}
+
+	return lastSeen, nil, nil
+}
+
+func (p *ServerboundChatMessage) Decode(r io.Reader, v Version) (err error) {
+	if p.Message, err = ReadString(r); err != nil || v < V1_19 {
+		return err
+	}
+
+	timestamp, err := ReadLong(r)
+	if err != nil {
+		return err
+	}
+	p.Timestamp = time.UnixMilli(timestamp)
+
+	if p.Salt, err = ReadLong(r); err != nil {
+		return err
+	}
+
+	if v < V1_19_3 {
+		return p.decodeHeadered(r, v)
+	}
+
+	hasSignature, err := ReadBool(r)
+	if err != nil {
+		return err
+	}
+
+	if hasSignature {
+		p.Signature = make([]byte, 256)
+		if _, err := io.ReadFull(r, p.Signature); err != nil {
+			return err
+		}
+	}
+
+	if p.Offset, err = ReadVarInt(r); err != nil {
+		return err
+	}
+
+	if _, err := io.ReadFull(r, p.Acknowledged[:]); err != nil {
+		return err
+	}
+
+	if v >= V1_21_5 {
+		p.Checksum, err = ReadByte(r)
+	}
+
+	return err
+}
+
+// decodeHeadered reads the 1.19-1.19.2 tail. The previewed flag is read but
+// not kept.
+func (p *ServerboundChatMessage) decodeHeadered(r io.Reader, v Version) (err error) {
+	if p.Signature, err = ReadBytes(r); err != nil {
+		return err
+	}
+
+	if _, err := ReadBool(r); err != nil {
+		return err
+	}
+
+	if v < V1_19_2 {
+		return nil
+	}
+
+	p.LastSeen, p.LastReceived, err = readLastSeenMessages(r)
+	return err
+}
+
+type ServerboundChatSessionUpdate struct {
+	SessionID    uuid.UUID
+	ExpiresAt    int64
+	PublicKey    []byte
+	KeySignature []byte
+}
+
+func (p *ServerboundChatSessionUpdate) Encode(w io.Writer, _ Version) error {
+	if _, err := w.Write(p.SessionID[:]); err != nil {
+		return err
+	}
+
+	if err := WriteLong(w, p.ExpiresAt); err != nil {
+		return err
+	}
+
+	if err := WriteByteSlice(w, p.PublicKey); err != nil {
+		return err
+	}
+
+	return WriteByteSlice(w, p.KeySignature)
+}
+
+func (p *ServerboundChatSessionUpdate) Decode(r io.Reader, _ Version) (err error) {
+	if p.SessionID, err = ReadUUID(r); err != nil {
+		return err
+	}
+
+	if p.ExpiresAt, err = ReadLong(r); err != nil {
+		return err
+	}
+
+	if p.PublicKey, err = ReadBytes(r); err != nil {
+		return err
+	}
+
+	p.KeySignature, err = ReadBytes(r)
+	return err
+}
+
+type ServerboundMessageAcknowledgement struct {
+	Offset int32
+
+	// 1.19.1-1.19.2 acknowledge with an explicit last-seen list instead.
+	LastSeen     []LastSeenMessage
+	LastReceived *LastSeenMessage
+}
+
+func (p *ServerboundMessageAcknowledgement) Encode(w io.Writer, v Version) error {
+	if v < V1_19_3 {
+		return writeLastSeenMessages(w, p.LastSeen, p.LastReceived)
+	}
+	return WriteVarInt(w, p.Offset)
+}
+
+func (p *ServerboundMessageAcknowledgement) Decode(r io.Reader, v Version) (err error) {
+	if v < V1_19_3 {
+		p.LastSeen, p.LastReceived, err = readLastSeenMessages(r)
+		return err
+	}
+	p.Offset, err = ReadVarInt(r)
+	return err
+}
+
+type ClientboundKeepAlive struct{ ID int64 }
+
+func (p *ClientboundKeepAlive) Encode(w io.Writer, v Version) error {
+	return writeKeepAliveID(w, v, p.ID)
+}
+func (p *ClientboundKeepAlive) Decode(r io.Reader, v Version) (err error) {
+	p.ID, err = readKeepAliveID(r, v)
+	return
+}
+
+type ServerboundKeepAlive struct{ ID int64 }
+
+func (p *ServerboundKeepAlive) Encode(w io.Writer, v Version) error {
+	return writeKeepAliveID(w, v, p.ID)
+}
+func (p *ServerboundKeepAlive) Decode(r io.Reader, v Version) (err error) {
+	p.ID, err = readKeepAliveID(r, v)
+	return
+}
+
+// Keep alive ids are an int on 1.7, a VarInt until 1.12.2 and a long after.
+func writeKeepAliveID(w io.Writer, v Version, id int64) error {
+	switch {
+	case v >= V1_12_2:
+		return WriteLong(w, id)
+	case v >= V1_8:
+		return WriteVarInt(w, int32(id))
+	}
+	return binary.Write(w, binary.BigEndian, int32(id))
+}
+
+func readKeepAliveID(r io.Reader, v Version) (int64, error) {
+	switch {
+	case v >= V1_12_2:
+		return ReadLong(r)
+	case v >= V1_8:
+		id, err := ReadVarInt(r)
+		return int64(id), err
+	}
+
+	var id int32
+	err := binary.Read(r, binary.BigEndian, &id)
+	return int64(id), err
+}
+
+type ServerboundClientSettings struct {
+	ClientSettings
+}
+
+func (p *ServerboundClientSettings) Encode(w io.Writer, v Version) error {
+	if err := WriteString(w, p.Locale); err != nil {
+		return err
+	}
+
+	if err := WriteByte(w, p.View); err != nil {
+		return err
+	}
+
+	if v <= V1_7 {
+		if err := WriteByte(w, byte(p.ChatMode)); err != nil {
+			return err
+		}
+	} else if v >= V1_8 {
+		if err := WriteVarInt(w, p.ChatMode); err != nil {
+			return err
+		}
+	}
+
+	if err := WriteBool(w, p.ChatColors); err != nil {
+		return err
+	}
+
+	if v <= V1_7 {
+		// Normal difficulty, then whether to show the cape.
+		if err := WriteByte(w, 2); err != nil {
+			return err
+		}
+		if err := WriteBool(w, p.SkinParts&0x01 != 0); err != nil {
+			return err
+		}
+	}
+
+	if v >= V1_8 {
+		if err := WriteByte(w, p.SkinParts); err != nil {
+			return err
+		}
+	}
+
+	if v >= V1_9 {
+		if err := WriteVarInt(w, p.MainHand); err != nil {
+			return err
+		}
+	}
+
+	if v >= V1_17_1 {
+		if err := WriteBool(w, p.TextFiltering); err != nil {
+			return err
+		}
+	}
+
+	if v >= V1_18 {
+		if err := WriteBool(w, !p.HideFromServerListing); err != nil {
+			return err
+		}
+	}
+
+	if v >= V1_21_3 {
+		if err := WriteVarInt(w, p.ParticleStatus); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *ServerboundClientSettings) Decode(r io.Reader, v Version) (err error) {
+	if p.Locale, err = ReadString(r); err != nil {
+		return err
+	}
+
+	if p.View, err = ReadByte(r); err != nil {
+		return err
+	}
+
+	if v <= V1_7 {
+		chatFlags, err := ReadByte(r)
+		if err != nil {
+			return err
+		}
+		p.ChatMode = int32(chatFlags)
+	} else if p.ChatMode, err = ReadVarInt(r); err != nil {
+		return err
+	}
+
+	if p.ChatColors, err = ReadBool(r); err != nil {
+		return err
+	}
+
+	if v <= V1_7 {
+		if _, err := ReadByte(r); err != nil {
+			return err
+		}
+		showCape, err := ReadBool(r)
+		if err != nil {
+			return err
+		}
+		if showCape {
+			p.SkinParts = 0x01
+		}
+	} else if p.SkinParts, err = ReadByte(r); err != nil {
+		return err
+	}
+
+	if v >= V1_9 {
+		if p.MainHand, err = ReadVarInt(r); err != nil {
+			return err
+		}
+	}
+
+	if v >= V1_17_1 {
+		if p.TextFiltering, err = ReadBool(r); err != nil {
+			return err
+		}
+	}
+
+	if v >= V1_18 {
+		allowListing, err := ReadBool(r)
+		if err != nil {
+			return err
+		}
+		p.HideFromServerListing = !allowListing
+	}
+
+	if v >= V1_21_3 {
+		p.ParticleStatus, err = ReadVarInt(r)
+	}
+
+	return err
+}
+
+type CustomPayload struct {
+	Channel string
+	Data    []byte
+}
+
+func (p *CustomPayload) Encode(w io.Writer, v Version) error {
+	return (*CustomPayloadData)(p).encode(w, v)
+}
+func (p *CustomPayload) Decode(r io.Reader, v Version) (err error) {
+	return (*CustomPayloadData)(p).decode(r, v)
+}
+
+type CustomPayloadData struct {
+	Channel string
+	Data    []byte
+}
+
+// 1.7 prefixes the payload with a short length; later versions use the rest
+// of the packet.
+func (p *CustomPayloadData) encode(w io.Writer, v Version) error {
+	_ = WriteString(w, p.Channel)
+	if v < V1_8 {
+		return writeEncryptionBytes(w, v, p.Data)
+	}
+	_, err := w.Write(p.Data)
+	return err
+}
+
+func (p *CustomPayloadData) decode(r io.Reader, v Version) (err error) {
+	p.Channel, err = ReadString(r)
+	if err != nil {
+		return err
+	}
+
+	if v < V1_8 {
+		p.Data, err = readEncryptionBytes(r, v)
+		return err
+	}
+
+	p.Data, err = io.ReadAll(r)
+	return err
+}
+
+type ServerboundCustomPayload struct{ CustomPayloadData }
+
+func (p *ServerboundCustomPayload) Encode(w io.Writer, v Version) error { return p.encode(w, v) }
+func (p *ServerboundCustomPayload) Decode(r io.Reader, v Version) error { return p.decode(r, v) }
+
+type ClientboundCustomPayload struct{ CustomPayloadData }
+
+func (p *ClientboundCustomPayload) Encode(w io.Writer, v Version) error { return p.encode(w, v) }
+func (p *ClientboundCustomPayload) Decode(r io.Reader, v Version) error { return p.decode(r, v) }
+
+type ClientboundFeatureFlags struct {
+	Features []string
+}
+
+func (p *ClientboundFeatureFlags) Encode(w io.Writer, _ Version) error {
+	return writeStrings(w, p.Features)
+}
+
+func (p *ClientboundFeatureFlags) Decode(r io.Reader, _ Version) (err error) {
+	p.Features, err = readStrings(r)
+	return err
+}
+
+type ClientboundUpdateTags struct {
+	Tags []RegistryTag
+}
+
+type RegistryTag struct {
+	Registry string
+	Tags     []Tag
+}
+
+type Tag struct {
+	Name    string
+	Entries []int32
+}
+
+func (p *ClientboundUpdateTags) Encode(w io.Writer, _ Version) error {
+	if err := WriteVarInt(w, int32(len(p.Tags))); err != nil {
+		return err
+	}
+
+	for _, registry := range p.Tags {
+		if err := WriteString(w, registry.Registry); err != nil {
+			return err
+		}
+
+		if err := WriteVarInt(w, int32(len(registry.Tags))); err != nil {
+			return err
+		}
+
+		for _, tag := range registry.Tags {
+			if err := WriteString(w, tag.Name); err != nil {
+				return err
+			}
+
+			if err := WriteVarInt(w, int32(len(tag.Entries))); err != nil {
+				return err
+			}
+
+			for _, entry := range tag.Entries {
+				if err := WriteVarInt(w, entry); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p *ClientboundUpdateTags) Decode(r io.Reader, _ Version) error {
+	count, err := ReadVarInt(r)
 	if err != nil {
 		return err
 	}
 
 	p.Tags = make([]RegistryTag, count)
 
-	for i := 0; i < int(count); i++ {
-		p.Tags[i].Registry, err = ReadString(r)
-		if err != nil {
+	for i := 0; i < int(count); i++ {
+		p.Tags[i].Registry, err = ReadString(r)
+		if err != nil {
+			return err
+		}
+
+		tagCount, err := ReadVarInt(r)
+		if err != nil {
+			return err
+		}
+
+		p.Tags[i].Tags = make([]Tag, tagCount)
+
+		for j := 0; j < int(tagCount); j++ {
+			p.Tags[i].Tags[j].Name, err = ReadString(r)
+			if err != nil {
+				return err
+			}
+
+			entryCount, err := ReadVarInt(r)
+			if err != nil {
+				return err
+			}
+
+			p.Tags[i].Tags[j].Entries = make([]int32, entryCount)
+			for k := 0; k < int(entryCount); k++ {
+				p.Tags[i].Tags[j].Entries[k], err = ReadVarInt(r)
+
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+type RegistryEntry struct {
+	ID   string
+	Data nbt.Tag
+}
+
+// ClientboundRegistryData carries the whole registry codec as one compound on
+// 1.20.2 and 1.20.3, and one registry per packet from 1.20.5.
+type ClientboundRegistryData struct {
+	Codec    nbt.Tag
+	Registry string
+	Entries  []RegistryEntry
+}
+
+func (p *ClientboundRegistryData) Encode(w io.Writer, v Version) error {
+	if v < V1_20_5 {
+		return WriteNBT(w, v, p.Codec)
+	}
+
+	if err := WriteString(w, p.Registry); err != nil {
+		return err
+	}
+
+	if err := WriteVarInt(w, int32(len(p.Entries))); err != nil {
+		return err
+	}
+
+	for _, entry := range p.Entries {
+		if err := WriteString(w, entry.ID); err != nil {
+			return err
+		}
+
+		if err := WriteBool(w, entry.Data != nil); err != nil {
+			return err
+		}
+
+		if entry.Data != nil {
+			if err := WriteNBT(w, v, entry.Data); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p *ClientboundRegistryData) Decode(r io.Reader, v Version) (err error) {
+	if v < V1_20_5 {
+		p.Codec, err = ReadNBT(r, v)
+		return err
+	}
+
+	if p.Registry, err = ReadString(r); err != nil {
+		return err
+	}
+
+	count, err := ReadVarInt(r)
+	if err != nil {
+		return err
+	}
+
+	if count < 0 {
+		return fmt.Errorf("invalid registry entry count %d", count)
+	}
+
+	p.Entries = make([]RegistryEntry, 0, min(count, 1024))
+	for i := int32(0); i < count; i++ {
+		var entry RegistryEntry
+		if entry.ID, err = ReadString(r); err != nil {
+			return err
+		}
+
+		hasData, err := ReadBool(r)
+		if err != nil {
+			return err
+		}
+
+		if hasData {
+			if entry.Data, err = ReadNBT(r, v); err != nil {
+				return fmt.Errorf("registry entry %s: %w", entry.ID, err)
+			}
+		}
+
+		p.Entries = append(p.Entries, entry)
+	}
+
+	return nil
+}
+
+const (
+	ChatPositionChat byte = iota
+	ChatPositionSystem
+	ChatPositionGameInfo
+)
+
+// ClientboundChatMessage is the pre-1.19 chat packet that carried player,
+// system and action bar text alike.
+type ClientboundChatMessage struct {
+	Component component.ChatComponent
+	Position  byte
+	Sender    uuid.UUID
+}
+
+func (p *ClientboundChatMessage) Encode(w io.Writer, v Version) error {
+	if err := WriteChatComponent(w, v, p.Component); err != nil {
+		return err
+	}
+
+	if v >= V1_8 {
+		if err := WriteByte(w, p.Position); err != nil {
+			return err
+		}
+	}
+
+	if v >= V1_16 {
+		return WriteUUID(w, p.Sender)
+	}
+
+	return nil
+}
+
+func (p *ClientboundChatMessage) Decode(r io.Reader, v Version) (err error) {
+	if p.Component, err = ReadChatComponent(r, v); err != nil {
+		return err
+	}
+
+	if v >= V1_8 {
+		if p.Position, err = ReadByte(r); err != nil {
+			return err
+		}
+	}
+
+	if v >= V1_16 {
+		p.Sender, err = ReadUUID(r)
+	}
+
+	return err
+}
+
+type ClientboundPlayerChat struct {
+	GlobalIndex int32
+	Sender      uuid.UUID
+	Index       int32
+	Signature   []byte
+
+	Message          string
+	FormattedContent *component.ChatComponent
+	UnsignedContent  *component.ChatComponent
+	Timestamp        time.Time
+	Salt             int64
+	FilterType       int32
+
+	ChatType   int32
+	SenderName component.ChatComponent
+	TargetName *component.ChatComponent
+}
+
+// Content returns the component the vanilla client would display, preferring
+// server-modified content over the signed plain message.
+func (p *ClientboundPlayerChat) Content() component.ChatComponent {
+	if p.UnsignedContent != nil {
+		return *p.UnsignedContent
+	}
+
+	if p.FormattedContent != nil {
+		return *p.FormattedContent
+	}
+
+	return component.ChatComponent{Text: p.Message}
+}
+
+// Encode writes the message without previous message references or a
+// partial filter mask, which the decoder does not keep either.
+func (p *ClientboundPlayerChat) Encode(w io.Writer, v Version) error {
+	switch {
+	case v >= V1_19_3:
+		return p.encodeChained(w, v)
+	case v >= V1_19_2:
+		return p.encodeHeadered(w, v)
+	}
+
+	signed := component.ChatComponent{Text: p.Message}
+	if p.FormattedContent != nil {
+		signed = *p.FormattedContent
+	}
+
+	if err := WriteChatComponent(w, v, signed); err != nil {
+		return err
+	}
+
+	if err := writeOptionalChatComponent(w, v, p.UnsignedContent); err != nil {
+		return err
+	}
+
+	if err := WriteVarInt(w, p.ChatType); err != nil {
+		return err
+	}
+
+	if err := WriteUUID(w, p.Sender); err != nil {
+		return err
+	}
+
+	if err := WriteChatComponent(w, v, p.SenderName); err != nil {
+		return err
+	}
+
+	if err := writeOptionalChatComponent(w, v, p.TargetName); err != nil {
+		return err
+	}
+
+	if err := p.writeTimestampAndSalt(w); err != nil {
+		return err
+	}
+
+	return WriteByteSlice(w, p.Signature)
+}
+
+func (p *ClientboundPlayerChat) encodeHeadered(w io.Writer, v Version) error {
+	// No previous signature.
+	if err := WriteBool(w, false); err != nil {
+		return err
+	}
+
+	if err := WriteUUID(w, p.Sender); err != nil {
+		return err
+	}
+
+	if err := WriteByteSlice(w, p.Signature); err != nil {
+		return err
+	}
+
+	if err := WriteString(w, p.Message); err != nil {
+		return err
+	}
+
+	if err := writeOptionalChatComponent(w, v, p.FormattedContent); err != nil {
+		return err
+	}
+
+	if err := p.writeTimestampAndSalt(w); err != nil {
+		return err
+	}
+
+	if err := WriteVarInt(w, 0); err != nil {
+		return err
+	}
+
+	if err := writeOptionalChatComponent(w, v, p.UnsignedContent); err != nil {
+		return err
+	}
+
+	if err := p.writeFilter(w); err != nil {
+		return err
+	}
+
+	return writeBoundChatType(w, v, p.ChatType, p.SenderName, p.TargetName)
+}
+
+func (p *ClientboundPlayerChat) encodeChained(w io.Writer, v Version) error {
+	if v >= V1_21_5 {
+		if err := WriteVarInt(w, p.GlobalIndex); err != nil {
+			return err
+		}
+	}
+
+	if err := WriteUUID(w, p.Sender); err != nil {
+		return err
+	}
+
+	if err := WriteVarInt(w, p.Index); err != nil {
+		return err
+	}
+
+	if p.Signature != nil && len(p.Signature) != 256 {
+		return fmt.Errorf("invalid message signature length %d", len(p.Signature))
+	}
+
+	if err := WriteBool(w, p.Signature != nil); err != nil {
+		return err
+	}
+
+	if _, err := w.Write(p.Signature); err != nil {
+		return err
+	}
+
+	if err := WriteString(w, p.Message); err != nil {
+		return err
+	}
+
+	if err := p.writeTimestampAndSalt(w); err != nil {
+		return err
+	}
+
+	if err := WriteVarInt(w, 0); err != nil {
+		return err
+	}
+
+	if err := writeOptionalChatComponent(w, v, p.UnsignedContent); err != nil {
+		return err
+	}
+
+	if err := p.writeFilter(w); err != nil {
+		return err
+	}
+
+	return writeBoundChatType(w, v, p.ChatType, p.SenderName, p.TargetName)
+}
+
+func (p *ClientboundPlayerChat) Decode(r io.Reader, v Version) (err error) {
+	switch {
+	case v >= V1_19_3:
+		return p.decodeChained(r, v)
+	case v >= V1_19_2:
+		return p.decodeHeadered(r, v)
+	}
+
+	signed, err := ReadChatComponent(r, v)
+	if err != nil {
+		return err
+	}
+	p.FormattedContent = &signed
+	p.Message = signed.String()
+
+	if p.UnsignedContent, err = readOptionalChatComponent(r, v); err != nil {
+		return err
+	}
+
+	if p.ChatType, err = ReadVarInt(r); err != nil {
+		return err
+	}
+
+	if p.Sender, err = ReadUUID(r); err != nil {
+		return err
+	}
+
+	if p.SenderName, err = ReadChatComponent(r, v); err != nil {
+		return err
+	}
+
+	if p.TargetName, err = readOptionalChatComponent(r, v); err != nil {
+		return err
+	}
+
+	if err := p.readTimestampAndSalt(r); err != nil {
+		return err
+	}
+
+	p.Signature, err = ReadBytes(r)
+	return err
+}
+
+func (p *ClientboundPlayerChat) decodeHeadered(r io.Reader, v Version) (err error) {
+	hasPrevious, err := ReadBool(r)
+	if err != nil {
+		return err
+	}
+
+	if hasPrevious {
+		if _, err := ReadBytes(r); err != nil {
 			return err
 		}
+	}
 
-		tagCount, err := ReadVarInt(r)
-		if err != nil {
+	if p.Sender, err = ReadUUID(r); err != nil {
+		return err
+	}
+
+	if p.Signature, err = ReadBytes(r); err != nil {
+		return err
+	}
+
+	if p.Message, err = ReadString(r); err != nil {
+		return err
+	}
+
+	if p.FormattedContent, err = readOptionalChatComponent(r, v); err != nil {
+		return err
+	}
+
+	if err := p.readTimestampAndSalt(r); err != nil {
+		return err
+	}
+
+	previousCount, err := ReadVarInt(r)
+	if err != nil {
+		return err
+	}
+
+	for i := int32(0); i < previousCount; i++ {
+		if _, err := ReadUUID(r); err != nil {
 			return err
 		}
 
-		p.Tags[i].Tags = make([]Tag, tagCount)
+		if _, err := ReadBytes(r); err != nil {
+			return err
+		}
+	}
 
-		for j := 0; j < int(tagCount); j++ {
-			p.Tags[i].Tags[j].Name, err = ReadString(r)
-			if err != nil {
-				return err
-			}
+	if p.UnsignedContent, err = readOptionalChatComponent(r, v); err != nil {
+		return err
+	}
 
-			entryCount, err := ReadVarInt(r)
-			if err != nil {
+	if err := p.readFilter(r); err != nil {
+		return err
+	}
+
+	p.ChatType, p.SenderName, p.TargetName, err = readBoundChatType(r, v)
+	return err
+}
+
+func (p *ClientboundPlayerChat) decodeChained(r io.Reader, v Version) (err error) {
+	if v >= V1_21_5 {
+		if p.GlobalIndex, err = ReadVarInt(r); err != nil {
+			return err
+		}
+	}
+
+	if p.Sender, err = ReadUUID(r); err != nil {
+		return err
+	}
+
+	if p.Index, err = ReadVarInt(r); err != nil {
+		return err
+	}
+
+	hasSignature, err := ReadBool(r)
+	if err != nil {
+		return err
+	}
+
+	if hasSignature {
+		p.Signature = make([]byte, 256)
+		if _, err := io.ReadFull(r, p.Signature); err != nil {
+			return err
+		}
+	}
+
+	if p.Message, err = ReadString(r); err != nil {
+		return err
+	}
+
+	if err := p.readTimestampAndSalt(r); err != nil {
+		return err
+	}
+
+	previousCount, err := ReadVarInt(r)
+	if err != nil {
+		return err
+	}
+
+	for i := int32(0); i < previousCount; i++ {
+		id, err := ReadVarInt(r)
+		if err != nil {
+			return err
+		}
+
+		if id == 0 {
+			if _, err := io.CopyN(io.Discard, r, 256); err != nil {
 				return err
 			}
+		}
+	}
 
-			p.Tags[i].Tags[j].Entries = make([]int32, entryCount)
-			for k := 0; k < int(entryCount); k++ {
-				p.Tags[i].Tags[j].Entries[k], err = ReadVarInt(r)
+	if p.UnsignedContent, err = readOptionalChatComponent(r, v); err != nil {
+		return err
+	}
 
-				if err != nil {
-					return err
-				}
-			}
+	if err := p.readFilter(r); err != nil {
+		return err
+	}
+
+	p.ChatType, p.SenderName, p.TargetName, err = readBoundChatType(r, v)
+	return err
+}
+
+func (p *ClientboundPlayerChat) readTimestampAndSalt(r io.Reader) (err error) {
+	timestamp, err := ReadLong(r)
+	if err != nil {
+		return err
+	}
+	p.Timestamp = time.UnixMilli(timestamp)
+
+	p.Salt, err = ReadLong(r)
+	return err
+}
+
+func (p *ClientboundPlayerChat) writeTimestampAndSalt(w io.Writer) error {
+	if err := WriteLong(w, p.Timestamp.UnixMilli()); err != nil {
+		return err
+	}
+
+	return WriteLong(w, p.Salt)
+}
+
+func (p *ClientboundPlayerChat) writeFilter(w io.Writer) error {
+	if err := WriteVarInt(w, p.FilterType); err != nil {
+		return err
+	}
+
+	if p.FilterType == 2 {
+		return WriteVarInt(w, 0)
+	}
+
+	return nil
+}
+
+func (p *ClientboundPlayerChat) readFilter(r io.Reader) (err error) {
+	if p.FilterType, err = ReadVarInt(r); err != nil {
+		return err
+	}
+
+	// Partially filtered messages carry a BitSet mask.
+	if p.FilterType == 2 {
+		words, err := ReadVarInt(r)
+		if err != nil {
+			return err
+		}
+
+		if words < 0 || words > MaxPacketDataSize/8 {
+			return fmt.Errorf("invalid filter mask length %d", words)
 		}
+
+		_, err = io.CopyN(io.Discard, r, int64(words)*8)
+		return err
 	}
+
 	return nil
 }
 
-type ClientboundRegistryData struct {
-	Registry string
-	Data     []byte
+// readBoundChatType reads the chat type reference together with the sender
+// and optional target names. From 1.20.5 the type is a registry holder that
+// may inline its definition, in which case -1 is returned as the type id.
+func readBoundChatType(r io.Reader, v Version) (chatType int32, name component.ChatComponent, target *component.ChatComponent, err error) {
+	if chatType, err = ReadVarInt(r); err != nil {
+		return
+	}
+
+	if v >= V1_20_5 {
+		chatType--
+		if chatType < 0 {
+			for i := 0; i < 2; i++ {
+				if err = skipChatTypeDecoration(r, v); err != nil {
+					return
+				}
+			}
+		}
+	}
+
+	if name, err = ReadChatComponent(r, v); err != nil {
+		return
+	}
+
+	target, err = readOptionalChatComponent(r, v)
+	return
 }
 
-func (p *ClientboundRegistryData) Encode(_ io.Writer, _ Version) error {
-	panic("client should not send RegistryData")
+// writeBoundChatType is the inverse of readBoundChatType. Inline chat type
+// definitions are not supported, so chatType must be a registry id.
+func writeBoundChatType(w io.Writer, v Version, chatType int32, name component.ChatComponent, target *component.ChatComponent) error {
+	if v >= V1_20_5 {
+		if chatType < 0 {
+			return fmt.Errorf("invalid chat type id %d", chatType)
+		}
+		chatType++
+	}
+
+	if err := WriteVarInt(w, chatType); err != nil {
+		return err
+	}
+
+	if err := WriteChatComponent(w, v, name); err != nil {
+		return err
+	}
+
+	return writeOptionalChatComponent(w, v, target)
 }
 
-func (p *ClientboundRegistryData) Decode(r io.Reader, _ Version) error {
-	p.Registry, _ = ReadString(r)
-	p.Data, _ = ReadBytes(r)
-	return nil
+func skipChatTypeDecoration(r io.Reader, v Version) error {
+	if _, err := ReadString(r); err != nil {
+		return err
+	}
+
+	count, err := ReadVarInt(r)
+	if err != nil {
+		return err
+	}
+
+	for i := int32(0); i < count; i++ {
+		if _, err := ReadVarInt(r); err != nil {
+			return err
+		}
+	}
+
+	_, err = ReadNBT(r, v)
+	return err
 }
 
-type ClientboundChatMessage struct {
+type ClientboundSystemChat struct {
 	Component component.ChatComponent
-	Sender    string
+	Overlay   bool
+}
+
+func (p *ClientboundSystemChat) Encode(w io.Writer, v Version) error {
+	if err := WriteChatComponent(w, v, p.Component); err != nil {
+		return err
+	}
+
+	if v >= V1_19_2 {
+		return WriteBool(w, p.Overlay)
+	}
+
+	if p.Overlay {
+		return WriteVarInt(w, 2)
+	}
+	return WriteVarInt(w, 1)
+}
+
+func (p *ClientboundSystemChat) Decode(r io.Reader, v Version) (err error) {
+	if p.Component, err = ReadChatComponent(r, v); err != nil {
+		return err
+	}
+
+	if v >= V1_19_2 {
+		p.Overlay, err = ReadBool(r)
+		return err
+	}
+
+	// 1.19 used a chat type id, where game_info is the action bar.
+	chatType, err := ReadVarInt(r)
+	p.Overlay = chatType == 2
+	return err
 }
 
-func (p *ClientboundChatMessage) Encode(_ io.Writer, _ Version) error {
-	return errors.New("client should not send Clientbound ChatMessage")
+type ClientboundDisguisedChat struct {
+	Message    component.ChatComponent
+	ChatType   int32
+	SenderName component.ChatComponent
+	TargetName *component.ChatComponent
 }
 
-func (p *ClientboundChatMessage) Decode(r io.Reader, v Version) (err error) {
-	//chatData, err := ReadString(r)
-	//if err != nil {
-	//	return err
-	//}
-	//
-	//var chatComponent component.ChatComponent
-	//
-	// TODO code it
+func (p *ClientboundDisguisedChat) Encode(w io.Writer, v Version) error {
+	if err := WriteChatComponent(w, v, p.Message); err != nil {
+		return err
+	}
 
-	return nil
+	return writeBoundChatType(w, v, p.ChatType, p.SenderName, p.TargetName)
+}
+
+func (p *ClientboundDisguisedChat) Decode(r io.Reader, v Version) (err error) {
+	if p.Message, err = ReadChatComponent(r, v); err != nil {
+		return err
+	}
+
+	p.ChatType, p.SenderName, p.TargetName, err = readBoundChatType(r, v)
+	return err
 }
 
 type ServerboundPlayerPosition struct {
 	X, Y, Z  float64
 	OnGround bool
+	// HorizontalCollision is only sent from 1.21.2.
+	HorizontalCollision bool
 }
 
-func (p *ServerboundPlayerPosition) Encode(w io.Writer, _ Version) error {
+// playerEyeHeight is the stance 1.7 sends next to the feet position.
+const playerEyeHeight = 1.62
+
+func (p *ServerboundPlayerPosition) Encode(w io.Writer, v Version) error {
 	_ = binary.Write(w, binary.BigEndian, p.X)
 	_ = binary.Write(w, binary.BigEndian, p.Y)
+	if v <= V1_7 {
+		_ = binary.Write(w, binary.BigEndian, p.Y+playerEyeHeight)
+	}
 	_ = binary.Write(w, binary.BigEndian, p.Z)
-	// TODO code it
-	return WriteBool(w, p.OnGround)
+
+	if v < V1_21_3 {
+		return WriteBool(w, p.OnGround)
+	}
+
+	var flags byte
+	if p.OnGround {
+		flags |= 0x01
+	}
+	if p.HorizontalCollision {
+		flags |= 0x02
+	}
+	return WriteByte(w, flags)
 }
 
-func (p *ServerboundPlayerPosition) Decode(_ io.Reader, _ Version) error {
-	return errors.New("server should not receive ServerboundPlayerPosition")
+func (p *ServerboundPlayerPosition) Decode(r io.Reader, v Version) error {
+	if err := binary.Read(r, binary.BigEndian, &p.X); err != nil {
+		return err
+	}
+
+	if err := binary.Read(r, binary.BigEndian, &p.Y); err != nil {
+		return err
+	}
+
+	if v <= V1_7 {
+		var stance float64
+		if err := binary.Read(r, binary.BigEndian, &stance); err != nil {
+			return err
+		}
+	}
+
+	if err := binary.Read(r, binary.BigEndian, &p.Z); err != nil {
+		return err
+	}
+
+	if v < V1_21_3 {
+		var err error
+		p.OnGround, err = ReadBool(r)
+		return err
+	}
+
+	flags, err := ReadByte(r)
+	p.OnGround = flags&0x01 != 0
+	p.HorizontalCollision = flags&0x02 != 0
+	return err
 }
@@ -0,0 +1,142 @@
+package protocol
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/obeliskdev/gophermc/component"
+	"github.com/obeliskdev/gophermc/nbt"
+)
+
+// packetSamples holds packets with non-zero fields. Packets missing here are
+// round tripped with their zero value.
+func packetSamples() map[string]Packet {
+	id := uuid.MustParse("0f5a4e38-6d3b-4c5e-9a1e-3c2b1a0d9e8f")
+	reason := component.Text("kicked").Styled(component.Style{Color: component.Red})
+	target := component.Text("Alex")
+	stamp := time.UnixMilli(1700000000000)
+
+	return map[string]Packet{
+		"ServerboundHandshake":       &ServerboundHandshake{ProtocolVersion: 767, ServerAddress: "example.com", ServerPort: 25565, NextState: StateLogin},
+		"ClientboundLoginDisconnect": &ClientboundLoginDisconnect{Reason: reason},
+		"ClientboundDisconnect":      &ClientboundDisconnect{Reason: reason},
+		"ClientboundLoginSuccess": &ClientboundLoginSuccess{
+			Username:   "Steve",
+			UUID:       id,
+			Properties: []Property{{Name: "textures", Value: "e30=", Signature: "c2ln"}, {Name: "plain", Value: "x"}},
+		},
+		"ClientboundLoginPluginRequest":  &ClientboundLoginPluginRequest{MessageID: 7, Channel: "velocity:player_info", Data: []byte{4}},
+		"ServerboundLoginPluginResponse": &ServerboundLoginPluginResponse{MessageID: 7, Successful: true, Data: []byte("payload")},
+		"ClientboundKeepAlive":           &ClientboundKeepAlive{ID: 1234},
+		"ServerboundKeepAlive":           &ServerboundKeepAlive{ID: 1234},
+		"ServerboundClientSettings": &ServerboundClientSettings{ClientSettings: ClientSettings{
+			Locale: "en_us", View: 10, ChatMode: 1, ChatColors: true, SkinParts: 0x7F, MainHand: 1,
+			TextFiltering: true, HideFromServerListing: true, ParticleStatus: 2,
+		}},
+		"ClientboundFeatureFlags": &ClientboundFeatureFlags{Features: []string{"minecraft:vanilla"}},
+		"ClientboundUpdateTags": &ClientboundUpdateTags{Tags: []RegistryTag{
+			{Registry: "minecraft:block", Tags: []Tag{{Name: "minecraft:logs", Entries: []int32{1, 2, 300}}}},
+		}},
+		"ClientboundChatMessage": &ClientboundChatMessage{Component: component.Text("hi"), Position: ChatPositionSystem, Sender: id},
+		"ClientboundPlayerChat": &ClientboundPlayerChat{
+			GlobalIndex: 3,
+			Sender:      id,
+			Index:       4,
+			Message:     "hello",
+			Timestamp:   stamp,
+			Salt:        99,
+			FilterType:  2,
+			ChatType:    1,
+			SenderName:  component.Text("Steve"),
+			TargetName:  &target,
+		},
+		"ClientboundSystemChat":    &ClientboundSystemChat{Component: component.Text("note"), Overlay: true},
+		"ClientboundDisguisedChat": &ClientboundDisguisedChat{Message: component.Text("psst"), ChatType: 2, SenderName: component.Text("Server")},
+		"ServerboundChatMessage":   &ServerboundChatMessage{Message: "hi", Timestamp: stamp, Salt: 7},
+		"ClientboundJoinGame": &ClientboundJoinGame{
+			EntityID:         42,
+			GameMode:         1,
+			PreviousGameMode: -1,
+			DimensionNames:   []string{"minecraft:overworld", "minecraft:the_nether"},
+			DimensionCodec:   nbt.Compound{"minecraft:dimension_type": nbt.Compound{"type": nbt.String("minecraft:dimension_type")}},
+			DimensionName:    "minecraft:overworld",
+			HashedSeed:       -5,
+			MaxPlayers:       20,
+			LevelType:        "default",
+			ViewDistance:     10,
+			DeathLocation:    &DeathLocation{Dimension: "minecraft:overworld", Position: BlockPosition{X: -100, Y: -60, Z: 2000}},
+			SeaLevel:         63,
+		},
+	}
+}
+
+func TestPacketRoundTrip(t *testing.T) {
+	samples := packetSamples()
+	for name := range samples {
+		if _, ok := packetConstructors[name]; !ok {
+			t.Errorf("sample %s has no constructor", name)
+		}
+	}
+
+	for name, factory := range packetConstructors {
+		packet, ok := samples[name]
+		if !ok {
+			packet = factory()
+		}
+
+		for _, v := range Versions {
+			var first bytes.Buffer
+			if err := packet.Encode(&first, v); err != nil {
+				t.Errorf("%s %s: encode: %v", name, v, err)
+				continue
+			}
+
+			decoded := factory()
+			r := bytes.NewReader(first.Bytes())
+			if err := decoded.Decode(r, v); err != nil {
+				t.Errorf("%s %s: decode: %v", name, v, err)
+				continue
+			}
+			if r.Len() != 0 {
+				t.Errorf("%s %s: %d of %d bytes left after decode", name, v, r.Len(), first.Len())
+				continue
+			}
+
+			var second bytes.Buffer
+			if err := decoded.Encode(&second, v); err != nil {
+				t.Errorf("%s %s: re-encode: %v", name, v, err)
+				continue
+			}
+			if !bytes.Equal(first.Bytes(), second.Bytes()) {
+				t.Errorf("%s %s: re-encoded bytes differ\n%x\n%x", name, v, first.Bytes(), second.Bytes())
+			}
+		}
+	}
+}
+
+func TestWritePosition(t *testing.T) {
+	pos := BlockPosition{X: -33554432, Y: -2048, Z: 33554431}
+	for _, v := range []Version{V1_13_2, V1_14} {
+		var buf bytes.Buffer
+		if err := WritePosition(&buf, v, pos); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := ReadPosition(&buf, v); err != nil || got != pos {
+			t.Errorf("%s: expected %+v, got %+v (%v)", v, pos, got, err)
+		}
+	}
+}
+
+func TestVarLong(t *testing.T) {
+	for _, value := range []int64{0, 1, 300, -1, 1 << 62, -1 << 63} {
+		var buf bytes.Buffer
+		if err := WriteVarLong(&buf, value); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := ReadVarLong(&buf); err != nil || got != value {
+			t.Errorf("expected %d, got %d (%v)", value, got, err)
+		}
+	}
+}
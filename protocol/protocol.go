@@ -9,6 +9,7 @@ import (
 
 const (
 	MaxVarIntSize     = 5
+	MaxVarLongSize    = 10
 	MaxPacketDataSize = 2097152
 )
 
@@ -18,6 +19,11 @@ func ReadUUID(r io.Reader) (uuid.UUID, error) {
 	return b, err
 }
 
+func WriteUUID(w io.Writer, id uuid.UUID) error {
+	_, err := w.Write(id[:])
+	return err
+}
+
 func ReadStringUUID(r io.Reader) (uuid.UUID, error) {
 	var s string
 	var err error
@@ -60,6 +66,34 @@ func ReadVarInt(r io.Reader) (int32, error) {
 	}
 	return 0, fmt.Errorf("varint is too big")
 }
+func WriteVarLong(w io.Writer, value int64) error {
+	uv := uint64(value)
+	for {
+		if (uv & ^uint64(0x7F)) == 0 {
+			return WriteByte(w, byte(uv))
+		}
+		if err := WriteByte(w, byte(uv&0x7F|0x80)); err != nil {
+			return err
+		}
+		uv >>= 7
+	}
+}
+func ReadVarLong(r io.Reader) (int64, error) {
+	var val uint64
+	var pos uint
+	for i := 0; i < MaxVarLongSize; i++ {
+		b, err := ReadByte(r)
+		if err != nil {
+			return 0, err
+		}
+		val |= uint64(b&0x7F) << pos
+		if (b & 0x80) == 0 {
+			return int64(val), nil
+		}
+		pos += 7
+	}
+	return 0, fmt.Errorf("varlong is too big")
+}
 func WriteString(w io.Writer, value string) error {
 	return WriteByteSlice(w, []byte(value))
 }
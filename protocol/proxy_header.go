@@ -0,0 +1,359 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// ProxyPolicy decides whether a ServerConn expects a PROXY protocol header
+// from a load balancer in front of it.
+type ProxyPolicy int
+
+const (
+	// ProxyHeaderNone reads no header. Anyone who can connect directly could
+	// otherwise claim any address.
+	ProxyHeaderNone ProxyPolicy = iota
+	// ProxyHeaderOptional reads a header when the connection starts with
+	// one, for servers reachable both directly and through the proxy.
+	ProxyHeaderOptional
+	// ProxyHeaderRequired rejects connections without a header.
+	ProxyHeaderRequired
+)
+
+// TLV types defined by the PROXY protocol v2 specification.
+const (
+	ProxyTLVALPN      = 0x01
+	ProxyTLVAuthority = 0x02
+	ProxyTLVCRC32C    = 0x03
+	ProxyTLVNoop      = 0x04
+	ProxyTLVUniqueID  = 0x05
+	ProxyTLVSSL       = 0x20
+	ProxyTLVNetNS     = 0x30
+)
+
+const (
+	proxyV1MaxLength = 107
+
+	proxyV2VersionCommand = 0x20
+	proxyV2CommandLocal   = 0x00
+	proxyV2CommandProxy   = 0x01
+
+	proxyV2FamilyUnspec = 0x00
+	proxyV2FamilyInet   = 0x10
+	proxyV2FamilyInet6  = 0x20
+	proxyV2FamilyUnix   = 0x30
+
+	proxyV2TransportStream = 0x01
+	proxyV2TransportDgram  = 0x02
+)
+
+var (
+	proxyV1Prefix    = []byte("PROXY ")
+	proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+)
+
+var ErrInvalidProxyHeader = errors.New("invalid PROXY protocol header")
+
+// ProxyHeader is the PROXY protocol header a load balancer sends ahead of
+// the connection it forwards.
+type ProxyHeader struct {
+	// Version is 1 for the text format and 2 for the binary one.
+	Version int
+	// Local marks connections the proxy made itself, such as health checks.
+	// Source and Destination are nil then.
+	Local bool
+	// Source is the original client address, Destination the address it
+	// connected to. They are *net.TCPAddr, or *net.UDPAddr in version 2.
+	Source      net.Addr
+	Destination net.Addr
+	// TLVs are the version 2 extensions.
+	TLVs []ProxyTLV
+}
+
+type ProxyTLV struct {
+	Type  byte
+	Value []byte
+}
+
+// TLV returns the value of the first TLV of type t.
+func (h *ProxyHeader) TLV(t byte) ([]byte, bool) {
+	for _, tlv := range h.TLVs {
+		if tlv.Type == t {
+			return tlv.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Write writes the header. Version 1 cannot carry UDP addresses or TLVs and
+// announces such connections as UNKNOWN.
+func (h *ProxyHeader) Write(w io.Writer) error {
+	var data []byte
+	var err error
+
+	switch h.Version {
+	case 1:
+		data = h.appendV1(nil)
+	case 2:
+		data, err = h.appendV2(nil)
+	default:
+		err = fmt.Errorf("unknown PROXY protocol version %d", h.Version)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+func (h *ProxyHeader) appendV1(data []byte) []byte {
+	data = append(data, proxyV1Prefix...)
+
+	src, srcOK := h.Source.(*net.TCPAddr)
+	dst, dstOK := h.Destination.(*net.TCPAddr)
+	if h.Local || !srcOK || !dstOK || (src.IP.To4() == nil) != (dst.IP.To4() == nil) {
+		return append(data, "UNKNOWN\r\n"...)
+	}
+
+	family := "TCP6"
+	srcIP, dstIP := src.IP.String(), dst.IP.String()
+	if src.IP.To4() != nil {
+		family = "TCP4"
+		srcIP, dstIP = src.IP.To4().String(), dst.IP.To4().String()
+	}
+
+	return fmt.Appendf(data, "%s %s %s %d %d\r\n", family, srcIP, dstIP, src.Port, dst.Port)
+}
+
+func (h *ProxyHeader) appendV2(data []byte) ([]byte, error) {
+	data = append(data, proxyV2Signature...)
+
+	command := byte(proxyV2CommandProxy)
+	if h.Local {
+		command = proxyV2CommandLocal
+	}
+	data = append(data, proxyV2VersionCommand|command)
+
+	var addresses []byte
+	family := byte(proxyV2FamilyUnspec)
+	if !h.Local {
+		srcIP, srcPort, srcTransport, err := proxyAddrParts(h.Source)
+		if err != nil {
+			return nil, err
+		}
+		dstIP, dstPort, dstTransport, err := proxyAddrParts(h.Destination)
+		if err != nil {
+			return nil, err
+		}
+		if srcTransport != dstTransport {
+			return nil, errors.New("PROXY header addresses use different transports")
+		}
+
+		if src4, dst4 := srcIP.To4(), dstIP.To4(); src4 != nil && dst4 != nil {
+			family = proxyV2FamilyInet | srcTransport
+			addresses = append(append(addresses, src4...), dst4...)
+		} else {
+			family = proxyV2FamilyInet6 | srcTransport
+			addresses = append(append(addresses, srcIP.To16()...), dstIP.To16()...)
+		}
+		addresses = binary.BigEndian.AppendUint16(addresses, uint16(srcPort))
+		addresses = binary.BigEndian.AppendUint16(addresses, uint16(dstPort))
+	}
+	data = append(data, family)
+
+	for _, tlv := range h.TLVs {
+		if len(tlv.Value) > 0xFFFF {
+			return nil, fmt.Errorf("PROXY header TLV 0x%02X too long", tlv.Type)
+		}
+		addresses = append(addresses, tlv.Type)
+		addresses = binary.BigEndian.AppendUint16(addresses, uint16(len(tlv.Value)))
+		addresses = append(addresses, tlv.Value...)
+	}
+	if len(addresses) > 0xFFFF {
+		return nil, errors.New("PROXY header too long")
+	}
+
+	data = binary.BigEndian.AppendUint16(data, uint16(len(addresses)))
+	return append(data, addresses...), nil
+}
+
+func proxyAddrParts(addr net.Addr) (net.IP, int, byte, error) {
+	switch a := addr.(type) {
+	case *net.TCPAddr:
+		return a.IP, a.Port, proxyV2TransportStream, nil
+	case *net.UDPAddr:
+		return a.IP, a.Port, proxyV2TransportDgram, nil
+	default:
+		return nil, 0, 0, fmt.Errorf("unsupported PROXY header address %v", addr)
+	}
+}
+
+// isProxyHeader reports whether head, the first 12 bytes of a connection,
+// starts a PROXY header of either version.
+func isProxyHeader(head []byte) bool {
+	return bytes.Equal(head, proxyV2Signature) || bytes.HasPrefix(head, proxyV1Prefix)
+}
+
+// ReadProxyHeader reads a header of either version without reading past
+// its end.
+func ReadProxyHeader(r io.Reader) (*ProxyHeader, error) {
+	head := make([]byte, len(proxyV2Signature))
+	if _, err := io.ReadFull(r, head); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case bytes.Equal(head, proxyV2Signature):
+		return readProxyHeaderV2(r)
+	case bytes.HasPrefix(head, proxyV1Prefix):
+		return readProxyHeaderV1(head, r)
+	default:
+		return nil, fmt.Errorf("%w: missing signature", ErrInvalidProxyHeader)
+	}
+}
+
+func readProxyHeaderV1(head []byte, r io.Reader) (*ProxyHeader, error) {
+	line := head
+	b := make([]byte, 1)
+	for !bytes.HasSuffix(line, []byte("\r\n")) {
+		if len(line) >= proxyV1MaxLength {
+			return nil, fmt.Errorf("%w: line too long", ErrInvalidProxyHeader)
+		}
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil, err
+		}
+		line = append(line, b[0])
+	}
+
+	fields := strings.Split(string(line[len(proxyV1Prefix):len(line)-2]), " ")
+	header := &ProxyHeader{Version: 1}
+
+	switch fields[0] {
+	case "UNKNOWN":
+		header.Local = true
+		return header, nil
+	case "TCP4", "TCP6":
+	default:
+		return nil, fmt.Errorf("%w: unknown protocol %q", ErrInvalidProxyHeader, fields[0])
+	}
+
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: %d fields", ErrInvalidProxyHeader, len(fields))
+	}
+
+	src, err := parseProxyV1Addr(fields[0], fields[1], fields[3])
+	if err != nil {
+		return nil, err
+	}
+	dst, err := parseProxyV1Addr(fields[0], fields[2], fields[4])
+	if err != nil {
+		return nil, err
+	}
+
+	header.Source, header.Destination = src, dst
+	return header, nil
+}
+
+func parseProxyV1Addr(family, ipStr, portStr string) (*net.TCPAddr, error) {
+	ip := net.ParseIP(ipStr)
+	if ip == nil || (family == "TCP4") != (ip.To4() != nil && !strings.Contains(ipStr, ":")) {
+		return nil, fmt.Errorf("%w: bad %s address %q", ErrInvalidProxyHeader, family, ipStr)
+	}
+
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("%w: bad port %q", ErrInvalidProxyHeader, portStr)
+	}
+
+	if family == "TCP4" {
+		ip = ip.To4()
+	}
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
+
+func readProxyHeaderV2(r io.Reader) (*ProxyHeader, error) {
+	fixed := make([]byte, 4)
+	if _, err := io.ReadFull(r, fixed); err != nil {
+		return nil, err
+	}
+
+	if fixed[0]&0xF0 != proxyV2VersionCommand {
+		return nil, fmt.Errorf("%w: version %d", ErrInvalidProxyHeader, fixed[0]>>4)
+	}
+
+	data := make([]byte, binary.BigEndian.Uint16(fixed[2:]))
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+
+	header := &ProxyHeader{Version: 2}
+	switch fixed[0] & 0x0F {
+	case proxyV2CommandLocal:
+		// The addresses of local connections are ignored, TLVs are not.
+		header.Local = true
+	case proxyV2CommandProxy:
+	default:
+		return nil, fmt.Errorf("%w: command %d", ErrInvalidProxyHeader, fixed[0]&0x0F)
+	}
+
+	var length int
+	switch fixed[1] & 0xF0 {
+	case proxyV2FamilyInet:
+		length = 2*net.IPv4len + 4
+	case proxyV2FamilyInet6:
+		length = 2*net.IPv6len + 4
+	case proxyV2FamilyUnix:
+		length = 2 * 108
+	case proxyV2FamilyUnspec:
+	default:
+		return nil, fmt.Errorf("%w: address family %d", ErrInvalidProxyHeader, fixed[1]>>4)
+	}
+	if len(data) < length {
+		return nil, fmt.Errorf("%w: addresses truncated", ErrInvalidProxyHeader)
+	}
+
+	addresses, tlvs := data[:length], data[length:]
+
+	// Unix sockets and unspecified families keep the connection's addresses.
+	if family := fixed[1] & 0xF0; !header.Local && (family == proxyV2FamilyInet || family == proxyV2FamilyInet6) {
+		ipLength := (length - 4) / 2
+		srcIP := net.IP(bytes.Clone(addresses[:ipLength]))
+		dstIP := net.IP(bytes.Clone(addresses[ipLength : 2*ipLength]))
+		srcPort := int(binary.BigEndian.Uint16(addresses[2*ipLength:]))
+		dstPort := int(binary.BigEndian.Uint16(addresses[2*ipLength+2:]))
+
+		switch fixed[1] & 0x0F {
+		case proxyV2TransportStream:
+			header.Source = &net.TCPAddr{IP: srcIP, Port: srcPort}
+			header.Destination = &net.TCPAddr{IP: dstIP, Port: dstPort}
+		case proxyV2TransportDgram:
+			header.Source = &net.UDPAddr{IP: srcIP, Port: srcPort}
+			header.Destination = &net.UDPAddr{IP: dstIP, Port: dstPort}
+		default:
+			return nil, fmt.Errorf("%w: transport %d", ErrInvalidProxyHeader, fixed[1]&0x0F)
+		}
+	}
+
+	for len(tlvs) > 0 {
+		if len(tlvs) < 3 {
+			return nil, fmt.Errorf("%w: TLV truncated", ErrInvalidProxyHeader)
+		}
+
+		valueLength := int(binary.BigEndian.Uint16(tlvs[1:]))
+		if len(tlvs) < 3+valueLength {
+			return nil, fmt.Errorf("%w: TLV 0x%02X truncated", ErrInvalidProxyHeader, tlvs[0])
+		}
+
+		header.TLVs = append(header.TLVs, ProxyTLV{Type: tlvs[0], Value: bytes.Clone(tlvs[3 : 3+valueLength])})
+		tlvs = tlvs[3+valueLength:]
+	}
+
+	return header, nil
+}
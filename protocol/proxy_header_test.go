@@ -0,0 +1,154 @@
+package protocol
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestProxyHeaderRoundTrip(t *testing.T) {
+	tcp4 := [2]net.Addr{&net.TCPAddr{IP: net.IPv4(203, 0, 113, 7).To4(), Port: 4242}, &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1).To4(), Port: 25565}}
+	tcp6 := [2]net.Addr{&net.TCPAddr{IP: net.ParseIP("2001:db8::7"), Port: 4242}, &net.TCPAddr{IP: net.ParseIP("2001:db8::1"), Port: 25565}}
+	udp4 := [2]net.Addr{&net.UDPAddr{IP: net.IPv4(203, 0, 113, 7).To4(), Port: 4242}, &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1).To4(), Port: 19132}}
+
+	for _, header := range []ProxyHeader{
+		{Version: 1, Source: tcp4[0], Destination: tcp4[1]},
+		{Version: 1, Source: tcp6[0], Destination: tcp6[1]},
+		{Version: 1, Local: true},
+		{Version: 2, Source: tcp4[0], Destination: tcp4[1]},
+		{Version: 2, Source: tcp6[0], Destination: tcp6[1], TLVs: []ProxyTLV{{Type: ProxyTLVAuthority, Value: []byte("mc.example.com")}, {Type: ProxyTLVUniqueID, Value: []byte{1, 2, 3}}}},
+		{Version: 2, Source: udp4[0], Destination: udp4[1]},
+		{Version: 2, Local: true, TLVs: []ProxyTLV{{Type: ProxyTLVNoop, Value: []byte{}}}},
+	} {
+		var buf bytes.Buffer
+		if err := header.Write(&buf); err != nil {
+			t.Fatal(err)
+		}
+		buf.WriteString("rest")
+
+		got, err := ReadProxyHeader(&buf)
+		if err != nil {
+			t.Fatalf("%+v: %v", header, err)
+		}
+		if !reflect.DeepEqual(*got, header) {
+			t.Errorf("expected %+v, got %+v", header, *got)
+		}
+		if buf.String() != "rest" {
+			t.Errorf("%+v: read past the header, %q left", header, buf.String())
+		}
+	}
+}
+
+func TestProxyHeaderV1Format(t *testing.T) {
+	var buf bytes.Buffer
+	header := ProxyHeader{Version: 1, Source: &net.TCPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 56324}, Destination: &net.TCPAddr{IP: net.IPv4(192, 168, 0, 11), Port: 443}}
+	_ = header.Write(&buf)
+	if buf.String() != "PROXY TCP4 192.168.0.1 192.168.0.11 56324 443\r\n" {
+		t.Fatalf("unexpected header %q", buf.String())
+	}
+
+	// A UDP source cannot be expressed in version 1.
+	buf.Reset()
+	header.Source = &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1}
+	_ = header.Write(&buf)
+	if buf.String() != "PROXY UNKNOWN\r\n" {
+		t.Fatalf("unexpected header %q", buf.String())
+	}
+
+	for _, invalid := range []string{
+		"PROXY TCP4 192.168.0.1 192.168.0.11 56324\r\n",
+		"PROXY TCP4 2001:db8::1 192.168.0.11 56324 443\r\n",
+		"PROXY TCP6 192.168.0.1 192.168.0.11 56324 443\r\n",
+		"PROXY TCP4 192.168.0.1 192.168.0.11 56324 70000\r\n",
+		"PROXY UDP4 192.168.0.1 192.168.0.11 56324 443\r\n",
+		"PROXY TCP4 " + string(bytes.Repeat([]byte("1"), 120)) + "\r\n",
+		"\r\n\r\n\x00\r\nQUIT\n\x21\x11\x00\x04\x00\x00\x00\x00",
+		"\r\n\r\n\x00\r\nQUIT\n\x31\x11\x00\x00",
+		"GET / HTTP/1.1\r\n",
+	} {
+		if _, err := ReadProxyHeader(bytes.NewReader([]byte(invalid))); !errors.Is(err, ErrInvalidProxyHeader) {
+			t.Errorf("%q: expected ErrInvalidProxyHeader, got %v", invalid, err)
+		}
+	}
+}
+
+// readProxied feeds data to a ServerConn with policy in a single write.
+func readProxied(t *testing.T, policy ProxyPolicy, data []byte) (*ServerConn, error) {
+	t.Helper()
+
+	server, client := net.Pipe()
+	t.Cleanup(func() {
+		_ = server.Close()
+		_ = client.Close()
+	})
+
+	go func() { _, _ = client.Write(data) }()
+
+	conn := NewServerConn(server)
+	conn.ProxyPolicy = policy
+	_, err := conn.ReadHandshake()
+	return conn, err
+}
+
+// handshakeFrame returns a status handshake whose frame starts with first,
+// the VarInt length of the packet.
+func handshakeFrame(t *testing.T, first byte) ([]byte, *ServerboundHandshake) {
+	t.Helper()
+
+	handshake := &ServerboundHandshake{ProtocolVersion: Latest.Protocol(), ServerPort: 25565, NextState: StateStatus}
+	for {
+		var frame bytes.Buffer
+		conn := NewConn(nil, Latest)
+		conn.writer = &frame
+		if err := conn.WritePacket(handshake); err != nil {
+			t.Fatal(err)
+		}
+		if frame.Bytes()[0] == first {
+			return frame.Bytes(), handshake
+		}
+		handshake.ServerAddress += "a"
+	}
+}
+
+func TestServerConnProxyHeader(t *testing.T) {
+	header := ProxyHeader{Version: 2, Source: &net.TCPAddr{IP: net.IPv4(203, 0, 113, 7).To4(), Port: 4242}, Destination: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1).To4(), Port: 25565}}
+	var prefix bytes.Buffer
+	_ = header.Write(&prefix)
+
+	frame, handshake := handshakeFrame(t, 'P')
+
+	for _, policy := range []ProxyPolicy{ProxyHeaderOptional, ProxyHeaderRequired} {
+		conn, err := readProxied(t, policy, append(bytes.Clone(prefix.Bytes()), frame...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if conn.Handshake.ServerAddress != handshake.ServerAddress || conn.RemoteAddr().String() != "203.0.113.7:4242" {
+			t.Errorf("unexpected connection from %s: %+v", conn.RemoteAddr(), conn.Handshake)
+		}
+	}
+
+	// Handshakes of 80 and 13 bytes start like a PROXY header.
+	for _, first := range []byte{'P', '\r'} {
+		frame, handshake := handshakeFrame(t, first)
+		conn, err := readProxied(t, ProxyHeaderOptional, frame)
+		if err != nil || conn.ProxyHeader != nil || conn.Handshake.ServerAddress != handshake.ServerAddress {
+			t.Fatalf("expected a plain handshake, got %v", err)
+		}
+		if _, err := readProxied(t, ProxyHeaderRequired, frame); !errors.Is(err, ErrInvalidProxyHeader) {
+			t.Fatalf("expected a missing header to fail, got %v", err)
+		}
+	}
+
+	// Without a policy the header is not read.
+	if _, err := readProxied(t, ProxyHeaderNone, append(bytes.Clone(prefix.Bytes()), frame...)); err == nil {
+		t.Fatal("expected the header to break the handshake")
+	}
+
+	var ping bytes.Buffer
+	_ = (&LegacyPing{Variant: LegacyPing16, Protocol: LegacyProtocol16, Hostname: "mc.example.com", Port: 25565}).Write(&ping)
+	if _, err := readProxied(t, ProxyHeaderOptional, ping.Bytes()); !errors.Is(err, ErrLegacyPing) {
+		t.Fatalf("expected a legacy ping, got %v", err)
+	}
+}
@@ -0,0 +1,239 @@
+package protocol
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/obeliskdev/gophermc/component"
+)
+
+var ErrUnsupportedVersion = errors.New("unsupported protocol version")
+
+// handshakeIntentTransfer is the next state sent by clients that were moved
+// here with a transfer packet. They continue with a regular login.
+const handshakeIntentTransfer = 3
+
+// Listener accepts Minecraft connections. Every accepted connection reads
+// serverbound and writes clientbound packets.
+type Listener struct {
+	net.Listener
+
+	// ProxyPolicy is passed on to accepted connections.
+	ProxyPolicy ProxyPolicy
+}
+
+func Listen(network, address string) (*Listener, error) {
+	l, err := net.Listen(network, address)
+	if err != nil {
+		return nil, err
+	}
+	return NewListener(l), nil
+}
+
+func NewListener(l net.Listener) *Listener {
+	return &Listener{Listener: l}
+}
+
+func (l *Listener) Accept() (*ServerConn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	c := NewServerConn(conn)
+	c.ProxyPolicy = l.ProxyPolicy
+	return c, nil
+}
+
+// ServerConn is the server side of a connection. Call ReadHandshake first;
+// it switches the connection to the client's version and requested state.
+type ServerConn struct {
+	*Conn
+
+	Handshake *ServerboundHandshake
+	// Transfer reports whether the client arrived through a transfer.
+	Transfer bool
+	// LegacyPing is set when ReadHandshake returned ErrLegacyPing.
+	LegacyPing *LegacyPing
+
+	ProxyPolicy ProxyPolicy
+	// ProxyHeader is the PROXY protocol header read by ReadHandshake.
+	ProxyHeader *ProxyHeader
+}
+
+func NewServerConn(conn net.Conn) *ServerConn {
+	c := NewConn(conn, Latest)
+	c.inbound = DirectionServerbound
+	c.outbound = DirectionClientbound
+	return &ServerConn{Conn: c}
+}
+
+// ReadHandshake reads the handshake and moves to the status or login state.
+// Status requests from unknown versions are answered with Latest, logins from
+// unknown versions fail with ErrUnsupportedVersion after the state and
+// Handshake have been set, so the caller can still send a disconnect.
+// Pre-1.7 server list pings fail with ErrLegacyPing and should be answered
+// with WriteLegacyStatus. A PROXY protocol header is read first when
+// ProxyPolicy asks for one.
+func (c *ServerConn) ReadHandshake() (*ServerboundHandshake, error) {
+	if c.State() != StateHandshaking {
+		return nil, fmt.Errorf("handshake in state %s", c.State())
+	}
+
+	if err := c.readProxyHeader(); err != nil {
+		return nil, err
+	}
+
+	if err := c.detectLegacyPing(); err != nil {
+		return nil, err
+	}
+
+	packet, err := c.ReadPacket()
+	if err != nil {
+		return nil, fmt.Errorf("read handshake: %w", err)
+	}
+
+	handshake, ok := packet.(*ServerboundHandshake)
+	if !ok {
+		return nil, fmt.Errorf("expected handshake, got %T", packet)
+	}
+	c.Handshake = handshake
+
+	version, known := VersionFromProtocol(handshake.ProtocolVersion)
+	if !known {
+		version = Latest
+	}
+	c.SetVersion(version)
+
+	switch handshake.NextState {
+	case StateStatus:
+		c.SetState(StateStatus)
+		return handshake, nil
+	case StateLogin, handshakeIntentTransfer:
+		c.Transfer = handshake.NextState == handshakeIntentTransfer
+		c.SetState(StateLogin)
+	default:
+		return handshake, fmt.Errorf("invalid handshake next state %d", handshake.NextState)
+	}
+
+	if !known {
+		return handshake, fmt.Errorf("%w: %d", ErrUnsupportedVersion, handshake.ProtocolVersion)
+	}
+
+	return handshake, nil
+}
+
+func (c *ServerConn) readProxyHeader() error {
+	switch c.ProxyPolicy {
+	case ProxyHeaderNone:
+		return nil
+	case ProxyHeaderOptional:
+		// Neither byte starts a legacy ping, and a handshake whose length
+		// starts with one is longer than the signature, so peeking the whole
+		// signature cannot block.
+		first, err := c.Peek(1)
+		if err != nil {
+			return fmt.Errorf("read handshake: %w", err)
+		}
+		if first[0] != proxyV1Prefix[0] && first[0] != proxyV2Signature[0] {
+			return nil
+		}
+
+		head, err := c.Peek(len(proxyV2Signature))
+		if err != nil {
+			return fmt.Errorf("read handshake: %w", err)
+		}
+		if !isProxyHeader(head) {
+			return nil
+		}
+	}
+
+	header, err := ReadProxyHeader(c.reader)
+	if err != nil {
+		return fmt.Errorf("read PROXY header: %w", err)
+	}
+	c.ProxyHeader = header
+	return nil
+}
+
+// RemoteAddr returns the client address from the PROXY header, if any.
+func (c *ServerConn) RemoteAddr() net.Addr {
+	if c.ProxyHeader != nil && c.ProxyHeader.Source != nil {
+		return c.ProxyHeader.Source
+	}
+	return c.Conn.RemoteAddr()
+}
+
+// detectLegacyPing looks at the first read from the client, which is all a
+// legacy client sends before waiting for the answer.
+func (c *ServerConn) detectLegacyPing() error {
+	if _, err := c.buffered.Peek(1); err != nil {
+		return fmt.Errorf("read handshake: %w", err)
+	}
+	head, _ := c.buffered.Peek(c.buffered.Buffered())
+	if head[0] != legacyPingID {
+		return nil
+	}
+
+	ping, err := readLegacyPing(bytes.Clone(head), c.buffered)
+	if err != nil {
+		return fmt.Errorf("read legacy ping: %w", err)
+	}
+	if ping == nil {
+		return nil
+	}
+
+	_, _ = c.buffered.Discard(c.buffered.Buffered())
+	c.LegacyPing = ping
+	c.SetState(StateStatus)
+	return ErrLegacyPing
+}
+
+// WriteLegacyStatus answers a legacy ping in the format its variant expects.
+func (c *ServerConn) WriteLegacyStatus(status LegacyStatus) error {
+	if c.LegacyPing == nil {
+		return errors.New("no legacy ping to answer")
+	}
+
+	return WriteLegacyKick(c.Conn, status.String(c.LegacyPing.Variant))
+}
+
+// Disconnect sends reason with the disconnect packet of the current state
+// and closes the connection.
+func (c *ServerConn) Disconnect(reason component.ChatComponent) error {
+	var packet Packet
+	switch c.State() {
+	case StateLogin:
+		packet = &ClientboundLoginDisconnect{Reason: reason}
+	case StateConfiguration, StatePlay:
+		packet = &ClientboundDisconnect{Reason: reason}
+	}
+
+	var err error
+	if packet != nil {
+		err = c.WritePacket(packet)
+	}
+
+	if closeErr := c.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// VersionFromProtocol returns the newest version speaking the protocol
+// number p.
+func VersionFromProtocol(p int32) (Version, bool) {
+	found := false
+	var best Version
+	for _, v := range Versions {
+		def := GetDefinition(v)
+		if def == nil || def.ProtocolVersion != p {
+			continue
+		}
+		if !found || v > best {
+			best, found = v, true
+		}
+	}
+	return best, found
+}
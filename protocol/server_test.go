@@ -0,0 +1,239 @@
+package protocol
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/obeliskdev/gophermc/component"
+)
+
+func TestVersionFromProtocol(t *testing.T) {
+	for _, v := range Versions {
+		got, ok := VersionFromProtocol(v.Protocol())
+		if !ok || got.Protocol() != v.Protocol() || got < v {
+			t.Errorf("%s: got %s, %v", v, got, ok)
+		}
+	}
+
+	if _, ok := VersionFromProtocol(-1); ok {
+		t.Error("expected unknown protocol to fail")
+	}
+}
+
+func dialListener(t *testing.T, l *Listener, v Version) *Conn {
+	t.Helper()
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return NewConn(conn, v)
+}
+
+func TestListenerLogin(t *testing.T) {
+	l, err := Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	id := uuid.New()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- func() error {
+			conn, err := l.Accept()
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			if _, err := conn.ReadHandshake(); err != nil {
+				return err
+			}
+			if conn.Version() != V1_21_4 || conn.State() != StateLogin {
+				return errors.New("handshake did not switch version and state")
+			}
+
+			packet, err := conn.ReadPacket()
+			if err != nil {
+				return err
+			}
+			start, ok := packet.(*ServerboundLoginStart)
+			if !ok {
+				return errors.New("expected login start")
+			}
+
+			if err := conn.WritePacket(&ClientboundSetCompression{Threshold: 16}); err != nil {
+				return err
+			}
+			conn.SetCompression(16)
+
+			if err := conn.WritePacket(&ClientboundLoginSuccess{Username: start.Username, UUID: start.UUID}); err != nil {
+				return err
+			}
+
+			if packet, err = conn.ReadPacket(); err != nil {
+				return err
+			}
+			if _, ok := packet.(*ServerboundLoginAcknowledged); !ok {
+				return errors.New("expected login acknowledged")
+			}
+			conn.SetState(StateConfiguration)
+
+			if err := conn.WritePacket(&ClientboundConfigKeepAlive{ID: 77}); err != nil {
+				return err
+			}
+			if packet, err = conn.ReadPacket(); err != nil {
+				return err
+			}
+			if keepAlive, ok := packet.(*ServerboundConfigKeepAlive); !ok || keepAlive.ID != 77 {
+				return errors.New("expected keep alive 77")
+			}
+
+			return conn.Disconnect(component.Text("bye"))
+		}()
+	}()
+
+	client := dialListener(t, l, V1_21_4)
+	handshake := &ServerboundHandshake{ProtocolVersion: V1_21_4.Protocol(), ServerAddress: "localhost", ServerPort: 25565, NextState: StateLogin}
+	if err := client.WritePacket(handshake); err != nil {
+		t.Fatal(err)
+	}
+	client.SetState(StateLogin)
+	if err := client.WritePacket(&ServerboundLoginStart{Username: "Steve", UUID: id}); err != nil {
+		t.Fatal(err)
+	}
+
+	packet, err := client.ReadPacket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	compression, ok := packet.(*ClientboundSetCompression)
+	if !ok {
+		t.Fatalf("expected set compression, got %T", packet)
+	}
+	client.SetCompression(int(compression.Threshold))
+
+	if packet, err = client.ReadPacket(); err != nil {
+		t.Fatal(err)
+	}
+	if success, ok := packet.(*ClientboundLoginSuccess); !ok || success.Username != "Steve" || success.UUID != id {
+		t.Fatalf("unexpected login success %+v", packet)
+	}
+
+	if err := client.WritePacket(&ServerboundLoginAcknowledged{}); err != nil {
+		t.Fatal(err)
+	}
+	client.SetState(StateConfiguration)
+
+	if packet, err = client.ReadPacket(); err != nil {
+		t.Fatal(err)
+	}
+	keepAlive, ok := packet.(*ClientboundConfigKeepAlive)
+	if !ok {
+		t.Fatalf("expected keep alive, got %T", packet)
+	}
+	if err := client.WritePacket(&ServerboundConfigKeepAlive{ID: keepAlive.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	if packet, err = client.ReadPacket(); err != nil {
+		t.Fatal(err)
+	}
+	if disconnect, ok := packet.(*ClientboundDisconnect); !ok || disconnect.Reason.String() != "bye" {
+		t.Fatalf("unexpected disconnect %+v", packet)
+	}
+
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestListenerStatus(t *testing.T) {
+	l, err := Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- func() error {
+			conn, err := l.Accept()
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			// Status requests from unknown versions are answered anyway.
+			if _, err := conn.ReadHandshake(); err != nil {
+				return err
+			}
+			if conn.Version() != Latest || conn.State() != StateStatus {
+				return errors.New("expected status with the latest version")
+			}
+
+			if _, err := conn.ReadPacket(); err != nil {
+				return err
+			}
+			if err := conn.WritePacket(&ClientboundStatusResponse{JSONResponse: `{"description":"hi"}`}); err != nil {
+				return err
+			}
+
+			packet, err := conn.ReadPacket()
+			if err != nil {
+				return err
+			}
+			return conn.WritePacket(&ClientboundPong{Payload: packet.(*ServerboundPing).Payload})
+		}()
+	}()
+
+	client := dialListener(t, l, Latest)
+	if err := client.WritePacket(&ServerboundHandshake{ProtocolVersion: 99999, ServerAddress: "localhost", ServerPort: 25565, NextState: StateStatus}); err != nil {
+		t.Fatal(err)
+	}
+	client.SetState(StateStatus)
+
+	if err := client.WritePacket(&ServerboundStatusRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	packet, err := client.ReadPacket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status, ok := packet.(*ClientboundStatusResponse); !ok || status.JSONResponse != `{"description":"hi"}` {
+		t.Fatalf("unexpected status %+v", packet)
+	}
+
+	if err := client.WritePacket(&ServerboundPing{Payload: 5}); err != nil {
+		t.Fatal(err)
+	}
+	if packet, err = client.ReadPacket(); err != nil {
+		t.Fatal(err)
+	}
+	if pong, ok := packet.(*ClientboundPong); !ok || pong.Payload != 5 {
+		t.Fatalf("unexpected pong %+v", packet)
+	}
+
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestListenerUnsupportedLogin(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+
+	conn := NewServerConn(server)
+	go func() {
+		_ = NewConn(client, Latest).WritePacket(&ServerboundHandshake{ProtocolVersion: 1, NextState: StateLogin})
+	}()
+
+	_, err := conn.ReadHandshake()
+	if !errors.Is(err, ErrUnsupportedVersion) || conn.State() != StateLogin {
+		t.Fatalf("expected unsupported version in login state, got %v in %s", err, conn.State())
+	}
+	_ = conn.Close()
+}
@@ -0,0 +1,269 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/obeliskdev/gophermc/component"
+)
+
+// StatusResponse is the JSON document carried by ClientboundStatusResponse.
+type StatusResponse struct {
+	Version            StatusVersion           `json:"version"`
+	Players            StatusPlayers           `json:"players"`
+	Description        component.ChatComponent `json:"description"`
+	Favicon            string                  `json:"favicon,omitempty"`
+	EnforcesSecureChat bool                    `json:"enforcesSecureChat"`
+
+	// ForgeData is sent by Forge servers from 1.13, ModInfo by FML before.
+	ForgeData *ForgeData `json:"forgeData,omitempty"`
+	ModInfo   *ModInfo   `json:"modinfo,omitempty"`
+
+	// Raw is the document as received by ParseStatusResponse.
+	Raw string `json:"-"`
+}
+
+type StatusVersion struct {
+	Name     string `json:"name"`
+	Protocol int32  `json:"protocol"`
+}
+
+type StatusPlayers struct {
+	Max    int            `json:"max"`
+	Online int            `json:"online"`
+	Sample []StatusPlayer `json:"sample,omitempty"`
+}
+
+type StatusPlayer struct {
+	Name string    `json:"name"`
+	ID   uuid.UUID `json:"id"`
+}
+
+// UnmarshalJSON accepts malformed ids, which servers that use the sample
+// for extra text lines often send, as uuid.Nil.
+func (p *StatusPlayer) UnmarshalJSON(data []byte) error {
+	var player struct {
+		Name string `json:"name"`
+		ID   string `json:"id"`
+	}
+	if err := json.Unmarshal(data, &player); err != nil {
+		return err
+	}
+
+	p.Name = player.Name
+	p.ID, _ = uuid.Parse(player.ID)
+	return nil
+}
+
+type ForgeData struct {
+	Channels          []ForgeChannel `json:"channels"`
+	Mods              []ForgeMod     `json:"mods"`
+	FMLNetworkVersion int            `json:"fmlNetworkVersion"`
+	Truncated         bool           `json:"truncated,omitempty"`
+	// Data holds the mods and channels in the packed form FML3 uses to keep
+	// the response small. ParseStatusResponse unpacks it into Mods and
+	// Channels.
+	Data string `json:"d,omitempty"`
+}
+
+type ForgeChannel struct {
+	Name     string `json:"res"`
+	Version  string `json:"version"`
+	Required bool   `json:"required"`
+}
+
+type ForgeMod struct {
+	ID      string `json:"modId"`
+	Version string `json:"modmarker"`
+}
+
+type ModInfo struct {
+	Type string       `json:"type"`
+	Mods []ModInfoMod `json:"modList"`
+}
+
+type ModInfoMod struct {
+	ID      string `json:"modid"`
+	Version string `json:"version"`
+}
+
+// ParseStatusResponse decodes a status document. The description may be a
+// plain string or a component. Packed Forge data that cannot be unpacked is
+// left in ForgeData.Data.
+func ParseStatusResponse(data string) (*StatusResponse, error) {
+	status := &StatusResponse{Raw: data}
+	if err := json.Unmarshal([]byte(data), status); err != nil {
+		return nil, fmt.Errorf("decode status response: %w", err)
+	}
+
+	if forge := status.ForgeData; forge != nil && forge.Data != "" {
+		_ = forge.unpack()
+	}
+
+	return status, nil
+}
+
+// FaviconPNG decodes the favicon data URI. It returns nil without a favicon.
+func (s *StatusResponse) FaviconPNG() ([]byte, error) {
+	if s.Favicon == "" {
+		return nil, nil
+	}
+
+	_, encoded, ok := strings.Cut(s.Favicon, ";base64,")
+	if !ok {
+		return nil, errors.New("favicon is not a base64 data uri")
+	}
+
+	// Old servers wrap the base64 data in lines.
+	encoded = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\r' {
+			return -1
+		}
+		return r
+	}, encoded)
+
+	return base64.StdEncoding.DecodeString(encoded)
+}
+
+// ForgeMods returns the mods a Forge server lists in its status, or nil for
+// other servers.
+func (s *StatusResponse) ForgeMods() []ForgeMod {
+	switch {
+	case s.ForgeData != nil:
+		return s.ForgeData.Mods
+	case s.ModInfo != nil:
+		mods := make([]ForgeMod, 0, len(s.ModInfo.Mods))
+		for _, mod := range s.ModInfo.Mods {
+			mods = append(mods, ForgeMod{ID: mod.ID, Version: mod.Version})
+		}
+		return mods
+	}
+	return nil
+}
+
+// JSON encodes the response with the chat component layout of v.
+func (s *StatusResponse) JSON(v Version) (string, error) {
+	description, err := s.Description.JSON(eventFormat(v))
+	if err != nil {
+		return "", err
+	}
+
+	type plain StatusResponse
+	data, err := json.Marshal(struct {
+		*plain
+		Description json.RawMessage `json:"description"`
+	}{(*plain)(s), description})
+	return string(data), err
+}
+
+// FaviconDataURI returns a PNG image in the form expected by
+// StatusResponse.Favicon. The client only shows 64x64 images.
+func FaviconDataURI(png []byte) string {
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(png)
+}
+
+// unpack decodes Data. Each character carries 15 bits of a byte stream that
+// starts with its length in the first two characters.
+func (f *ForgeData) unpack() error {
+	chars := []rune(f.Data)
+	if len(chars) < 2 {
+		return errors.New("forge data too short")
+	}
+
+	size := int(chars[0]&0x7FFF) | int(chars[1]&0x7FFF)<<15
+	if size > len(chars)*2 {
+		return errors.New("forge data length exceeds its content")
+	}
+
+	data := make([]byte, 0, size)
+	var buffer, bits int
+	for _, c := range chars[2:] {
+		buffer |= int(c&0x7FFF) << bits
+		bits += 15
+		for bits >= 8 && len(data) < size {
+			data = append(data, byte(buffer))
+			buffer >>= 8
+			bits -= 8
+		}
+	}
+	if len(data) < size {
+		return errors.New("forge data truncated")
+	}
+
+	r := bytes.NewReader(data)
+	truncated, err := ReadBool(r)
+	if err != nil {
+		return err
+	}
+
+	modCount, err := ReadUShort(r)
+	if err != nil {
+		return err
+	}
+
+	var mods []ForgeMod
+	var channels []ForgeChannel
+	for i := 0; i < int(modCount); i++ {
+		flags, err := ReadVarInt(r)
+		if err != nil {
+			return err
+		}
+
+		var mod ForgeMod
+		if mod.ID, err = ReadString(r); err != nil {
+			return err
+		}
+
+		// The low bit marks mods that are only needed on the server.
+		if flags&1 == 0 {
+			if mod.Version, err = ReadString(r); err != nil {
+				return err
+			}
+		}
+
+		for j := int32(0); j < flags>>1; j++ {
+			channel, err := readForgeChannel(r)
+			if err != nil {
+				return err
+			}
+			channel.Name = mod.ID + ":" + channel.Name
+			channels = append(channels, channel)
+		}
+
+		mods = append(mods, mod)
+	}
+
+	channelCount, err := ReadVarInt(r)
+	if err != nil {
+		return err
+	}
+
+	for i := int32(0); i < channelCount; i++ {
+		channel, err := readForgeChannel(r)
+		if err != nil {
+			return err
+		}
+		channels = append(channels, channel)
+	}
+
+	f.Truncated = f.Truncated || truncated
+	f.Mods = append(f.Mods, mods...)
+	f.Channels = append(f.Channels, channels...)
+	return nil
+}
+
+func readForgeChannel(r *bytes.Reader) (channel ForgeChannel, err error) {
+	if channel.Name, err = ReadString(r); err != nil {
+		return
+	}
+	if channel.Version, err = ReadString(r); err != nil {
+		return
+	}
+	channel.Required, err = ReadBool(r)
+	return
+}
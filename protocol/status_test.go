@@ -0,0 +1,110 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestParseStatusResponse(t *testing.T) {
+	status, err := ParseStatusResponse(`{
+		"version": {"name": "Paper 1.21.4", "protocol": 769},
+		"players": {"max": 100, "online": 2, "sample": [
+			{"name": "Steve", "id": "069a79f4-44e9-4726-a5be-fca90e38aaf5"},
+			{"name": "§aVisit example.com", "id": "not-a-uuid"}
+		]},
+		"description": {"text": "A ", "extra": [{"text": "server", "color": "gold"}]},
+		"favicon": "data:image/png;base64,iVBO\nRw==",
+		"enforcesSecureChat": true
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status.Version.Name != "Paper 1.21.4" || status.Version.Protocol != 769 || status.Players.Online != 2 {
+		t.Errorf("unexpected status %+v", status)
+	}
+	if status.Players.Sample[0].ID != uuid.MustParse("069a79f4-44e9-4726-a5be-fca90e38aaf5") || status.Players.Sample[1].ID != uuid.Nil {
+		t.Errorf("unexpected sample %+v", status.Players.Sample)
+	}
+	if status.Description.String() != "A server" || !strings.Contains(status.Raw, `"not-a-uuid"`) {
+		t.Errorf("unexpected description %+v", status.Description)
+	}
+
+	png, err := status.FaviconPNG()
+	if err != nil || !bytes.Equal(png, []byte("\x89PNG")) {
+		t.Errorf("unexpected favicon %q, %v", png, err)
+	}
+
+	legacy, err := ParseStatusResponse(`{"description": "§cOld MOTD", "modinfo": {"type": "FML", "modList": [{"modid": "mcp", "version": "9.42"}]}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if legacy.Description.Text != "§cOld MOTD" || legacy.ModInfo == nil || legacy.ModInfo.Mods[0].ID != "mcp" {
+		t.Errorf("unexpected legacy status %+v", legacy)
+	}
+	if png, err := legacy.FaviconPNG(); png != nil || err != nil {
+		t.Errorf("expected no favicon, got %q, %v", png, err)
+	}
+}
+
+// packForgeData packs data 15 bits per character like Forge does for "d".
+func packForgeData(data []byte) string {
+	chars := []rune{rune(len(data) & 0x7FFF), rune(len(data) >> 15 & 0x7FFF)}
+	var buffer, bits int
+	for _, b := range data {
+		buffer |= int(b) << bits
+		bits += 8
+		for bits >= 15 {
+			chars = append(chars, rune(buffer&0x7FFF))
+			buffer >>= 15
+			bits -= 15
+		}
+	}
+	if bits > 0 {
+		chars = append(chars, rune(buffer&0x7FFF))
+	}
+	return string(chars)
+}
+
+func TestParseStatusForgeData(t *testing.T) {
+	var buf bytes.Buffer
+	_ = WriteBool(&buf, false)
+	_ = WriteUShort(&buf, 2)
+	_ = WriteVarInt(&buf, 1<<1)
+	_ = WriteString(&buf, "forge")
+	_ = WriteString(&buf, "47.2.0")
+	_ = WriteString(&buf, "tier_sorting")
+	_ = WriteString(&buf, "1.0")
+	_ = WriteBool(&buf, false)
+	_ = WriteVarInt(&buf, 1)
+	_ = WriteString(&buf, "servermod")
+	_ = WriteVarInt(&buf, 1)
+	_ = WriteString(&buf, "minecraft:unregister")
+	_ = WriteString(&buf, "FML3")
+	_ = WriteBool(&buf, true)
+
+	document, _ := json.Marshal(map[string]any{
+		"description": "",
+		"forgeData":   map[string]any{"fmlNetworkVersion": 3, "channels": []any{}, "mods": []any{}, "d": packForgeData(buf.Bytes())},
+	})
+
+	status, err := ParseStatusResponse(string(document))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	forge := status.ForgeData
+	want := []ForgeMod{{ID: "forge", Version: "47.2.0"}, {ID: "servermod"}}
+	if forge == nil || forge.FMLNetworkVersion != 3 || len(forge.Mods) != 2 || forge.Mods[0] != want[0] || forge.Mods[1] != want[1] {
+		t.Fatalf("unexpected forge data %+v", forge)
+	}
+
+	channels := []ForgeChannel{{Name: "forge:tier_sorting", Version: "1.0"}, {Name: "minecraft:unregister", Version: "FML3", Required: true}}
+	if len(forge.Channels) != 2 || forge.Channels[0] != channels[0] || forge.Channels[1] != channels[1] {
+		t.Fatalf("unexpected channels %+v", forge.Channels)
+	}
+}
@@ -4,7 +4,12 @@ package protocol
 
 import (
 	"crypto/sha1"
+	"encoding/json"
+	"fmt"
 	"github.com/google/uuid"
+	"github.com/obeliskdev/gophermc/component"
+	"github.com/obeliskdev/gophermc/nbt"
+	"io"
 	"sync"
 )
 
@@ -42,6 +47,13 @@ const (
 	DirectionClientbound Direction = false
 )
 
+func (d Direction) String() string {
+	if d == DirectionServerbound {
+		return "C -> S"
+	}
+	return "S -> C"
+}
+
 func OfflineUUID(username string) uuid.UUID {
 	hasher := sha1.New()
 	hasher.Write([]byte("OfflinePlayer:" + username))
@@ -60,6 +72,11 @@ type ClientSettings struct {
 	ChatColors bool
 	SkinParts  byte
 	MainHand   int32
+
+	TextFiltering bool
+	// HideFromServerListing is sent inverted as "allow server listings".
+	HideFromServerListing bool
+	ParticleStatus        int32
 }
 
 type KnownPack struct {
@@ -91,3 +108,168 @@ func (p *PlayerPosition) Update(
 	p.Yaw, p.HeadYaw, p.Pitch = yaw, headYaw, pitch
 	p.OnGround = ground
 }
+
+// ReadNBT reads a root tag in the encoding used by v: named roots before
+// 1.20.2 and nameless network roots after. An absent value reads as nil.
+func ReadNBT(r io.Reader, v Version) (nbt.Tag, error) {
+	if v >= V1_20_2 {
+		_, tag, err := nbt.NewNetworkDecoder(r).ReadTag()
+		return tag, err
+	}
+
+	_, tag, err := nbt.NewDecoder(r).ReadTag()
+	return tag, err
+}
+
+func WriteNBT(w io.Writer, v Version, tag nbt.Tag) error {
+	if v >= V1_20_2 {
+		return nbt.NewNetworkEncoder(w).WriteTag("", tag)
+	}
+
+	return nbt.NewEncoder(w).WriteTag("", tag)
+}
+
+// ReadChatComponent reads a text component, encoded as a JSON string before
+// 1.20.3 and as network NBT from 1.20.3 onwards.
+func ReadChatComponent(r io.Reader, v Version) (component.ChatComponent, error) {
+	var chat component.ChatComponent
+
+	if v >= V1_20_3 {
+		tag, err := ReadNBT(r, v)
+		if err != nil {
+			return chat, fmt.Errorf("read nbt component: %w", err)
+		}
+
+		if err := chat.UnmarshalNBT(tag); err != nil {
+			return chat, fmt.Errorf("decode chat component: %w", err)
+		}
+
+		return chat, nil
+	}
+
+	return readJSONChatComponent(r)
+}
+
+func readJSONChatComponent(r io.Reader) (component.ChatComponent, error) {
+	var chat component.ChatComponent
+
+	s, err := ReadString(r)
+	if err != nil {
+		return chat, err
+	}
+
+	if err := json.Unmarshal([]byte(s), &chat); err != nil {
+		return chat, fmt.Errorf("decode chat component: %w", err)
+	}
+
+	return chat, nil
+}
+
+// WriteChatComponent writes a text component in the encoding used by v.
+func WriteChatComponent(w io.Writer, v Version, chat component.ChatComponent) error {
+	if v >= V1_20_3 {
+		tag, err := chat.NBTTag(eventFormat(v))
+		if err != nil {
+			return err
+		}
+
+		return WriteNBT(w, v, tag)
+	}
+
+	return writeJSONChatComponent(w, v, chat)
+}
+
+func writeJSONChatComponent(w io.Writer, v Version, chat component.ChatComponent) error {
+	data, err := chat.JSON(eventFormat(v))
+	if err != nil {
+		return err
+	}
+
+	return WriteString(w, string(data))
+}
+
+func eventFormat(v Version) component.EventFormat {
+	if v >= V1_21_5 {
+		return component.SnakeCaseEvents
+	}
+	return component.CamelCaseEvents
+}
+
+func writeOptionalChatComponent(w io.Writer, v Version, chat *component.ChatComponent) error {
+	if err := WriteBool(w, chat != nil); err != nil || chat == nil {
+		return err
+	}
+
+	return WriteChatComponent(w, v, *chat)
+}
+
+func readOptionalChatComponent(r io.Reader, v Version) (*component.ChatComponent, error) {
+	present, err := ReadBool(r)
+	if err != nil || !present {
+		return nil, err
+	}
+
+	chat, err := ReadChatComponent(r, v)
+	return &chat, err
+}
+
+// BlockPosition is a block coordinate, packed into a single long on the wire.
+type BlockPosition struct {
+	X, Y, Z int32
+}
+
+// WritePosition packs pos as x:26 z:26 y:12 from 1.14, and x:26 y:12 z:26
+// before.
+func WritePosition(w io.Writer, v Version, pos BlockPosition) error {
+	x, y, z := int64(pos.X)&0x3FFFFFF, int64(pos.Y)&0xFFF, int64(pos.Z)&0x3FFFFFF
+	if v >= V1_14 {
+		return WriteLong(w, x<<38|z<<12|y)
+	}
+	return WriteLong(w, x<<38|y<<26|z)
+}
+
+func ReadPosition(r io.Reader, v Version) (BlockPosition, error) {
+	packed, err := ReadLong(r)
+	if err != nil {
+		return BlockPosition{}, err
+	}
+
+	if v >= V1_14 {
+		return BlockPosition{X: int32(packed >> 38), Y: int32(packed << 52 >> 52), Z: int32(packed << 26 >> 38)}, nil
+	}
+	return BlockPosition{X: int32(packed >> 38), Y: int32(packed << 26 >> 52), Z: int32(packed << 38 >> 38)}, nil
+}
+
+func writeStrings(w io.Writer, values []string) error {
+	if err := WriteVarInt(w, int32(len(values))); err != nil {
+		return err
+	}
+
+	for _, value := range values {
+		if err := WriteString(w, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readStrings(r io.Reader) ([]string, error) {
+	count, err := ReadVarInt(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if count < 0 {
+		return nil, fmt.Errorf("invalid string array length %d", count)
+	}
+
+	values := make([]string, 0, min(count, 1024))
+	for i := int32(0); i < count; i++ {
+		value, err := ReadString(r)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
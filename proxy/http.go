@@ -0,0 +1,93 @@
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+var ErrHTTPConnectRejected = errors.New("proxy: CONNECT rejected")
+
+// HTTP dials through an HTTP proxy with the CONNECT method, sending basic
+// credentials when Username is set.
+type HTTP struct {
+	Addr     string
+	Username string
+	Password string
+	// Header is added to the CONNECT request.
+	Header http.Header
+	// Forward connects to the proxy, a plain net.Dialer when nil.
+	Forward Dialer
+}
+
+func (h *HTTP) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	if network != "tcp" && network != "tcp4" && network != "tcp6" {
+		return nil, fmt.Errorf("proxy: http cannot dial %s", network)
+	}
+
+	var buffered *bufferedConn
+	conn, err := dialProxy(ctx, h.Forward, h.Addr, func(conn net.Conn) error {
+		var err error
+		buffered, err = h.connect(conn, address)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if buffered != nil {
+		return buffered, nil
+	}
+	return conn, nil
+}
+
+func (h *HTTP) connect(conn net.Conn, address string) (*bufferedConn, error) {
+	request := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: h.Header.Clone(),
+	}
+	if request.Header == nil {
+		request.Header = make(http.Header)
+	}
+	if h.Username != "" {
+		credentials := base64.StdEncoding.EncodeToString([]byte(h.Username + ":" + h.Password))
+		request.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+
+	if err := request.Write(conn); err != nil {
+		return nil, fmt.Errorf("proxy: send CONNECT: %w", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, request)
+	if err != nil {
+		return nil, fmt.Errorf("proxy: read CONNECT response: %w", err)
+	}
+	_ = response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s", ErrHTTPConnectRejected, response.Status)
+	}
+
+	// Keep anything the proxy sent past the response.
+	if reader.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, reader: reader}, nil
+	}
+	return nil, nil
+}
+
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
@@ -0,0 +1,180 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+)
+
+const (
+	socksVersion = 0x05
+
+	socksNoAuth       = 0x00
+	socksPasswordAuth = 0x02
+	socksNoAcceptable = 0xFF
+
+	socksConnect = 0x01
+
+	socksIPv4   = 0x01
+	socksDomain = 0x03
+	socksIPv6   = 0x04
+)
+
+var (
+	ErrSOCKS5AuthFailed = errors.New("proxy: socks5 authentication failed")
+	ErrSOCKS5Rejected   = errors.New("proxy: socks5 connect rejected")
+)
+
+var socksReplies = []string{
+	1: "general failure",
+	2: "connection not allowed by ruleset",
+	3: "network unreachable",
+	4: "host unreachable",
+	5: "connection refused",
+	6: "TTL expired",
+	7: "command not supported",
+	8: "address type not supported",
+}
+
+// SOCKS5 dials through a SOCKS5 proxy (RFC 1928), with username and
+// password authentication (RFC 1929) when Username is set.
+type SOCKS5 struct {
+	Addr     string
+	Username string
+	Password string
+	// Forward connects to the proxy, a plain net.Dialer when nil.
+	Forward Dialer
+}
+
+func (s *SOCKS5) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	if network != "tcp" && network != "tcp4" && network != "tcp6" {
+		return nil, fmt.Errorf("proxy: socks5 cannot dial %s", network)
+	}
+
+	return dialProxy(ctx, s.Forward, s.Addr, func(conn net.Conn) error {
+		if err := s.authenticate(conn); err != nil {
+			return err
+		}
+		return s.connect(conn, address)
+	})
+}
+
+func (s *SOCKS5) authenticate(conn net.Conn) error {
+	methods := []byte{socksNoAuth}
+	if s.Username != "" {
+		methods = []byte{socksPasswordAuth}
+	}
+
+	greeting := append([]byte{socksVersion, byte(len(methods))}, methods...)
+	if _, err := conn.Write(greeting); err != nil {
+		return fmt.Errorf("proxy: socks5 greeting: %w", err)
+	}
+
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return fmt.Errorf("proxy: socks5 greeting: %w", err)
+	}
+	if reply[0] != socksVersion {
+		return fmt.Errorf("proxy: unexpected socks version %d", reply[0])
+	}
+
+	switch reply[1] {
+	case socksNoAuth:
+		return nil
+	case socksPasswordAuth:
+	case socksNoAcceptable:
+		return ErrSOCKS5AuthFailed
+	default:
+		return fmt.Errorf("proxy: socks5 chose unknown method %d", reply[1])
+	}
+
+	if len(s.Username) > 255 || len(s.Password) > 255 {
+		return errors.New("proxy: socks5 credentials too long")
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte(0x01)
+	buf.WriteByte(byte(len(s.Username)))
+	buf.WriteString(s.Username)
+	buf.WriteByte(byte(len(s.Password)))
+	buf.WriteString(s.Password)
+	if _, err := conn.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("proxy: socks5 authentication: %w", err)
+	}
+
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return fmt.Errorf("proxy: socks5 authentication: %w", err)
+	}
+	if reply[1] != 0x00 {
+		return ErrSOCKS5AuthFailed
+	}
+	return nil
+}
+
+func (s *SOCKS5) connect(conn net.Conn, address string) error {
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("proxy: %w", err)
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return fmt.Errorf("proxy: invalid port %q", portStr)
+	}
+
+	request := []byte{socksVersion, socksConnect, 0x00}
+	if ip := net.ParseIP(host); ip == nil {
+		if len(host) > 255 {
+			return fmt.Errorf("proxy: host name too long: %s", host)
+		}
+		request = append(request, socksDomain, byte(len(host)))
+		request = append(request, host...)
+	} else if ip4 := ip.To4(); ip4 != nil {
+		request = append(append(request, socksIPv4), ip4...)
+	} else {
+		request = append(append(request, socksIPv6), ip.To16()...)
+	}
+	request = binary.BigEndian.AppendUint16(request, uint16(port))
+
+	if _, err := conn.Write(request); err != nil {
+		return fmt.Errorf("proxy: socks5 connect: %w", err)
+	}
+
+	// The reply names the bound address, which is read and discarded.
+	reply := make([]byte, 4)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return fmt.Errorf("proxy: socks5 connect: %w", err)
+	}
+	if reply[1] != 0x00 {
+		reason := "unknown error"
+		if int(reply[1]) < len(socksReplies) {
+			reason = socksReplies[reply[1]]
+		}
+		return fmt.Errorf("%w: %s", ErrSOCKS5Rejected, reason)
+	}
+
+	var skip int
+	switch reply[3] {
+	case socksIPv4:
+		skip = net.IPv4len
+	case socksIPv6:
+		skip = net.IPv6len
+	case socksDomain:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return fmt.Errorf("proxy: socks5 connect: %w", err)
+		}
+		skip = int(length[0])
+	default:
+		return fmt.Errorf("proxy: socks5 reply has address type %d", reply[3])
+	}
+
+	if _, err := io.ReadFull(conn, make([]byte, skip+2)); err != nil {
+		return fmt.Errorf("proxy: socks5 connect: %w", err)
+	}
+	return nil
+}
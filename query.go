@@ -0,0 +1,25 @@
+package gophermc
+
+import (
+	"context"
+	"net"
+	"strconv"
+
+	"github.com/obeliskdev/gophermc/query"
+)
+
+// Query asks the server for its full stat over the UDP query protocol, which
+// the server only answers with enable-query=true. Unlike Status it lists
+// every online player and the installed plugins.
+func (c *Client) Query(ctx context.Context) (*query.FullStat, error) {
+	if err := c.resolve(ctx, false); err != nil {
+		return nil, err
+	}
+
+	port := c.queryPort
+	if port == 0 {
+		port = c.addr.Port
+	}
+
+	return query.Full(ctx, net.JoinHostPort(c.addr.IP.String(), strconv.Itoa(port)))
+}
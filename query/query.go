@@ -0,0 +1,302 @@
+// Package query implements the GameSpy4 based UDP query protocol servers
+// expose with enable-query=true. Unlike the server list ping it reports the
+// map, the plugin list and every online player.
+package query
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/obeliskdev/fastrand"
+)
+
+const (
+	typeStat      = 0x00
+	typeHandshake = 0x09
+
+	// sessionMask keeps the session id in the range the vanilla server
+	// accepts.
+	sessionMask = 0x0F0F0F0F
+
+	maxPacketSize = 65535
+)
+
+var (
+	magic = []byte{0xFE, 0xFD}
+
+	// The full stat response pads its two sections with fixed strings.
+	fullStatPadding   = []byte("splitnum\x00\x80\x00")
+	fullStatPlayerKey = []byte("\x01player_\x00\x00")
+)
+
+var ErrMalformedResponse = errors.New("query: malformed response")
+
+type Client struct {
+	// Timeout limits each attempt, 2 seconds by default.
+	Timeout time.Duration
+	// Retries is the number of attempts after the first one timed out.
+	Retries int
+	Dialer  *net.Dialer
+}
+
+var DefaultClient = &Client{Retries: 2}
+
+// BasicStat is the short answer, roughly what the server list shows.
+type BasicStat struct {
+	MOTD       string
+	GameType   string
+	Map        string
+	NumPlayers int
+	MaxPlayers int
+	HostPort   uint16
+	HostIP     string
+}
+
+type FullStat struct {
+	MOTD       string
+	GameType   string
+	GameID     string
+	Version    string
+	Map        string
+	NumPlayers int
+	MaxPlayers int
+	HostPort   uint16
+	HostIP     string
+
+	// ServerMod and Plugins are split from the plugins field, which Bukkit
+	// based servers fill with "Mod: plugin 1.0; other 2.0". Vanilla leaves
+	// it empty.
+	ServerMod string
+	Plugins   []string
+
+	Players []string
+	// Values holds every key/value pair as sent.
+	Values map[string]string
+}
+
+func Basic(ctx context.Context, address string) (*BasicStat, error) {
+	return DefaultClient.Basic(ctx, address)
+}
+
+func Full(ctx context.Context, address string) (*FullStat, error) {
+	return DefaultClient.Full(ctx, address)
+}
+
+func (c *Client) Basic(ctx context.Context, address string) (*BasicStat, error) {
+	data, err := c.request(ctx, address, false)
+	if err != nil {
+		return nil, err
+	}
+	return parseBasicStat(data)
+}
+
+func (c *Client) Full(ctx context.Context, address string) (*FullStat, error) {
+	data, err := c.request(ctx, address, true)
+	if err != nil {
+		return nil, err
+	}
+	return parseFullStat(data)
+}
+
+// request performs the handshake and a stat request, retrying the whole
+// exchange when an attempt times out. It returns the stat payload.
+func (c *Client) request(ctx context.Context, address string, full bool) ([]byte, error) {
+	dialer := c.Dialer
+	if dialer == nil {
+		dialer = &net.Dialer{}
+	}
+
+	conn, err := dialer.DialContext(ctx, "udp", address)
+	if err != nil {
+		return nil, fmt.Errorf("query: dial: %w", err)
+	}
+	defer conn.Close()
+
+	for attempt := 0; ; attempt++ {
+		data, err := c.exchange(ctx, conn, full)
+
+		var netErr net.Error
+		if err == nil || !errors.As(err, &netErr) || !netErr.Timeout() || attempt >= c.Retries || ctx.Err() != nil {
+			return data, err
+		}
+	}
+}
+
+func (c *Client) exchange(ctx context.Context, conn net.Conn, full bool) ([]byte, error) {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+
+	deadline := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	_ = conn.SetDeadline(deadline)
+
+	session := fastrand.NumberN[int32](1<<31-1) & sessionMask
+
+	if err := writeRequest(conn, typeHandshake, session, nil); err != nil {
+		return nil, fmt.Errorf("query: send handshake: %w", err)
+	}
+
+	data, err := readResponse(conn, typeHandshake, session)
+	if err != nil {
+		return nil, fmt.Errorf("query: read handshake: %w", err)
+	}
+
+	token, err := strconv.ParseInt(string(bytes.TrimRight(data, "\x00")), 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("%w: challenge token %q", ErrMalformedResponse, data)
+	}
+
+	payload := binary.BigEndian.AppendUint32(nil, uint32(token))
+	if full {
+		payload = append(payload, 0, 0, 0, 0)
+	}
+
+	if err := writeRequest(conn, typeStat, session, payload); err != nil {
+		return nil, fmt.Errorf("query: send stat request: %w", err)
+	}
+
+	data, err = readResponse(conn, typeStat, session)
+	if err != nil {
+		return nil, fmt.Errorf("query: read stat: %w", err)
+	}
+	return data, nil
+}
+
+func writeRequest(conn net.Conn, packetType byte, session int32, payload []byte) error {
+	packet := []byte{magic[0], magic[1], packetType}
+	packet = binary.BigEndian.AppendUint32(packet, uint32(session))
+	_, err := conn.Write(append(packet, payload...))
+	return err
+}
+
+// readResponse returns the payload of the next response of packetType for
+// session, skipping stray datagrams from earlier attempts.
+func readResponse(conn net.Conn, packetType byte, session int32) ([]byte, error) {
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		if n < 5 || buf[0] != packetType || int32(binary.BigEndian.Uint32(buf[1:5])) != session {
+			continue
+		}
+		return bytes.Clone(buf[5:n]), nil
+	}
+}
+
+func parseBasicStat(data []byte) (*BasicStat, error) {
+	fields := make([]string, 5)
+	for i := range fields {
+		var ok bool
+		if fields[i], data, ok = cutString(data); !ok {
+			return nil, fmt.Errorf("%w: basic stat has %d fields", ErrMalformedResponse, i)
+		}
+	}
+
+	if len(data) < 2 {
+		return nil, fmt.Errorf("%w: basic stat is missing the port", ErrMalformedResponse)
+	}
+
+	stat := &BasicStat{
+		MOTD:     fields[0],
+		GameType: fields[1],
+		Map:      fields[2],
+		// The port is the only little endian value in the protocol.
+		HostPort: binary.LittleEndian.Uint16(data),
+	}
+	stat.HostIP, _, _ = cutString(data[2:])
+
+	var err error
+	if stat.NumPlayers, err = strconv.Atoi(fields[3]); err != nil {
+		return nil, fmt.Errorf("%w: player count %q", ErrMalformedResponse, fields[3])
+	}
+	if stat.MaxPlayers, err = strconv.Atoi(fields[4]); err != nil {
+		return nil, fmt.Errorf("%w: max players %q", ErrMalformedResponse, fields[4])
+	}
+	return stat, nil
+}
+
+func parseFullStat(data []byte) (*FullStat, error) {
+	data, ok := bytes.CutPrefix(data, fullStatPadding)
+	if !ok {
+		return nil, fmt.Errorf("%w: missing full stat padding", ErrMalformedResponse)
+	}
+
+	stat := &FullStat{Values: make(map[string]string)}
+	for {
+		var key, value string
+		if key, data, ok = cutString(data); !ok {
+			return nil, fmt.Errorf("%w: unterminated key", ErrMalformedResponse)
+		}
+		if key == "" {
+			break
+		}
+		if value, data, ok = cutString(data); !ok {
+			return nil, fmt.Errorf("%w: unterminated value for %s", ErrMalformedResponse, key)
+		}
+		stat.Values[key] = value
+	}
+
+	if data, ok = bytes.CutPrefix(data, fullStatPlayerKey); !ok {
+		return nil, fmt.Errorf("%w: missing player section", ErrMalformedResponse)
+	}
+
+	for {
+		var player string
+		if player, data, ok = cutString(data); !ok || player == "" {
+			break
+		}
+		stat.Players = append(stat.Players, player)
+	}
+
+	stat.MOTD = stat.Values["hostname"]
+	stat.GameType = stat.Values["gametype"]
+	stat.GameID = stat.Values["game_id"]
+	stat.Version = stat.Values["version"]
+	stat.Map = stat.Values["map"]
+	stat.HostIP = stat.Values["hostip"]
+	stat.NumPlayers, _ = strconv.Atoi(stat.Values["numplayers"])
+	stat.MaxPlayers, _ = strconv.Atoi(stat.Values["maxplayers"])
+
+	port, _ := strconv.ParseUint(stat.Values["hostport"], 10, 16)
+	stat.HostPort = uint16(port)
+
+	stat.ServerMod, stat.Plugins = splitPlugins(stat.Values["plugins"])
+	return stat, nil
+}
+
+func splitPlugins(value string) (string, []string) {
+	mod, list, found := strings.Cut(value, ":")
+	if !found {
+		return strings.TrimSpace(value), nil
+	}
+
+	var plugins []string
+	for _, plugin := range strings.Split(list, ";") {
+		if plugin = strings.TrimSpace(plugin); plugin != "" {
+			plugins = append(plugins, plugin)
+		}
+	}
+	return strings.TrimSpace(mod), plugins
+}
+
+func cutString(data []byte) (string, []byte, bool) {
+	i := bytes.IndexByte(data, 0)
+	if i < 0 {
+		return "", data, false
+	}
+	return string(data[:i]), data[i+1:], true
+}
@@ -0,0 +1,165 @@
+package query
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"reflect"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testToken = 9513307
+
+// responder is a local query server. It drops the first drop handshakes to
+// exercise retries.
+type responder struct {
+	conn       net.PacketConn
+	drop       int32
+	handshakes atomic.Int32
+}
+
+func startResponder(t *testing.T, drop int32) *responder {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	r := &responder{conn: conn, drop: drop}
+	go r.serve()
+	return r
+}
+
+func (r *responder) serve() {
+	buf := make([]byte, 1500)
+	for {
+		n, addr, err := r.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		packet := buf[:n]
+		if n < 7 || !bytes.HasPrefix(packet, magic) {
+			continue
+		}
+
+		header := append([]byte{packet[2]}, packet[3:7]...)
+		payload := packet[7:]
+
+		switch packet[2] {
+		case typeHandshake:
+			if r.handshakes.Add(1) <= r.drop {
+				continue
+			}
+			_, _ = r.conn.WriteTo(append(header, strconv.Itoa(testToken)+"\x00"...), addr)
+		case typeStat:
+			if len(payload) < 4 || binary.BigEndian.Uint32(payload) != testToken {
+				continue
+			}
+			if len(payload) == 8 {
+				_, _ = r.conn.WriteTo(append(header, fullStatResponse()...), addr)
+			} else {
+				_, _ = r.conn.WriteTo(append(header, basicStatResponse()...), addr)
+			}
+		}
+	}
+}
+
+func basicStatResponse() []byte {
+	data := []byte("A Minecraft Server\x00SMP\x00world\x002\x0020\x00")
+	data = binary.LittleEndian.AppendUint16(data, 25565)
+	return append(data, "127.0.0.1\x00"...)
+}
+
+func fullStatResponse() []byte {
+	data := append([]byte(nil), fullStatPadding...)
+	for _, kv := range [][2]string{
+		{"hostname", "A Minecraft Server"},
+		{"gametype", "SMP"},
+		{"game_id", "MINECRAFT"},
+		{"version", "1.21.4"},
+		{"plugins", "Paper on 1.21.4: WorldEdit 7.3.0; LuckPerms 5.4"},
+		{"map", "world"},
+		{"numplayers", "2"},
+		{"maxplayers", "20"},
+		{"hostport", "25565"},
+		{"hostip", "127.0.0.1"},
+	} {
+		data = append(data, kv[0]+"\x00"+kv[1]+"\x00"...)
+	}
+	data = append(data, 0)
+	data = append(data, fullStatPlayerKey...)
+	return append(data, "Steve\x00Alex\x00\x00"...)
+}
+
+func TestBasic(t *testing.T) {
+	r := startResponder(t, 0)
+
+	stat, err := Basic(context.Background(), r.conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := BasicStat{MOTD: "A Minecraft Server", GameType: "SMP", Map: "world", NumPlayers: 2, MaxPlayers: 20, HostPort: 25565, HostIP: "127.0.0.1"}
+	if *stat != want {
+		t.Fatalf("expected %+v, got %+v", want, *stat)
+	}
+}
+
+func TestFull(t *testing.T) {
+	r := startResponder(t, 0)
+
+	stat, err := Full(context.Background(), r.conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stat.MOTD != "A Minecraft Server" || stat.GameID != "MINECRAFT" || stat.Version != "1.21.4" || stat.NumPlayers != 2 || stat.HostPort != 25565 {
+		t.Errorf("unexpected stat %+v", stat)
+	}
+	if stat.ServerMod != "Paper on 1.21.4" || !reflect.DeepEqual(stat.Plugins, []string{"WorldEdit 7.3.0", "LuckPerms 5.4"}) {
+		t.Errorf("unexpected plugins %q %q", stat.ServerMod, stat.Plugins)
+	}
+	if !reflect.DeepEqual(stat.Players, []string{"Steve", "Alex"}) {
+		t.Errorf("unexpected players %q", stat.Players)
+	}
+}
+
+func TestRetries(t *testing.T) {
+	r := startResponder(t, 1)
+	client := &Client{Timeout: 100 * time.Millisecond, Retries: 1}
+
+	if _, err := client.Basic(context.Background(), r.conn.LocalAddr().String()); err != nil {
+		t.Fatal(err)
+	}
+	if n := r.handshakes.Load(); n != 2 {
+		t.Fatalf("expected 2 handshakes, got %d", n)
+	}
+
+	r = startResponder(t, 2)
+	_, err := client.Basic(context.Background(), r.conn.LocalAddr().String())
+
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+}
+
+func TestMalformedResponse(t *testing.T) {
+	if _, err := parseBasicStat([]byte("motd\x00SMP\x00")); !errors.Is(err, ErrMalformedResponse) {
+		t.Errorf("expected malformed basic stat, got %v", err)
+	}
+	if _, err := parseFullStat([]byte("hostname\x00")); !errors.Is(err, ErrMalformedResponse) {
+		t.Errorf("expected malformed full stat, got %v", err)
+	}
+
+	if mod, plugins := splitPlugins("CraftBukkit"); mod != "CraftBukkit" || plugins != nil {
+		t.Errorf("unexpected split %q %q", mod, plugins)
+	}
+}
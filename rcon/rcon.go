@@ -0,0 +1,295 @@
+// Package rcon implements the Source RCON protocol Minecraft servers expose
+// with enable-rcon=true, for running console commands remotely.
+package rcon
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+)
+
+const (
+	typeResponse = 0
+	typeCommand  = 2
+	typeAuth     = 3
+	// The server answers a login with typeCommand.
+	typeAuthResponse = typeCommand
+
+	// authFailedID is the request id of the response to a wrong password.
+	authFailedID = -1
+
+	// MaxCommandLength is the longest command the vanilla server reads; it
+	// accepts packets of up to 1460 bytes.
+	MaxCommandLength = 1446
+
+	// Responses are split into packets of at most 4096 bytes of body. The
+	// limit only guards against corrupt lengths.
+	maxPacketLength = 1 << 16
+)
+
+var (
+	ErrAuthFailed      = errors.New("rcon: authentication failed")
+	ErrCommandTooLong  = errors.New("rcon: command too long")
+	ErrClosed          = errors.New("rcon: connection closed")
+	ErrInvalidResponse = errors.New("rcon: invalid response")
+)
+
+// Client is an authenticated RCON connection. Commands may be sent from
+// several goroutines; they are pipelined on the connection and every
+// response is matched to its command by request id.
+type Client struct {
+	conn net.Conn
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	nextID  int32
+	pending map[int32]*call
+	// terminators maps the id of the empty packet sent after a command to
+	// that command. The server answers in order, so its response marks the
+	// end of the command's possibly split response.
+	terminators map[int32]*call
+	err         error
+	closed      chan struct{}
+}
+
+type call struct {
+	id       int32
+	response strings.Builder
+	done     chan struct{}
+	err      error
+}
+
+// Dial connects to address and logs in with password.
+func Dial(ctx context.Context, address, password string) (*Client, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("rcon: dial: %w", err)
+	}
+
+	client, err := NewClient(ctx, conn, password)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+// NewClient logs in on an established connection.
+func NewClient(ctx context.Context, conn net.Conn, password string) (*Client, error) {
+	c := &Client{
+		conn:        conn,
+		nextID:      1,
+		pending:     make(map[int32]*call),
+		terminators: make(map[int32]*call),
+		closed:      make(chan struct{}),
+	}
+
+	if err := c.authenticate(ctx, password); err != nil {
+		return nil, err
+	}
+
+	go c.readLoop()
+	return c, nil
+}
+
+func (c *Client) authenticate(ctx context.Context, password string) error {
+	stop := context.AfterFunc(ctx, func() { _ = c.conn.Close() })
+	defer stop()
+
+	id := c.allocateID()
+	if err := writePacket(c.conn, id, typeAuth, password); err != nil {
+		return c.contextError(ctx, fmt.Errorf("rcon: send login: %w", err))
+	}
+
+	for {
+		responseID, packetType, _, err := readPacket(c.conn)
+		if err != nil {
+			return c.contextError(ctx, fmt.Errorf("rcon: read login response: %w", err))
+		}
+
+		// Source servers send an empty response value before the result.
+		if packetType != typeAuthResponse {
+			continue
+		}
+
+		switch responseID {
+		case id:
+			return nil
+		case authFailedID:
+			return ErrAuthFailed
+		default:
+			return fmt.Errorf("%w: login answered with id %d", ErrInvalidResponse, responseID)
+		}
+	}
+}
+
+func (c *Client) contextError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
+
+// Command runs command and returns the server's response. Canceling ctx
+// abandons the call; the server still runs the command.
+func (c *Client) Command(ctx context.Context, command string) (string, error) {
+	if len(command) > MaxCommandLength {
+		return "", fmt.Errorf("%w: %d bytes", ErrCommandTooLong, len(command))
+	}
+
+	cl := &call{done: make(chan struct{})}
+
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return "", c.err
+	}
+	cl.id = c.allocateIDLocked()
+	terminator := c.allocateIDLocked()
+	c.pending[cl.id] = cl
+	c.terminators[terminator] = cl
+	c.mu.Unlock()
+
+	if err := c.send(cl.id, command, terminator); err != nil {
+		c.abandon(cl.id, terminator)
+		return "", fmt.Errorf("rcon: send command: %w", err)
+	}
+
+	select {
+	case <-cl.done:
+		if cl.err != nil {
+			return "", cl.err
+		}
+		return cl.response.String(), nil
+	case <-ctx.Done():
+		c.abandon(cl.id, terminator)
+		return "", ctx.Err()
+	}
+}
+
+// send writes the command followed by its terminating empty packet. They
+// are written separately, so the vanilla server reads each on its own.
+func (c *Client) send(id int32, command string, terminator int32) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if err := writePacket(c.conn, id, typeCommand, command); err != nil {
+		return err
+	}
+	return writePacket(c.conn, terminator, typeResponse, "")
+}
+
+func (c *Client) abandon(id, terminator int32) {
+	c.mu.Lock()
+	delete(c.pending, id)
+	delete(c.terminators, terminator)
+	c.mu.Unlock()
+}
+
+func (c *Client) allocateID() int32 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.allocateIDLocked()
+}
+
+func (c *Client) allocateIDLocked() int32 {
+	id := c.nextID
+	c.nextID++
+	if c.nextID <= 0 {
+		c.nextID = 1
+	}
+	return id
+}
+
+func (c *Client) readLoop() {
+	for {
+		id, _, body, err := readPacket(c.conn)
+		if err != nil {
+			c.fail(err)
+			return
+		}
+
+		c.mu.Lock()
+		if cl, ok := c.pending[id]; ok {
+			cl.response.WriteString(body)
+		} else if cl, ok := c.terminators[id]; ok {
+			delete(c.terminators, id)
+			delete(c.pending, cl.id)
+			close(cl.done)
+		}
+		c.mu.Unlock()
+	}
+}
+
+func (c *Client) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	select {
+	case <-c.closed:
+		c.err = ErrClosed
+	default:
+		c.err = fmt.Errorf("%w: %w", ErrClosed, err)
+	}
+
+	for id, cl := range c.pending {
+		cl.err = c.err
+		close(cl.done)
+		delete(c.pending, id)
+	}
+	clear(c.terminators)
+}
+
+// Close closes the connection. Pending commands fail with ErrClosed.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	select {
+	case <-c.closed:
+	default:
+		close(c.closed)
+	}
+	c.mu.Unlock()
+
+	return c.conn.Close()
+}
+
+// A packet is its little endian length, request id and type, followed by
+// the NUL terminated body and an empty NUL terminated string.
+func writePacket(w io.Writer, id, packetType int32, body string) error {
+	buf := make([]byte, 0, 14+len(body))
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(10+len(body)))
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(id))
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(packetType))
+	buf = append(buf, body...)
+	buf = append(buf, 0, 0)
+
+	_, err := w.Write(buf)
+	return err
+}
+
+func readPacket(r io.Reader) (id, packetType int32, body string, err error) {
+	var length int32
+	if err = binary.Read(r, binary.LittleEndian, &length); err != nil {
+		return 0, 0, "", err
+	}
+	if length < 10 || length > maxPacketLength {
+		return 0, 0, "", fmt.Errorf("%w: packet length %d", ErrInvalidResponse, length)
+	}
+
+	data := make([]byte, length)
+	if _, err = io.ReadFull(r, data); err != nil {
+		return 0, 0, "", err
+	}
+
+	id = int32(binary.LittleEndian.Uint32(data))
+	packetType = int32(binary.LittleEndian.Uint32(data[4:]))
+	body, _, _ = strings.Cut(string(data[8:]), "\x00")
+	return id, packetType, body, nil
+}
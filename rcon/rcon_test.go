@@ -0,0 +1,237 @@
+package rcon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testPassword = "hunter2"
+
+// fakeServer behaves like the vanilla RCON server: responses are split into
+// 4096 byte packets and unknown packet types are answered with a message.
+func fakeServer(t *testing.T, handle func(command string) string) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveConn(conn, handle)
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func serveConn(conn net.Conn, handle func(string) string) {
+	defer conn.Close()
+
+	authenticated := false
+	for {
+		id, packetType, body, err := readPacket(conn)
+		if err != nil {
+			return
+		}
+
+		switch {
+		case packetType == typeAuth:
+			if body != testPassword {
+				_ = writePacket(conn, authFailedID, typeAuthResponse, "")
+				return
+			}
+			authenticated = true
+			_ = writePacket(conn, id, typeAuthResponse, "")
+		case !authenticated:
+			return
+		case packetType == typeCommand:
+			response := handle(body)
+			for {
+				chunk := response[:min(len(response), 4096)]
+				response = response[len(chunk):]
+				_ = writePacket(conn, id, typeResponse, chunk)
+				if response == "" {
+					break
+				}
+			}
+		default:
+			_ = writePacket(conn, id, typeResponse, fmt.Sprintf("Unknown request %x", packetType))
+		}
+	}
+}
+
+func echo(command string) string {
+	if n, ok := strings.CutPrefix(command, "repeat "); ok {
+		return strings.Repeat("x", len(n)*5000)
+	}
+	return "ran " + command
+}
+
+func TestCommand(t *testing.T) {
+	client, err := Dial(context.Background(), fakeServer(t, echo), testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	response, err := client.Command(context.Background(), "op Steve")
+	if err != nil || response != "ran op Steve" {
+		t.Fatalf("unexpected response %q (%v)", response, err)
+	}
+
+	// A response longer than one packet is reassembled.
+	response, err = client.Command(context.Background(), "repeat ab")
+	if err != nil || response != strings.Repeat("x", 10000) {
+		t.Fatalf("unexpected response of %d bytes (%v)", len(response), err)
+	}
+
+	response, err = client.Command(context.Background(), "list")
+	if err != nil || response != "ran list" {
+		t.Fatalf("unexpected response %q (%v)", response, err)
+	}
+
+	if _, err := client.Command(context.Background(), strings.Repeat("a", MaxCommandLength+1)); !errors.Is(err, ErrCommandTooLong) {
+		t.Fatalf("expected ErrCommandTooLong, got %v", err)
+	}
+}
+
+func TestCommandPipelining(t *testing.T) {
+	client, err := Dial(context.Background(), fakeServer(t, echo), testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			command := fmt.Sprintf("say %d", i)
+			if i%10 == 0 {
+				command = "repeat " + strings.Repeat("a", i/10+1)
+			}
+
+			response, err := client.Command(context.Background(), command)
+			if err != nil || response != echo(command) {
+				t.Errorf("%s: unexpected response of %d bytes (%v)", command, len(response), err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestCommandRoutedByID(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	defer serverConn.Close()
+
+	go func() {
+		id, _, _, err := readPacket(serverConn)
+		if err == nil {
+			_ = writePacket(serverConn, id, typeAuthResponse, "")
+		}
+	}()
+	client, err := NewClient(context.Background(), clientConn, testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	results := map[string]chan string{"first": make(chan string, 1), "second": make(chan string, 1)}
+	for command := range results {
+		go func() {
+			response, err := client.Command(context.Background(), command)
+			if err != nil {
+				t.Errorf("%s: %v", command, err)
+			}
+			results[command] <- response
+		}()
+	}
+
+	// Both commands and their terminators are written before anything is
+	// answered.
+	commands := make(map[string]int32)
+	var terminators []int32
+	for range 4 {
+		id, packetType, body, err := readPacket(serverConn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if packetType == typeCommand {
+			commands[body] = id
+		} else {
+			terminators = append(terminators, id)
+		}
+	}
+	if len(commands) != 2 || len(terminators) != 2 {
+		t.Fatalf("expected two commands and two terminators, got %v and %v", commands, terminators)
+	}
+
+	// Answer the second command first; each ends at its own terminator.
+	for _, command := range []string{"second", "first"} {
+		id := commands[command]
+		_ = writePacket(serverConn, id, typeResponse, "ran ")
+		_ = writePacket(serverConn, id, typeResponse, command)
+		_ = writePacket(serverConn, id+1, typeResponse, "")
+		if response := <-results[command]; response != "ran "+command {
+			t.Fatalf("%s: unexpected response %q", command, response)
+		}
+	}
+}
+
+func TestAuthFailed(t *testing.T) {
+	if _, err := Dial(context.Background(), fakeServer(t, echo), "wrong"); !errors.Is(err, ErrAuthFailed) {
+		t.Fatalf("expected ErrAuthFailed, got %v", err)
+	}
+}
+
+func TestCommandContext(t *testing.T) {
+	block := make(chan struct{})
+	addr := fakeServer(t, func(command string) string {
+		if command == "slow" {
+			<-block
+		}
+		return echo(command)
+	})
+	defer close(block)
+
+	client, err := Dial(context.Background(), addr, testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.Command(ctx, "slow"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a deadline error, got %v", err)
+	}
+
+	done := make(chan error)
+	go func() {
+		_, err := client.Command(context.Background(), "stuck")
+		done <- err
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	_ = client.Close()
+	if err := <-done; !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+	if _, err := client.Command(context.Background(), "list"); err == nil {
+		t.Fatal("expected an error after Close")
+	}
+}
@@ -0,0 +1,116 @@
+package gophermc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+const defaultPort = 25565
+
+// Resolver looks up the server given to WithAddr. *net.Resolver implements
+// it; tests can inject their own with WithResolver.
+type Resolver interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+var ErrNoAddress = errors.New("no server address")
+
+// parseAddr splits a server address as typed into the multiplayer screen:
+// a host or IP literal with an optional port. IPv6 literals with a port are
+// bracketed, "[::1]:25565"; without one the brackets are optional. A port of
+// 0 means none was given.
+func parseAddr(addr string) (string, int, error) {
+	if addr == "" {
+		return "", 0, ErrNoAddress
+	}
+
+	if ip := net.ParseIP(strings.Trim(addr, "[]")); ip != nil {
+		return ip.String(), 0, nil
+	}
+
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		var addrErr *net.AddrError
+		if !errors.As(err, &addrErr) || addrErr.Err != "missing port in address" {
+			return "", 0, err
+		}
+		return addr, 0, nil
+	}
+
+	if host == "" {
+		return "", 0, fmt.Errorf("missing host in address %q", addr)
+	}
+
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil || port == 0 {
+		return "", 0, fmt.Errorf("invalid port %q", portStr)
+	}
+
+	return host, int(port), nil
+}
+
+// resolve sets c.addr from the host given to WithAddr. Like the game, it
+// only looks for a _minecraft._tcp SRV record when no port was given and
+// falls back to the host itself when there is none. With byName, the host
+// name is not looked up: it is left in c.dialHost for a dialer such as a
+// SOCKS5 proxy to resolve, and c.addr only carries the port.
+func (c *Client) resolve(ctx context.Context, byName bool) error {
+	c.dialHost = ""
+	if c.host == "" {
+		if c.addr == nil {
+			return ErrNoAddress
+		}
+		return nil
+	}
+
+	host, port := c.host, c.port
+
+	if ip := net.ParseIP(host); ip != nil {
+		c.addr = &net.TCPAddr{IP: ip, Port: portOr(port, defaultPort)}
+		return nil
+	}
+
+	resolver := c.resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	if port == 0 {
+		port = defaultPort
+
+		// Records come sorted by priority and weight.
+		if _, records, err := resolver.LookupSRV(ctx, "minecraft", "tcp", host); err == nil && len(records) > 0 {
+			host = strings.TrimSuffix(records[0].Target, ".")
+			port = int(records[0].Port)
+		}
+	}
+
+	if byName {
+		c.addr = &net.TCPAddr{Port: port}
+		c.dialHost = host
+		return nil
+	}
+
+	ips, err := resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", host, err)
+	}
+	if len(ips) == 0 {
+		return fmt.Errorf("failed to resolve %s: no addresses", host)
+	}
+
+	c.addr = &net.TCPAddr{IP: ips[0].IP, Zone: ips[0].Zone, Port: port}
+	return nil
+}
+
+func portOr(port, fallback int) int {
+	if port == 0 {
+		return fallback
+	}
+	return port
+}
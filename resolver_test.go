@@ -0,0 +1,125 @@
+package gophermc_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/obeliskdev/gophermc"
+	"github.com/obeliskdev/gophermc/component"
+	"github.com/obeliskdev/gophermc/protocol"
+)
+
+type fakeResolver struct {
+	srv   map[string]*net.SRV
+	hosts map[string]string
+}
+
+func (r *fakeResolver) LookupSRV(_ context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	if record, ok := r.srv[name]; ok && service == "minecraft" && proto == "tcp" {
+		return "_minecraft._tcp." + name, []*net.SRV{record}, nil
+	}
+	return "", nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+func (r *fakeResolver) LookupIPAddr(_ context.Context, host string) ([]net.IPAddr, error) {
+	if ip, ok := r.hosts[host]; ok {
+		return []net.IPAddr{{IP: net.ParseIP(ip)}}, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func TestResolveSRV(t *testing.T) {
+	hostnames := make(chan string, 1)
+	addr := startStatusServer(t, &gophermc.StatusServer{
+		Handler: func(r *gophermc.StatusRequest) *protocol.StatusResponse {
+			hostnames <- r.Hostname
+			return &protocol.StatusResponse{Description: component.Text("srv")}
+		},
+	})
+	_, portStr, _ := net.SplitHostPort(addr)
+	port, _ := strconv.Atoi(portStr)
+
+	resolver := &fakeResolver{
+		srv:   map[string]*net.SRV{"play.example.com": {Target: "node1.example.net.", Port: uint16(port)}},
+		hosts: map[string]string{"node1.example.net": "127.0.0.1", "direct.example.com": "127.0.0.1"},
+	}
+
+	for _, tc := range []struct {
+		addr     string
+		hostname string
+	}{
+		{"play.example.com", "play.example.com"},
+		{"direct.example.com:" + portStr, "direct.example.com"},
+	} {
+		client, err := gophermc.NewClient(gophermc.WithAddr(tc.addr), gophermc.WithResolver(resolver))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		_, _, err = client.Status(ctx)
+		cancel()
+		if err != nil {
+			t.Fatalf("%s: %v", tc.addr, err)
+		}
+		if hostname := <-hostnames; hostname != tc.hostname {
+			t.Errorf("%s: expected handshake host %s, got %s", tc.addr, tc.hostname, hostname)
+		}
+	}
+
+	// An explicit port skips the SRV record.
+	client, _ := gophermc.NewClient(gophermc.WithAddr("play.example.com:25565"), gophermc.WithResolver(resolver))
+	var dnsErr *net.DNSError
+	if err := client.Connect(context.Background()); !errors.As(err, &dnsErr) {
+		t.Fatalf("expected a lookup error, got %v", err)
+	}
+}
+
+func TestResolveIPv6(t *testing.T) {
+	l, err := net.Listen("tcp", "[::1]:0")
+	if err != nil {
+		t.Skipf("IPv6 unavailable: %v", err)
+	}
+	defer l.Close()
+
+	go func() {
+		if conn, err := l.Accept(); err == nil {
+			_ = conn.Close()
+		}
+	}()
+
+	client, err := gophermc.NewClient(gophermc.WithAddr(l.Addr().String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	_ = client.Close()
+	if hostname := client.ServerHostname(); hostname != "::1" {
+		t.Errorf("unexpected hostname %q", hostname)
+	}
+}
+
+func TestWithAddrErrors(t *testing.T) {
+	for _, addr := range []string{"", "example.com:port", "example.com:0", "example.com:70000", ":25565", "a:b:c:d"} {
+		if _, err := gophermc.NewClient(gophermc.WithAddr(addr)); err == nil {
+			t.Errorf("%q: expected an error", addr)
+		}
+	}
+
+	for _, addr := range []string{"example.com", "example.com:25566", "127.0.0.1", "::1", "[::1]", "[2001:db8::1]:25565"} {
+		if _, err := gophermc.NewClient(gophermc.WithAddr(addr)); err != nil {
+			t.Errorf("%q: %v", addr, err)
+		}
+	}
+
+	client, _ := gophermc.NewClient()
+	if err := client.Connect(context.Background()); !errors.Is(err, gophermc.ErrNoAddress) {
+		t.Errorf("expected ErrNoAddress, got %v", err)
+	}
+}
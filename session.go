@@ -0,0 +1,139 @@
+package gophermc
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const DefaultSessionServerURL = "https://sessionserver.mojang.com/session/minecraft/join"
+
+var (
+	ErrInvalidSession = errors.New("invalid session")
+	ErrRateLimited    = errors.New("rate limited by session server")
+	ErrBadToken       = errors.New("bad access token")
+)
+
+// Authenticator joins a server session on behalf of the client before the
+// encryption response is sent, so the server's hasJoined check succeeds.
+type Authenticator interface {
+	JoinServer(ctx context.Context, serverHash string) error
+}
+
+type SessionError struct {
+	StatusCode int
+	Type       string
+	Message    string
+	Err        error
+}
+
+func (e *SessionError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("session server returned %d (%s): %s", e.StatusCode, e.Type, e.Message)
+	}
+	return fmt.Sprintf("session server returned %d", e.StatusCode)
+}
+
+func (e *SessionError) Unwrap() error {
+	return e.Err
+}
+
+type SessionAuthenticator struct {
+	URL         string
+	AccessToken string
+	ProfileID   uuid.UUID
+	HTTPClient  *http.Client
+}
+
+func (a *SessionAuthenticator) JoinServer(ctx context.Context, serverHash string) error {
+	url := a.URL
+	if url == "" {
+		url = DefaultSessionServerURL
+	}
+
+	httpClient := a.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"accessToken":     a.AccessToken,
+		"selectedProfile": strings.ReplaceAll(a.ProfileID.String(), "-", ""),
+		"serverId":        serverHash,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create session request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("session request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	return newSessionError(resp)
+}
+
+func newSessionError(resp *http.Response) error {
+	var payload struct {
+		Error        string `json:"error"`
+		ErrorMessage string `json:"errorMessage"`
+	}
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	_ = json.Unmarshal(data, &payload)
+
+	sessionErr := &SessionError{
+		StatusCode: resp.StatusCode,
+		Type:       payload.Error,
+		Message:    payload.ErrorMessage,
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		sessionErr.Err = ErrRateLimited
+	case resp.StatusCode == http.StatusUnauthorized,
+		strings.Contains(strings.ToLower(payload.ErrorMessage), "invalid token"):
+		sessionErr.Err = ErrBadToken
+	default:
+		sessionErr.Err = ErrInvalidSession
+	}
+
+	return sessionErr
+}
+
+// ServerHash computes the Minecraft-style SHA-1 digest of the login
+// parameters: a signed, two's complement number printed in hex.
+func ServerHash(serverID string, sharedSecret, publicKey []byte) string {
+	hasher := sha1.New()
+	hasher.Write([]byte(serverID))
+	hasher.Write(sharedSecret)
+	hasher.Write(publicKey)
+	hash := hasher.Sum(nil)
+
+	n := new(big.Int).SetBytes(hash)
+	if hash[0]&0x80 != 0 {
+		n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(len(hash)*8)))
+	}
+
+	return n.Text(16)
+}
@@ -0,0 +1,176 @@
+package gophermc_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"github.com/obeliskdev/gophermc"
+	"github.com/obeliskdev/gophermc/protocol"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestServerHash(t *testing.T) {
+	// Reference digests published alongside the session protocol description.
+	cases := map[string]string{
+		"Notch": "4ed1f46bbe04bc756bcb17c0c7ce3e4632f06a48",
+		"jeb_":  "-7c9d5b0044c130109a5d7b5fb5c317c02b4e28c1",
+		"simon": "88e16a1019277b15d58faf0541e11910eb756f6",
+	}
+
+	for input, expected := range cases {
+		if got := gophermc.ServerHash(input, nil, nil); got != expected {
+			t.Errorf("ServerHash(%q) = %s, expected %s", input, got, expected)
+		}
+	}
+}
+
+func TestJoinOnlineMode(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	profileID := uuid.MustParse("069a79f4-44e9-4726-a5be-fca90e38aaf5")
+
+	var received map[string]string
+	sessionServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer sessionServer.Close()
+
+	playerKey := newTestPlayerKey(t)
+	privateDER, _ := x509.MarshalPKCS8PrivateKey(playerKey.PrivateKey)
+	authorization := make(chan string, 1)
+	certificatesServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization <- r.Header.Get("Authorization")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"keyPair": map[string]string{
+				"privateKey": string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: privateDER})),
+				"publicKey":  string(pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: playerKey.PublicKey})),
+			},
+			"publicKeySignature":   base64.StdEncoding.EncodeToString(playerKey.Signature),
+			"publicKeySignatureV2": base64.StdEncoding.EncodeToString(playerKey.SignatureV2),
+			"expiresAt":            playerKey.ExpiresAt.Format(time.RFC3339),
+		})
+	}))
+	defer certificatesServer.Close()
+
+	server := newFakeServer(t, protocol.V1_19_4)
+
+	secretChan := make(chan []byte, 1)
+	errChan := make(chan error, 1)
+	go func() {
+		secret, err := server.encryptedLogin(key, "")
+		secretChan <- secret
+		errChan <- err
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := gophermc.NewClient(
+		gophermc.WithAddr(server.Addr()),
+		gophermc.WithVersion(protocol.V1_19_4),
+		gophermc.WithUsername("Notch"),
+		gophermc.WithUUID(profileID),
+		gophermc.WithAccessToken("token"),
+		gophermc.WithSessionServerURL(sessionServer.URL),
+		gophermc.WithCertificatesURL(certificatesServer.URL),
+	)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.Join(ctx); err != nil {
+		t.Fatalf("Join failed: %v", err)
+	}
+
+	secret := <-secretChan
+	if err := <-errChan; err != nil {
+		t.Fatalf("fake server failed: %v", err)
+	}
+
+	publicKey, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	expectedHash := gophermc.ServerHash("", secret, publicKey)
+
+	if received["serverId"] != expectedHash {
+		t.Fatalf("expected server hash %s, got %s", expectedHash, received["serverId"])
+	}
+	if received["accessToken"] != "token" {
+		t.Fatalf("unexpected access token %q", received["accessToken"])
+	}
+	if received["selectedProfile"] != "069a79f444e94726a5befca90e38aaf5" {
+		t.Fatalf("unexpected selected profile %q", received["selectedProfile"])
+	}
+	if header := <-authorization; header != "Bearer token" {
+		t.Fatalf("unexpected certificates authorization %q", header)
+	}
+}
+
+func TestJoinSessionErrors(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	cases := []struct {
+		name     string
+		status   int
+		body     string
+		expected error
+	}{
+		{"invalid session", http.StatusForbidden, `{"error":"ForbiddenOperationException","errorMessage":"Forbidden"}`, gophermc.ErrInvalidSession},
+		{"bad token", http.StatusForbidden, `{"error":"ForbiddenOperationException","errorMessage":"Invalid token"}`, gophermc.ErrBadToken},
+		{"unauthorized", http.StatusUnauthorized, ``, gophermc.ErrBadToken},
+		{"rate limited", http.StatusTooManyRequests, ``, gophermc.ErrRateLimited},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			sessionServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			defer sessionServer.Close()
+
+			server := newFakeServer(t, protocol.V1_12_2)
+			go func() { _, _ = server.encryptedLogin(key, "") }()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			client, _ := gophermc.NewClient(
+				gophermc.WithAddr(server.Addr()),
+				gophermc.WithVersion(protocol.V1_12_2),
+				gophermc.WithAccessToken("token"),
+				gophermc.WithSessionServerURL(sessionServer.URL),
+			)
+			defer client.Close()
+
+			err := client.Join(ctx)
+			if !errors.Is(err, tc.expected) {
+				t.Fatalf("expected %v, got %v", tc.expected, err)
+			}
+
+			var sessionErr *gophermc.SessionError
+			if !errors.As(err, &sessionErr) || sessionErr.StatusCode != tc.status {
+				t.Fatalf("expected SessionError with status %d, got %v", tc.status, err)
+			}
+		})
+	}
+}
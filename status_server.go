@@ -0,0 +1,237 @@
+package gophermc
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/obeliskdev/gophermc/component"
+	"github.com/obeliskdev/gophermc/protocol"
+)
+
+// StatusRequest describes the client asking for a status response.
+type StatusRequest struct {
+	// Hostname is the address the client connected to, without the
+	// trailing dot or data that mods and proxies append after a NUL.
+	Hostname   string
+	Port       uint16
+	Protocol   int32
+	Version    protocol.Version
+	RemoteAddr net.Addr
+
+	// Handshake is nil for legacy pings, which set LegacyPing instead.
+	Handshake  *protocol.ServerboundHandshake
+	LegacyPing *protocol.LegacyPing
+}
+
+// StatusHandler builds the response for a request. Returning nil closes the
+// connection without an answer.
+type StatusHandler func(*StatusRequest) *protocol.StatusResponse
+
+// StatusServer answers server list pings and turns away logins. It can run
+// on its own or be fed connections from a protocol.Listener.
+type StatusServer struct {
+	Handler StatusHandler
+	// LoginMessage is shown to players trying to join.
+	LoginMessage *component.ChatComponent
+	// Timeout limits the lifetime of each connection, 10 seconds by default.
+	Timeout time.Duration
+	// ProxyPolicy is used by ListenAndServe; Serve uses the listener's.
+	ProxyPolicy protocol.ProxyPolicy
+
+	mu        sync.Mutex
+	listeners map[*protocol.Listener]struct{}
+}
+
+var ErrStatusServerClosed = errors.New("status server closed")
+
+func (s *StatusServer) ListenAndServe(address string) error {
+	l, err := protocol.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	l.ProxyPolicy = s.ProxyPolicy
+	return s.Serve(l)
+}
+
+// Serve accepts connections on l until it fails or Close is called, in
+// which case ErrStatusServerClosed is returned.
+func (s *StatusServer) Serve(l *protocol.Listener) error {
+	s.mu.Lock()
+	if s.listeners == nil {
+		s.listeners = make(map[*protocol.Listener]struct{})
+	}
+	s.listeners[l] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		_, open := s.listeners[l]
+		delete(s.listeners, l)
+		s.mu.Unlock()
+
+		if open {
+			_ = l.Close()
+		}
+	}()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			_, open := s.listeners[l]
+			s.mu.Unlock()
+
+			if !open {
+				return ErrStatusServerClosed
+			}
+			return err
+		}
+
+		go func() { _ = s.ServeConn(conn) }()
+	}
+}
+
+// Close stops every Serve call and closes their listeners. Connections that
+// are being answered are left to finish.
+func (s *StatusServer) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var err error
+	for l := range s.listeners {
+		delete(s.listeners, l)
+		if closeErr := l.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// ServeConn handles a single connection and closes it.
+func (s *StatusServer) ServeConn(conn *protocol.ServerConn) error {
+	defer conn.Close()
+
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+
+	handshake, err := conn.ReadHandshake()
+	if errors.Is(err, protocol.ErrLegacyPing) {
+		return s.writeLegacyStatus(conn)
+	}
+	if conn.State() == protocol.StateLogin {
+		message := component.Text("This server only answers status requests")
+		if s.LoginMessage != nil {
+			message = *s.LoginMessage
+		}
+		return conn.Disconnect(message)
+	}
+	if err != nil {
+		return err
+	}
+
+	request := &StatusRequest{
+		Hostname:   statusHostname(handshake.ServerAddress),
+		Port:       handshake.ServerPort,
+		Protocol:   handshake.ProtocolVersion,
+		Version:    conn.Version(),
+		RemoteAddr: conn.RemoteAddr(),
+		Handshake:  handshake,
+	}
+
+	answered := false
+	for {
+		packet, err := conn.ReadPacket()
+		if err != nil {
+			return err
+		}
+
+		switch p := packet.(type) {
+		case *protocol.ServerboundStatusRequest:
+			if answered {
+				return errors.New("duplicate status request")
+			}
+			answered = true
+
+			if err := s.writeStatus(conn, request); err != nil {
+				return err
+			}
+
+		case *protocol.ServerboundPing:
+			return conn.WritePacket(&protocol.ClientboundPong{Payload: p.Payload})
+
+		default:
+			return fmt.Errorf("unexpected packet %T in status", packet)
+		}
+	}
+}
+
+func (s *StatusServer) writeStatus(conn *protocol.ServerConn, request *StatusRequest) error {
+	if s.Handler == nil {
+		return errors.New("status server has no handler")
+	}
+
+	response := s.Handler(request)
+	if response == nil {
+		return errors.New("status handler declined the request")
+	}
+
+	// Without an explicit version the client is told it is compatible.
+	if response.Version.Name == "" && response.Version.Protocol == 0 {
+		copied := *response
+		copied.Version = protocol.StatusVersion{Name: request.Version.String(), Protocol: request.Version.Protocol()}
+		if _, known := protocol.VersionFromProtocol(request.Protocol); known {
+			copied.Version.Protocol = request.Protocol
+		}
+		response = &copied
+	}
+
+	data, err := response.JSON(request.Version)
+	if err != nil {
+		return fmt.Errorf("encode status response: %w", err)
+	}
+
+	return conn.WritePacket(&protocol.ClientboundStatusResponse{JSONResponse: data})
+}
+
+// writeLegacyStatus answers pre-1.7 clients. Their protocol numbers are not
+// in Versions, so the handler sees Latest with the legacy protocol number.
+func (s *StatusServer) writeLegacyStatus(conn *protocol.ServerConn) error {
+	if s.Handler == nil {
+		return errors.New("status server has no handler")
+	}
+
+	ping := conn.LegacyPing
+	response := s.Handler(&StatusRequest{
+		Hostname:   statusHostname(ping.Hostname),
+		Port:       uint16(ping.Port),
+		Protocol:   int32(ping.Protocol),
+		Version:    protocol.Latest,
+		RemoteAddr: conn.RemoteAddr(),
+		LegacyPing: ping,
+	})
+	if response == nil {
+		return errors.New("status handler declined the request")
+	}
+
+	legacy := response.Legacy()
+	if legacy.Version == "" && legacy.Protocol == 0 {
+		legacy.Version = protocol.Latest.String()
+		legacy.Protocol = int32(ping.Protocol)
+	}
+
+	return conn.WriteLegacyStatus(legacy)
+}
+
+func statusHostname(address string) string {
+	if i := strings.IndexByte(address, 0); i >= 0 {
+		address = address[:i]
+	}
+	return strings.TrimSuffix(address, ".")
+}
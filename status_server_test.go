@@ -0,0 +1,232 @@
+package gophermc_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/obeliskdev/gophermc"
+	"github.com/obeliskdev/gophermc/component"
+	"github.com/obeliskdev/gophermc/protocol"
+)
+
+func startStatusServer(t *testing.T, server *gophermc.StatusServer) string {
+	t.Helper()
+
+	l, err := protocol.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(l) }()
+	t.Cleanup(func() {
+		_ = server.Close()
+		if err := <-done; !errors.Is(err, gophermc.ErrStatusServerClosed) {
+			t.Errorf("Serve returned %v", err)
+		}
+	})
+
+	return l.Addr().String()
+}
+
+func TestStatusServerAllVersions(t *testing.T) {
+	steve := uuid.MustParse("069a79f4-44e9-4726-a5be-fca90e38aaf5")
+	favicon := protocol.FaviconDataURI([]byte("\x89PNG"))
+
+	var mu sync.Mutex
+	requests := make(map[int32]*gophermc.StatusRequest)
+
+	addr := startStatusServer(t, &gophermc.StatusServer{
+		Handler: func(r *gophermc.StatusRequest) *protocol.StatusResponse {
+			mu.Lock()
+			requests[r.Protocol] = r
+			mu.Unlock()
+
+			return &protocol.StatusResponse{
+				Players: protocol.StatusPlayers{
+					Max:    20,
+					Online: 1,
+					Sample: []protocol.StatusPlayer{{Name: "Steve", ID: steve}},
+				},
+				Description:        component.Text("Hello " + r.Hostname).Styled(component.Style{Color: component.Gold}),
+				Favicon:            favicon,
+				EnforcesSecureChat: true,
+			}
+		},
+	})
+
+	for _, v := range protocol.Versions {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		client, err := gophermc.NewClient(
+			gophermc.WithAddr(addr),
+			gophermc.WithServerHostname("lobby.example.com"),
+			gophermc.WithVersion(v),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		status, _, err := client.Status(ctx)
+		_ = client.Close()
+		cancel()
+		if err != nil {
+			t.Errorf("%s: %v", v, err)
+			continue
+		}
+		data := status.Raw
+
+		if status.Version.Protocol != v.Protocol() || status.Description.String() != "Hello lobby.example.com" {
+			t.Errorf("%s: unexpected status %s", v, data)
+		}
+		if status.Favicon != favicon || len(status.Players.Sample) != 1 || status.Players.Sample[0].ID != steve || !status.EnforcesSecureChat {
+			t.Errorf("%s: unexpected status %s", v, data)
+		}
+
+		mu.Lock()
+		request := requests[v.Protocol()]
+		mu.Unlock()
+		if request == nil || request.Port == 0 || request.Version.Protocol() != v.Protocol() {
+			t.Errorf("%s: unexpected request %+v", v, request)
+		}
+	}
+}
+
+func TestStatusServerRejectsLogin(t *testing.T) {
+	message := component.Text("Under maintenance")
+	addr := startStatusServer(t, &gophermc.StatusServer{
+		Handler:      func(*gophermc.StatusRequest) *protocol.StatusResponse { return &protocol.StatusResponse{} },
+		LoginMessage: &message,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, err := gophermc.NewClient(gophermc.WithAddr(addr), gophermc.WithVersion(protocol.V1_20_5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if err := client.Join(ctx); err == nil || !strings.Contains(err.Error(), "Under maintenance") {
+		t.Fatalf("expected the login message, got %v", err)
+	}
+}
+
+func TestStatusServerLegacyPing(t *testing.T) {
+	var mu sync.Mutex
+	var hostnames []string
+
+	addr := startStatusServer(t, &gophermc.StatusServer{
+		Handler: func(r *gophermc.StatusRequest) *protocol.StatusResponse {
+			mu.Lock()
+			hostnames = append(hostnames, r.Hostname)
+			mu.Unlock()
+
+			return &protocol.StatusResponse{
+				Players:     protocol.StatusPlayers{Max: 20, Online: 4},
+				Description: component.Text("Old times").Styled(component.Style{Color: component.Gold}),
+			}
+		},
+	})
+
+	for _, variant := range []protocol.LegacyPingVariant{protocol.LegacyPingBeta, protocol.LegacyPing14, protocol.LegacyPing16} {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		client, err := gophermc.NewClient(gophermc.WithAddr(addr), gophermc.WithServerHostname("old.example.com"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		status, _, err := client.LegacyStatus(ctx, variant)
+		cancel()
+		if err != nil {
+			t.Fatalf("variant %d: %v", variant, err)
+		}
+
+		if status.Players.Online != 4 || status.Players.Max != 20 || status.Description.String() != "Old times" {
+			t.Errorf("variant %d: unexpected status %q", variant, status.Raw)
+		}
+
+		wantProtocol := int32(protocol.LegacyProtocol16)
+		if variant != protocol.LegacyPing16 {
+			wantProtocol = 0
+		}
+		if variant != protocol.LegacyPingBeta && (status.Version.Protocol != wantProtocol || status.Version.Name == "") {
+			t.Errorf("variant %d: unexpected version %+v", variant, status.Version)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(hostnames) != 3 || hostnames[2] != "old.example.com" {
+		t.Errorf("unexpected hostnames %q", hostnames)
+	}
+}
+
+// legacyOnlyServer behaves like a 1.6 server: it kicks anything but a
+// legacy ping with a 0xFF packet.
+func legacyOnlyServer(t *testing.T) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			buf := make([]byte, 512)
+			n, _ := conn.Read(buf)
+
+			reason := "Outdated client! Please use 1.6.4"
+			if n > 0 && buf[0] == 0xFE {
+				reason = protocol.LegacyStatus{Protocol: 78, Version: "1.6.4", MOTD: "§cLegacy", Online: 1, Max: 8}.String(protocol.LegacyPing16)
+			}
+			_ = protocol.WriteLegacyKick(conn, reason)
+			_ = conn.Close()
+		}
+	}()
+
+	return l.Addr().String()
+}
+
+func TestStatusLegacyFallback(t *testing.T) {
+	addr := legacyOnlyServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, err := gophermc.NewClient(gophermc.WithAddr(addr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := client.Status(ctx); !errors.Is(err, gophermc.ErrLegacyServer) {
+		t.Fatalf("expected ErrLegacyServer without fallback, got %v", err)
+	}
+	_ = client.Close()
+
+	client, err = gophermc.NewClient(gophermc.WithAddr(addr), gophermc.WithLegacyPingFallback(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	status, _, err := client.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Version.Name != "1.6.4" || status.Players.Max != 8 || status.Description.String() != "Legacy" {
+		t.Fatalf("unexpected status %+v", status)
+	}
+}